@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SaptuneAnnotationDir defines saptunes directory of the annotations of the
+// applied notes
+const SaptuneAnnotationDir = "/var/lib/saptune/annotation"
+
+// GetPathToAnnotation returns path to the annotation file of a note.
+func (app *App) GetPathToAnnotation(noteID string) string {
+	return path.Join(app.State.StateDirPrefix, SaptuneAnnotationDir, noteID)
+}
+
+// AnnotateNote records the annotation, e.g. the number of the change
+// ticket, with the applied note. The annotation is kept, when the tuning is
+// reverted and applied again by the daemon, and removed, when the note is
+// reverted permanently
+func (app *App) AnnotateNote(noteID, annotation string) error {
+	if app.PositionInNoteApplyOrder(noteID) < 0 {
+		return fmt.Errorf("note %s is not applied", noteID)
+	}
+	if strings.ContainsAny(annotation, "\n\r") {
+		return fmt.Errorf("the annotation of note %s must not contain line breaks", noteID)
+	}
+	if err := os.MkdirAll(path.Join(app.State.StateDirPrefix, SaptuneAnnotationDir), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(app.GetPathToAnnotation(noteID), []byte(annotation+"\n"), 0644)
+}
+
+// NoteAnnotation returns the annotation recorded, when the note was
+// applied. The second return value is false, if the note has no annotation
+func (app *App) NoteAnnotation(noteID string) (string, bool) {
+	content, err := ioutil.ReadFile(app.GetPathToAnnotation(noteID))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(content)), true
+}
+
+// AnnotatedNotes returns the IDs of the notes with annotation
+func (app *App) AnnotatedNotes() []string {
+	notes := make([]string, 0)
+	dirContent, err := ioutil.ReadDir(path.Join(app.State.StateDirPrefix, SaptuneAnnotationDir))
+	if err != nil {
+		return notes
+	}
+	for _, info := range dirContent {
+		notes = append(notes, info.Name())
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// removeAnnotation removes the annotation of a note
+func (app *App) removeAnnotation(noteID string) error {
+	if err := os.Remove(app.GetPathToAnnotation(noteID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
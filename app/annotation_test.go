@@ -0,0 +1,58 @@
+package app
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestAnnotateNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+
+	if err := tuneApp.AnnotateNote("1001", "RITM12345"); err == nil {
+		t.Fatal("expected an error for a not applied note")
+	}
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.AnnotateNote("1001", "RITM12345\nsecond line"); err == nil {
+		t.Fatal("expected an error for an annotation with line break")
+	}
+	if annotation, ok := tuneApp.NoteAnnotation("1001"); ok {
+		t.Fatal(annotation)
+	}
+	if err := tuneApp.AnnotateNote("1001", "RITM12345"); err != nil {
+		t.Fatal(err)
+	}
+	if annotation, ok := tuneApp.NoteAnnotation("1001"); !ok || annotation != "RITM12345" {
+		t.Fatal(annotation, ok)
+	}
+	if notes := tuneApp.AnnotatedNotes(); !reflect.DeepEqual(notes, []string{"1001"}) {
+		t.Fatal(notes)
+	}
+
+	// the revert and apply by the daemon keeps the annotation
+	if err := tuneApp.RevertAll(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.TuneAll(); err != nil {
+		t.Fatal(err)
+	}
+	if annotation, ok := tuneApp.NoteAnnotation("1001"); !ok || annotation != "RITM12345" {
+		t.Fatal(annotation, ok)
+	}
+
+	// the permanent revert removes the annotation
+	if err := tuneApp.RevertNote("1001", true); err != nil {
+		t.Fatal(err)
+	}
+	if annotation, ok := tuneApp.NoteAnnotation("1001"); ok {
+		t.Fatal(annotation)
+	}
+	if notes := tuneApp.AnnotatedNotes(); len(notes) != 0 {
+		t.Fatal(notes)
+	}
+}
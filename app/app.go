@@ -11,27 +11,38 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // define saptunes main configuration file and variables
 const (
-	SysconfigSaptuneFile = "/etc/sysconfig/saptune"
-	TuneForSolutionsKey  = "TUNE_FOR_SOLUTIONS"
-	TuneForNotesKey      = "TUNE_FOR_NOTES"
-	NoteApplyOrderKey    = "NOTE_APPLY_ORDER"
+	SysconfigSaptuneFile  = "/etc/sysconfig/saptune"
+	TuneForSolutionsKey   = "TUNE_FOR_SOLUTIONS"
+	TuneForNotesKey       = "TUNE_FOR_NOTES"
+	NoteApplyOrderKey     = "NOTE_APPLY_ORDER"
+	ExcludedNotesKey      = "SOLUTION_EXCLUDED_NOTES"
+	NoteVersionPinsKey    = "NOTE_VERSION_PINS"
+	NoteVersionPinModeKey = "NOTE_VERSION_PIN_MODE"
+	maxVerifyWorkers      = 8 // maximum number of notes verified at the same time
 )
 
 // App defines the application configuration and serialised state information.
 type App struct {
-	SysconfigPrefix  string
-	AllNotes         map[string]note.Note         // all notes
-	AllSolutions     map[string]solution.Solution // all solutions
-	TuneForSolutions []string                     // list of solution names to tune, must always be sorted in ascending order.
-	TuneForNotes     []string                     // list of additional notes to tune, must always be sorted in ascending order.
-	NoteApplyOrder   []string                     // list of notes in applied order. Do NOT sort.
-	State            *State                       // examine and manage serialised notes.
+	SysconfigPrefix       string
+	AllNotes              map[string]note.Note         // all notes
+	AllSolutions          map[string]solution.Solution // all solutions
+	TuneForSolutions      []string                     // list of solution names to tune, must always be sorted in ascending order.
+	TuneForNotes          []string                     // list of additional notes to tune, must always be sorted in ascending order.
+	NoteApplyOrder        []string                     // list of notes in applied order. Do NOT sort.
+	ExcludedNotes         map[string][]string          // notes of the enabled solutions, which are intentionally not tuned, per solution name
+	IgnoreBounds          bool                         // apply parameter values outside of the bounds of the Note definitions
+	NoteVersionPins       map[string]string            // the versions of the Note definitions the notes are pinned to, per Note ID
+	RefuseVersionMismatch bool                         // refuse to apply or verify a note, whose version differs from the pinned version
+	State                 *State                       // examine and manage serialised notes.
 }
 
 // InitialiseApp load application configuration. Panic on error.
@@ -47,10 +58,15 @@ func InitialiseApp(sysconfigPrefix, stateDirPrefix string, allNotes map[string]n
 		app.TuneForSolutions = sysconf.GetStringArray(TuneForSolutionsKey, []string{})
 		app.TuneForNotes = sysconf.GetStringArray(TuneForNotesKey, []string{})
 		app.NoteApplyOrder = sysconf.GetStringArray(NoteApplyOrderKey, []string{})
+		app.ExcludedNotes = parseExcludedNotes(sysconf.GetStringArray(ExcludedNotesKey, []string{}))
+		app.NoteVersionPins = parseNoteVersionPins(sysconf.GetStringArray(NoteVersionPinsKey, []string{}))
+		app.RefuseVersionMismatch = parseNoteVersionPinMode(sysconf.GetString(NoteVersionPinModeKey, "warn"))
 	} else {
 		app.TuneForSolutions = []string{}
 		app.TuneForNotes = []string{}
 		app.NoteApplyOrder = []string{}
+		app.ExcludedNotes = make(map[string][]string)
+		app.NoteVersionPins = make(map[string]string)
 	}
 	sort.Strings(app.TuneForSolutions)
 	sort.Strings(app.TuneForNotes)
@@ -76,6 +92,209 @@ func (app *App) PositionInNoteApplyOrder(noteID string) int {
 	return -1 //not found
 }
 
+// MoveNoteInApplyOrder moves the note noteID directly 'before' or 'after'
+// the note refNoteID in the list of applied notes and saves the new order.
+// The move is rejected, if the notes of an enabled solution would no longer
+// be applied in the order defined by the solution.
+func (app *App) MoveNoteInApplyOrder(noteID, where, refNoteID string) error {
+	if where != "before" && where != "after" {
+		return fmt.Errorf("wrong position '%s', use 'before' or 'after'", where)
+	}
+	if noteID == refNoteID {
+		return fmt.Errorf("Note %s can not be moved relative to itself", noteID)
+	}
+	for _, id := range []string{noteID, refNoteID} {
+		if app.PositionInNoteApplyOrder(id) < 0 {
+			return fmt.Errorf("Note %s is not part of the current order of applied notes", id)
+		}
+	}
+	newOrder := make([]string, 0, len(app.NoteApplyOrder))
+	for _, id := range app.NoteApplyOrder {
+		switch id {
+		case noteID:
+			continue
+		case refNoteID:
+			if where == "before" {
+				newOrder = append(newOrder, noteID, refNoteID)
+			} else {
+				newOrder = append(newOrder, refNoteID, noteID)
+			}
+		default:
+			newOrder = append(newOrder, id)
+		}
+	}
+	if err := app.checkNoteApplyOrder(newOrder); err != nil {
+		return err
+	}
+	app.NoteApplyOrder = newOrder
+	return app.SaveConfig()
+}
+
+// checkNoteApplyOrder checks, if the given order of applied notes contains
+// every note only once and if the notes of all enabled solutions are applied
+// in the order defined by the solution
+func (app *App) checkNoteApplyOrder(order []string) error {
+	position := make(map[string]int)
+	for cnt, noteID := range order {
+		if _, ok := position[noteID]; ok {
+			return fmt.Errorf("Note %s is listed more than once in the order of applied notes", noteID)
+		}
+		position[noteID] = cnt
+	}
+	for _, solName := range app.TuneForSolutions {
+		sol, err := app.GetSolutionNotes(solName)
+		if err != nil {
+			return err
+		}
+		prevNote := ""
+		for _, noteID := range sol {
+			pos, ok := position[noteID]
+			if !ok {
+				continue
+			}
+			if prevNote != "" && pos < position[prevNote] {
+				return fmt.Errorf("Note %s needs to be applied after Note %s as required by the enabled solution %s", noteID, prevNote, solName)
+			}
+			prevNote = noteID
+		}
+	}
+	for _, noteID := range order {
+		for _, reqID := range app.noteRequires(noteID) {
+			if pos, ok := position[reqID]; ok && pos > position[noteID] {
+				return fmt.Errorf("Note %s needs to be applied after Note %s as it requires this Note", noteID, reqID)
+			}
+		}
+	}
+	return nil
+}
+
+// noteRequires returns the IDs of the notes, which need to be applied
+// before the given note as listed in the [requires] section of the Note
+// definition file
+func (app *App) noteRequires(noteID string) []string {
+	if iniNote, ok := app.AllNotes[noteID].(note.INISettings); ok {
+		return iniNote.Requires()
+	}
+	return []string{}
+}
+
+// resolveNoteApplyOrder sorts the given order of applied notes, so that
+// every note is applied after the notes it requires. Required notes, which
+// are not part of the order, are ignored. Apart from that the given order
+// is kept. Returns an error, if the requirements of the notes are cyclic
+func (app *App) resolveNoteApplyOrder(order []string) ([]string, error) {
+	resolved := make([]string, 0, len(order))
+	inOrder := make(map[string]bool)
+	for _, noteID := range order {
+		inOrder[noteID] = true
+	}
+	err := app.walkNoteRequirements(order, func(noteID string) bool { return inOrder[noteID] }, func(noteID string) {
+		resolved = append(resolved, noteID)
+	})
+	return resolved, err
+}
+
+// orderNotes sorts the given order of applied notes like
+// resolveNoteApplyOrder, but does not fail for notes with cyclic
+// requirements. These notes are appended to the resolved order and their
+// errors are returned per note, so that only these notes can be skipped
+func (app *App) orderNotes(order []string) ([]string, map[string]error) {
+	resolved := make([]string, 0, len(order))
+	cyclic := make(map[string]error)
+	inOrder := make(map[string]bool)
+	for _, noteID := range order {
+		inOrder[noteID] = true
+	}
+	seen := make(map[string]bool)
+	for _, noteID := range order {
+		noteOrder := make([]string, 0)
+		err := app.walkNoteRequirements([]string{noteID}, func(reqID string) bool { return inOrder[reqID] }, func(reqID string) {
+			noteOrder = append(noteOrder, reqID)
+		})
+		if err != nil {
+			cyclic[noteID] = err
+			continue
+		}
+		for _, reqID := range noteOrder {
+			if !seen[reqID] {
+				seen[reqID] = true
+				resolved = append(resolved, reqID)
+			}
+		}
+	}
+	for _, noteID := range order {
+		if _, ok := cyclic[noteID]; ok {
+			resolved = append(resolved, noteID)
+		}
+	}
+	return resolved, cyclic
+}
+
+// DaemonApplyOrder returns the enabled notes in the order, in which they are
+// applied by 'saptune daemon apply' - every note after the notes it
+// requires. Returns an error, if the requirements of the notes are cyclic
+func (app *App) DaemonApplyOrder() ([]string, error) {
+	return app.resolveNoteApplyOrder(app.NoteApplyOrder)
+}
+
+// UnmetRequirements returns the IDs of the notes, which are required
+// directly or indirectly by the given note, but are not enabled yet.
+// The notes are returned in the order they need to be applied.
+// Returns an error, if the requirements of the notes are cyclic
+func (app *App) UnmetRequirements(noteID string) ([]string, error) {
+	unmet := make([]string, 0)
+	err := app.walkNoteRequirements([]string{noteID}, func(string) bool { return true }, func(reqID string) {
+		if reqID != noteID && app.PositionInNoteApplyOrder(reqID) < 0 {
+			unmet = append(unmet, reqID)
+		}
+	})
+	return unmet, err
+}
+
+// walkNoteRequirements visits the given notes and the notes they require,
+// if follow returns true for them, in an order, that every note is visited
+// after the notes it requires. Every note is visited only once
+func (app *App) walkNoteRequirements(noteIDs []string, follow func(string) bool, visit func(string)) error {
+	const (
+		inProgress = 1
+		done       = 2
+	)
+	state := make(map[string]int)
+	var walk func(noteID string, chain []string) error
+	walk = func(noteID string, chain []string) error {
+		switch state[noteID] {
+		case done:
+			return nil
+		case inProgress:
+			for cnt, chainID := range chain {
+				if chainID == noteID {
+					chain = chain[cnt:]
+					break
+				}
+			}
+			return fmt.Errorf("the notes have cyclic requirements: %s -> %s", strings.Join(chain, " -> "), noteID)
+		}
+		state[noteID] = inProgress
+		for _, reqID := range app.noteRequires(noteID) {
+			if !follow(reqID) {
+				continue
+			}
+			if err := walk(reqID, append(chain, noteID)); err != nil {
+				return err
+			}
+		}
+		state[noteID] = done
+		visit(noteID)
+		return nil
+	}
+	for _, noteID := range noteIDs {
+		if err := walk(noteID, []string{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SaveConfig save configuration to file /etc/sysconfig/saptune.
 func (app *App) SaveConfig() error {
 	sysconf, err := txtparser.ParseSysconfigFile(path.Join(app.SysconfigPrefix, SysconfigSaptuneFile), true)
@@ -85,6 +304,10 @@ func (app *App) SaveConfig() error {
 	sysconf.SetStrArray(TuneForSolutionsKey, app.TuneForSolutions)
 	sysconf.SetStrArray(TuneForNotesKey, app.TuneForNotes)
 	sysconf.SetStrArray(NoteApplyOrderKey, app.NoteApplyOrder)
+	if _, exists := sysconf.KeyValue[ExcludedNotesKey]; exists || len(app.ExcludedNotes) != 0 {
+		// only written, if a solution was applied partially
+		sysconf.SetStrArray(ExcludedNotesKey, app.excludedNotesEntries())
+	}
 	return ioutil.WriteFile(path.Join(app.SysconfigPrefix, SysconfigSaptuneFile), []byte(sysconf.ToText()), 0644)
 }
 
@@ -94,6 +317,9 @@ func (app *App) GetSortedSolutionEnabledNotes() (allNoteIDs []string) {
 	allNoteIDs = make([]string, 0, 0)
 	for _, sol := range app.TuneForSolutions {
 		for _, noteID := range app.AllSolutions[sol] {
+			if app.IsNoteExcluded(sol, noteID) {
+				continue
+			}
 			if i := sort.SearchStrings(allNoteIDs, noteID); !(i < len(allNoteIDs) && allNoteIDs[i] == noteID) {
 				allNoteIDs = append(allNoteIDs, noteID)
 				sort.Strings(allNoteIDs)
@@ -107,6 +333,25 @@ func (app *App) GetSortedSolutionEnabledNotes() (allNoteIDs []string) {
 // if the note does not exist.
 func (app *App) GetNoteByID(id string) (note.Note, error) {
 	if n, exists := app.AllNotes[id]; exists {
+		if iniNote, ok := n.(note.INISettings); ok {
+			if iniNote.IncludeFiles == nil {
+				// resolve the included Note definition files
+				// when the note is used. AllNotes is not
+				// changed, as notes are verified in parallel
+				includeFiles, err := note.ResolveIncludes(id, app.AllNotes)
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve the includes of Note %s - %v", id, err)
+				}
+				iniNote.IncludeFiles = includeFiles
+			}
+			// the solution specific override files of the
+			// enabled solutions take precedence
+			iniNote.Solutions = app.enabledSolutionsOfNote(id)
+			// only the selected parameters of a partially applied
+			// note are tuned
+			iniNote.OnlyParams, _ = app.NoteParamSelection(id)
+			n = iniNote
+		}
 		return n, nil
 	}
 	return nil, fmt.Errorf(`the Note ID "%s" is not recognised by saptune.
@@ -114,6 +359,21 @@ Run "saptune note list" for a complete list of supported notes.
 and then please double check your input and /etc/sysconfig/saptune`, id)
 }
 
+// enabledSolutionsOfNote returns the sorted names of the enabled solutions,
+// which contain the note
+func (app *App) enabledSolutionsOfNote(noteID string) []string {
+	var solNames []string
+	for _, solName := range app.TuneForSolutions {
+		for _, solNote := range app.AllSolutions[solName] {
+			if solNote == noteID && !app.IsNoteExcluded(solName, noteID) {
+				solNames = append(solNames, solName)
+				break
+			}
+		}
+	}
+	return solNames
+}
+
 // GetSolutionByName return the solution corresponding to the name,
 // or an error if it does not exist.
 func (app *App) GetSolutionByName(name string) (solution.Solution, error) {
@@ -128,41 +388,56 @@ and then please double check your input and /etc/sysconfig/saptune`, name)
 // TuneNote apply tuning for a note.
 // If the note is not yet covered by one of the enabled solutions,
 // the note number will be added into the list of additional notes.
+// The note is refused, if a parameter value to apply is outside of the
+// bounds of the Note definition, unless IgnoreBounds is set.
 func (app *App) TuneNote(noteID string) error {
+	return app.tuneNote(noteID, !app.IgnoreBounds)
+}
+
+// tuneNote apply tuning for a note. If checkBounds is set, the parameter
+// values to apply are checked against the bounds of the Note definition
+// before anything is changed
+func (app *App) tuneNote(noteID string, checkBounds bool) error {
 	forceApply := false
 	aNote, err := app.GetNoteByID(noteID)
 	if err != nil {
 		return err
 	}
-	solNotes := app.GetSortedSolutionEnabledNotes()
-	searchInSol := sort.SearchStrings(solNotes, noteID)
-	searchInNote := sort.SearchStrings(app.TuneForNotes, noteID)
-	if !(searchInSol < len(solNotes) && solNotes[searchInSol] == noteID) && !(searchInNote < len(app.TuneForNotes) && app.TuneForNotes[searchInNote] == noteID) {
-		// Note is not covered by any of the existing solution, hence adding it into the additions' list
-		app.TuneForNotes = append(app.TuneForNotes, noteID)
-		sort.Strings(app.TuneForNotes)
-	}
-	// to prevent double noteIDs in the apply order list
-	i := app.PositionInNoteApplyOrder(noteID)
-	if i < 0 { // noteID not yet available
-		app.NoteApplyOrder = append(app.NoteApplyOrder, noteID)
-	}
-	if err := app.SaveConfig(); err != nil {
-		return err
-	}
 
 	// check, if system already complies with the requirements.
 	// set values for later use
-	conforming, _, valApplyList, err := app.VerifyNote(noteID)
+	conforming, comparisons, valApplyList, err := app.VerifyNote(noteID)
 	if err != nil {
 		return err
 	}
+	if checkBounds {
+		if err := checkNoteBounds(noteID, aNote, comparisons, valApplyList); err != nil {
+			return err
+		}
+	}
+	if err := app.EnableNote(noteID); err != nil {
+		return err
+	}
+	if iniNote, ok := aNote.(note.INISettings); ok && iniNote.CheckOnly() {
+		// the parameter values of a 'check only' note are only
+		// verified, but never set
+		system.InfoLog("Note '%s' is marked as 'check only'. Its parameter values are verified, but NOT set.", noteID)
+		return nil
+	}
 
 	// Save current state for the Note in any case
 	currentState, err := aNote.Initialise()
 	if err != nil {
 		return fmt.Errorf("Failed to examine system for the current status of note %s - %v", noteID, err)
 	}
+	if iniState, ok := currentState.(note.INISettings); ok && len(iniState.OnlyParams) != 0 {
+		// record only the parameters tuned by a partially applied note
+		for key := range iniState.SysctlParams {
+			if !iniState.IsManaged(key) {
+				delete(iniState.SysctlParams, key)
+			}
+		}
+	}
 	if reflect.TypeOf(currentState).String() == "note.INISettings" {
 		// in case of vm.dirty parameters save additionally the
 		// counterpart values to be able to revert the values
@@ -191,6 +466,17 @@ func (app *App) TuneNote(noteID string) error {
 			}
 		}
 	}
+	if iniState, ok := currentState.(note.INISettings); ok {
+		// remember the Note definition to detect later changes, e.g.
+		// by a package update
+		iniState.DefinitionHash = iniState.GetDefinitionHash()
+		currentState = iniState
+	}
+	// record the start of the apply before anything is changed, so that
+	// an interrupted apply can be detected
+	if err = app.startJournal(noteID); err != nil {
+		return fmt.Errorf("Failed to write the journal of note %s - %v", noteID, err)
+	}
 	if err = app.State.Store(noteID, currentState, false); err != nil {
 		return fmt.Errorf("Failed to save current state of note %s - %v", noteID, err)
 	}
@@ -206,13 +492,141 @@ func (app *App) TuneNote(noteID string) error {
 	if conforming && !forceApply {
 		// Do not apply the Note, if the system already complies with
 		// the requirements.
-		return nil
+		system.EventLog("apply", noteID, "Note '%s' applied, the system already complies with its requirements", noteID)
+		return app.finishJournal(noteID)
 	}
 	if err := optimised.Apply(); err != nil {
 		return fmt.Errorf("Failed to apply note %s - %v", noteID, err)
 	}
+	system.EventLog("apply", noteID, "Note '%s' applied", noteID)
 
-	return nil
+	return app.finishJournal(noteID)
+}
+
+// checkNoteBounds returns an error listing the parameters, whose values to
+// apply are outside of the bounds of the Note definition
+func checkNoteBounds(noteID string, aNote note.Note, comparisons map[string]note.FieldComparison, valApplyList []string) error {
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok || iniNote.CheckOnly() {
+		// nothing is set for a 'check only' note
+		return nil
+	}
+	values := make(map[string]string)
+	for _, key := range valApplyList {
+		if comparison, ok := comparisons[fmt.Sprintf("SysctlParams[%s]", key)]; ok {
+			values[key], _ = comparison.ExpectedValue.(string)
+		}
+	}
+	violations, err := iniNote.OutOfBounds(values)
+	if err != nil || len(violations) == 0 {
+		return err
+	}
+	msgs := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		msgs = append(msgs, violation.String())
+	}
+	return &boundsError{noteID: noteID, violations: msgs}
+}
+
+// boundsError lists the parameters of a note, whose values to apply are
+// outside of the bounds of the Note definition
+type boundsError struct {
+	noteID     string
+	violations []string
+}
+
+func (err *boundsError) Error() string {
+	return fmt.Sprintf("refuse to apply note %s, the values of the following parameters are outside of the bounds defined by the note:\n\t%s\nUse '--ignore-bounds' to apply the values nevertheless", err.noteID, strings.Join(err.violations, "\n\t"))
+}
+
+// EnableNote enables a note without applying it.
+// If the note is not yet covered by one of the enabled solutions,
+// the note number will be added into the list of additional notes.
+// The note is appended to the apply order, so it will be applied by the
+// next call of TuneAll.
+func (app *App) EnableNote(noteID string) error {
+	if _, err := app.GetNoteByID(noteID); err != nil {
+		return err
+	}
+	// the note needs to be applied after the notes it requires
+	order := make([]string, 0, len(app.NoteApplyOrder)+1)
+	order = append(order, app.NoteApplyOrder...)
+	if app.PositionInNoteApplyOrder(noteID) < 0 {
+		order = append(order, noteID)
+	}
+	// notes with cyclic requirements, which are already enabled, do
+	// not block the enabling of other notes
+	order, cyclic := app.orderNotes(order)
+	if err := cyclic[noteID]; err != nil {
+		return err
+	}
+	solNotes := app.GetSortedSolutionEnabledNotes()
+	searchInSol := sort.SearchStrings(solNotes, noteID)
+	searchInNote := sort.SearchStrings(app.TuneForNotes, noteID)
+	if !(searchInSol < len(solNotes) && solNotes[searchInSol] == noteID) && !(searchInNote < len(app.TuneForNotes) && app.TuneForNotes[searchInNote] == noteID) {
+		// Note is not covered by any of the existing solution, hence adding it into the additions' list
+		app.TuneForNotes = append(app.TuneForNotes, noteID)
+		sort.Strings(app.TuneForNotes)
+	}
+	app.NoteApplyOrder = order
+	return app.SaveConfig()
+}
+
+// DisableNote removes a note, which is enabled, but not yet applied, from
+// the list of additional notes and from the apply order.
+// An already applied note needs to be reverted instead.
+func (app *App) DisableNote(noteID string) error {
+	if _, err := app.GetNoteByID(noteID); err != nil {
+		return err
+	}
+	if app.IsNoteApplied(noteID) {
+		return fmt.Errorf("note %s is already applied, please revert it instead", noteID)
+	}
+	solNotes := app.GetSortedSolutionEnabledNotes()
+	if i := sort.SearchStrings(solNotes, noteID); i < len(solNotes) && solNotes[i] == noteID {
+		return fmt.Errorf("note %s is enabled by a solution and can not be disabled separately", noteID)
+	}
+	i := app.PositionInNoteApplyOrder(noteID)
+	if i < 0 {
+		return fmt.Errorf("note %s is not enabled", noteID)
+	}
+	app.NoteApplyOrder = append(app.NoteApplyOrder[0:i], app.NoteApplyOrder[i+1:]...)
+	if i := sort.SearchStrings(app.TuneForNotes, noteID); i < len(app.TuneForNotes) && app.TuneForNotes[i] == noteID {
+		app.TuneForNotes = append(app.TuneForNotes[0:i], app.TuneForNotes[i+1:]...)
+	}
+	return app.SaveConfig()
+}
+
+// ChangedNoteDefinitions returns the IDs of the applied notes, whose Note
+// definition file has changed since the note was applied
+func (app *App) ChangedNoteDefinitions() []string {
+	changed := make([]string, 0)
+	appliedNotes, err := app.State.List()
+	if err != nil {
+		return changed
+	}
+	for _, noteID := range appliedNotes {
+		iniNote, ok := app.AllNotes[noteID].(note.INISettings)
+		if !ok {
+			continue
+		}
+		var stored note.INISettings
+		if err := app.State.Retrieve(noteID, &stored); err != nil || stored.DefinitionHash == "" {
+			// state saved by an older version of saptune
+			continue
+		}
+		if stored.DefinitionHash != iniNote.GetDefinitionHash() {
+			changed = append(changed, noteID)
+		}
+	}
+	return changed
+}
+
+// IsNoteApplied returns true, if a saved state exists for the note, which
+// means that the note was applied to the system
+func (app *App) IsNoteApplied(noteID string) bool {
+	_, err := os.Stat(app.State.GetPathToNote(noteID))
+	return err == nil
 }
 
 // TuneSolution apply tuning for a solution.
@@ -220,19 +634,46 @@ func (app *App) TuneNote(noteID string) error {
 // of tuned solution names.
 // If the solution covers any of the additional notes, those notes will be removed.
 func (app *App) TuneSolution(solName string) (removedExplicitNotes []string, err error) {
+	return app.TuneSolutionExcept(solName, nil)
+}
+
+// TuneSolutionExcept apply tuning for a solution without the notes listed
+// in excluded. The excluded notes are recorded for the solution, so they
+// are neither tuned nor expected by verify as long as the solution is
+// enabled. Excluded notes, which are enabled additionally, stay in the
+// list of additional notes.
+func (app *App) TuneSolutionExcept(solName string, excluded []string) (removedExplicitNotes []string, err error) {
 	removedExplicitNotes = make([]string, 0, 0)
 	sol, err := app.GetSolutionByName(solName)
 	if err != nil {
 		return
 	}
+	for _, noteID := range excluded {
+		if !isInSolution(sol, noteID) {
+			err = fmt.Errorf("Note %s is not part of the solution %s", noteID, solName)
+			return
+		}
+	}
 	if i := sort.SearchStrings(app.TuneForSolutions, solName); !(i < len(app.TuneForSolutions) && app.TuneForSolutions[i] == solName) {
 		app.TuneForSolutions = append(app.TuneForSolutions, solName)
 		sort.Strings(app.TuneForSolutions)
-		if err = app.SaveConfig(); err != nil {
-			return
+	}
+	if len(excluded) != 0 {
+		if app.ExcludedNotes == nil {
+			app.ExcludedNotes = make(map[string][]string)
 		}
+		app.ExcludedNotes[solName] = append([]string{}, excluded...)
+		sort.Strings(app.ExcludedNotes[solName])
+	} else {
+		delete(app.ExcludedNotes, solName)
+	}
+	if err = app.SaveConfig(); err != nil {
+		return
 	}
 	for _, noteID := range sol {
+		if app.IsNoteExcluded(solName, noteID) {
+			continue
+		}
 		// Remove solution's notes from additional notes list.
 		if i := sort.SearchStrings(app.TuneForNotes, noteID); i < len(app.TuneForNotes) && app.TuneForNotes[i] == noteID {
 			app.TuneForNotes = append(app.TuneForNotes[0:i], app.TuneForNotes[i+1:]...)
@@ -248,14 +689,127 @@ func (app *App) TuneSolution(solName string) (removedExplicitNotes []string, err
 	return
 }
 
+// SolutionNotesToExclude returns the notes of the solution, which are
+// excluded, if only the notes listed in only or all notes except the
+// notes listed in except are tuned. Only one of both lists can be used.
+func (app *App) SolutionNotesToExclude(solName string, only, except []string) ([]string, error) {
+	sol, err := app.GetSolutionByName(solName)
+	if err != nil {
+		return nil, err
+	}
+	if len(only) != 0 && len(except) != 0 {
+		return nil, fmt.Errorf("'--only' and '--except' can not be used together")
+	}
+	for _, noteID := range append(append([]string{}, only...), except...) {
+		if !isInSolution(sol, noteID) {
+			return nil, fmt.Errorf("Note %s is not part of the solution %s", noteID, solName)
+		}
+	}
+	if len(only) == 0 {
+		return append([]string{}, except...), nil
+	}
+	excluded := make([]string, 0, len(sol))
+	for _, noteID := range sol {
+		if !isInSolution(only, noteID) {
+			excluded = append(excluded, noteID)
+		}
+	}
+	return excluded, nil
+}
+
+// GetSolutionNotes returns the notes of the solution in the order of the
+// solution without the notes excluded from the enabled solution.
+func (app *App) GetSolutionNotes(solName string) ([]string, error) {
+	sol, err := app.GetSolutionByName(solName)
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]string, 0, len(sol))
+	for _, noteID := range sol {
+		if !app.IsNoteExcluded(solName, noteID) {
+			notes = append(notes, noteID)
+		}
+	}
+	return notes, nil
+}
+
+// IsNoteExcluded returns true, if the note is intentionally not tuned for
+// the enabled solution.
+func (app *App) IsNoteExcluded(solName, noteID string) bool {
+	return isInSolution(app.ExcludedNotes[solName], noteID)
+}
+
+// isInSolution returns true, if the note is part of the list of notes
+func isInSolution(noteIDs []string, noteID string) bool {
+	for _, id := range noteIDs {
+		if id == noteID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExcludedNotes converts the entries 'SolutionName:NoteID' of the
+// configuration into the excluded notes per solution
+func parseExcludedNotes(entries []string) map[string][]string {
+	excluded := make(map[string][]string)
+	for _, entry := range entries {
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			system.WarningLog("skip malformed entry '%s' of %s, expected 'SolutionName:NoteID'", entry, ExcludedNotesKey)
+			continue
+		}
+		excluded[fields[0]] = append(excluded[fields[0]], fields[1])
+	}
+	return excluded
+}
+
+// excludedNotesEntries returns the excluded notes of the solutions as
+// sorted entries 'SolutionName:NoteID' for the configuration
+func (app *App) excludedNotesEntries() []string {
+	entries := make([]string, 0, len(app.ExcludedNotes))
+	for solName, noteIDs := range app.ExcludedNotes {
+		for _, noteID := range noteIDs {
+			entries = append(entries, solName+":"+noteID)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
 // TuneAll tune for all currently enabled solutions and notes.
 func (app *App) TuneAll() error {
-	for _, noteID := range app.NoteApplyOrder {
+	// revert the temporarily applied notes, which expired while the
+	// system was down
+	if err := app.handleTemporaryNotes(time.Now()); err != nil {
+		return err
+	}
+	// apply the notes after the notes they require. Notes with cyclic
+	// requirements can not be ordered, so only these notes are skipped
+	order, cyclic := app.orderNotes(app.NoteApplyOrder)
+	if !reflect.DeepEqual(order, app.NoteApplyOrder) {
+		app.NoteApplyOrder = order
+		if err := app.SaveConfig(); err != nil {
+			return err
+		}
+	}
+	for _, noteID := range order {
+		if err := cyclic[noteID]; err != nil {
+			_ = system.ErrorLog("skip Note %s - %v", noteID, err)
+			continue
+		}
 		if _, err := app.GetNoteByID(noteID); err != nil {
-			_ = system.ErrorLog(err.Error())
+			_ = system.ErrorLog("%v", err)
 			continue
 		}
-		if err := app.TuneNote(noteID); err != nil {
+		// a note with values outside of its bounds, e.g. because
+		// of a changed override file, is skipped, so the tuning
+		// of the other notes at boot time is not blocked
+		if err := app.tuneNote(noteID, !app.IgnoreBounds); err != nil {
+			if _, ok := err.(*boundsError); ok {
+				_ = system.ErrorLog("skip Note %s - %v", noteID, err)
+				continue
+			}
 			return err
 		}
 	}
@@ -305,13 +859,197 @@ func (app *App) RevertNote(noteID string, permanent bool) error {
 	} else if !os.IsNotExist(err) {
 		return err
 	}
-	return nil
+	if permanent {
+		// a temporarily applied note is no longer temporary
+		if err := app.removeTTL(noteID); err != nil {
+			return err
+		}
+		// the annotation needs to be given again, if the note is
+		// applied again
+		if err := app.removeAnnotation(noteID); err != nil {
+			return err
+		}
+		// a later apply tunes all parameters of the note again
+		if err := app.removeParamSelection(noteID); err != nil {
+			return err
+		}
+		// a Note definition read from stdin is only kept as long as
+		// the note is applied
+		if app.IsTransientNote(noteID) {
+			if err := app.removeTransientNote(noteID); err != nil {
+				return err
+			}
+			delete(app.AllNotes, noteID)
+		}
+	}
+	system.EventLog("revert", noteID, "Note '%s' reverted", noteID)
+	// an interrupted apply of the note is finished by the revert
+	return app.finishJournal(noteID)
+}
+
+// RevertNoteParameter reverts a single parameter tuned by the note to the
+// value it had before the note was applied and removes the parameter from
+// the stored state of the note. The note itself stays enabled.
+func (app *App) RevertNoteParameter(noteID, paramName string) error {
+	noteTemplate, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if _, ok := noteTemplate.(note.INISettings); !ok {
+		return fmt.Errorf("reverting a single parameter is not supported for note %s", noteID)
+	}
+	var noteRecovered note.INISettings
+	if err := app.State.Retrieve(noteID, &noteRecovered); os.IsNotExist(err) {
+		return fmt.Errorf("note %s is not applied, so there is nothing to revert", noteID)
+	} else if err != nil {
+		return err
+	}
+	if _, ok := noteRecovered.SysctlParams[paramName]; !ok {
+		return fmt.Errorf("parameter '%s' is not part of the saved state of note %s", paramName, noteID)
+	}
+	if err := noteRecovered.SetValuesToApply([]string{"revert", paramName}).Apply(); err != nil {
+		return err
+	}
+	// rewrite the state file without the reverted parameter
+	delete(noteRecovered.SysctlParams, paramName)
+	return app.State.Store(noteID, noteRecovered, true)
+}
+
+// RevertNoteToDefault permanently reverts the note like RevertNote, but
+// afterwards sets the sysctl parameters tuned by the note to their default
+// values instead of the values saved before the note was applied.
+// Parameters still tuned by another applied note and sysctl parameters
+// without known default value keep the saved value, as do all parameters
+// of the other sections.
+// It returns the sysctl parameters set to their default values and those,
+// which keep the saved value.
+func (app *App) RevertNoteToDefault(noteID string) ([]string, []string, error) {
+	noteTemplate, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	iniNote, ok := noteTemplate.(note.INISettings)
+	if !ok {
+		return nil, nil, fmt.Errorf("reverting to the default values is not supported for note %s", noteID)
+	}
+	var noteRecovered note.INISettings
+	if err := app.State.Retrieve(noteID, &noteRecovered); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("note %s is not applied, so there is nothing to revert", noteID)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	ini, err := iniNote.ParseDefinition()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := app.RevertNote(noteID, true); err != nil {
+		return nil, nil, err
+	}
+	defaults := make([]string, 0)
+	saved := make([]string, 0)
+	for _, param := range ini.AllValues {
+		if param.Section != note.INISectionSysctl {
+			continue
+		}
+		if _, ok := noteRecovered.SysctlParams[param.Key]; !ok {
+			continue
+		}
+		if !note.IsLastNoteOfParameter(param.Key) {
+			// another applied note still tunes the parameter
+			saved = append(saved, param.Key)
+			continue
+		}
+		set, err := system.SetSysctlDefault(param.Key)
+		if err != nil {
+			return defaults, saved, err
+		}
+		if set {
+			defaults = append(defaults, param.Key)
+		} else {
+			saved = append(saved, param.Key)
+		}
+	}
+	return defaults, saved, nil
+}
+
+// NotesWithTag returns the enabled notes carrying the given tag in the
+// order they are applied. Tags are only supported by notes based on a Note
+// definition file.
+func (app *App) NotesWithTag(tag string) []string {
+	noteIDs := make([]string, 0)
+	for _, noteID := range app.NoteApplyOrder {
+		aNote, err := app.GetNoteByID(noteID)
+		if err != nil {
+			continue
+		}
+		iniNote, ok := aNote.(note.INISettings)
+		if !ok {
+			continue
+		}
+		for _, noteTag := range iniNote.Tags() {
+			if noteTag == tag {
+				noteIDs = append(noteIDs, noteID)
+				break
+			}
+		}
+	}
+	return noteIDs
+}
+
+// RevertTag permanently reverts all enabled notes carrying the given tag.
+// The notes are reverted in reverse apply order. It returns the reverted
+// notes.
+func (app *App) RevertTag(tag string) ([]string, error) {
+	noteIDs := app.NotesWithTag(tag)
+	reverted := make([]string, 0, len(noteIDs))
+	for i := len(noteIDs) - 1; i >= 0; i-- {
+		if err := app.RevertNote(noteIDs[i], true); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, noteIDs[i])
+	}
+	return reverted, nil
+}
+
+// RevertNotesKeepSolutions permanently reverts the manually enabled notes,
+// which are not part of an enabled solution. The enabled solutions and their
+// notes stay applied. The notes are reverted in reverse apply order.
+// It returns the reverted notes and the preserved notes in apply order.
+func (app *App) RevertNotesKeepSolutions() ([]string, []string, error) {
+	solNotes := make(map[string]struct{})
+	for _, solName := range app.TuneForSolutions {
+		sol, err := app.GetSolutionNotes(solName)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, noteID := range sol {
+			solNotes[noteID] = struct{}{}
+		}
+	}
+	reverted := make([]string, 0, len(app.TuneForNotes))
+	preserved := make([]string, 0, len(app.NoteApplyOrder))
+	applyOrder := append([]string{}, app.NoteApplyOrder...)
+	for i := len(applyOrder) - 1; i >= 0; i-- {
+		noteID := applyOrder[i]
+		j := sort.SearchStrings(app.TuneForNotes, noteID)
+		manual := j < len(app.TuneForNotes) && app.TuneForNotes[j] == noteID
+		if _, inSol := solNotes[noteID]; inSol || !manual {
+			preserved = append([]string{noteID}, preserved...)
+			continue
+		}
+		if err := app.RevertNote(noteID, true); err != nil {
+			return reverted, preserved, err
+		}
+		reverted = append(reverted, noteID)
+	}
+	return reverted, preserved, nil
 }
 
 // RevertSolution permanently revert notes tuned by the solution and
 // clear their stored states.
 func (app *App) RevertSolution(solName string) error {
-	sol, err := app.GetSolutionByName(solName)
+	// the notes excluded from the solution were not tuned by the solution
+	sol, err := app.GetSolutionNotes(solName)
 	if err != nil {
 		return err
 	}
@@ -319,6 +1057,7 @@ func (app *App) RevertSolution(solName string) error {
 	i := sort.SearchStrings(app.TuneForSolutions, solName)
 	if i < len(app.TuneForSolutions) && app.TuneForSolutions[i] == solName {
 		app.TuneForSolutions = append(app.TuneForSolutions[0:i], app.TuneForSolutions[i+1:]...)
+		delete(app.ExcludedNotes, solName)
 		if err := app.SaveConfig(); err != nil {
 			return err
 		}
@@ -331,7 +1070,7 @@ func (app *App) RevertSolution(solName string) error {
 	// Do not revert notes that are referred to by other enabled solutions
 	for _, otherSolName := range app.TuneForSolutions {
 		if otherSolName != solName {
-			otherSolNotes, err := app.GetSolutionByName(otherSolName)
+			otherSolNotes, err := app.GetSolutionNotes(otherSolName)
 			if err != nil {
 				return err
 			}
@@ -361,12 +1100,22 @@ func (app *App) RevertSolution(solName string) error {
 // RevertAll revert all tuned parameters (both solutions and additional notes),
 // and clear stored states.
 func (app *App) RevertAll(permanent bool) error {
+	return app.RevertAllWithProgress(permanent, nil)
+}
+
+// RevertAllWithProgress works like RevertAll, but calls the function
+// progress, if not nil, before each note is reverted with the note, the
+// number of the note and the number of all notes to revert.
+func (app *App) RevertAllWithProgress(permanent bool, progress func(noteID string, cnt, total int)) error {
 	allErrs := make([]error, 0, 0)
 
 	// Simply revert all notes from serialised states
 	otherNotes, err := app.State.List()
 	if err == nil {
-		for _, otherNoteID := range otherNotes {
+		for cnt, otherNoteID := range otherNotes {
+			if progress != nil {
+				progress(otherNoteID, cnt+1, len(otherNotes))
+			}
 			if err := app.RevertNote(otherNoteID, permanent); err != nil {
 				allErrs = append(allErrs, err)
 			}
@@ -377,6 +1126,7 @@ func (app *App) RevertAll(permanent bool) error {
 	if permanent {
 		app.TuneForNotes = make([]string, 0, 0)
 		app.TuneForSolutions = make([]string, 0, 0)
+		app.ExcludedNotes = make(map[string][]string)
 		if err := app.SaveConfig(); err != nil {
 			allErrs = append(allErrs, err)
 		}
@@ -396,6 +1146,10 @@ func (app *App) VerifyNote(noteID string) (conforming bool, comparisons map[stri
 	if err != nil {
 		return
 	}
+	// a package update may have changed the Note definition
+	if err = app.checkNoteVersionPin(noteID); err != nil {
+		return
+	}
 	if reflect.TypeOf(theNote).String() == "note.INISettings" {
 		// workaround to prevent storing of parameter state files
 		// during verify
@@ -426,27 +1180,61 @@ func (app *App) VerifyNote(noteID string) (conforming bool, comparisons map[stri
 		optimisedNote = optimisedNote.(note.INISettings).SetValuesToApply(make([]string, 0))
 	}
 	conforming, comparisons, valApplyList = note.CompareNoteFields(inspectedNote, optimisedNote)
+	setGrubStates(comparisons)
+	markEnvironmentParams(comparisons)
+	if iniNote, ok := theNote.(note.INISettings); ok {
+		setSeverities(comparisons, iniNote.ParamSeverities())
+	}
 	return
 }
 
+// setSeverities adds the severities of the [severity] section of the Note
+// definition to the comparisons of the related parameters
+func setSeverities(comparisons map[string]note.FieldComparison, severities map[string]string) {
+	for key, comparison := range comparisons {
+		severity, ok := severities[comparison.ReflectMapKey]
+		if comparison.ReflectFieldName != "SysctlParams" || !ok {
+			continue
+		}
+		comparison.Severity = severity
+		comparisons[key] = comparison
+	}
+}
+
+// setGrubStates checks the grub parameters of the comparisons against the
+// running kernel and the boot loader configuration, so that a needed reboot
+// can be reported
+func setGrubStates(comparisons map[string]note.FieldComparison) {
+	for key, comparison := range comparisons {
+		if comparison.ReflectFieldName != "SysctlParams" || !strings.HasPrefix(comparison.ReflectMapKey, "grub:") {
+			continue
+		}
+		expected, _ := comparison.ExpectedValue.(string)
+		comparison.GrubState = note.GetGrubState(comparison.ReflectMapKey, expected)
+		comparisons[key] = comparison
+	}
+}
+
 // VerifySolution inspect the system and verify that all parameters conform
 // to all of the notes associated to the solution.
 // The note comparison results will always contain all fields from all notes.
 func (app *App) VerifySolution(solName string) (unsatisfiedNotes []string, comparisons map[string]map[string]note.FieldComparison, err error) {
 	unsatisfiedNotes = make([]string, 0, 0)
 	comparisons = make(map[string]map[string]note.FieldComparison)
-	sol, err := app.GetSolutionByName(solName)
+	// the notes excluded from the enabled solution are not expected
+	sol, err := app.GetSolutionNotes(solName)
 	if err != nil {
 		return nil, nil, err
 	}
+	results := app.verifyNotes(sol)
 	for _, noteID := range sol {
-		conforming, noteComparisons, _, err := app.VerifyNote(noteID)
-		if err != nil {
-			return nil, nil, err
-		} else if !conforming {
+		result := results[noteID]
+		if result.err != nil {
+			return nil, nil, result.err
+		} else if !result.conforming {
 			unsatisfiedNotes = append(unsatisfiedNotes, noteID)
 		}
-		comparisons[noteID] = noteComparisons
+		comparisons[noteID] = result.comparisons
 	}
 	return
 }
@@ -454,30 +1242,138 @@ func (app *App) VerifySolution(solName string) (unsatisfiedNotes []string, compa
 // VerifyAll inspect the system and verify all parameters against all enabled
 // notes/solutions.
 // The note comparison results will always contain all fields from all notes.
+// The notes are verified concurrently, see verifyNotes.
 func (app *App) VerifyAll() (unsatisfiedNotes []string, comparisons map[string]map[string]note.FieldComparison, err error) {
 	unsatisfiedNotes = make([]string, 0, 0)
 	comparisons = make(map[string]map[string]note.FieldComparison)
+	// collect the notes of the enabled solutions and the additionally
+	// tuned notes in the order they need to be reported
+	noteIDs := make([]string, 0, len(app.TuneForNotes))
 	for _, solName := range app.TuneForSolutions {
-		// Collect field comparison results from solution notes
-		unsatisfiedSolNotes, noteComparisons, err := app.VerifySolution(solName)
+		sol, err := app.GetSolutionNotes(solName)
 		if err != nil {
 			return nil, nil, err
-		} else if len(unsatisfiedSolNotes) > 0 {
-			unsatisfiedNotes = append(unsatisfiedNotes, unsatisfiedSolNotes...)
-		}
-		for noteName, noteComparisonResult := range noteComparisons {
-			comparisons[noteName] = noteComparisonResult
 		}
+		noteIDs = append(noteIDs, sol...)
 	}
-	for _, noteID := range app.TuneForNotes {
-		// Collect field comparison results from additionally tuned notes
-		conforming, noteComparisons, _, err := app.VerifyNote(noteID)
-		if err != nil {
-			return nil, nil, err
-		} else if !conforming {
+	noteIDs = append(noteIDs, app.TuneForNotes...)
+
+	results := app.verifyNotes(noteIDs)
+	for _, noteID := range noteIDs {
+		result := results[noteID]
+		if result.err != nil {
+			return nil, nil, result.err
+		} else if !result.conforming {
 			unsatisfiedNotes = append(unsatisfiedNotes, noteID)
 		}
-		comparisons[noteID] = noteComparisons
+		comparisons[noteID] = result.comparisons
 	}
 	return
 }
+
+// noteVerifyResult contains the result of the verification of a single note
+type noteVerifyResult struct {
+	conforming  bool
+	comparisons map[string]note.FieldComparison
+	err         error
+}
+
+// verifyNotes verifies the given notes concurrently using a bounded pool
+// of workers. Each note is verified only once, even if it is listed
+// several times.
+func (app *App) verifyNotes(noteIDs []string) map[string]noteVerifyResult {
+	results := make(map[string]noteVerifyResult)
+	uniqueIDs := make([]string, 0, len(noteIDs))
+	seen := make(map[string]bool)
+	for _, noteID := range noteIDs {
+		if !seen[noteID] {
+			seen[noteID] = true
+			uniqueIDs = append(uniqueIDs, noteID)
+		}
+	}
+	workers := runtime.NumCPU()
+	if workers > maxVerifyWorkers {
+		workers = maxVerifyWorkers
+	}
+	if workers > len(uniqueIDs) {
+		workers = len(uniqueIDs)
+	}
+
+	var resultsLock sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for noteID := range jobs {
+				conforming, comparisons, _, err := app.VerifyNote(noteID)
+				resultsLock.Lock()
+				results[noteID] = noteVerifyResult{conforming: conforming, comparisons: comparisons, err: err}
+				resultsLock.Unlock()
+			}
+		}()
+	}
+	for _, noteID := range uniqueIDs {
+		jobs <- noteID
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// ParameterConflict describes a parameter, which is set to different values
+// by more than one of the enabled notes
+type ParameterConflict struct {
+	Parameter string
+	NoteIDs   []string          // notes setting the parameter, in apply order
+	Values    map[string]string // parameter value of each note
+	Winner    string            // note applied last, so its value is used
+}
+
+// NoteConflicts returns all parameters, which are set to different values by
+// the enabled notes, sorted by parameter name. The notes are examined in the
+// order of NoteApplyOrder, so the note applied last wins.
+// Parameters disabled by an override file ('untouched') or not managed by a
+// partially applied note are not taken into account, as well as notes
+// without a Note definition file.
+func (app *App) NoteConflicts() ([]ParameterConflict, error) {
+	conflicts := make(map[string]*ParameterConflict)
+	for _, noteID := range app.NoteApplyOrder {
+		aNote, err := app.GetNoteByID(noteID)
+		if err != nil {
+			return nil, err
+		}
+		iniNote, ok := aNote.(note.INISettings)
+		if !ok {
+			continue
+		}
+		params, err := iniNote.DefinedParams()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the definition of Note %s - %v", noteID, err)
+		}
+		for key, value := range params {
+			if value == "untouched" || value == note.NotManaged {
+				continue
+			}
+			if _, ok := conflicts[key]; !ok {
+				conflicts[key] = &ParameterConflict{Parameter: key, NoteIDs: []string{}, Values: make(map[string]string)}
+			}
+			conflicts[key].NoteIDs = append(conflicts[key].NoteIDs, noteID)
+			conflicts[key].Values[noteID] = value
+			conflicts[key].Winner = noteID
+		}
+	}
+
+	result := make([]ParameterConflict, 0)
+	for _, conflict := range conflicts {
+		for _, noteID := range conflict.NoteIDs {
+			if conflict.Values[noteID] != conflict.Values[conflict.Winner] {
+				result = append(result, *conflict)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Parameter < result[j].Parameter })
+	return result, nil
+}
@@ -6,10 +6,13 @@ import (
 	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/sap/solution"
+	"github.com/SUSE/saptune/system"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -175,6 +178,20 @@ func TestGetSortedSolutionNotes(t *testing.T) {
 	}
 }
 
+func TestEnabledSolutionsOfNote(t *testing.T) {
+	tuneApp := InitialiseApp(OSPackageInGOPATH, "", AllTestNotes, AllTestSolutions)
+	if sols := tuneApp.enabledSolutionsOfNote("1001"); len(sols) != 0 {
+		t.Fatal(sols)
+	}
+	tuneApp.TuneForSolutions = []string{"sol1", "sol12", "sol2"}
+	if sols := tuneApp.enabledSolutionsOfNote("1001"); !reflect.DeepEqual(sols, []string{"sol1", "sol12"}) {
+		t.Fatal(sols)
+	}
+	if sols := tuneApp.enabledSolutionsOfNote("1002"); !reflect.DeepEqual(sols, []string{"sol12", "sol2"}) {
+		t.Fatal(sols)
+	}
+}
+
 func TestOptimiseNoteOnly(t *testing.T) {
 	os.RemoveAll(SampleNoteDataDir)
 	defer os.RemoveAll(SampleNoteDataDir)
@@ -460,3 +477,684 @@ func TestVerifyNoteAndSolutions(t *testing.T) {
 		t.Fatal(notes, comparisons, err)
 	}
 }
+
+func TestTuneSolutionExcept(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+
+	if excluded, err := tuneApp.SolutionNotesToExclude("sol12", []string{"1001"}, nil); err != nil || !reflect.DeepEqual(excluded, []string{"1002"}) {
+		t.Fatal(excluded, err)
+	}
+	if excluded, err := tuneApp.SolutionNotesToExclude("sol12", nil, []string{"1001"}); err != nil || !reflect.DeepEqual(excluded, []string{"1001"}) {
+		t.Fatal(excluded, err)
+	}
+	if _, err := tuneApp.SolutionNotesToExclude("sol12", []string{"1001"}, []string{"1002"}); err == nil {
+		t.Error("expected an error for '--only' together with '--except'")
+	}
+	if _, err := tuneApp.SolutionNotesToExclude("sol1", nil, []string{"1002"}); err == nil {
+		t.Error("expected an error for a note, which is not part of the solution")
+	}
+	if _, err := tuneApp.TuneSolutionExcept("sol1", []string{"1002"}); err == nil {
+		t.Error("expected an error for a note, which is not part of the solution")
+	}
+
+	// tune sol12 without note 1002
+	if _, err := tuneApp.TuneSolutionExcept("sol12", []string{"1002"}); err != nil {
+		t.Fatal(err)
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{"sol12"})
+	VerifyFileContent(t, SampleParamFile, "optimised1")
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001"}) {
+		t.Error(tuneApp.NoteApplyOrder)
+	}
+	if !tuneApp.IsNoteExcluded("sol12", "1002") || tuneApp.IsNoteExcluded("sol12", "1001") {
+		t.Error(tuneApp.ExcludedNotes)
+	}
+	reloaded := InitialiseApp(tuneApp.SysconfigPrefix, tuneApp.State.StateDirPrefix, AllTestNotes, AllTestSolutions)
+	if !reflect.DeepEqual(reloaded.ExcludedNotes, map[string][]string{"sol12": []string{"1002"}}) {
+		t.Error(reloaded.ExcludedNotes)
+	}
+	if notes, err := tuneApp.GetSolutionNotes("sol12"); err != nil || !reflect.DeepEqual(notes, []string{"1001"}) {
+		t.Error(notes, err)
+	}
+	if enabled := tuneApp.GetSortedSolutionEnabledNotes(); !reflect.DeepEqual(enabled, []string{"1001"}) {
+		t.Error(enabled)
+	}
+	// the excluded note is not expected by verify
+	if notes, comparisons, err := tuneApp.VerifySolution("sol12"); err != nil || len(notes) != 0 || len(comparisons) != 1 {
+		t.Fatal(notes, comparisons, err)
+	}
+	if notes, comparisons, err := tuneApp.VerifyAll(); err != nil || len(notes) != 0 || len(comparisons) != 1 {
+		t.Fatal(notes, comparisons, err)
+	}
+
+	// revert removes the record of the excluded notes
+	if err := tuneApp.RevertSolution("sol12"); err != nil {
+		t.Fatal(err)
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{})
+	VerifyFileContent(t, SampleParamFile, "")
+	if len(tuneApp.ExcludedNotes) != 0 {
+		t.Error(tuneApp.ExcludedNotes)
+	}
+	reloaded = InitialiseApp(tuneApp.SysconfigPrefix, tuneApp.State.StateDirPrefix, AllTestNotes, AllTestSolutions)
+	if len(reloaded.ExcludedNotes) != 0 {
+		t.Error(reloaded.ExcludedNotes)
+	}
+}
+
+func TestCheckNoteBounds(t *testing.T) {
+	boundsFile := path.Join(SampleNoteDataDir, "boundsNote")
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	WriteFileOrPanic(boundsFile, "[bounds]\nvm.dirty_ratio = 5:40\n\n[sysctl]\nvm.dirty_ratio = 95\nvm.swappiness = 10\n")
+	boundsNote := note.INISettings{ConfFilePath: boundsFile, ID: "boundsNote", DescriptiveName: ""}
+	comparisons := map[string]note.FieldComparison{
+		"SysctlParams[vm.dirty_ratio]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.dirty_ratio", ActualValue: "20", ExpectedValue: "95"},
+		"SysctlParams[vm.swappiness]":  {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValue: "60", ExpectedValue: "10"},
+	}
+
+	err := checkNoteBounds("boundsNote", boundsNote, comparisons, []string{"vm.dirty_ratio", "vm.swappiness"})
+	if err == nil || !strings.Contains(err.Error(), "parameter 'vm.dirty_ratio' with value '95' is outside of the bounds '5:40'") || !strings.Contains(err.Error(), "--ignore-bounds") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// only the values to apply are checked
+	if err := checkNoteBounds("boundsNote", boundsNote, comparisons, []string{"vm.swappiness"}); err != nil {
+		t.Error(err)
+	}
+	// other notes and 'check only' notes are not checked
+	if err := checkNoteBounds("1001", SampleNote1{}, comparisons, []string{"vm.dirty_ratio"}); err != nil {
+		t.Error(err)
+	}
+	WriteFileOrPanic(boundsFile, "[check_only]\n[bounds]\nvm.dirty_ratio = 5:40\n\n[sysctl]\nvm.dirty_ratio = 95\n")
+	if err := checkNoteBounds("boundsNote", boundsNote, comparisons, []string{"vm.dirty_ratio"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTuneAllBounds(t *testing.T) {
+	boundsFile := path.Join(SampleNoteDataDir, "boundsNote")
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	WriteFileOrPanic(boundsFile, "[bounds]\nvm.dirty_ratio = 5:40\n\n[sysctl]\nvm.dirty_ratio = 95\n")
+	boundsNote := note.INISettings{ConfFilePath: boundsFile, ID: "boundsNote", DescriptiveName: ""}
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "boundsNote": boundsNote}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	tuneApp.TuneForNotes = []string{"1001", "boundsNote"}
+	tuneApp.NoteApplyOrder = []string{"boundsNote", "1001"}
+
+	// the note with values outside of its bounds is skipped, the other
+	// notes are applied
+	if err := tuneApp.TuneAll(); err != nil {
+		t.Fatal(err)
+	}
+	if tuneApp.IsNoteApplied("boundsNote") || !tuneApp.IsNoteApplied("1001") {
+		t.Fatal(tuneApp.IsNoteApplied("boundsNote"), tuneApp.IsNoteApplied("1001"))
+	}
+	if err := tuneApp.RevertNote("1001", true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRevertNoteParameter(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldSwappiness, _ := system.GetSysctlString("vm.swappiness")
+	oldPressure, _ := system.GetSysctlString("vm.vfs_cache_pressure")
+	defer func() {
+		_ = system.SetSysctlString("vm.swappiness", oldSwappiness)
+		_ = system.SetSysctlString("vm.vfs_cache_pressure", oldPressure)
+	}()
+	if err := system.SetSysctlString("vm.swappiness", "60"); err != nil {
+		t.Skipf("sysctl parameters can not be set: %v", err)
+	}
+	_ = system.SetSysctlString("vm.vfs_cache_pressure", "100")
+
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[sysctl]\nvm.swappiness = 10\nvm.vfs_cache_pressure = 50\n")
+	iniNote := note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "iniNote": iniNote}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	// note not applied
+	if err := tuneApp.RevertNoteParameter("iniNote", "vm.swappiness"); err == nil {
+		t.Fatal("expected an error for a not applied note")
+	}
+	// note without Note definition file
+	if err := tuneApp.RevertNoteParameter("1001", "Param"); err == nil {
+		t.Fatal("expected an error for a note without Note definition file")
+	}
+
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "10" {
+		t.Fatalf("vm.swappiness not tuned: %s", value)
+	}
+	// parameter not available in the state file
+	if err := tuneApp.RevertNoteParameter("iniNote", "unknownParam"); err == nil {
+		t.Fatal("expected an error for a parameter, which is not part of the state file")
+	}
+	if err := tuneApp.RevertNoteParameter("iniNote", "vm.swappiness"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "60" {
+		t.Errorf("vm.swappiness not reverted: %s", value)
+	}
+	if value, _ := system.GetSysctlString("vm.vfs_cache_pressure"); value != "50" {
+		t.Errorf("vm.vfs_cache_pressure reverted, too: %s", value)
+	}
+	var stored note.INISettings
+	if err := tuneApp.State.Retrieve("iniNote", &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored.SysctlParams, map[string]string{"vm.vfs_cache_pressure": "100"}) {
+		t.Fatal(stored.SysctlParams)
+	}
+	// reverting the same parameter again is not possible
+	if err := tuneApp.RevertNoteParameter("iniNote", "vm.swappiness"); err == nil {
+		t.Fatal("expected an error for an already reverted parameter")
+	}
+
+	// the revert of the note leaves the separately reverted parameter
+	// alone, as it is no longer part of the saved state
+	_ = system.SetSysctlString("vm.swappiness", "30")
+	if err := tuneApp.RevertNote("iniNote", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "30" {
+		t.Errorf("separately reverted vm.swappiness changed by the revert of the note: %s", value)
+	}
+	if value, _ := system.GetSysctlString("vm.vfs_cache_pressure"); value != "100" {
+		t.Errorf("vm.vfs_cache_pressure not reverted: %s", value)
+	}
+}
+
+func TestRevertNoteToDefault(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[grub]\nnuma_balancing=disable\n")
+	iniNote := note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "iniNote": iniNote}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	// note not applied
+	if _, _, err := tuneApp.RevertNoteToDefault("iniNote"); err == nil {
+		t.Fatal("expected an error for a not applied note")
+	}
+	// note without Note definition file
+	if _, _, err := tuneApp.RevertNoteToDefault("1001"); err == nil {
+		t.Fatal("expected an error for a note without Note definition file")
+	}
+
+	iniNote.SysctlParams = map[string]string{"grub:numa_balancing": "enable"}
+	if err := tuneApp.State.Store("iniNote", iniNote, true); err != nil {
+		t.Fatal(err)
+	}
+	tuneApp.TuneForNotes = []string{"iniNote"}
+	tuneApp.NoteApplyOrder = []string{"iniNote"}
+	defaults, saved, err := tuneApp.RevertNoteToDefault("iniNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no sysctl parameters, so nothing is set to its default value
+	if len(defaults) != 0 || len(saved) != 0 {
+		t.Fatal(defaults, saved)
+	}
+	if tuneApp.IsNoteApplied("iniNote") || len(tuneApp.TuneForNotes) != 0 || len(tuneApp.NoteApplyOrder) != 0 {
+		t.Fatal(tuneApp.TuneForNotes, tuneApp.NoteApplyOrder)
+	}
+
+	// sysctl parameters, the default value of vm.swappiness is taken
+	// from the stubbed sysctl configuration, the one of
+	// vm.vfs_cache_pressure from the kernel defaults
+	oldSwappiness, _ := system.GetSysctlString("vm.swappiness")
+	oldPressure, _ := system.GetSysctlString("vm.vfs_cache_pressure")
+	defer func() {
+		_ = system.SetSysctlString("vm.swappiness", oldSwappiness)
+		_ = system.SetSysctlString("vm.vfs_cache_pressure", oldPressure)
+	}()
+	if err := system.SetSysctlString("vm.swappiness", "60"); err != nil {
+		t.Skipf("sysctl parameters can not be set: %v", err)
+	}
+	_ = system.SetSysctlString("vm.vfs_cache_pressure", "70")
+	sysctlDir := path.Join(SampleNoteDataDir, "sysctl.d")
+	if err := os.MkdirAll(sysctlDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	WriteFileOrPanic(path.Join(sysctlDir, "99-test.conf"), "vm.swappiness = 25\n")
+	oldDirs, oldFile := system.SysctlConfigDirs, system.SysctlConfigFile
+	defer func() { system.SysctlConfigDirs, system.SysctlConfigFile = oldDirs, oldFile }()
+	system.SysctlConfigDirs = []string{sysctlDir}
+	system.SysctlConfigFile = path.Join(SampleNoteDataDir, "sysctl.conf")
+
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[sysctl]\nvm.swappiness = 10\nvm.vfs_cache_pressure = 50\n")
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "10" {
+		t.Fatalf("vm.swappiness not tuned: %s", value)
+	}
+	defaults, saved, err = tuneApp.RevertNoteToDefault("iniNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(defaults)
+	if !reflect.DeepEqual(defaults, []string{"vm.swappiness", "vm.vfs_cache_pressure"}) || len(saved) != 0 {
+		t.Fatal(defaults, saved)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "25" {
+		t.Errorf("vm.swappiness not set to the boot value: %s", value)
+	}
+	if value, _ := system.GetSysctlString("vm.vfs_cache_pressure"); value != "100" {
+		t.Errorf("vm.vfs_cache_pressure not set to the kernel default: %s", value)
+	}
+	if tuneApp.IsNoteApplied("iniNote") {
+		t.Fatal("note still applied")
+	}
+}
+
+func TestSetSeverities(t *testing.T) {
+	comparisons := map[string]note.FieldComparison{
+		"SysctlParams[vm.swappiness]":   {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness"},
+		"SysctlParams[kernel.shmmni]":   {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni"},
+		"OverrideParams[vm.swappiness]": {ReflectFieldName: "OverrideParams", ReflectMapKey: "vm.swappiness"},
+	}
+	setSeverities(comparisons, map[string]string{"vm.swappiness": "info"})
+	if comparisons["SysctlParams[vm.swappiness]"].Severity != "info" || comparisons["SysctlParams[kernel.shmmni]"].Severity != "" || comparisons["OverrideParams[vm.swappiness]"].Severity != "" {
+		t.Error(comparisons)
+	}
+}
+
+func TestVerifyNotes(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	if err := tuneApp.TuneNote("1002"); err != nil {
+		t.Fatal(err)
+	}
+	results := tuneApp.verifyNotes([]string{"1001", "1002", "1001", "8932147"})
+	if len(results) != 3 {
+		t.Fatal(results)
+	}
+	if results["1001"].err != nil || results["1001"].conforming {
+		t.Fatal(results["1001"])
+	}
+	if results["1002"].err != nil || !results["1002"].conforming || len(results["1002"].comparisons) == 0 {
+		t.Fatal(results["1002"])
+	}
+	if results["8932147"].err == nil {
+		t.Fatal("expected an error for a non-existing note")
+	}
+	if results := tuneApp.verifyNotes([]string{}); len(results) != 0 {
+		t.Fatal(results)
+	}
+}
+
+func TestNoteConflicts(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile1 := path.Join(SampleNoteDataDir, "iniNote1")
+	WriteFileOrPanic(iniFile1, "[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 32768\nnet.ipv4.ip_local_port_range = 31768 61999\n")
+	iniFile2 := path.Join(SampleNoteDataDir, "iniNote2")
+	WriteFileOrPanic(iniFile2, "[sysctl]\nvm.swappiness = 60\nkernel.shmmni = 32768\nnet.ipv4.ip_local_port_range = 9000 65499\n")
+	allNotes := map[string]note.Note{
+		"1001":     SampleNote1{},
+		"iniNote1": note.INISettings{ConfFilePath: iniFile1, ID: "iniNote1", DescriptiveName: ""},
+		"iniNote2": note.INISettings{ConfFilePath: iniFile2, ID: "iniNote2", DescriptiveName: ""},
+	}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	tuneApp.NoteApplyOrder = []string{"iniNote2", "1001", "iniNote1"}
+	conflicts, err := tuneApp.NoteConflicts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ParameterConflict{
+		{Parameter: "net.ipv4.ip_local_port_range", NoteIDs: []string{"iniNote2", "iniNote1"}, Values: map[string]string{"iniNote2": "9000\t65499", "iniNote1": "31768\t61999"}, Winner: "iniNote1"},
+		{Parameter: "vm.swappiness", NoteIDs: []string{"iniNote2", "iniNote1"}, Values: map[string]string{"iniNote2": "60", "iniNote1": "10"}, Winner: "iniNote1"},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Fatalf("got: %+v, expected: %+v", conflicts, expected)
+	}
+
+	tuneApp.NoteApplyOrder = []string{"iniNote1"}
+	if conflicts, err := tuneApp.NoteConflicts(); err != nil || len(conflicts) != 0 {
+		t.Fatal(conflicts, err)
+	}
+	tuneApp.NoteApplyOrder = []string{"iniNote1", "8932147"}
+	if _, err := tuneApp.NoteConflicts(); err == nil {
+		t.Fatal("expected an error for a non-existing note")
+	}
+}
+
+func TestMoveNoteInApplyOrder(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	tuneApp.TuneForSolutions = []string{"sol12"}
+	tuneApp.TuneForNotes = []string{"extraNote"}
+	tuneApp.NoteApplyOrder = []string{"1001", "extraNote", "1002"}
+
+	if err := tuneApp.MoveNoteInApplyOrder("extraNote", "before", "1001"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"extraNote", "1001", "1002"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if err := tuneApp.MoveNoteInApplyOrder("extraNote", "after", "1002"); err != nil {
+		t.Fatal(err)
+	}
+	// the new order needs to be saved
+	tuneApp2 := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	if !reflect.DeepEqual(tuneApp2.NoteApplyOrder, []string{"1001", "1002", "extraNote"}) {
+		t.Fatal(tuneApp2.NoteApplyOrder)
+	}
+
+	// solution sol12 requires 1001 to be applied before 1002
+	if err := tuneApp.MoveNoteInApplyOrder("1002", "before", "1001"); err == nil {
+		t.Fatal("expected an error for a move, which violates the order of solution sol12")
+	}
+	if err := tuneApp.MoveNoteInApplyOrder("1001", "after", "extraNote"); err == nil {
+		t.Fatal("expected an error for a move, which violates the order of solution sol12")
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001", "1002", "extraNote"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	// wrong input
+	if err := tuneApp.MoveNoteInApplyOrder("1001", "behind", "1002"); err == nil {
+		t.Fatal("expected an error for a wrong position")
+	}
+	if err := tuneApp.MoveNoteInApplyOrder("1001", "before", "1001"); err == nil {
+		t.Fatal("expected an error for moving a note relative to itself")
+	}
+	if err := tuneApp.MoveNoteInApplyOrder("8932147", "before", "1001"); err == nil {
+		t.Fatal("expected an error for a note, which is not applied")
+	}
+	if err := tuneApp.checkNoteApplyOrder([]string{"1001", "1002", "1001"}); err == nil {
+		t.Fatal("expected an error for a note listed twice")
+	}
+}
+
+func TestEnableDisableNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	if err := tuneApp.EnableNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	// enabling twice must not duplicate the note
+	if err := tuneApp.EnableNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	VerifyConfig(t, tuneApp, []string{"1001"}, []string{})
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	// the note is only enabled, but not applied
+	if tuneApp.IsNoteApplied("1001") {
+		t.Fatal("note 1001 should not be applied")
+	}
+	if content, err := ioutil.ReadFile(SampleParamFile); err == nil && len(content) != 0 {
+		t.Fatalf("note 1001 should not change the system, but found '%s'", string(content))
+	}
+	if err := tuneApp.EnableNote("8932147"); err == nil {
+		t.Fatal("expected an error for a non-existing note")
+	}
+
+	if err := tuneApp.DisableNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{})
+	if len(tuneApp.NoteApplyOrder) != 0 {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if err := tuneApp.DisableNote("1001"); err == nil {
+		t.Fatal("expected an error for a note, which is not enabled")
+	}
+
+	// a note of an enabled solution stays enabled
+	tuneApp.TuneForSolutions = []string{"sol1"}
+	tuneApp.NoteApplyOrder = []string{"1001"}
+	if err := tuneApp.DisableNote("1001"); err == nil {
+		t.Fatal("expected an error for a note of an enabled solution")
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	tuneApp.TuneForSolutions = []string{}
+	tuneApp.NoteApplyOrder = []string{}
+
+	// staged notes are applied by TuneAll
+	if err := tuneApp.EnableNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.TuneAll(); err != nil {
+		t.Fatal(err)
+	}
+	if !tuneApp.IsNoteApplied("1001") {
+		t.Fatal("note 1001 should be applied")
+	}
+	// an applied note needs to be reverted
+	if err := tuneApp.DisableNote("1001"); err == nil {
+		t.Fatal("expected an error for an applied note")
+	}
+}
+
+func TestRevertTag(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile1 := path.Join(SampleNoteDataDir, "iniNote1")
+	WriteFileOrPanic(iniFile1, "[tags]\nHANA\n[grub]\nnuma_balancing=disable\n")
+	iniFile2 := path.Join(SampleNoteDataDir, "iniNote2")
+	WriteFileOrPanic(iniFile2, "[tags]\nHANA production\n[grub]\ntransparent_hugepage=never\n")
+	iniFile3 := path.Join(SampleNoteDataDir, "iniNote3")
+	WriteFileOrPanic(iniFile3, "[grub]\nintel_idle.max_cstate=1\n")
+	allNotes := map[string]note.Note{
+		"1001":     SampleNote1{},
+		"iniNote1": note.INISettings{ConfFilePath: iniFile1, ID: "iniNote1", DescriptiveName: ""},
+		"iniNote2": note.INISettings{ConfFilePath: iniFile2, ID: "iniNote2", DescriptiveName: ""},
+		"iniNote3": note.INISettings{ConfFilePath: iniFile3, ID: "iniNote3", DescriptiveName: ""},
+	}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	for _, noteID := range []string{"iniNote1", "1001", "iniNote2", "iniNote3"} {
+		if err := tuneApp.EnableNote(noteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if noteIDs := tuneApp.NotesWithTag("HANA"); !reflect.DeepEqual(noteIDs, []string{"iniNote1", "iniNote2"}) {
+		t.Fatal(noteIDs)
+	}
+	if noteIDs := tuneApp.NotesWithTag("unknown"); len(noteIDs) != 0 {
+		t.Fatal(noteIDs)
+	}
+	reverted, err := tuneApp.RevertTag("HANA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reverted in reverse apply order
+	if !reflect.DeepEqual(reverted, []string{"iniNote2", "iniNote1"}) {
+		t.Fatal(reverted)
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001", "iniNote3"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if !reflect.DeepEqual(tuneApp.TuneForNotes, []string{"1001", "iniNote3"}) {
+		t.Fatal(tuneApp.TuneForNotes)
+	}
+}
+
+func TestRevertNotesKeepSolutions(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile3 := path.Join(SampleNoteDataDir, "iniNote3")
+	WriteFileOrPanic(iniFile3, "[grub]\nintel_idle.max_cstate=1\n")
+	allNotes := map[string]note.Note{
+		"1001":     SampleNote1{},
+		"1002":     SampleNote2{},
+		"iniNote3": note.INISettings{ConfFilePath: iniFile3, ID: "iniNote3", DescriptiveName: ""},
+	}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if _, err := tuneApp.TuneSolution("sol1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, noteID := range []string{"iniNote3", "1002"} {
+		if err := tuneApp.EnableNote(noteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reverted, preserved, err := tuneApp.RevertNotesKeepSolutions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reverted in reverse apply order
+	if !reflect.DeepEqual(reverted, []string{"1002", "iniNote3"}) {
+		t.Fatal(reverted)
+	}
+	if !reflect.DeepEqual(preserved, []string{"1001"}) {
+		t.Fatal(preserved)
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"1001"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if len(tuneApp.TuneForNotes) != 0 || !reflect.DeepEqual(tuneApp.TuneForSolutions, []string{"sol1"}) {
+		t.Fatal(tuneApp.TuneForNotes, tuneApp.TuneForSolutions)
+	}
+}
+
+func TestNoteRequirements(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile1 := path.Join(SampleNoteDataDir, "iniNote1")
+	WriteFileOrPanic(iniFile1, "[requires]\niniNote2\n[grub]\nnuma_balancing=disable\n")
+	iniFile2 := path.Join(SampleNoteDataDir, "iniNote2")
+	WriteFileOrPanic(iniFile2, "[requires]\niniNote3\n[grub]\ntransparent_hugepage=never\n")
+	iniFile3 := path.Join(SampleNoteDataDir, "iniNote3")
+	WriteFileOrPanic(iniFile3, "[grub]\nintel_idle.max_cstate=1\n")
+	cycleFile := path.Join(SampleNoteDataDir, "cycleNote")
+	WriteFileOrPanic(cycleFile, "[requires]\ncycleNote2\n")
+	cycleFile2 := path.Join(SampleNoteDataDir, "cycleNote2")
+	WriteFileOrPanic(cycleFile2, "[requires]\ncycleNote\n")
+	allNotes := map[string]note.Note{
+		"1001":       SampleNote1{},
+		"iniNote1":   note.INISettings{ConfFilePath: iniFile1, ID: "iniNote1", DescriptiveName: ""},
+		"iniNote2":   note.INISettings{ConfFilePath: iniFile2, ID: "iniNote2", DescriptiveName: ""},
+		"iniNote3":   note.INISettings{ConfFilePath: iniFile3, ID: "iniNote3", DescriptiveName: ""},
+		"cycleNote":  note.INISettings{ConfFilePath: cycleFile, ID: "cycleNote", DescriptiveName: ""},
+		"cycleNote2": note.INISettings{ConfFilePath: cycleFile2, ID: "cycleNote2", DescriptiveName: ""},
+	}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	if unmet, err := tuneApp.UnmetRequirements("iniNote1"); err != nil || !reflect.DeepEqual(unmet, []string{"iniNote3", "iniNote2"}) {
+		t.Fatal(unmet, err)
+	}
+	if unmet, err := tuneApp.UnmetRequirements("1001"); err != nil || len(unmet) != 0 {
+		t.Fatal(unmet, err)
+	}
+	if _, err := tuneApp.UnmetRequirements("cycleNote"); err == nil || err.Error() != "the notes have cyclic requirements: cycleNote -> cycleNote2 -> cycleNote" {
+		t.Fatal(err)
+	}
+
+	// the required notes are moved before the notes requiring them
+	for _, noteID := range []string{"iniNote1", "1001", "iniNote2"} {
+		if err := tuneApp.EnableNote(noteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"iniNote2", "iniNote1", "1001"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if unmet, err := tuneApp.UnmetRequirements("iniNote1"); err != nil || !reflect.DeepEqual(unmet, []string{"iniNote3"}) {
+		t.Fatal(unmet, err)
+	}
+	if err := tuneApp.EnableNote("iniNote3"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"iniNote3", "iniNote2", "iniNote1", "1001"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if err := tuneApp.MoveNoteInApplyOrder("iniNote3", "after", "iniNote2"); err == nil {
+		t.Fatal("expected an error for moving a note after a note requiring it")
+	}
+
+	// cyclic requirements are rejected
+	if err := tuneApp.EnableNote("cycleNote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.EnableNote("cycleNote2"); err == nil {
+		t.Fatal("expected an error for cyclic requirements")
+	}
+	if tuneApp.PositionInNoteApplyOrder("cycleNote2") >= 0 {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+
+	// TuneAll skips only the notes with cyclic requirements, e.g. after
+	// a change of the Note definition files
+	tuneApp.NoteApplyOrder = []string{"cycleNote2", "iniNote3", "iniNote2", "cycleNote", "iniNote1", "1001"}
+	if err := tuneApp.TuneAll(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tuneApp.NoteApplyOrder, []string{"iniNote3", "iniNote2", "iniNote1", "1001", "cycleNote2", "cycleNote"}) {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+	if !tuneApp.IsNoteApplied("1001") || tuneApp.IsNoteApplied("cycleNote") || tuneApp.IsNoteApplied("cycleNote2") {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+}
+
+func TestChangedNoteDefinitions(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[grub]\nnuma_balancing=disable\n")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "iniNote": note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	for _, noteID := range []string{"1001", "iniNote"} {
+		if err := tuneApp.TuneNote(noteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if changed := tuneApp.ChangedNoteDefinitions(); len(changed) != 0 {
+		t.Fatal(changed)
+	}
+	// simulate a package update of the Note definition
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=2 DATE=01.01.2021 NAME=\"ini test note\"\n[grub]\nnuma_balancing=enable\n")
+	if changed := tuneApp.ChangedNoteDefinitions(); !reflect.DeepEqual(changed, []string{"iniNote"}) {
+		t.Fatal(changed)
+	}
+	if err := tuneApp.RevertAll(true); err != nil {
+		t.Fatal(err)
+	}
+	if changed := tuneApp.ChangedNoteDefinitions(); len(changed) != 0 {
+		t.Fatal(changed)
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"strings"
+)
+
+// BaselineID is used instead of a Note ID for the comparisons of the
+// parameters of a baseline file
+const BaselineID = "baseline"
+
+// ReadBaseline reads the expected parameter values from a baseline file.
+// The file contains lines 'key = value' like a sysconfig file. It returns
+// the keys in the order of the file and the values
+func ReadBaseline(fileName string) ([]string, map[string]string, error) {
+	conf, err := txtparser.ParseSysconfigFile(fileName, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make([]string, 0, len(conf.AllValues))
+	values := make(map[string]string)
+	for _, entry := range conf.AllValues {
+		if entry.Key == "" {
+			continue
+		}
+		if _, exists := values[entry.Key]; !exists {
+			keys = append(keys, entry.Key)
+		}
+		values[entry.Key] = entry.Value
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("the baseline file '%s' does not contain any parameter", fileName)
+	}
+	return keys, values, nil
+}
+
+// VerifyBaseline compares the current values of the system with the
+// expected values of the baseline file, independent of the expected values
+// of the Note definitions. The current value of a parameter is read like
+// during verify, if an enabled note tunes the parameter, otherwise the
+// parameter is read as sysctl parameter. A parameter, which can not be
+// read, gets the value 'NA'.
+// The comparisons use the keys of the Note comparisons, so they can be
+// printed like the result of a Note verify with BaselineID as Note ID.
+func (app *App) VerifyBaseline(fileName string) (conforming bool, comparisons map[string]note.FieldComparison, err error) {
+	keys, expected, err := ReadBaseline(fileName)
+	if err != nil {
+		return false, nil, err
+	}
+	actual := make(map[string]string)
+	if len(app.NoteApplyOrder) != 0 {
+		_, noteComparisons, err := app.VerifyAll()
+		if err != nil {
+			return false, nil, err
+		}
+		for _, noteID := range app.NoteApplyOrder {
+			for _, comparison := range noteComparisons[noteID] {
+				if comparison.ReflectFieldName != "SysctlParams" || comparison.ReflectMapKey == "" {
+					continue
+				}
+				if _, exists := actual[comparison.ReflectMapKey]; !exists {
+					actual[comparison.ReflectMapKey] = comparison.ActualValueJS
+				}
+			}
+		}
+	}
+	conforming = true
+	comparisons = map[string]note.FieldComparison{
+		"ConfFilePath": {ReflectFieldName: "ConfFilePath", ActualValue: fileName},
+		"ID":           {ReflectFieldName: "ID", ActualValue: BaselineID},
+	}
+	for _, key := range keys {
+		value, exists := actual[key]
+		if !exists {
+			if value, err = system.GetSysctlString(key); err != nil {
+				value = "NA"
+			}
+		}
+		match := strings.Join(strings.Fields(value), " ") == strings.Join(strings.Fields(expected[key]), " ")
+		if !match {
+			conforming = false
+		}
+		comparisons[fmt.Sprintf("%s[%s]", "SysctlParams", key)] = note.FieldComparison{
+			ReflectFieldName: "SysctlParams",
+			ReflectMapKey:    key,
+			ActualValue:      value,
+			ExpectedValue:    expected[key],
+			ActualValueJS:    value,
+			ExpectedValueJS:  expected[key],
+			MatchExpectation: match,
+		}
+	}
+	return conforming, comparisons, nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestVerifyBaseline(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hdl := reloadHandler{values: map[string]string{"baseline.param1": "1", "baseline.param2": "2"}}
+	if err := note.RegisterSectionHandler("baselinetest", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer note.UnregisterSectionHandler("baselinetest")
+	for _, param := range []string{"baseline.param1", "baseline.param2"} {
+		defer note.CleanUpParamFile(param)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[baselinetest]\nbaseline.param1 = 5\nbaseline.param2 = 7\n")
+	allNotes := map[string]note.Note{"iniNote": note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := path.Join(SampleNoteDataDir, "baseline")
+	if _, _, err := tuneApp.VerifyBaseline(baseline); err == nil {
+		t.Fatal("expected an error for a missing baseline file")
+	}
+	WriteFileOrPanic(baseline, "# only comments\n\n")
+	if _, _, err := tuneApp.VerifyBaseline(baseline); err == nil {
+		t.Fatal("expected an error for a baseline file without parameters")
+	}
+
+	// the values are independent of the Note definition
+	WriteFileOrPanic(baseline, "# golden state\nbaseline.param1 = 5\nbaseline.param2 = \"8\"\nno.such.param = 1\n")
+	conforming, comparisons, err := tuneApp.VerifyBaseline(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conforming {
+		t.Error("expected deviations from the baseline")
+	}
+	if comp := comparisons["SysctlParams[baseline.param1]"]; !comp.MatchExpectation || comp.ActualValueJS != "5" || comp.ExpectedValueJS != "5" {
+		t.Errorf("unexpected comparison %+v", comp)
+	}
+	if comp := comparisons["SysctlParams[baseline.param2]"]; comp.MatchExpectation || comp.ActualValueJS != "7" || comp.ExpectedValueJS != "8" {
+		t.Errorf("unexpected comparison %+v", comp)
+	}
+	if comp := comparisons["SysctlParams[no.such.param]"]; comp.MatchExpectation || comp.ActualValue != "NA" {
+		t.Errorf("unexpected comparison %+v", comp)
+	}
+	if comparisons["ConfFilePath"].ActualValue != baseline {
+		t.Errorf("unexpected file %v", comparisons["ConfFilePath"].ActualValue)
+	}
+
+	WriteFileOrPanic(baseline, "baseline.param1 = 5\nbaseline.param2 = 7\n")
+	if conforming, _, err := tuneApp.VerifyBaseline(baseline); err != nil || !conforming {
+		t.Error(conforming, err)
+	}
+}
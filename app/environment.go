@@ -0,0 +1,84 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// EnvGatedParamsFile lists per kind of virtualization environment the
+// parameters, which can not be set in such an environment, as glob patterns
+// of the parameter names
+var EnvGatedParamsFile = "/usr/share/saptune/env_gated_params"
+
+// detectVirtualization returns the kind and the type of the virtualization
+// environment. Replaced by the tests.
+var detectVirtualization = system.GetVirtualization
+
+var virtOnce sync.Once
+var virtKind, virtType string
+
+// Virtualization returns the kind and the type of the virtualization
+// environment saptune is running in. The environment is detected only once.
+func Virtualization() (string, string) {
+	virtOnce.Do(func() {
+		virtKind, virtType = detectVirtualization()
+	})
+	return virtKind, virtType
+}
+
+// envGatedParams reads the glob patterns of the parameters, which can not
+// be set in the virtualization environment of the given kind, from
+// EnvGatedParamsFile
+func envGatedParams(kind string) []string {
+	patterns := make([]string, 0)
+	content, err := txtparser.ParseINIFile(EnvGatedParamsFile, false)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			system.WarningLog("failed to read the environment gated parameters from '%s': %v", EnvGatedParamsFile, err)
+		}
+		return patterns
+	}
+	for _, entry := range content.AllValues {
+		if entry.Section == kind {
+			patterns = append(patterns, strings.Fields(entry.Value)...)
+		}
+	}
+	return patterns
+}
+
+// IsEnvGatedParam returns true, if the parameter matches one of the glob
+// patterns of the parameters, which can not be set in a virtualization
+// environment
+func IsEnvGatedParam(param string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if match, _ := path.Match(pattern, param); match {
+			return true
+		}
+	}
+	return false
+}
+
+// markEnvironmentParams marks the deviating parameters, which can not be set
+// in the current virtualization environment, as not applicable. The
+// deviation is reported as informational, but the parameters stay
+// non-compliant
+func markEnvironmentParams(comparisons map[string]note.FieldComparison) {
+	kind, vtype := Virtualization()
+	if kind == "" {
+		return
+	}
+	patterns := envGatedParams(kind)
+	for key, comparison := range comparisons {
+		if comparison.MatchExpectation || comparison.ReflectFieldName != "SysctlParams" || !IsEnvGatedParam(comparison.ReflectMapKey, patterns) {
+			continue
+		}
+		system.InfoLog("parameter '%s' can not be set in this environment (%s), its deviation is informational", comparison.ReflectMapKey, vtype)
+		comparison.NotApplicable = vtype
+		comparisons[key] = comparison
+	}
+}
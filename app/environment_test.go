@@ -0,0 +1,88 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+)
+
+// writeEnvGatedParams points EnvGatedParamsFile to a file with the given
+// content and returns a function restoring the original file name
+func writeEnvGatedParams(t *testing.T, content string) func() {
+	oldFile := EnvGatedParamsFile
+	EnvGatedParamsFile = path.Join(os.TempDir(), "saptune_env_gated_params")
+	if err := ioutil.WriteFile(EnvGatedParamsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Remove(EnvGatedParamsFile)
+		EnvGatedParamsFile = oldFile
+	}
+}
+
+func TestEnvGatedParams(t *testing.T) {
+	defer writeEnvGatedParams(t, "# comment\n[vm]\ncpu = force_latency energy_perf_bias governor\n[container]\ncpu = force_latency governor\nblock = IO_SCHEDULER_* NRREQ_*\n")()
+
+	vmParams := envGatedParams(system.VirtKindVM)
+	if !IsEnvGatedParam("force_latency", vmParams) || !IsEnvGatedParam("energy_perf_bias", vmParams) {
+		t.Error("cpu settings should be gated in a virtual machine")
+	}
+	if IsEnvGatedParam("vm.swappiness", vmParams) || IsEnvGatedParam("IO_SCHEDULER_sda", vmParams) {
+		t.Error("vm.swappiness and IO_SCHEDULER_sda should not be gated in a virtual machine")
+	}
+	containerParams := envGatedParams(system.VirtKindContainer)
+	if !IsEnvGatedParam("IO_SCHEDULER_sda", containerParams) || !IsEnvGatedParam("governor", containerParams) {
+		t.Error("IO_SCHEDULER_sda and governor should be gated in a container")
+	}
+	if IsEnvGatedParam("kernel.shmmax", containerParams) || IsEnvGatedParam("energy_perf_bias", containerParams) {
+		t.Error("kernel.shmmax and energy_perf_bias should not be gated in a container")
+	}
+	if params := envGatedParams(""); len(params) != 0 {
+		t.Errorf("nothing should be gated on bare metal: %v", params)
+	}
+
+	// without the file nothing is gated
+	EnvGatedParamsFile = "/file_does_not_exist"
+	if params := envGatedParams(system.VirtKindVM); len(params) != 0 {
+		t.Errorf("nothing should be gated without file: %v", params)
+	}
+}
+
+func TestMarkEnvironmentParams(t *testing.T) {
+	defer writeEnvGatedParams(t, "[vm]\ncpu = governor\n")()
+	oldDetect := detectVirtualization
+	defer func() {
+		detectVirtualization = oldDetect
+		virtOnce = sync.Once{}
+	}()
+	newComparisons := func() map[string]note.FieldComparison {
+		return map[string]note.FieldComparison{
+			"SysctlParams[governor]":      {ReflectFieldName: "SysctlParams", ReflectMapKey: "governor", ActualValue: "all:none", ExpectedValue: "performance", MatchExpectation: false},
+			"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValue: "60", ExpectedValue: "10", MatchExpectation: false},
+		}
+	}
+
+	// the deviation is labelled, but stays non-compliant
+	detectVirtualization = func() (string, string) { return system.VirtKindVM, "kvm" }
+	virtOnce = sync.Once{}
+	comparisons := newComparisons()
+	markEnvironmentParams(comparisons)
+	if comp := comparisons["SysctlParams[governor]"]; comp.MatchExpectation || comp.NotApplicable != "kvm" {
+		t.Errorf("governor not marked as not applicable: %+v", comp)
+	}
+	if comp := comparisons["SysctlParams[vm.swappiness]"]; comp.MatchExpectation || comp.NotApplicable != "" {
+		t.Errorf("vm.swappiness marked as not applicable: %+v", comp)
+	}
+
+	detectVirtualization = func() (string, string) { return "", "" }
+	virtOnce = sync.Once{}
+	comparisons = newComparisons()
+	markEnvironmentParams(comparisons)
+	if comp := comparisons["SysctlParams[governor]"]; comp.MatchExpectation || comp.NotApplicable != "" {
+		t.Errorf("governor marked as not applicable on bare metal: %+v", comp)
+	}
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// extraNote returns the Note definition of a vendor or customer specific
+// note from the directory extraDir. Built-in notes are rejected
+func (app *App) extraNote(noteID, extraDir string) (note.INISettings, error) {
+	if _, err := app.GetNoteByID(noteID); err != nil {
+		return note.INISettings{}, err
+	}
+	iniNote, ok := app.AllNotes[noteID].(note.INISettings)
+	if !ok || path.Clean(path.Dir(iniNote.ConfFilePath)) != path.Clean(extraDir) {
+		return note.INISettings{}, fmt.Errorf("Note %s is not a vendor or customer specific Note definition from '%s'", noteID, extraDir)
+	}
+	return iniNote, nil
+}
+
+// RenameNote renames a vendor or customer specific Note definition from the
+// directory extraDir. The Note definition file and an existing override file
+// from overrideDir are renamed, the ID in the version section of the Note
+// definition file is changed and the references to the note in the
+// configuration are updated. Built-in notes and applied notes are not
+// renamed, as the saved states of the parameters refer to the Note ID
+func (app *App) RenameNote(oldID, newID, extraDir, overrideDir string) error {
+	iniNote, err := app.extraNote(oldID, extraDir)
+	if err != nil {
+		return fmt.Errorf("%v and can not be renamed", err)
+	}
+	if newID == "" || strings.ContainsAny(newID, "/- \t") {
+		return fmt.Errorf("'%s' is not a valid Note ID, the ID must not contain '/', '-' or blanks", newID)
+	}
+	if _, err := app.GetNoteByID(newID); err == nil {
+		return fmt.Errorf("Note %s already exists", newID)
+	}
+	if app.IsNoteApplied(oldID) {
+		return fmt.Errorf("Note %s is applied, please revert the note before renaming it", oldID)
+	}
+	for solName, notes := range app.AllSolutions {
+		for _, noteID := range notes {
+			if noteID == oldID {
+				return fmt.Errorf("Note %s is part of the solution %s and can not be renamed", oldID, solName)
+			}
+		}
+	}
+
+	// old style file names keep their descriptive name
+	newFile := path.Join(extraDir, newID+".conf")
+	if fileName := path.Base(iniNote.ConfFilePath); strings.HasPrefix(fileName, oldID+"-") {
+		newFile = path.Join(extraDir, newID+strings.TrimPrefix(fileName, oldID))
+	}
+	overrideFile := path.Join(overrideDir, oldID)
+	newOverrideFile := path.Join(overrideDir, newID)
+	for _, fileName := range []string{newFile, newOverrideFile} {
+		if _, err := os.Stat(fileName); err == nil {
+			return fmt.Errorf("file '%s' already exists", fileName)
+		}
+	}
+
+	// a valid checksum is renewed for the changed content, an invalid
+	// checksum stays invalid
+	checksum, _ := note.VerifyChecksum(iniNote.ConfFilePath)
+	content, err := ioutil.ReadFile(iniNote.ConfFilePath)
+	if err != nil {
+		return err
+	}
+	noteHeader := regexp.MustCompile(`(SAP-NOTE=)` + regexp.QuoteMeta(oldID) + `(\s)`)
+	content = noteHeader.ReplaceAll(content, []byte("${1}"+newID+"${2}"))
+	if err := ioutil.WriteFile(newFile, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(iniNote.ConfFilePath); err != nil {
+		return err
+	}
+	switch checksum {
+	case note.ChecksumValid:
+		if err := note.WriteChecksumFile(newFile); err != nil {
+			return err
+		}
+		if err := os.Remove(iniNote.ConfFilePath + note.ChecksumSuffix); err != nil {
+			return err
+		}
+	case note.ChecksumInvalid:
+		if err := os.Rename(iniNote.ConfFilePath+note.ChecksumSuffix, newFile+note.ChecksumSuffix); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(overrideFile); err == nil {
+		if err := os.Rename(overrideFile, newOverrideFile); err != nil {
+			return err
+		}
+	}
+
+	// URL of a downloaded Note definition
+	if source, ok := app.NoteSource(oldID); ok {
+		if err := app.saveNoteSource(newID, source); err != nil {
+			return err
+		}
+		if err := app.removeNoteSource(oldID); err != nil {
+			return err
+		}
+	}
+
+	iniNote.ID = newID
+	iniNote.ConfFilePath = newFile
+	delete(app.AllNotes, oldID)
+	app.AllNotes[newID] = iniNote
+
+	for i, noteID := range app.TuneForNotes {
+		if noteID == oldID {
+			app.TuneForNotes[i] = newID
+		}
+	}
+	sort.Strings(app.TuneForNotes)
+	for i, noteID := range app.NoteApplyOrder {
+		if noteID == oldID {
+			app.NoteApplyOrder[i] = newID
+		}
+	}
+	return app.SaveConfig()
+}
+
+// DeletableNoteFiles returns the Note definition file and the existing
+// override file of a vendor or customer specific note from the directory
+// extraDir, which will be removed by DeleteNote. An error is returned, if
+// the note can not be deleted, because it is a built-in note, it is applied
+// or enabled or it is part of an enabled solution
+func (app *App) DeletableNoteFiles(noteID, extraDir, overrideDir string) ([]string, error) {
+	iniNote, err := app.extraNote(noteID, extraDir)
+	if err != nil {
+		return nil, fmt.Errorf("%v and can not be deleted", err)
+	}
+	if solNames := app.enabledSolutionsOfNote(noteID); len(solNames) != 0 {
+		return nil, fmt.Errorf("Note %s is part of the enabled solution %s and can not be deleted", noteID, strings.Join(solNames, ", "))
+	}
+	if app.IsNoteApplied(noteID) {
+		return nil, fmt.Errorf("Note %s is applied, please revert the note before deleting it", noteID)
+	}
+	if app.PositionInNoteApplyOrder(noteID) >= 0 {
+		return nil, fmt.Errorf("Note %s is enabled, please disable the note before deleting it", noteID)
+	}
+	files := []string{iniNote.ConfFilePath}
+	if _, err := os.Stat(iniNote.ConfFilePath + note.ChecksumSuffix); err == nil {
+		files = append(files, iniNote.ConfFilePath+note.ChecksumSuffix)
+	}
+	if _, err := os.Stat(path.Join(overrideDir, noteID)); err == nil {
+		files = append(files, path.Join(overrideDir, noteID))
+	}
+	return files, nil
+}
+
+// DeleteNote removes the Note definition file of a vendor or customer
+// specific note from the directory extraDir and its override file from
+// overrideDir. The note needs to be reverted before
+func (app *App) DeleteNote(noteID, extraDir, overrideDir string) error {
+	files, err := app.DeletableNoteFiles(noteID, extraDir, overrideDir)
+	if err != nil {
+		return err
+	}
+	for _, fileName := range files {
+		if err := os.Remove(fileName); err != nil {
+			return err
+		}
+	}
+	delete(app.AllNotes, noteID)
+	return app.removeNoteSource(noteID)
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/sap/solution"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestRenameNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	extraDir := path.Join(SampleNoteDataDir, "extra")
+	overrideDir := path.Join(SampleNoteDataDir, "override")
+	for _, dir := range []string{extraDir, overrideDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	extraFile := path.Join(extraDir, "oldNote-My_Note.conf")
+	WriteFileOrPanic(extraFile, "[version]\n# SAP-NOTE=oldNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"oldNote test\"\n[sysctl]\nvm.swappiness = 10\n")
+	WriteFileOrPanic(path.Join(overrideDir, "oldNote"), "[sysctl]\nvm.swappiness = 20\n")
+	if err := note.WriteChecksumFile(extraFile); err != nil {
+		t.Fatal(err)
+	}
+	oldNote := note.INISettings{ConfFilePath: extraFile, ID: "oldNote", DescriptiveName: "My_Note"}
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "oldNote": oldNote}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	tuneApp.TuneForNotes = []string{"oldNote"}
+	tuneApp.NoteApplyOrder = []string{"1001", "oldNote"}
+	oldNote.SysctlParams = map[string]string{"vm.swappiness": "60"}
+	if err := tuneApp.State.Store("oldNote", oldNote, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// built-in notes, existing or invalid IDs are rejected
+	if err := tuneApp.RenameNote("1001", "newNote", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for a built-in note")
+	}
+	if err := tuneApp.RenameNote("oldNote", "1001", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for an existing note")
+	}
+	if err := tuneApp.RenameNote("oldNote", "new-Note", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for an invalid note ID")
+	}
+	if err := tuneApp.RenameNote("unknownNote", "newNote", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for an unknown note")
+	}
+	// the saved states of the parameters refer to the Note ID
+	if err := tuneApp.RenameNote("oldNote", "newNote", extraDir, overrideDir); err == nil || !strings.Contains(err.Error(), "please revert the note") {
+		t.Errorf("expected an error for an applied note, got '%v'", err)
+	}
+	if err := tuneApp.State.Remove("oldNote"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tuneApp.RenameNote("oldNote", "newNote", extraDir, overrideDir); err != nil {
+		t.Fatal(err)
+	}
+	newFile := path.Join(extraDir, "newNote-My_Note.conf")
+	content, err := ioutil.ReadFile(newFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "# SAP-NOTE=newNote CATEGORY=test") {
+		t.Errorf("Note ID not changed: %s", string(content))
+	}
+	if state, err := note.VerifyChecksum(newFile); state != note.ChecksumValid {
+		t.Errorf("checksum of the renamed file is %s: %v", state, err)
+	}
+	for _, fileName := range []string{extraFile, extraFile + note.ChecksumSuffix, path.Join(overrideDir, "oldNote"), tuneApp.State.GetPathToNote("oldNote")} {
+		if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+			t.Errorf("'%s' still exists", fileName)
+		}
+	}
+	if _, err := os.Stat(path.Join(overrideDir, "newNote")); err != nil {
+		t.Error(err)
+	}
+	if _, err := tuneApp.GetNoteByID("newNote"); err != nil {
+		t.Error(err)
+	}
+	tuneApp = InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if strings.Join(tuneApp.TuneForNotes, " ") != "newNote" || strings.Join(tuneApp.NoteApplyOrder, " ") != "1001 newNote" {
+		t.Error(tuneApp.TuneForNotes, tuneApp.NoteApplyOrder)
+	}
+}
+
+func TestDeleteNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	extraDir := path.Join(SampleNoteDataDir, "extra")
+	overrideDir := path.Join(SampleNoteDataDir, "override")
+	for _, dir := range []string{extraDir, overrideDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	extraFile := path.Join(extraDir, "delNote.conf")
+	overrideFile := path.Join(overrideDir, "delNote")
+	WriteFileOrPanic(extraFile, "[version]\n# SAP-NOTE=delNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"delNote test\"\n[sysctl]\nvm.swappiness = 10\n")
+	WriteFileOrPanic(overrideFile, "[sysctl]\nvm.swappiness = 20\n")
+	delNote := note.INISettings{ConfFilePath: extraFile, ID: "delNote", DescriptiveName: ""}
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "delNote": delNote}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, map[string]solution.Solution{"delSol": {"delNote"}})
+
+	if _, err := tuneApp.DeletableNoteFiles("1001", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for a built-in note")
+	}
+	tuneApp.TuneForSolutions = []string{"delSol"}
+	if _, err := tuneApp.DeletableNoteFiles("delNote", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for a note of an enabled solution")
+	}
+	tuneApp.TuneForSolutions = []string{}
+	if err := tuneApp.State.Store("delNote", delNote, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tuneApp.DeletableNoteFiles("delNote", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for an applied note")
+	}
+	if err := tuneApp.State.Remove("delNote"); err != nil {
+		t.Fatal(err)
+	}
+	tuneApp.NoteApplyOrder = []string{"delNote"}
+	if err := tuneApp.DeleteNote("delNote", extraDir, overrideDir); err == nil {
+		t.Error("expected an error for an enabled note")
+	}
+	tuneApp.NoteApplyOrder = []string{}
+
+	files, err := tuneApp.DeletableNoteFiles("delNote", extraDir, overrideDir)
+	if err != nil || strings.Join(files, " ") != extraFile+" "+overrideFile {
+		t.Fatal(files, err)
+	}
+	if err := tuneApp.DeleteNote("delNote", extraDir, overrideDir); err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range files {
+		if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+			t.Errorf("'%s' still exists", fileName)
+		}
+	}
+	if _, err := tuneApp.GetNoteByID("delNote"); err == nil {
+		t.Error("note still available")
+	}
+}
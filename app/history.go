@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path"
+	"time"
+)
+
+// SaptuneHistoryFile defines the file, which records the apply, revert and
+// customise actions. It is kept separate from the log file of tuned
+const SaptuneHistoryFile = "/var/log/saptune/history"
+
+// MaxHistorySize is the size of the history file, which causes the rotation
+// of the file. Only one rotated file is kept
+const MaxHistorySize = 1024 * 1024
+
+// HistoryEntry describes an apply, revert or customise action
+type HistoryEntry struct {
+	Time   string `json:"time"`
+	User   string `json:"user"`
+	Action string `json:"action"`
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Result string `json:"result"`
+}
+
+// GetPathToHistory returns path to the history file.
+func (app *App) GetPathToHistory() string {
+	return path.Join(app.State.StateDirPrefix, SaptuneHistoryFile)
+}
+
+// HistoryUser returns the user, who started saptune. If saptune was
+// started by sudo, the calling user is returned
+func HistoryUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "unknown"
+}
+
+// RecordHistory appends an entry for the action on the note or solution to
+// the history file. The result is 'success' or the error message, if the
+// action failed. If the history file is larger than MaxHistorySize, it is
+// rotated before
+func (app *App) RecordHistory(action, objType, objID string, actionErr error) error {
+	result := "success"
+	if actionErr != nil {
+		result = "failed: " + actionErr.Error()
+	}
+	entry := HistoryEntry{
+		Time:   time.Now().Format(time.RFC3339),
+		User:   HistoryUser(),
+		Action: action,
+		Type:   objType,
+		ID:     objID,
+		Result: result,
+	}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	histFile := app.GetPathToHistory()
+	if err := os.MkdirAll(path.Dir(histFile), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(histFile); err == nil && info.Size() >= MaxHistorySize {
+		if err := os.Rename(histFile, histFile+".1"); err != nil {
+			return err
+		}
+	}
+	// append only
+	hist, err := os.OpenFile(histFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := hist.Write(append(content, '\n')); err != nil {
+		hist.Close()
+		return err
+	}
+	return hist.Close()
+}
+
+// ReadHistory returns the entries of the history file and of the rotated
+// history file, which were recorded at or after 'since', in the order they
+// were recorded. Lines, which can not be parsed, are skipped
+func (app *App) ReadHistory(since time.Time) ([]HistoryEntry, error) {
+	entries := make([]HistoryEntry, 0)
+	for _, fileName := range []string{app.GetPathToHistory() + ".1", app.GetPathToHistory()} {
+		hist, err := os.Open(fileName)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return entries, err
+		}
+		scanner := bufio.NewScanner(hist)
+		for scanner.Scan() {
+			entry := HistoryEntry{}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			if recorded, err := time.Parse(time.RFC3339, entry.Time); err != nil || recorded.Before(since) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		err = scanner.Err()
+		hist.Close()
+		if err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordHistory(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	os.Setenv("SUDO_USER", "histuser")
+	defer os.Unsetenv("SUDO_USER")
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+
+	if entries, err := tuneApp.ReadHistory(time.Time{}); err != nil || len(entries) != 0 {
+		t.Fatal(entries, err)
+	}
+	if err := tuneApp.RecordHistory("apply", "note", "1001", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.RecordHistory("revert", "solution", "sol1", errors.New("revert failed")); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := tuneApp.ReadHistory(time.Time{})
+	if err != nil || len(entries) != 2 {
+		t.Fatal(entries, err)
+	}
+	if entries[0].User != "histuser" || entries[0].Action != "apply" || entries[0].Type != "note" || entries[0].ID != "1001" || entries[0].Result != "success" {
+		t.Fatal(entries[0])
+	}
+	if entries[1].Action != "revert" || entries[1].Type != "solution" || entries[1].ID != "sol1" || entries[1].Result != "failed: revert failed" {
+		t.Fatal(entries[1])
+	}
+	if entries, err := tuneApp.ReadHistory(time.Now().Add(time.Hour)); err != nil || len(entries) != 0 {
+		t.Fatal(entries, err)
+	}
+
+	// the history file is rotated, if it is too large
+	if err := ioutil.WriteFile(tuneApp.GetPathToHistory(), []byte(strings.Repeat("garbage\n", MaxHistorySize/8)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.RecordHistory("customise", "note", "1002", nil); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(tuneApp.GetPathToHistory() + ".1"); err != nil || info.Size() != MaxHistorySize {
+		t.Fatal(info, err)
+	}
+	// unparsable lines are skipped
+	entries, err = tuneApp.ReadHistory(time.Time{})
+	if err != nil || len(entries) != 1 || entries[0].Action != "customise" {
+		t.Fatal(entries, err)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"time"
+)
+
+// SaptuneJournalDir defines saptunes journal directory
+const SaptuneJournalDir = "/var/lib/saptune/journal"
+
+// JournalEntry marks the apply of a note as started. The entry is written
+// before the first parameter is changed and removed after the note was
+// applied completely, so a left over entry shows, that the apply of the
+// note was interrupted. The values of the parameters before the apply are
+// not part of the entry, as they are available in the state file of the
+// note, which is written before the first parameter is changed, too, and
+// which is kept by a following apply or used by a following revert.
+type JournalEntry struct {
+	NoteID  string
+	Started string
+}
+
+// GetPathToJournal returns path to the journal file of a note.
+func (app *App) GetPathToJournal(noteID string) string {
+	return path.Join(app.State.StateDirPrefix, SaptuneJournalDir, noteID)
+}
+
+// startJournal writes the journal entry of a note before the note is
+// applied. An existing entry is not overwritten, as it records the start of
+// the interrupted apply
+func (app *App) startJournal(noteID string) error {
+	if app.IsNoteInterrupted(noteID) {
+		return nil
+	}
+	entry := JournalEntry{NoteID: noteID, Started: time.Now().Format(time.RFC3339)}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(path.Join(app.State.StateDirPrefix, SaptuneJournalDir), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(app.GetPathToJournal(noteID), content, 0644)
+}
+
+// finishJournal removes the journal entry of a note after the note was
+// applied or reverted completely
+func (app *App) finishJournal(noteID string) error {
+	if err := os.Remove(app.GetPathToJournal(noteID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReadJournal reads the journal entry of a note
+func (app *App) ReadJournal(noteID string) (entry JournalEntry, err error) {
+	content, err := ioutil.ReadFile(app.GetPathToJournal(noteID))
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(content, &entry)
+	return
+}
+
+// IsNoteInterrupted returns true, if the apply of the note was interrupted
+func (app *App) IsNoteInterrupted(noteID string) bool {
+	_, err := os.Stat(app.GetPathToJournal(noteID))
+	return err == nil
+}
+
+// InterruptedNotes returns the IDs of the notes, whose apply was
+// interrupted, e.g. because saptune was killed. The system may be tuned
+// only partially for these notes
+func (app *App) InterruptedNotes() []string {
+	interrupted := make([]string, 0)
+	dirContent, err := ioutil.ReadDir(path.Join(app.State.StateDirPrefix, SaptuneJournalDir))
+	if err != nil {
+		return interrupted
+	}
+	for _, info := range dirContent {
+		interrupted = append(interrupted, info.Name())
+	}
+	sort.Strings(interrupted)
+	return interrupted
+}
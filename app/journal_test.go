@@ -0,0 +1,71 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[grub]\nnuma_balancing=disable\n")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "iniNote": note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	// a clean apply does not leave a journal
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	if interrupted := tuneApp.InterruptedNotes(); len(interrupted) != 0 {
+		t.Fatal(interrupted)
+	}
+
+	// simulate an interrupted apply
+	if err := tuneApp.startJournal("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if interrupted := tuneApp.InterruptedNotes(); !reflect.DeepEqual(interrupted, []string{"iniNote"}) || !tuneApp.IsNoteInterrupted("iniNote") {
+		t.Fatal(interrupted)
+	}
+	started, err := tuneApp.ReadJournal("iniNote")
+	if err != nil || started.NoteID != "iniNote" {
+		t.Fatal(started, err)
+	}
+	// the start of the interrupted apply is kept
+	if err := tuneApp.startJournal("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := tuneApp.ReadJournal("iniNote")
+	if err != nil || entry != started {
+		t.Fatal(entry, err)
+	}
+
+	// finishing the apply removes the journal
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if tuneApp.IsNoteInterrupted("iniNote") {
+		t.Fatal("journal not removed after apply")
+	}
+
+	// reverting the note removes the journal, too
+	if err := tuneApp.startJournal("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.RevertNote("iniNote", true); err != nil {
+		t.Fatal(err)
+	}
+	if interrupted := tuneApp.InterruptedNotes(); len(interrupted) != 0 {
+		t.Fatal(interrupted)
+	}
+	if err := tuneApp.RevertAll(true); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+// SaptuneLastVerifyFile defines the file, which contains the compliance of
+// the parameters found by the last verify of the notes
+const SaptuneLastVerifyFile = "/var/lib/saptune/last_verify"
+
+// LastVerify contains the compliance of the parameters of the notes found
+// by the last verify. The compliance is stored per note and parameter
+type LastVerify struct {
+	Created    string
+	Compliance map[string]map[string]bool
+}
+
+// GetPathToLastVerify returns path to the file containing the result of
+// the last verify.
+func (app *App) GetPathToLastVerify() string {
+	return path.Join(app.State.StateDirPrefix, SaptuneLastVerifyFile)
+}
+
+// ReadLastVerify reads the result of the last verify. If the notes were
+// never verified before, an empty result is returned
+func (app *App) ReadLastVerify() (LastVerify, error) {
+	last := LastVerify{}
+	content, err := ioutil.ReadFile(app.GetPathToLastVerify())
+	if err != nil && !os.IsNotExist(err) {
+		return last, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(content, &last); err != nil {
+			return last, err
+		}
+	}
+	if last.Compliance == nil {
+		last.Compliance = make(map[string]map[string]bool)
+	}
+	return last, nil
+}
+
+// SaveLastVerify saves the compliance of the parameters of the verified
+// notes as the result of the last verify. The results of notes not
+// verified this time are kept
+func (app *App) SaveLastVerify(comparisons map[string]map[string]note.FieldComparison) error {
+	last, err := app.ReadLastVerify()
+	if err != nil {
+		// replace the unreadable result
+		last = LastVerify{Compliance: make(map[string]map[string]bool)}
+	}
+	last.Created = time.Now().Format(time.RFC3339)
+	for noteID, noteComparisons := range comparisons {
+		last.Compliance[noteID] = make(map[string]bool)
+		for _, comparison := range noteComparisons {
+			if snapshotValue(comparison) {
+				last.Compliance[noteID][comparison.ReflectMapKey] = comparison.MatchExpectation
+			}
+		}
+	}
+	content, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(path.Dir(app.GetPathToLastVerify()), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(app.GetPathToLastVerify(), content, 0644)
+}
+
+// ComplianceChanges returns the comparisons of the parameters, whose
+// compliance changed since the last verify. A parameter not verified
+// before is only returned, if it is not compliant. The fields without map
+// key like the Note ID are kept for the notes with changed parameters.
+func ComplianceChanges(last LastVerify, comparisons map[string]map[string]note.FieldComparison) map[string]map[string]note.FieldComparison {
+	changes := make(map[string]map[string]note.FieldComparison)
+	for noteID, noteComparisons := range comparisons {
+		changedParams := make(map[string]bool)
+		for _, comparison := range noteComparisons {
+			if !snapshotValue(comparison) {
+				continue
+			}
+			wasCompliant, known := last.Compliance[noteID][comparison.ReflectMapKey]
+			if (known && wasCompliant != comparison.MatchExpectation) || (!known && !comparison.MatchExpectation) {
+				changedParams[comparison.ReflectMapKey] = true
+			}
+		}
+		if len(changedParams) == 0 {
+			continue
+		}
+		changes[noteID] = make(map[string]note.FieldComparison)
+		for key, comparison := range noteComparisons {
+			if comparison.ReflectMapKey == "" || changedParams[comparison.ReflectMapKey] {
+				changes[noteID][key] = comparison
+			}
+		}
+	}
+	return changes
+}
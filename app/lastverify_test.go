@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLastVerify(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+
+	last, err := tuneApp.ReadLastVerify()
+	if err != nil || len(last.Compliance) != 0 {
+		t.Fatal(last, err)
+	}
+	param := func(key string, compliant bool) note.FieldComparison {
+		return note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: key, MatchExpectation: compliant}
+	}
+	comparisons := map[string]map[string]note.FieldComparison{
+		"1001": {
+			"ID":                      {ReflectFieldName: "ID"},
+			"SysctlParams[always]":    param("always", false),
+			"SysctlParams[fixed]":     param("fixed", false),
+			"SysctlParams[compliant]": param("compliant", true),
+		},
+	}
+	if err := tuneApp.SaveLastVerify(comparisons); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.SaveLastVerify(map[string]map[string]note.FieldComparison{"1002": {"SysctlParams[other]": param("other", true)}}); err != nil {
+		t.Fatal(err)
+	}
+	last, err = tuneApp.ReadLastVerify()
+	if err != nil || last.Created == "" || len(last.Compliance["1001"]) != 3 || !last.Compliance["1002"]["other"] {
+		t.Fatal(last, err)
+	}
+
+	comparisons["1001"]["SysctlParams[fixed]"] = param("fixed", true)
+	comparisons["1001"]["SysctlParams[compliant]"] = param("compliant", false)
+	comparisons["1001"]["SysctlParams[new]"] = param("new", false)
+	comparisons["1001"]["SysctlParams[newOK]"] = param("newOK", true)
+	changes := ComplianceChanges(last, comparisons)
+	if len(changes) != 1 {
+		t.Fatal(changes)
+	}
+	for _, key := range []string{"ID", "SysctlParams[fixed]", "SysctlParams[compliant]", "SysctlParams[new]"} {
+		if _, ok := changes["1001"][key]; !ok {
+			t.Errorf("missing '%s' in %v", key, changes)
+		}
+	}
+	if len(changes["1001"]) != 4 {
+		t.Error(changes)
+	}
+	if changes := ComplianceChanges(last, map[string]map[string]note.FieldComparison{"1002": {"SysctlParams[other]": param("other", true)}}); len(changes) != 0 {
+		t.Error(changes)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// SaptunePartialDir defines saptunes directory of the parameters selected by
+// 'saptune note apply --only' for the partially applied notes
+const SaptunePartialDir = "/var/lib/saptune/partial"
+
+// GetPathToPartial returns path to the file containing the selected
+// parameters of a partially applied note.
+func (app *App) GetPathToPartial(noteID string) string {
+	return path.Join(app.State.StateDirPrefix, SaptunePartialDir, noteID)
+}
+
+// NoteParamSelection returns the parameters of the note selected by
+// 'saptune note apply --only'. The second return value is false, if the
+// note is not applied partially
+func (app *App) NoteParamSelection(noteID string) ([]string, bool) {
+	content, err := ioutil.ReadFile(app.GetPathToPartial(noteID))
+	if err != nil {
+		return nil, false
+	}
+	params := strings.Fields(string(content))
+	return params, len(params) != 0
+}
+
+// TuneNoteParams applies only the given parameters of the note. The other
+// parameters of the note are not managed, they are neither set nor saved
+// and are shown as 'not managed' during verify. The selection is kept, when
+// the tuning is reverted and applied again by the daemon, and removed, when
+// the note is reverted permanently.
+// Parameter names not defined by the note are refused. A note, which is
+// already applied, needs to be reverted before.
+func (app *App) TuneNoteParams(noteID string, params []string) error {
+	aNote, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return fmt.Errorf("applying single parameters is not supported for note %s", noteID)
+	}
+	if len(params) == 0 {
+		return fmt.Errorf("no parameter of note %s selected", noteID)
+	}
+	if app.PositionInNoteApplyOrder(noteID) >= 0 {
+		return fmt.Errorf("note %s is already applied. Please revert it before applying only some of its parameters", noteID)
+	}
+	defined, err := iniNote.DefinedParams()
+	if err != nil {
+		return fmt.Errorf("Failed to read the definition of Note %s - %v", noteID, err)
+	}
+	unknown := make([]string, 0)
+	for _, param := range params {
+		if _, ok := defined[param]; !ok {
+			unknown = append(unknown, param)
+		}
+	}
+	if len(unknown) != 0 {
+		return fmt.Errorf("the parameter(s) '%s' are not defined by note %s", strings.Join(unknown, "', '"), noteID)
+	}
+	if err := os.MkdirAll(path.Join(app.State.StateDirPrefix, SaptunePartialDir), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(app.GetPathToPartial(noteID), []byte(strings.Join(params, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := app.TuneNote(noteID); err != nil {
+		app.removeParamSelection(noteID)
+		return err
+	}
+	return nil
+}
+
+// removeParamSelection removes the selected parameters of a partially
+// applied note
+func (app *App) removeParamSelection(noteID string) error {
+	if err := os.Remove(app.GetPathToPartial(noteID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestTuneNoteParams(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hdl := reloadHandler{values: map[string]string{"partial.param1": "1", "partial.param2": "2", "partial.param3": "3"}}
+	if err := note.RegisterSectionHandler("partialtest", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer note.UnregisterSectionHandler("partialtest")
+	for _, param := range []string{"partial.param1", "partial.param2", "partial.param3"} {
+		defer note.CleanUpParamFile(param)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[partialtest]\npartial.param1 = 5\npartial.param2 = 7\npartial.param3 = 9\n")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "iniNote": note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	if err := tuneApp.TuneNoteParams("iniNote", []string{"partial.param1", "no.such.param"}); err == nil {
+		t.Fatal("expected an error for an unknown parameter")
+	}
+	if _, ok := tuneApp.NoteParamSelection("iniNote"); ok || tuneApp.PositionInNoteApplyOrder("iniNote") >= 0 {
+		t.Fatal("the note must not be applied after an error")
+	}
+	if err := tuneApp.TuneNoteParams("1001", []string{"param"}); err == nil {
+		t.Fatal("expected an error for a note without Note definition file")
+	}
+
+	if err := tuneApp.TuneNoteParams("iniNote", []string{"partial.param1", "partial.param3"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hdl.values, map[string]string{"partial.param1": "5", "partial.param2": "2", "partial.param3": "9"}) {
+		t.Fatal(hdl.values)
+	}
+	if params, ok := tuneApp.NoteParamSelection("iniNote"); !ok || !reflect.DeepEqual(params, []string{"partial.param1", "partial.param3"}) {
+		t.Fatal(params, ok)
+	}
+	if err := tuneApp.TuneNoteParams("iniNote", []string{"partial.param2"}); err == nil {
+		t.Fatal("expected an error for an already applied note")
+	}
+
+	// the saved state contains only the selected parameters
+	var saved note.INISettings
+	if err := tuneApp.State.Retrieve("iniNote", &saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.SysctlParams["partial.param2"]; ok || saved.SysctlParams["partial.param1"] != "1" {
+		t.Fatal(saved.SysctlParams)
+	}
+
+	// the other parameters are not managed and conform
+	hdl.values["partial.param2"] = "4"
+	conforming, comparisons, _, err := tuneApp.VerifyNote("iniNote")
+	if err != nil || !conforming {
+		t.Fatal(conforming, comparisons, err)
+	}
+	if comparisons["OverrideParams[partial.param2]"].ExpectedValueJS != note.NotManaged {
+		t.Error(comparisons["OverrideParams[partial.param2]"])
+	}
+	if comparisons["SysctlParams[partial.param2]"].ExpectedValueJS != "4" || comparisons["SysctlParams[partial.param1]"].ExpectedValueJS != "5" {
+		t.Error(comparisons)
+	}
+
+	// a revert by the daemon keeps the selection
+	if err := tuneApp.RevertNote("iniNote", false); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["partial.param1"] != "1" || hdl.values["partial.param2"] != "4" || hdl.values["partial.param3"] != "3" {
+		t.Fatal(hdl.values)
+	}
+	if _, ok := tuneApp.NoteParamSelection("iniNote"); !ok {
+		t.Fatal("the selection was removed by a temporary revert")
+	}
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["partial.param1"] != "5" || hdl.values["partial.param2"] != "4" {
+		t.Fatal(hdl.values)
+	}
+
+	// a permanent revert removes the selection
+	if err := tuneApp.RevertNote("iniNote", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tuneApp.NoteParamSelection("iniNote"); ok {
+		t.Fatal("the selection was not removed")
+	}
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["partial.param2"] != "7" {
+		t.Fatal(hdl.values)
+	}
+	if err := tuneApp.RevertNote("iniNote", true); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,274 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ReloadedParameter describes a parameter changed by ReloadNotes
+type ReloadedParameter struct {
+	NoteID   string
+	Param    string
+	OldValue string
+	NewValue string
+}
+
+// ReloadNotes re-reads the Note definition and override files of the
+// applied notes and sets only the parameters, whose current value differs
+// from the freshly parsed expected value. In contrast to a revert and
+// re-apply of all notes the conforming parameters are not touched and the
+// saved states of the notes are kept, so that a later revert still restores
+// the values from before the first apply. Parameters not yet part of the
+// saved state of a note, e.g. added by an override file, are added to the
+// saved state with their current value. Parameters removed from the Note
+// definition are reverted and removed from the saved state.
+// A parameter defined by several notes is only set from the last of these
+// notes in the apply order, as its value wins.
+// It returns the changed parameters in the apply order of the notes.
+func (app *App) ReloadNotes() ([]ReloadedParameter, error) {
+	reloaded := make([]ReloadedParameter, 0)
+	_, comparisons, err := app.VerifyAll()
+	if err != nil {
+		return reloaded, err
+	}
+	owners := app.paramOwners(comparisons)
+	for _, noteID := range app.NoteApplyOrder {
+		aNote, err := app.GetNoteByID(noteID)
+		if err != nil {
+			return reloaded, err
+		}
+		iniNote, ok := aNote.(note.INISettings)
+		if !ok || iniNote.CheckOnly() || !app.IsNoteApplied(noteID) {
+			continue
+		}
+		reverted, err := app.revertRemovedParams(noteID, iniNote)
+		reloaded = append(reloaded, reverted...)
+		if err != nil {
+			return reloaded, err
+		}
+		params := make([]string, 0)
+		for _, param := range reloadParams(comparisons[noteID]) {
+			if owners[param] == noteID {
+				params = append(params, param)
+			}
+		}
+		if len(params) == 0 {
+			continue
+		}
+		if !app.IgnoreBounds {
+			if err := checkNoteBounds(noteID, aNote, comparisons[noteID], params); err != nil {
+				return reloaded, err
+			}
+		}
+		updated, err := app.reloadNote(noteID, iniNote, comparisons[noteID], params)
+		reloaded = append(reloaded, updated...)
+		if err != nil {
+			return reloaded, err
+		}
+		system.EventLog("reload", noteID, "Note '%s' reloaded, %d parameter(s) updated", noteID, len(params))
+	}
+	return reloaded, nil
+}
+
+// RefreshNote sets the parameters of an applied note again, whose current
+// value deviates from the expected value, e.g. after they were changed
+// outside of saptune. Like ReloadNotes the conforming parameters are not
+// touched and the saved state of the note is kept, so that a later revert
+// still restores the values from before the note was applied. Parameters
+// tuned by another note applied later are not set, as the value of the
+// later note wins.
+// It returns the changed parameters.
+func (app *App) RefreshNote(noteID string) ([]ReloadedParameter, error) {
+	refreshed := make([]ReloadedParameter, 0)
+	aNote, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return refreshed, err
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return refreshed, fmt.Errorf("refreshing is not supported for note %s", noteID)
+	}
+	if !app.IsNoteApplied(noteID) {
+		return refreshed, fmt.Errorf("note %s is not applied, so there is nothing to refresh", noteID)
+	}
+	if iniNote.CheckOnly() {
+		// nothing is set for a 'check only' note
+		return refreshed, nil
+	}
+	_, comparisons, _, err := app.VerifyNote(noteID)
+	if err != nil {
+		return refreshed, err
+	}
+	params := make([]string, 0)
+	for _, param := range reloadParams(comparisons) {
+		if isParamOwner(noteID, param) {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return refreshed, nil
+	}
+	if !app.IgnoreBounds {
+		if err := checkNoteBounds(noteID, aNote, comparisons, params); err != nil {
+			return refreshed, err
+		}
+	}
+	refreshed, err = app.reloadNote(noteID, iniNote, comparisons, params)
+	if err != nil {
+		return refreshed, err
+	}
+	system.EventLog("refresh", noteID, "Note '%s' refreshed, %d parameter(s) set again", noteID, len(params))
+	return refreshed, nil
+}
+
+// paramOwners returns for each parameter the last applied note in the apply
+// order, which sets the parameter. Its value is the one, which wins
+func (app *App) paramOwners(comparisons map[string]map[string]note.FieldComparison) map[string]string {
+	owners := make(map[string]string)
+	for _, noteID := range app.NoteApplyOrder {
+		if iniNote, ok := app.AllNotes[noteID].(note.INISettings); (ok && iniNote.CheckOnly()) || !app.IsNoteApplied(noteID) {
+			continue
+		}
+		for _, comparison := range comparisons[noteID] {
+			if comparison.ReflectFieldName == "SysctlParams" && comparison.ReflectMapKey != "" {
+				owners[comparison.ReflectMapKey] = noteID
+			}
+		}
+	}
+	return owners
+}
+
+// isParamOwner returns true, if the note is the last note in the parameter
+// saved state file of the parameter, so its value is the one, which wins.
+// A parameter without saved state is not set by another note
+func isParamOwner(noteID, param string) bool {
+	entries := note.GetSavedParameterNotes(param).AllNotes
+	if len(entries) == 0 {
+		return true
+	}
+	owner := entries[len(entries)-1].NoteID
+	return owner == noteID || owner == "start"
+}
+
+// revertRemovedParams reverts the parameters of an applied note, which were
+// removed from the Note definition after the note was applied, and removes
+// them from the saved state of the note
+func (app *App) revertRemovedParams(noteID string, iniNote note.INISettings) ([]ReloadedParameter, error) {
+	reverted := make([]ReloadedParameter, 0)
+	var savedState note.INISettings
+	if err := app.State.Retrieve(noteID, &savedState); err != nil {
+		return reverted, err
+	}
+	savedState.ConfFilePath = iniNote.ConfFilePath
+	savedState.IncludeFiles = iniNote.IncludeFiles
+	removed, err := savedState.RemovedParams()
+	if err != nil || len(removed) == 0 {
+		return reverted, err
+	}
+	// the value set by the note is reported as former value
+	oldValues := make(map[string]string)
+	for _, param := range removed {
+		pEntries := note.GetSavedParameterNotes(param)
+		if pos := note.PositionInParameterList(noteID, pEntries.AllNotes); pos > 0 {
+			oldValues[param] = pEntries.AllNotes[pos].Value
+		}
+	}
+	if err := savedState.SetValuesToApply(append([]string{"revert"}, removed...)).Apply(); err != nil {
+		return reverted, fmt.Errorf("Failed to revert the parameters removed from note %s - %v", noteID, err)
+	}
+	for _, param := range removed {
+		reverted = append(reverted, ReloadedParameter{NoteID: noteID, Param: param, OldValue: oldValues[param], NewValue: savedState.SysctlParams[param]})
+		delete(savedState.SysctlParams, param)
+	}
+	sections := make([]string, 0, len(savedState.ParamSections))
+	for _, sectionParam := range savedState.ParamSections {
+		fields := strings.SplitN(sectionParam, ":", 2)
+		if _, ok := savedState.SysctlParams[fields[len(fields)-1]]; ok {
+			sections = append(sections, sectionParam)
+		}
+	}
+	savedState.ParamSections = sections
+	if err := app.State.Store(noteID, savedState, true); err != nil {
+		return reverted, fmt.Errorf("Failed to save current state of note %s - %v", noteID, err)
+	}
+	system.EventLog("reload", noteID, "Note '%s' reloaded, %d removed parameter(s) reverted", noteID, len(removed))
+	return reverted, nil
+}
+
+// reloadParams returns the sorted names of the deviating parameters of a
+// note, which can be set
+func reloadParams(comparisons map[string]note.FieldComparison) []string {
+	params := make([]string, 0)
+	for _, comparison := range comparisons {
+		if comparison.MatchExpectation || comparison.ReflectFieldName != "SysctlParams" {
+			continue
+		}
+		switch strings.Split(comparison.ReflectMapKey, ":")[0] {
+		case "rpm", "grub", "reminder":
+			// only checked, never set
+			continue
+		}
+		params = append(params, comparison.ReflectMapKey)
+	}
+	sort.Strings(params)
+	return params
+}
+
+// reloadNote sets the given parameters of an applied note to the expected
+// values and adds new parameters to the saved state of the note
+func (app *App) reloadNote(noteID string, iniNote note.INISettings, comparisons map[string]note.FieldComparison, params []string) ([]ReloadedParameter, error) {
+	updated := make([]ReloadedParameter, 0, len(params))
+	var savedState note.INISettings
+	if err := app.State.Retrieve(noteID, &savedState); err != nil && !os.IsNotExist(err) {
+		return updated, err
+	}
+	if savedState.SysctlParams == nil {
+		savedState.SysctlParams = make(map[string]string)
+	}
+
+	// the 'verify' workaround prevents the writing of the parameter
+	// saved state files during Initialise and Optimise
+	currentState, err := iniNote.SetValuesToApply([]string{"verify"}).Initialise()
+	if err != nil {
+		return updated, fmt.Errorf("Failed to examine system for the current status of note %s - %v", noteID, err)
+	}
+	stateChanged := false
+	for _, param := range params {
+		actval, _ := comparisons[fmt.Sprintf("SysctlParams[%s]", param)].ActualValue.(string)
+		if _, ok := savedState.SysctlParams[param]; !ok {
+			savedState.SysctlParams[param] = actval
+			for _, sectionParam := range currentState.(note.INISettings).ParamSections {
+				if fields := strings.SplitN(sectionParam, ":", 2); len(fields) == 2 && fields[1] == param {
+					savedState.ParamSections = append(savedState.ParamSections, sectionParam)
+					break
+				}
+			}
+			note.CreateParameterStartValues(param, actval)
+			stateChanged = true
+		}
+	}
+	if stateChanged {
+		if err := app.State.Store(noteID, savedState, true); err != nil {
+			return updated, fmt.Errorf("Failed to save current state of note %s - %v", noteID, err)
+		}
+	}
+	optimised, err := currentState.Optimise()
+	if err != nil {
+		return updated, fmt.Errorf("Failed to calculate optimised parameters for note %s - %v", noteID, err)
+	}
+	optimisedNote := optimised.(note.INISettings)
+	if err := optimisedNote.SetValuesToApply(params).Apply(); err != nil {
+		return updated, fmt.Errorf("Failed to apply note %s - %v", noteID, err)
+	}
+	for _, param := range params {
+		newval := optimisedNote.SysctlParams[param]
+		note.UpdateParameterNoteValues(param, newval, noteID)
+		actval, _ := comparisons[fmt.Sprintf("SysctlParams[%s]", param)].ActualValue.(string)
+		updated = append(updated, ReloadedParameter{NoteID: noteID, Param: param, OldValue: actval, NewValue: newval})
+	}
+	return updated, nil
+}
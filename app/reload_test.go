@@ -0,0 +1,271 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+// reloadHandler is a section handler keeping the parameter values in a map
+type reloadHandler struct {
+	values map[string]string
+}
+
+func (hdl reloadHandler) Get(key string) (string, error) {
+	return hdl.values[key], nil
+}
+
+func (hdl reloadHandler) Opt(key, actval, cfgval string) string {
+	return cfgval
+}
+
+func (hdl reloadHandler) Set(key, value string, revert bool) error {
+	hdl.values[key] = value
+	return nil
+}
+
+func TestReloadNotes(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hdl := reloadHandler{values: map[string]string{"reload.param1": "1", "reload.param2": "2", "reload.param3": "3"}}
+	if err := note.RegisterSectionHandler("reloadtest", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer note.UnregisterSectionHandler("reloadtest")
+	for _, param := range []string{"reload.param1", "reload.param2", "reload.param3"} {
+		defer note.CleanUpParamFile(param)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[reloadtest]\nreload.param1 = 5\nreload.param2 = 7\n")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "iniNote": note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if err := tuneApp.TuneNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing changed
+	reloaded, err := tuneApp.ReloadNotes()
+	if err != nil || len(reloaded) != 0 {
+		t.Fatal(reloaded, err)
+	}
+
+	// change a value and add a new parameter
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[reloadtest]\nreload.param1 = 6\nreload.param2 = 7\nreload.param3 = 9\n")
+	reloaded, err = tuneApp.ReloadNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ReloadedParameter{
+		{NoteID: "iniNote", Param: "reload.param1", OldValue: "5", NewValue: "6"},
+		{NoteID: "iniNote", Param: "reload.param3", OldValue: "3", NewValue: "9"},
+	}
+	if !reflect.DeepEqual(reloaded, expected) {
+		t.Fatal(reloaded)
+	}
+	if !reflect.DeepEqual(hdl.values, map[string]string{"reload.param1": "6", "reload.param2": "7", "reload.param3": "9"}) {
+		t.Fatal(hdl.values)
+	}
+	// the values from before the first apply are kept
+	var stored note.INISettings
+	if err := tuneApp.State.Retrieve("iniNote", &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored.SysctlParams, map[string]string{"reload.param1": "1", "reload.param2": "2", "reload.param3": "3"}) {
+		t.Fatal(stored.SysctlParams)
+	}
+	if reloaded, err = tuneApp.ReloadNotes(); err != nil || len(reloaded) != 0 {
+		t.Fatal(reloaded, err)
+	}
+
+	// values outside of the bounds are refused
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[reloadtest]\nreload.param1 = 60\nreload.param2 = 7\nreload.param3 = 9\n[bounds]\nreload.param1 = 1:10\n")
+	if _, err := tuneApp.ReloadNotes(); err == nil {
+		t.Fatal("expected an error for a value outside of the bounds")
+	}
+	if hdl.values["reload.param1"] != "6" {
+		t.Fatal(hdl.values)
+	}
+	tuneApp.IgnoreBounds = true
+	if reloaded, err = tuneApp.ReloadNotes(); err != nil || len(reloaded) != 1 || hdl.values["reload.param1"] != "60" {
+		t.Fatal(reloaded, err, hdl.values)
+	}
+
+	// the revert restores the values from before the first apply
+	if err := tuneApp.RevertNote("iniNote", true); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hdl.values, map[string]string{"reload.param1": "1", "reload.param2": "2", "reload.param3": "3"}) {
+		t.Fatal(hdl.values)
+	}
+}
+
+func TestReloadNotesOwnersAndRemoved(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hdl := reloadHandler{values: map[string]string{"owner.param1": "1", "owner.param2": "2"}}
+	if err := note.RegisterSectionHandler("ownertest", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer note.UnregisterSectionHandler("ownertest")
+	for _, param := range []string{"owner.param1", "owner.param2"} {
+		defer note.CleanUpParamFile(param)
+	}
+	fileA := path.Join(SampleNoteDataDir, "noteA")
+	fileB := path.Join(SampleNoteDataDir, "noteB")
+	WriteFileOrPanic(fileA, "[ownertest]\nowner.param1 = 5\nowner.param2 = 7\n")
+	WriteFileOrPanic(fileB, "[ownertest]\nowner.param1 = 8\n")
+	allNotes := map[string]note.Note{"noteA": note.INISettings{ConfFilePath: fileA, ID: "noteA"}, "noteB": note.INISettings{ConfFilePath: fileB, ID: "noteB"}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	for _, noteID := range []string{"noteA", "noteB"} {
+		if err := tuneApp.TuneNote(noteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(hdl.values, map[string]string{"owner.param1": "8", "owner.param2": "7"}) {
+		t.Fatal(hdl.values)
+	}
+
+	// the value of the last note in the apply order wins, so the changed
+	// value of the first note is not set
+	WriteFileOrPanic(fileA, "[ownertest]\nowner.param1 = 6\nowner.param2 = 7\n")
+	if reloaded, err := tuneApp.ReloadNotes(); err != nil || len(reloaded) != 0 {
+		t.Fatal(reloaded, err)
+	}
+	if hdl.values["owner.param1"] != "8" {
+		t.Fatal(hdl.values)
+	}
+
+	// a parameter removed from the Note definition is reverted
+	WriteFileOrPanic(fileA, "[ownertest]\nowner.param1 = 6\n")
+	reloaded, err := tuneApp.ReloadNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded, []ReloadedParameter{{NoteID: "noteA", Param: "owner.param2", OldValue: "7", NewValue: "2"}}) {
+		t.Fatal(reloaded)
+	}
+	if !reflect.DeepEqual(hdl.values, map[string]string{"owner.param1": "8", "owner.param2": "2"}) {
+		t.Fatal(hdl.values)
+	}
+	var stored note.INISettings
+	if err := tuneApp.State.Retrieve("noteA", &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored.SysctlParams, map[string]string{"owner.param1": "1"}) || !reflect.DeepEqual(stored.ParamSections, []string{"ownertest:owner.param1"}) {
+		t.Fatal(stored.SysctlParams, stored.ParamSections)
+	}
+	if !note.IsLastNoteOfParameter("owner.param2") {
+		t.Error("parameter saved state file of the removed parameter not cleaned up")
+	}
+	if reloaded, err := tuneApp.ReloadNotes(); err != nil || len(reloaded) != 0 {
+		t.Fatal(reloaded, err)
+	}
+}
+
+func TestRefreshNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldSwappiness, _ := system.GetSysctlString("vm.swappiness")
+	oldPressure, _ := system.GetSysctlString("vm.vfs_cache_pressure")
+	defer func() {
+		_ = system.SetSysctlString("vm.swappiness", oldSwappiness)
+		_ = system.SetSysctlString("vm.vfs_cache_pressure", oldPressure)
+	}()
+	if err := system.SetSysctlString("vm.swappiness", "60"); err != nil {
+		t.Skipf("sysctl parameters can not be set: %v", err)
+	}
+	_ = system.SetSysctlString("vm.vfs_cache_pressure", "100")
+	for _, param := range []string{"vm.swappiness", "vm.vfs_cache_pressure"} {
+		defer note.CleanUpParamFile(param)
+	}
+	refreshFile := path.Join(SampleNoteDataDir, "refreshNote")
+	WriteFileOrPanic(refreshFile, "[version]\n# SAP-NOTE=refreshNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"refresh test note\"\n[sysctl]\nvm.swappiness = 10\nvm.vfs_cache_pressure = 50\n")
+	laterFile := path.Join(SampleNoteDataDir, "laterNote")
+	WriteFileOrPanic(laterFile, "[version]\n# SAP-NOTE=laterNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"later test note\"\n[sysctl]\nvm.vfs_cache_pressure = 70\n")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "refreshNote": note.INISettings{ConfFilePath: refreshFile, ID: "refreshNote", DescriptiveName: ""}, "laterNote": note.INISettings{ConfFilePath: laterFile, ID: "laterNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if _, err := tuneApp.RefreshNote("refreshNote"); err == nil {
+		t.Fatal("expected an error for a note, which is not applied")
+	}
+	if _, err := tuneApp.RefreshNote("1001"); err == nil {
+		t.Fatal("expected an error for a note without Note definition file")
+	}
+	if err := tuneApp.TuneNote("refreshNote"); err != nil {
+		t.Fatal(err)
+	}
+	if refreshed, err := tuneApp.RefreshNote("refreshNote"); err != nil || len(refreshed) != 0 {
+		t.Fatal(refreshed, err)
+	}
+
+	// a parameter changed outside of saptune is set again
+	_ = system.SetSysctlString("vm.swappiness", "30")
+	refreshed, err := tuneApp.RefreshNote("refreshNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(refreshed, []ReloadedParameter{{NoteID: "refreshNote", Param: "vm.swappiness", OldValue: "30", NewValue: "10"}}) {
+		t.Fatal(refreshed)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "10" {
+		t.Fatal(value)
+	}
+	// the values from before the apply are kept
+	var stored note.INISettings
+	if err := tuneApp.State.Retrieve("refreshNote", &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored.SysctlParams, map[string]string{"vm.swappiness": "60", "vm.vfs_cache_pressure": "100"}) {
+		t.Fatal(stored.SysctlParams)
+	}
+
+	// a parameter tuned by a note applied later is left to this note
+	if err := tuneApp.TuneNote("laterNote"); err != nil {
+		t.Fatal(err)
+	}
+	_ = system.SetSysctlString("vm.swappiness", "30")
+	_ = system.SetSysctlString("vm.vfs_cache_pressure", "90")
+	refreshed, err = tuneApp.RefreshNote("refreshNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(refreshed, []ReloadedParameter{{NoteID: "refreshNote", Param: "vm.swappiness", OldValue: "30", NewValue: "10"}}) {
+		t.Fatal(refreshed)
+	}
+	if value, _ := system.GetSysctlString("vm.vfs_cache_pressure"); value != "90" {
+		t.Fatal(value)
+	}
+	refreshed, err = tuneApp.RefreshNote("laterNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(refreshed, []ReloadedParameter{{NoteID: "laterNote", Param: "vm.vfs_cache_pressure", OldValue: "90", NewValue: "70"}}) {
+		t.Fatal(refreshed)
+	}
+
+	for _, noteID := range []string{"laterNote", "refreshNote"} {
+		if err := tuneApp.RevertNote(noteID, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "60" {
+		t.Error(value)
+	}
+	if value, _ := system.GetSysctlString("vm.vfs_cache_pressure"); value != "100" {
+		t.Error(value)
+	}
+}
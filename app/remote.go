@@ -0,0 +1,162 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// SaptuneNoteSourceDir defines saptunes directory containing the URLs, from
+// which Note definitions were downloaded
+const SaptuneNoteSourceDir = "/var/lib/saptune/note_sources"
+
+// MaxRemoteNoteSize is the maximal size of a downloaded Note definition
+const MaxRemoteNoteSize = 1024 * 1024
+
+// MaxRemoteNoteRedirects is the maximal number of redirects followed while
+// downloading a Note definition
+const MaxRemoteNoteRedirects = 5
+
+// remoteNoteClient downloads the Note definitions. The certificate of the
+// server is always verified. Replaced by the tests
+var remoteNoteClient = &http.Client{Timeout: 60 * time.Second, CheckRedirect: checkRemoteNoteRedirect}
+
+// checkRemoteNoteRedirect refuses redirects to URLs not using https, so a
+// Note definition is never downloaded over plain http, and limits the
+// number of redirects to MaxRemoteNoteRedirects
+func checkRemoteNoteRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing the redirect to '%s', only 'https' is supported", req.URL)
+	}
+	if len(via) > MaxRemoteNoteRedirects {
+		return fmt.Errorf("stopped after %d redirects", MaxRemoteNoteRedirects)
+	}
+	return nil
+}
+
+// RemoteNoteID returns the Note ID of a Note definition referenced by an
+// URL. The URL needs to use https and to end with the file name of the Note
+// definition 'NoteID.conf'
+func RemoteNoteID(noteURL string) (string, error) {
+	u, err := url.Parse(noteURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported URL '%s', only 'https' is supported", noteURL)
+	}
+	fileName := path.Base(u.Path)
+	noteID := strings.TrimSuffix(fileName, ".conf")
+	if !strings.HasSuffix(fileName, ".conf") || noteID == "" || strings.ContainsAny(noteID, "- \t") {
+		return "", fmt.Errorf("the URL '%s' does not end with a Note definition file name 'NoteID.conf'", noteURL)
+	}
+	return noteID, nil
+}
+
+// DownloadNoteDefinition downloads the Note definition from the URL.
+// Note definitions larger than MaxRemoteNoteSize are rejected
+func DownloadNoteDefinition(noteURL string) ([]byte, error) {
+	// the redirect policy applies to a replaced client, too
+	client := *remoteNoteClient
+	client.CheckRedirect = checkRemoteNoteRedirect
+	resp, err := client.Get(noteURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of '%s' failed: %s", noteURL, resp.Status)
+	}
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxRemoteNoteSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxRemoteNoteSize {
+		return nil, fmt.Errorf("the Note definition '%s' is larger than %d bytes", noteURL, MaxRemoteNoteSize)
+	}
+	return content, nil
+}
+
+// GetPathToNoteSource returns path to the file containing the URL, from
+// which the Note definition was downloaded.
+func (app *App) GetPathToNoteSource(noteID string) string {
+	return path.Join(app.State.StateDirPrefix, SaptuneNoteSourceDir, noteID)
+}
+
+// NoteSource returns the URL, from which the Note definition was
+// downloaded. The second return value is false, if the Note definition was
+// not downloaded
+func (app *App) NoteSource(noteID string) (string, bool) {
+	content, err := ioutil.ReadFile(app.GetPathToNoteSource(noteID))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(content)), true
+}
+
+// saveNoteSource records the URL, from which the Note definition was
+// downloaded
+func (app *App) saveNoteSource(noteID, noteURL string) error {
+	if err := os.MkdirAll(path.Join(app.State.StateDirPrefix, SaptuneNoteSourceDir), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(app.GetPathToNoteSource(noteID), []byte(noteURL+"\n"), 0644)
+}
+
+// removeNoteSource removes the record of the URL, from which the Note
+// definition was downloaded
+func (app *App) removeNoteSource(noteID string) error {
+	if err := os.Remove(app.GetPathToNoteSource(noteID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// InstallRemoteNote downloads the Note definition from the URL, validates it
+// and stores it in the directory extraDir. The URL is recorded as the source
+// of the Note. A Note downloaded before from the same URL is updated, if it
+// is not applied. It returns the Note ID and the problems found by the
+// validation. In case of problems the Note definition is not stored
+func (app *App) InstallRemoteNote(noteURL, extraDir string) (string, []note.ValidationProblem, error) {
+	noteID, err := RemoteNoteID(noteURL)
+	if err != nil {
+		return "", nil, err
+	}
+	fileName := path.Join(extraDir, noteID+".conf")
+	if _, exists := app.AllNotes[noteID]; exists {
+		if source, ok := app.NoteSource(noteID); !ok || source != noteURL {
+			return "", nil, fmt.Errorf("Note %s already exists and was not downloaded from '%s'", noteID, noteURL)
+		}
+		if app.IsNoteApplied(noteID) {
+			return "", nil, fmt.Errorf("Note %s is applied, please revert the note before updating it", noteID)
+		}
+	} else if _, err := os.Stat(fileName); err == nil {
+		return "", nil, fmt.Errorf("file '%s' already exists", fileName)
+	}
+
+	content, err := DownloadNoteDefinition(noteURL)
+	if err != nil {
+		return "", nil, err
+	}
+	if problems := note.ValidateNoteDefinition(noteURL, string(content)); len(problems) != 0 {
+		return "", problems, fmt.Errorf("the Note definition '%s' is not valid", noteURL)
+	}
+	if err := os.MkdirAll(extraDir, 0755); err != nil {
+		return "", nil, err
+	}
+	if err := ioutil.WriteFile(fileName, content, 0644); err != nil {
+		return "", nil, err
+	}
+	if err := app.saveNoteSource(noteID, noteURL); err != nil {
+		return "", nil, err
+	}
+	app.AllNotes[noteID] = note.INISettings{ConfFilePath: fileName, ID: noteID, DescriptiveName: ""}
+	return noteID, nil, nil
+}
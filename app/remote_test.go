@@ -0,0 +1,157 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+var remoteNoteDefinition = "[version]\n# SAP-NOTE=remoteNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"remote test note\"\n[sysctl]\nvm.swappiness = 10\n"
+
+func TestRemoteNoteID(t *testing.T) {
+	noteID, err := RemoteNoteID("https://config.example/notes/1410736.conf")
+	if err != nil || noteID != "1410736" {
+		t.Error(noteID, err)
+	}
+	for _, noteURL := range []string{"http://config.example/notes/1410736.conf", "https://config.example/notes/1410736", "https://config.example/notes/.conf", "https://config.example/notes/1410736-name.conf", "ftp://config.example/1410736.conf"} {
+		if _, err := RemoteNoteID(noteURL); err == nil {
+			t.Errorf("expected an error for '%s'", noteURL)
+		}
+	}
+}
+
+func TestInstallRemoteNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	extraDir := path.Join(SampleNoteDataDir, "extra")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Base(r.URL.Path) {
+		case "remoteNote.conf":
+			w.Write([]byte(remoteNoteDefinition))
+		case "invalidNote.conf":
+			w.Write([]byte("[unknown]\nparam = 1\n"))
+		case "hugeNote.conf":
+			w.Write([]byte(strings.Repeat("#", MaxRemoteNoteSize+1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	oldClient := remoteNoteClient
+	defer func() { remoteNoteClient = oldClient }()
+	remoteNoteClient = server.Client()
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), map[string]note.Note{"1001": SampleNote1{}}, AllTestSolutions)
+
+	if _, _, err := tuneApp.InstallRemoteNote(server.URL+"/notes/missingNote.conf", extraDir); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if _, _, err := tuneApp.InstallRemoteNote(server.URL+"/notes/hugeNote.conf", extraDir); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("expected an error for a too large file, got: %v", err)
+	}
+	if _, problems, err := tuneApp.InstallRemoteNote(server.URL+"/notes/invalidNote.conf", extraDir); err == nil || len(problems) == 0 {
+		t.Errorf("expected validation problems, got: %v %v", problems, err)
+	}
+	if _, err := os.Stat(path.Join(extraDir, "invalidNote.conf")); !os.IsNotExist(err) {
+		t.Error("invalid Note definition stored")
+	}
+	if _, _, err := tuneApp.InstallRemoteNote(server.URL+"/notes/1001.conf", extraDir); err == nil {
+		t.Error("expected an error for an existing note")
+	}
+
+	noteURL := server.URL + "/notes/remoteNote.conf"
+	noteID, problems, err := tuneApp.InstallRemoteNote(noteURL, extraDir)
+	if err != nil || noteID != "remoteNote" || len(problems) != 0 {
+		t.Fatal(noteID, problems, err)
+	}
+	if source, ok := tuneApp.NoteSource(noteID); !ok || source != noteURL {
+		t.Errorf("wrong source '%s'", source)
+	}
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(aNote.Name(), "remote test note") {
+		t.Errorf("wrong name '%s'", aNote.Name())
+	}
+	if _, ok := tuneApp.NoteSource("1001"); ok {
+		t.Error("unexpected source for note 1001")
+	}
+
+	// update from the same URL, but not from another URL
+	if _, _, err := tuneApp.InstallRemoteNote(noteURL, extraDir); err != nil {
+		t.Error(err)
+	}
+	if _, _, err := tuneApp.InstallRemoteNote(server.URL+"/other/remoteNote.conf", extraDir); err == nil {
+		t.Error("expected an error for a note downloaded from another URL")
+	}
+	if err := tuneApp.State.Store(noteID, aNote, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := tuneApp.InstallRemoteNote(noteURL, extraDir); err == nil {
+		t.Error("expected an error for an applied note")
+	}
+	if err := tuneApp.State.Remove(noteID); err != nil {
+		t.Fatal(err)
+	}
+
+	// the source is removed together with the note
+	if err := tuneApp.DeleteNote(noteID, extraDir, path.Join(SampleNoteDataDir, "override")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tuneApp.NoteSource(noteID); ok {
+		t.Error("source not removed")
+	}
+}
+
+func TestDownloadNoteDefinitionRedirect(t *testing.T) {
+	plainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(remoteNoteDefinition))
+	}))
+	defer plainServer.Close()
+	var tlsURL string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/insecure/remoteNote.conf":
+			http.Redirect(w, r, plainServer.URL+"/notes/remoteNote.conf", http.StatusFound)
+		case "/moved/remoteNote.conf":
+			http.Redirect(w, r, tlsURL+"/notes/remoteNote.conf", http.StatusFound)
+		case "/loop/remoteNote.conf":
+			http.Redirect(w, r, tlsURL+"/loop/remoteNote.conf", http.StatusFound)
+		case "/notes/remoteNote.conf":
+			w.Write([]byte(remoteNoteDefinition))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	tlsURL = server.URL
+	oldClient := remoteNoteClient
+	defer func() { remoteNoteClient = oldClient }()
+	remoteNoteClient = server.Client()
+
+	if _, err := DownloadNoteDefinition(server.URL + "/insecure/remoteNote.conf"); err == nil || !strings.Contains(err.Error(), "only 'https' is supported") {
+		t.Errorf("expected an error for a redirect to http, got: %v", err)
+	}
+	if _, err := DownloadNoteDefinition(server.URL + "/loop/remoteNote.conf"); err == nil || !strings.Contains(err.Error(), "redirects") {
+		t.Errorf("expected an error for too many redirects, got: %v", err)
+	}
+	content, err := DownloadNoteDefinition(server.URL + "/moved/remoteNote.conf")
+	if err != nil || string(content) != remoteNoteDefinition {
+		t.Errorf("redirect to https not followed: '%s' %v", string(content), err)
+	}
+
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	extraDir := path.Join(SampleNoteDataDir, "extra")
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), map[string]note.Note{"1001": SampleNote1{}}, AllTestSolutions)
+	if _, _, err := tuneApp.InstallRemoteNote(server.URL+"/insecure/remoteNote.conf", extraDir); err == nil {
+		t.Error("expected an error for a redirect to http")
+	}
+	if _, err := os.Stat(path.Join(extraDir, "remoteNote.conf")); !os.IsNotExist(err) {
+		t.Error("Note definition downloaded over http stored")
+	}
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// SaptuneSnapshotDir defines saptunes snapshot directory
+const SaptuneSnapshotDir = "/var/lib/saptune/snapshots"
+
+// Snapshot contains the actual values of all parameters of the enabled
+// notes at the time the snapshot was saved
+type Snapshot struct {
+	Name    string
+	Created string
+	Values  map[string]map[string]note.FieldComparison
+}
+
+// GetPathToSnapshot returns path to the snapshot file.
+func (app *App) GetPathToSnapshot(name string) string {
+	return path.Join(app.State.StateDirPrefix, SaptuneSnapshotDir, name)
+}
+
+// checkSnapshotName returns an error, if the name can not be used as file
+// name of a snapshot
+func checkSnapshotName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid snapshot name '%s'", name)
+	}
+	return nil
+}
+
+// snapshotValue returns true, if the comparison contains an actual system
+// value, which needs to be part of a snapshot. Fields without map key
+// like the Note ID or the file name do not describe the system
+func snapshotValue(comparison note.FieldComparison) bool {
+	return comparison.ReflectMapKey != "" && comparison.ReflectMapKey != "reminder" && comparison.ReflectFieldName != "Inform" && comparison.ReflectFieldName != "OverrideParams"
+}
+
+// SaveSnapshot verifies all enabled notes and saves the actual values of
+// the parameters to a snapshot file. An existing snapshot with the same
+// name is overwritten. Returns the number of saved parameters.
+func (app *App) SaveSnapshot(name string) (int, error) {
+	if err := checkSnapshotName(name); err != nil {
+		return 0, err
+	}
+	_, comparisons, err := app.VerifyAll()
+	if err != nil {
+		return 0, err
+	}
+	snapshot := Snapshot{Name: name, Created: time.Now().Format(time.RFC3339), Values: make(map[string]map[string]note.FieldComparison)}
+	cnt := 0
+	for noteID, noteComparisons := range comparisons {
+		snapshot.Values[noteID] = make(map[string]note.FieldComparison)
+		for key, comparison := range noteComparisons {
+			if !snapshotValue(comparison) {
+				continue
+			}
+			// only the actual system value is of interest
+			snapshot.Values[noteID][key] = note.FieldComparison{ReflectFieldName: comparison.ReflectFieldName, ReflectMapKey: comparison.ReflectMapKey, ActualValueJS: comparison.ActualValueJS}
+			cnt++
+		}
+	}
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err = os.MkdirAll(path.Join(app.State.StateDirPrefix, SaptuneSnapshotDir), 0755); err != nil {
+		return 0, err
+	}
+	return cnt, ioutil.WriteFile(app.GetPathToSnapshot(name), content, 0644)
+}
+
+// ReadSnapshot reads a previously saved snapshot
+func (app *App) ReadSnapshot(name string) (snapshot Snapshot, err error) {
+	if err = checkSnapshotName(name); err != nil {
+		return
+	}
+	content, err := ioutil.ReadFile(app.GetPathToSnapshot(name))
+	if os.IsNotExist(err) {
+		return snapshot, fmt.Errorf("snapshot '%s' does not exist", name)
+	} else if err != nil {
+		return
+	}
+	err = json.Unmarshal(content, &snapshot)
+	return
+}
+
+// DiffSnapshot verifies all enabled notes and compares the actual values
+// of the parameters with the values saved in the snapshot.
+// It returns the parameters, which have changed since the snapshot was
+// saved. ExpectedValueJS contains the value from the snapshot,
+// ActualValueJS the current value of the system. A parameter not
+// available in the snapshot or on the system has an empty value.
+func (app *App) DiffSnapshot(name string) (Snapshot, map[string]map[string]note.FieldComparison, error) {
+	changes := make(map[string]map[string]note.FieldComparison)
+	snapshot, err := app.ReadSnapshot(name)
+	if err != nil {
+		return snapshot, nil, err
+	}
+	_, comparisons, err := app.VerifyAll()
+	if err != nil {
+		return snapshot, nil, err
+	}
+	addChange := func(noteID, key string, comparison note.FieldComparison) {
+		if changes[noteID] == nil {
+			changes[noteID] = make(map[string]note.FieldComparison)
+		}
+		changes[noteID][key] = comparison
+	}
+	for noteID, noteComparisons := range comparisons {
+		for key, comparison := range noteComparisons {
+			if !snapshotValue(comparison) {
+				continue
+			}
+			saved, ok := snapshot.Values[noteID][key]
+			if !ok || saved.ActualValueJS != comparison.ActualValueJS {
+				addChange(noteID, key, note.FieldComparison{ReflectFieldName: comparison.ReflectFieldName, ReflectMapKey: comparison.ReflectMapKey, ActualValueJS: comparison.ActualValueJS, ExpectedValueJS: saved.ActualValueJS})
+			}
+		}
+	}
+	for noteID, savedComparisons := range snapshot.Values {
+		for key, saved := range savedComparisons {
+			if _, ok := comparisons[noteID][key]; !ok {
+				addChange(noteID, key, note.FieldComparison{ReflectFieldName: saved.ReflectFieldName, ReflectMapKey: saved.ReflectMapKey, ExpectedValueJS: saved.ActualValueJS})
+			}
+		}
+	}
+	return snapshot, changes, nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	if err := os.MkdirAll(SampleNoteDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "iniNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=iniNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"ini test note\"\n[grub]\nnuma_balancing=disable\n")
+	allNotes := map[string]note.Note{"iniNote": note.INISettings{ConfFilePath: iniFile, ID: "iniNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if err := tuneApp.EnableNote("iniNote"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := tuneApp.SaveSnapshot(name); err == nil {
+			t.Errorf("expected an error for the snapshot name '%s'", name)
+		}
+	}
+	if _, _, err := tuneApp.DiffSnapshot("unknown"); err == nil {
+		t.Error("expected an error for a non existing snapshot")
+	}
+
+	cnt, err := tuneApp.SaveSnapshot("base")
+	if err != nil || cnt != 1 {
+		t.Fatal(cnt, err)
+	}
+	if _, changes, err := tuneApp.DiffSnapshot("base"); err != nil || len(changes) != 0 {
+		t.Fatal(changes, err)
+	}
+
+	// simulate a changed system value
+	snapshot, err := tuneApp.ReadSnapshot("base")
+	if err != nil || snapshot.Name != "base" {
+		t.Fatal(snapshot, err)
+	}
+	key := "SysctlParams[grub:numa_balancing]"
+	saved := snapshot.Values["iniNote"][key]
+	actual := saved.ActualValueJS
+	saved.ActualValueJS = "changed"
+	snapshot.Values["iniNote"][key] = saved
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(tuneApp.GetPathToSnapshot("base"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, changes, err := tuneApp.DiffSnapshot("base")
+	if err != nil || len(changes["iniNote"]) != 1 {
+		t.Fatal(changes, err)
+	}
+	if change := changes["iniNote"][key]; change.ExpectedValueJS != "changed" || change.ActualValueJS != actual || change.ReflectMapKey != "grub:numa_balancing" {
+		t.Fatal(change)
+	}
+}
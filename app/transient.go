@@ -0,0 +1,114 @@
+package app
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// SaptuneTransientNoteDir defines saptunes directory of the Note definitions
+// read from stdin, which are not stored in the directory of the extra Note
+// definitions
+const SaptuneTransientNoteDir = "/var/lib/saptune/transient"
+
+// TransientNotePrefix is the prefix of the Note IDs assigned to the Note
+// definitions read from stdin
+const TransientNotePrefix = "transient_"
+
+// TransientNoteID returns the Note ID assigned to a Note definition read
+// from stdin. The ID is derived from the content, so the same definition
+// always gets the same ID
+func TransientNoteID(content []byte) string {
+	return fmt.Sprintf("%s%x", TransientNotePrefix, sha256.Sum256(content))[:len(TransientNotePrefix)+8]
+}
+
+// GetPathToTransientNote returns path to the Note definition file of a
+// transient note.
+func (app *App) GetPathToTransientNote(noteID string) string {
+	return path.Join(app.State.StateDirPrefix, SaptuneTransientNoteDir, noteID+".conf")
+}
+
+// InstallTransientNote validates the Note definition read from stdin and
+// registers it with a transient Note ID. If persist is set, the definition
+// is stored in the directory extraDir like every other extra Note
+// definition, otherwise it is kept in the saptune state directory only, so
+// that the note can be reverted later. It returns the Note ID and the
+// problems found by the validation. In case of problems the Note definition
+// is not stored
+func (app *App) InstallTransientNote(content []byte, extraDir string, persist bool) (string, []note.ValidationProblem, error) {
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return "", nil, fmt.Errorf("the Note definition read from stdin is empty")
+	}
+	if problems := note.ValidateNoteDefinition("<stdin>", string(content)); len(problems) != 0 {
+		return "", problems, fmt.Errorf("the Note definition read from stdin is not valid")
+	}
+	noteID := TransientNoteID(content)
+	fileName := app.GetPathToTransientNote(noteID)
+	if persist {
+		fileName = path.Join(extraDir, noteID+".conf")
+	}
+	if _, exists := app.AllNotes[noteID]; exists {
+		// the same definition was read before
+		if !persist {
+			return noteID, nil, nil
+		}
+		if _, err := os.Stat(fileName); err == nil {
+			return noteID, nil, nil
+		}
+		if app.IsNoteApplied(noteID) {
+			return "", nil, fmt.Errorf("Note %s is applied, please revert the note before storing it permanently", noteID)
+		}
+	}
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return "", nil, err
+	}
+	if err := ioutil.WriteFile(fileName, content, 0644); err != nil {
+		return "", nil, err
+	}
+	if persist {
+		// the definition is no longer transient
+		if err := app.removeTransientNote(noteID); err != nil {
+			return "", nil, err
+		}
+	}
+	app.AllNotes[noteID] = note.INISettings{ConfFilePath: fileName, ID: noteID, DescriptiveName: ""}
+	return noteID, nil, nil
+}
+
+// LoadTransientNotes registers the transient notes found in the saptune
+// state directory, so that they can be verified and reverted
+func (app *App) LoadTransientNotes() {
+	dirContent, err := ioutil.ReadDir(path.Join(app.State.StateDirPrefix, SaptuneTransientNoteDir))
+	if err != nil {
+		return
+	}
+	for _, info := range dirContent {
+		noteID := strings.TrimSuffix(info.Name(), ".conf")
+		if !strings.HasPrefix(noteID, TransientNotePrefix) || !strings.HasSuffix(info.Name(), ".conf") {
+			continue
+		}
+		if _, exists := app.AllNotes[noteID]; exists {
+			continue
+		}
+		app.AllNotes[noteID] = note.INISettings{ConfFilePath: app.GetPathToTransientNote(noteID), ID: noteID, DescriptiveName: ""}
+	}
+}
+
+// IsTransientNote returns true, if the Note definition was read from stdin
+// and is kept in the saptune state directory only
+func (app *App) IsTransientNote(noteID string) bool {
+	_, err := os.Stat(app.GetPathToTransientNote(noteID))
+	return err == nil
+}
+
+// removeTransientNote removes the Note definition of a transient note
+func (app *App) removeTransientNote(noteID string) error {
+	if err := os.Remove(app.GetPathToTransientNote(noteID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
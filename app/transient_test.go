@@ -0,0 +1,86 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestInstallTransientNote(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	hdl := reloadHandler{values: map[string]string{"transient.param": "1"}}
+	if err := note.RegisterSectionHandler("transienttest", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer note.UnregisterSectionHandler("transienttest")
+	defer note.CleanUpParamFile("transient.param")
+	extraDir := path.Join(SampleNoteDataDir, "extra")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	if _, _, err := tuneApp.InstallTransientNote([]byte(" \n"), extraDir, false); err == nil {
+		t.Fatal("expected an error for an empty Note definition")
+	}
+	_, problems, err := tuneApp.InstallTransientNote([]byte("[transienttest]\ntransient.param\n"), extraDir, false)
+	if err == nil || len(problems) != 1 || problems[0].Line != 2 || problems[0].FileName != "<stdin>" {
+		t.Fatal(problems, err)
+	}
+	if len(tuneApp.AllNotes) != 1 {
+		t.Fatal(tuneApp.AllNotes)
+	}
+
+	content := []byte("[transienttest]\ntransient.param = 5\n")
+	noteID, problems, err := tuneApp.InstallTransientNote(content, extraDir, false)
+	if err != nil || len(problems) != 0 {
+		t.Fatal(problems, err)
+	}
+	if !strings.HasPrefix(noteID, TransientNotePrefix) || len(noteID) != len(TransientNotePrefix)+8 || noteID != TransientNoteID(content) {
+		t.Fatal(noteID)
+	}
+	if _, err := os.Stat(path.Join(extraDir, noteID+".conf")); !os.IsNotExist(err) {
+		t.Fatal("the transient note must not be stored in the extra directory")
+	}
+	if !tuneApp.IsTransientNote(noteID) {
+		t.Fatal("expected a transient note")
+	}
+	if err := tuneApp.TuneNote(noteID); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["transient.param"] != "5" {
+		t.Fatal(hdl.values)
+	}
+
+	// a later run finds the transient note again
+	laterApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), map[string]note.Note{"1001": SampleNote1{}}, AllTestSolutions)
+	laterApp.LoadTransientNotes()
+	if _, err := laterApp.GetNoteByID(noteID); err != nil {
+		t.Fatal(err)
+	}
+	if err := laterApp.RevertNote(noteID, true); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["transient.param"] != "1" {
+		t.Fatal(hdl.values)
+	}
+	if laterApp.IsTransientNote(noteID) {
+		t.Fatal("the transient note definition was not removed")
+	}
+	if _, exists := laterApp.AllNotes[noteID]; exists {
+		t.Fatal("the transient note is still registered")
+	}
+
+	// with 'persist' the Note definition is stored in the extra directory
+	persistApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), map[string]note.Note{"1001": SampleNote1{}}, AllTestSolutions)
+	if persistID, _, err := persistApp.InstallTransientNote(content, extraDir, true); err != nil || persistID != noteID {
+		t.Fatal(persistID, err)
+	}
+	if _, err := os.Stat(path.Join(extraDir, noteID+".conf")); err != nil {
+		t.Fatal(err)
+	}
+	if persistApp.IsTransientNote(noteID) {
+		t.Fatal("the persistent note must not be transient")
+	}
+}
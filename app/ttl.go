@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SaptuneTTLDir defines saptunes directory of the temporarily applied notes
+const SaptuneTTLDir = "/var/lib/saptune/ttl"
+
+// saptuneBinary is the command called by the timer to revert a note
+const saptuneBinary = "/usr/sbin/saptune"
+
+// unsupported characters of a systemd unit name
+var isNoUnitChar = regexp.MustCompile(`[^a-zA-Z0-9:_.-]`)
+
+// RevertTimerUnit returns the name of the transient systemd timer, which
+// reverts the temporarily applied note
+func RevertTimerUnit(noteID string) string {
+	return "saptune-revert-" + isNoUnitChar.ReplaceAllString(noteID, "_")
+}
+
+// scheduleRevert starts the timer, which reverts the note after the delay.
+// Replaced by the tests
+var scheduleRevert = func(noteID string, delay time.Duration) error {
+	return system.SystemdRunTimer(RevertTimerUnit(noteID), delay, saptuneBinary, "note", "revert", noteID)
+}
+
+// cancelRevert stops the timer of the note. Replaced by the tests
+var cancelRevert = func(noteID string) {
+	system.SystemdStopTimer(RevertTimerUnit(noteID))
+}
+
+// GetPathToTTL returns path to the file containing the expiry time of a
+// temporarily applied note.
+func (app *App) GetPathToTTL(noteID string) string {
+	return path.Join(app.State.StateDirPrefix, SaptuneTTLDir, noteID)
+}
+
+// TuneNoteTemporary applies the note and schedules the permanent revert of
+// the note after the time to live has expired
+func (app *App) TuneNoteTemporary(noteID string, ttl time.Duration) error {
+	if err := app.TuneNote(noteID); err != nil {
+		return err
+	}
+	expiry := time.Now().Add(ttl).Format(time.RFC3339)
+	if err := os.MkdirAll(path.Join(app.State.StateDirPrefix, SaptuneTTLDir), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(app.GetPathToTTL(noteID), []byte(expiry+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := scheduleRevert(noteID, ttl); err != nil {
+		// without the timer the note would stay applied forever
+		if rerr := app.RevertNote(noteID, true); rerr != nil {
+			return fmt.Errorf("%v, failed to revert the note - %v", err, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// NoteExpiry returns the time, when the temporarily applied note will be
+// reverted and true, or false, if the note is not applied temporarily
+func (app *App) NoteExpiry(noteID string) (time.Time, bool) {
+	content, err := ioutil.ReadFile(app.GetPathToTTL(noteID))
+	if err != nil {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, strings.TrimSpace(string(content)))
+	if err != nil {
+		system.WarningLog("wrong expiry time in '%s' - %v", app.GetPathToTTL(noteID), err)
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// TemporaryNotes returns the IDs of the temporarily applied notes
+func (app *App) TemporaryNotes() []string {
+	notes := make([]string, 0)
+	dirContent, err := ioutil.ReadDir(path.Join(app.State.StateDirPrefix, SaptuneTTLDir))
+	if err != nil {
+		return notes
+	}
+	for _, info := range dirContent {
+		notes = append(notes, info.Name())
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// removeTTL stops the timer of a temporarily applied note and removes its
+// expiry time, so the note is no longer temporary
+func (app *App) removeTTL(noteID string) error {
+	if _, ok := app.NoteExpiry(noteID); !ok {
+		return nil
+	}
+	cancelRevert(noteID)
+	if err := os.Remove(app.GetPathToTTL(noteID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// handleTemporaryNotes reverts the temporarily applied notes, whose time to
+// live has expired, e.g. while the system was down, and reschedules the
+// revert of the others, as the transient timers do not survive a reboot
+func (app *App) handleTemporaryNotes(now time.Time) error {
+	for _, noteID := range app.TemporaryNotes() {
+		expiry, ok := app.NoteExpiry(noteID)
+		if !ok {
+			continue
+		}
+		if !expiry.After(now) {
+			system.InfoLog("time to live of note '%s' expired at %s, reverting the note", noteID, expiry.Format(time.RFC3339))
+			err := app.RevertNote(noteID, true)
+			if herr := app.RecordHistory("revert", "note", noteID, err); herr != nil {
+				system.WarningLog("failed to record the revert of note '%s' in the history file '%s' - %v", noteID, app.GetPathToHistory(), herr)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := scheduleRevert(noteID, expiry.Sub(now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
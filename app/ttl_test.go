@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTemporaryNotes(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	scheduled := make(map[string]time.Duration)
+	cancelled := make([]string, 0)
+	oldSchedule, oldCancel := scheduleRevert, cancelRevert
+	defer func() { scheduleRevert, cancelRevert = oldSchedule, oldCancel }()
+	scheduleRevert = func(noteID string, delay time.Duration) error {
+		scheduled[noteID] = delay
+		return nil
+	}
+	cancelRevert = func(noteID string) { cancelled = append(cancelled, noteID) }
+
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "1002": SampleNote2{}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	if err := tuneApp.TuneNoteTemporary("1001", 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if scheduled["1001"] != 30*time.Minute {
+		t.Fatal(scheduled)
+	}
+	expiry, ok := tuneApp.NoteExpiry("1001")
+	if !ok || expiry.Before(time.Now().Add(29*time.Minute)) || expiry.After(time.Now().Add(31*time.Minute)) {
+		t.Fatal(expiry, ok)
+	}
+	if notes := tuneApp.TemporaryNotes(); !reflect.DeepEqual(notes, []string{"1001"}) {
+		t.Fatal(notes)
+	}
+
+	// the revert during shutdown keeps the note temporary
+	if err := tuneApp.RevertNote("1001", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tuneApp.NoteExpiry("1001"); !ok || len(cancelled) != 0 {
+		t.Fatal("time to live removed by a non permanent revert", cancelled)
+	}
+
+	// after the reboot the revert is rescheduled with the remaining time
+	if err := tuneApp.TuneAll(); err != nil {
+		t.Fatal(err)
+	}
+	if delay := scheduled["1001"]; delay > 30*time.Minute || delay < 29*time.Minute {
+		t.Fatal(scheduled)
+	}
+	if tuneApp.PositionInNoteApplyOrder("1001") < 0 {
+		t.Fatal(tuneApp.NoteApplyOrder)
+	}
+
+	// a note expired while the system was down is reverted permanently
+	if err := tuneApp.handleTemporaryNotes(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tuneApp.NoteExpiry("1001"); ok || tuneApp.PositionInNoteApplyOrder("1001") >= 0 || tuneApp.IsNoteApplied("1001") {
+		t.Fatal("expired note not reverted", tuneApp.NoteApplyOrder)
+	}
+	if !reflect.DeepEqual(cancelled, []string{"1001"}) {
+		t.Fatal(cancelled)
+	}
+	if entries, err := tuneApp.ReadHistory(time.Time{}); err != nil || len(entries) != 1 || entries[0].Action != "revert" || entries[0].ID != "1001" || entries[0].Result != "success" {
+		t.Fatal(entries, err)
+	}
+
+	// without the timer the note is not applied at all
+	scheduleRevert = func(noteID string, delay time.Duration) error {
+		return fmt.Errorf("no timer")
+	}
+	if err := tuneApp.TuneNoteTemporary("1002", time.Hour); err == nil || err.Error() != "no timer" {
+		t.Fatal(err)
+	}
+	if _, ok := tuneApp.NoteExpiry("1002"); ok || tuneApp.PositionInNoteApplyOrder("1002") >= 0 {
+		t.Fatal("note applied without a timer", tuneApp.NoteApplyOrder)
+	}
+	if err := tuneApp.RevertAll(true); err != nil {
+		t.Fatal(err)
+	}
+}
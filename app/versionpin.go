@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"strings"
+)
+
+// parseNoteVersionPins converts the entries 'NoteID:Version' of the
+// configuration into a map of the pinned versions per Note ID
+func parseNoteVersionPins(entries []string) map[string]string {
+	pins := make(map[string]string)
+	for _, entry := range entries {
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			system.WarningLog("skip malformed entry '%s' of %s, expected 'NoteID:Version'", entry, NoteVersionPinsKey)
+			continue
+		}
+		pins[fields[0]] = fields[1]
+	}
+	return pins
+}
+
+// parseNoteVersionPinMode returns true, if a note, whose version differs
+// from the pinned version, should be refused. Supported are 'warn' and
+// 'refuse', unknown values are handled like 'warn'
+func parseNoteVersionPinMode(mode string) bool {
+	switch mode {
+	case "warn":
+		return false
+	case "refuse":
+		return true
+	}
+	system.WarningLog("wrong value '%s' of %s, supported are 'warn' and 'refuse'. Using 'warn'", mode, NoteVersionPinModeKey)
+	return false
+}
+
+// NoteVersion returns the version of the Note definition file of a note.
+// Notes without Note definition file or without version return ""
+func (app *App) NoteVersion(noteID string) string {
+	aNote, err := app.GetNoteByID(noteID)
+	if err != nil {
+		return ""
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return ""
+	}
+	return txtparser.GetINIFileVersionSectionEntry(iniNote.ConfFilePath, "version")
+}
+
+// NoteVersionPin returns the version the note is pinned to and the actual
+// version of the Note definition file. The last return value is false, if
+// the note is not pinned
+func (app *App) NoteVersionPin(noteID string) (string, string, bool) {
+	pinned, ok := app.NoteVersionPins[noteID]
+	if !ok {
+		return "", "", false
+	}
+	return pinned, app.NoteVersion(noteID), true
+}
+
+// checkNoteVersionPin compares the version of the Note definition file
+// with the version the note is pinned to. A differing version is logged
+// as warning or, if configured, returned as error
+func (app *App) checkNoteVersionPin(noteID string) error {
+	pinned, actual, ok := app.NoteVersionPin(noteID)
+	if !ok || pinned == actual {
+		return nil
+	}
+	if app.RefuseVersionMismatch {
+		return fmt.Errorf("the Note definition of note %s has version '%s', but the note is pinned to version '%s' in %s. Please check the changes of the Note definition and adjust the pinned version", noteID, actual, pinned, NoteVersionPinsKey)
+	}
+	system.WarningLog("ATTENTION: the Note definition of note %s has version '%s', but the note is pinned to version '%s' in %s. The tuning of the note may have changed, please check the changes of the Note definition and adjust the pinned version", noteID, actual, pinned, NoteVersionPinsKey)
+	return nil
+}
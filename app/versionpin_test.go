@@ -0,0 +1,82 @@
+package app
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestNoteVersionPins(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	hdl := reloadHandler{values: map[string]string{"pin.param": "1"}}
+	if err := note.RegisterSectionHandler("pintest", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer note.UnregisterSectionHandler("pintest")
+	defer note.CleanUpParamFile("pin.param")
+	confDir := path.Join(SampleNoteDataDir, "conf")
+	if err := os.MkdirAll(path.Join(confDir, path.Dir(SysconfigSaptuneFile)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	iniFile := path.Join(SampleNoteDataDir, "pinNote")
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=pinNote CATEGORY=test VERSION=3 DATE=01.01.2020 NAME=\"pin test note\"\n[pintest]\npin.param = 5\n")
+	WriteFileOrPanic(path.Join(confDir, SysconfigSaptuneFile), "NOTE_VERSION_PINS=\"pinNote:3 1001:1 malformed\"\n")
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "pinNote": note.INISettings{ConfFilePath: iniFile, ID: "pinNote", DescriptiveName: ""}}
+	tuneApp := InitialiseApp(confDir, path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+
+	if !reflect.DeepEqual(tuneApp.NoteVersionPins, map[string]string{"pinNote": "3", "1001": "1"}) || tuneApp.RefuseVersionMismatch {
+		t.Fatal(tuneApp.NoteVersionPins, tuneApp.RefuseVersionMismatch)
+	}
+	if pinned, actual, ok := tuneApp.NoteVersionPin("pinNote"); !ok || pinned != "3" || actual != "3" {
+		t.Fatal(pinned, actual, ok)
+	}
+	// a note without Note definition file has no version
+	if pinned, actual, ok := tuneApp.NoteVersionPin("1001"); !ok || pinned != "1" || actual != "" {
+		t.Fatal(pinned, actual, ok)
+	}
+	if _, _, ok := tuneApp.NoteVersionPin("1002"); ok {
+		t.Fatal("note 1002 is not pinned")
+	}
+
+	// a package update changes the version of the Note definition
+	WriteFileOrPanic(iniFile, "[version]\n# SAP-NOTE=pinNote CATEGORY=test VERSION=4 DATE=01.01.2021 NAME=\"pin test note\"\n[pintest]\npin.param = 6\n")
+	if pinned, actual, ok := tuneApp.NoteVersionPin("pinNote"); !ok || pinned != "3" || actual != "4" {
+		t.Fatal(pinned, actual, ok)
+	}
+	// 'warn' applies the note nevertheless
+	if err := tuneApp.TuneNote("pinNote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tuneApp.RevertNote("pinNote", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// 'refuse' neither applies nor verifies the note
+	WriteFileOrPanic(path.Join(confDir, SysconfigSaptuneFile), "NOTE_VERSION_PINS=\"pinNote:3\"\nNOTE_VERSION_PIN_MODE=\"refuse\"\n")
+	tuneApp = InitialiseApp(confDir, path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if !tuneApp.RefuseVersionMismatch {
+		t.Fatal("expected the mode 'refuse'")
+	}
+	if err := tuneApp.TuneNote("pinNote"); err == nil {
+		t.Fatal("expected an error for a note with differing version")
+	}
+	if hdl.values["pin.param"] != "1" {
+		t.Fatal(hdl.values)
+	}
+	if _, _, _, err := tuneApp.VerifyNote("pinNote"); err == nil {
+		t.Fatal("expected an error for a note with differing version")
+	}
+	// the pinned version is adjusted
+	tuneApp.NoteVersionPins["pinNote"] = "4"
+	if _, _, _, err := tuneApp.VerifyNote("pinNote"); err != nil {
+		t.Fatal(err)
+	}
+
+	// unknown modes are handled like 'warn'
+	if parseNoteVersionPinMode("ignore") {
+		t.Fatal("expected the mode 'warn'")
+	}
+}
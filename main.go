@@ -1,23 +1,38 @@
 package main
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/SUSE/saptune/app"
+	_ "github.com/SUSE/saptune/plugins/sysfs" // registers the section handler for [sysfs]
 	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/sap/solution"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
 	"io"
 	"io/ioutil"
+	"math"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path"
 	"reflect"
 	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
+	"unsafe"
 )
 
 // constant definitions
@@ -26,15 +41,19 @@ const (
 	TunedService          = "tuned.service"
 	TunedProfileName      = "saptune"
 	logFile               = "/var/log/tuned/tuned.log"
-	NoteTuningSheets      = "/usr/share/saptune/notes/"
-	OverrideTuningSheets  = "/etc/saptune/override/"
-	ExtraTuningSheets     = "/etc/saptune/extra/" // ExtraTuningSheets is a directory located on file system for external parties to place their tuning option files.
 	exitTunedStopped      = 1
 	exitTunedWrongProfile = 2
 	exitNotTuned          = 3
+	exitNotCompliant      = 4                 // system deviates from the recommendations
+	defaultWaitTimeout    = 120 * time.Second // default timeout of 'daemon start --wait'
+	daemonWaitInterval    = 2 * time.Second   // poll interval of 'daemon start --wait'
+	defaultWatchInterval  = 2 * time.Second   // default interval of 'note verify --watch'
 	saptuneV1             = "/usr/sbin/saptune_v1"
 	setGreenText          = "\033[32m"
 	setRedText            = "\033[31m"
+	setYellowText         = "\033[33m"
+	setBoldText           = "\033[1m"
+	clearScreen           = "\033[H\033[2J"
 	resetTextColor        = "\033[0m"
 	footnote1X86          = "[1] setting is not supported by the system"
 	footnote1IBM          = "[1] setting is not relevant for the system"
@@ -42,6 +61,15 @@ const (
 	footnote3             = "[3] value is only checked, but NOT set"
 	footnote4             = "[4] cpu idle state settings differ"
 	footnote5             = "[5] expected value does not contain a supported scheduler"
+	footnote6             = "[6] deviation is informational only, setting can not be changed in this environment (%s)"
+	footnote7             = "[7] value is set in the boot loader configuration, but NOT active - reboot needed"
+	footnote8             = "[8] value is neither active nor set in the boot loader configuration"
+	// states and exit codes of the Nagios plugin convention used by
+	// 'verify --format=nagios'
+	nagiosOK       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+	nagiosUnknown  = 3
 )
 
 // PrintHelpAndExit Print the usage and exit
@@ -49,23 +77,109 @@ func PrintHelpAndExit(exitStatus int) {
 	fmt.Println(`saptune: Comprehensive system optimisation management for SAP solutions.
 Daemon control:
   saptune daemon [ start | status | stop ]
+  saptune daemon start [--wait[=TIMEOUT]]
+  saptune daemon start --dry-run
+  saptune daemon status --json
+  saptune daemon status --quiet
+  saptune daemon reload [--ignore-bounds]
 Tune system according to SAP and SUSE notes:
   saptune note [ list | verify ]
+  saptune note list [--verbose] [--enabled-only|--solution-only|--override-only|--applied-only]
+  saptune note list --json [--enabled-only|--solution-only|--override-only|--applied-only]
+  saptune note search Text
   saptune note [ apply | simulate | verify | customise | create | revert | show ] NoteID
+  saptune note apply [--with-requirements] [--ttl DURATION] [--ignore-bounds] [--note TEXT] [--start-daemon] NoteID
+  saptune note apply --simulate-first [--yes] NoteID
+  saptune note apply --refresh [--ignore-bounds] NoteID
+  saptune note apply --only=ParameterName,... [--ignore-bounds] [--note TEXT] [--start-daemon] NoteID
+  saptune note apply-url URL
+  saptune note apply [--stdin | -] [--persist] [--ttl DURATION] [--ignore-bounds] [--note TEXT] < NoteDefinition
+  saptune note simulate --all
+  saptune note [ enable | disable ] NoteID
+  saptune note show [--raw] NoteID
+  saptune note customise NoteID --set parameter=value [--set parameter=value ...]
+  saptune note customise NoteID --wizard
+  saptune note diff NoteID1 NoteID2
+  saptune note validate NoteID
+  saptune note export NoteID [FILE]
+  saptune note conflicts
+  saptune note move NoteID [ before | after ] OtherNoteID
+  saptune note rename NoteID NewNoteID
+  saptune note delete [--yes] NoteID
+  saptune note revert NoteID ParameterName
+  saptune note revert NoteID --to-default
+  saptune note verify [--format=prometheus|csv|nagios] [--explain] [--diff-only] [--paranoid] [--ignore=NoteID:Parameter[,...]] [NoteID]
+  saptune note verify --since last [--explain] [--diff-only] [NoteID]
+  saptune note verify --param ParameterName
+  saptune note verify --param-prefix Prefix [--explain] [--diff-only] [NoteID]
+  saptune note verify --baseline FILE [--format=prometheus|csv|nagios] [--explain] [--diff-only]
+  saptune note verify --watch[=SECONDS] [--param-prefix Prefix] [--diff-only] [NoteID]
 Tune system for all notes applicable to your SAP solution:
   saptune solution [ list | verify ]
+  saptune solution list --notes
   saptune solution [ apply | simulate | verify | revert ] SolutionName
+  saptune solution revert --all
+  saptune solution apply SolutionName [--only=NoteID,... | --except=NoteID,...] [--ignore-bounds]
+  saptune solution verify [--format=prometheus|csv|nagios] [--explain] [--diff-only] [--paranoid] [--ignore=NoteID:Parameter[,...]] [SolutionName]
+  saptune solution create SolutionName NoteID...
+  saptune solution show SolutionName
+  saptune solution diff SolutionName SolutionName
 Revert all parameters tuned by the SAP notes or solutions:
-  saptune revert all
+  saptune revert all [--quiet] [--keep-solutions]
+  saptune revert tag TagName
+Print a summary of the daemon, the enabled notes and solutions and their compliance:
+  saptune status [--format=json]
+Provide the status for monitoring tools by a read-only HTTP server (endpoints /status and /healthz):
+  saptune serve --listen=[ADDRESS]:PORT
+Save the current values of the tuned parameters and compare the system against them later:
+  saptune snapshot [ save | diff ] SnapshotName
+Show the recorded apply, revert and customise actions:
+  saptune history [--json] [--since=DATE]
+Collect the configuration and the state of the system for bug reports:
+  saptune support [--tarball=FILE] [--redact]
+Check, if the system is ready to be tuned by saptune:
+  saptune check
+Create the configuration file /etc/sysconfig/saptune, if it is missing:
+  saptune setup
+Migrate the solutions and notes of saptune version 1 to saptune version 2:
+  saptune migrate [--commit]
 Print current saptune version:
-  saptune version
+  saptune version [--detailed]
 Print this message:
-  saptune help`)
+  saptune help
+Global options:
+  --no-color            do not use colour in the output (same as setting NO_COLOR)
+  --note-dir=DIR        use the Note definitions from DIR (same as setting SAPTUNE_NOTE_DIR)
+  --override-dir=DIR    use the override files from DIR (same as setting SAPTUNE_OVERRIDE_DIR)
+  --extra-dir=DIR       use the vendor specific Note definitions from DIR (same as setting SAPTUNE_EXTRA_DIR)
+  --output-file=PATH    write the verify, simulate and support reports to PATH instead of stdout
+  --footnotes=json      print the footnotes of the verify and simulate reports in JSON format instead of the table
+  --wide                do not truncate long values in the verify and simulate tables to the terminal width
+  --timeout=DURATION    stop waiting for systemctl and tuned-adm after DURATION, 0 waits forever (default: COMMAND_TIMEOUT)`)
 	os.Exit(exitStatus)
 }
 
-// Print the message to stderr and exit 1.
-func errorExit(template string, stuff ...interface{}) {
+// ExitError is returned by the action functions instead of terminating
+// saptune, so that the actions can be tested and used by other programs.
+// main logs the message as error and exits with Code. An empty message
+// exits without logging, e.g. if the result was already printed. With Help
+// the help text is printed instead of the message
+type ExitError struct {
+	Code    int
+	Message string
+	Help    bool
+}
+
+// Error returns the message of the failed action
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
+// newExitError returns the error of a failed action with exit code 1. Like
+// the errors of system.ErrorLog the message ends with a newline. If the
+// last element of stuff is the error of a failed command, the exit code of
+// this command is used instead
+func newExitError(template string, stuff ...interface{}) *ExitError {
 	exState := 1
 	fieldType := ""
 	field := len(stuff) - 1
@@ -78,24 +192,281 @@ func errorExit(template string, stuff ...interface{}) {
 			exState = exitError.Sys().(syscall.WaitStatus).ExitStatus()
 		}
 	}
-	_ = system.ErrorLog(template+"\n", stuff...)
-	os.Exit(exState)
+	return &ExitError{Code: exState, Message: fmt.Sprintf(template+"\n", stuff...)}
+}
+
+// usageError returns the error of an action, which is called with missing
+// or wrong arguments. The help text is printed instead of a message
+func usageError() *ExitError {
+	return &ExitError{Code: 1, Help: true}
+}
+
+// exitOnError terminates saptune, if an action failed. The message is
+// printed to stderr and saptune exits with the exit code of the error.
+// Errors, which are not an ExitError, exit with 1
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	var exErr *ExitError
+	if !errors.As(err, &exErr) {
+		exErr = newExitError("%v", err)
+	}
+	if exErr.Help {
+		PrintHelpAndExit(exErr.Code)
+	}
+	if exErr.Message == "" {
+		os.Exit(exErr.Code)
+	}
+	_ = system.ErrorLog("%s", exErr.Message)
+	if outputFormat == "nagios" {
+		// a monitoring system needs the state on stdout
+		msg := strings.Join(strings.Fields(exErr.Message), " ")
+		fmt.Printf("SAPTUNE UNKNOWN - %s\n", msg)
+		os.Exit(nagiosUnknown)
+	}
+	os.Exit(exErr.Code)
+}
+
+// Print the message to stderr and exit 1. Only used by main, the actions
+// return an ExitError instead.
+func errorExit(template string, stuff ...interface{}) {
+	exitOnError(newExitError(template, stuff...))
 }
 
 // Return the i-th command line parameter, or empty string if it is not specified.
+// Command line options (starting with '--') are skipped.
 func cliArg(i int) string {
-	if len(os.Args) >= i+1 {
-		return os.Args[i]
+	args := cliPositionalArgs()
+	if len(args) >= i+1 {
+		return args[i]
 	}
 	return ""
 }
 
+// cliArgsFrom returns all command line parameters starting with the i-th
+// one. Command line options (starting with '--') are skipped.
+func cliArgsFrom(i int) []string {
+	args := cliPositionalArgs()
+	if len(args) > i {
+		return args[i:]
+	}
+	return []string{}
+}
+
+// cliValueOptions are the command line options, which may take their value
+// from the following command line parameter ('--name value') instead of
+// '--name=value'
+var cliValueOptions = []string{"baseline", "complete", "except", "ignore", "listen", "note", "only", "output-file", "param", "param-prefix", "set", "since", "tarball", "timeout", "ttl"}
+
+// cliIsValueOption returns true, if arg is one of the cliValueOptions
+// without a value
+func cliIsValueOption(arg string) bool {
+	for _, name := range cliValueOptions {
+		if arg == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// cliPositionalArgs returns the command line parameters without the
+// command line options and their values
+func cliPositionalArgs() []string {
+	args := make([]string, 0, len(os.Args))
+	for i := 0; i < len(os.Args); i++ {
+		if cliIsValueOption(os.Args[i]) {
+			// skip the value of the option
+			i++
+			continue
+		}
+		if strings.HasPrefix(os.Args[i], "--") {
+			continue
+		}
+		args = append(args, os.Args[i])
+	}
+	return args
+}
+
+// cliFlag returns true, if the command line option '--name' or
+// '--name=value' is specified
+func cliFlag(name string) bool {
+	for _, arg := range os.Args {
+		if arg == "--"+name || strings.HasPrefix(arg, "--"+name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// cliFlagValue returns the value of the command line option '--name=value'
+// or an empty string, if the option is not specified. If the option is
+// specified several times, the last value is used
+func cliFlagValue(name string) string {
+	values := cliFlagValues(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// cliFlagValues returns the values of all occurrences of the command line
+// option '--name=value' in the order given on the command line. Options
+// listed in cliValueOptions may be specified as '--name value' too
+func cliFlagValues(name string) []string {
+	values := make([]string, 0)
+	for i, arg := range os.Args {
+		if strings.HasPrefix(arg, "--"+name+"=") {
+			values = append(values, strings.TrimPrefix(arg, "--"+name+"="))
+		} else if arg == "--"+name && cliIsValueOption(arg) && i+1 < len(os.Args) {
+			values = append(values, os.Args[i+1])
+		}
+	}
+	return values
+}
+
+// tuningDirectory returns the directory set by the command line option
+// '--<option>=<dir>' or by the environment variable env. The command line
+// option takes precedence. If neither is set, defaultDir is returned.
+func tuningDirectory(env, option, defaultDir string) string {
+	dir := cliFlagValue(option)
+	if dir == "" {
+		dir = os.Getenv(env)
+	}
+	if dir == "" {
+		return defaultDir
+	}
+	if !strings.HasSuffix(dir, "/") {
+		dir = dir + "/"
+	}
+	return dir
+}
+
+// setupTuningDirectories sets the directories of the Note definition files,
+// the override files and the vendor specific files from the environment
+// variables SAPTUNE_NOTE_DIR, SAPTUNE_OVERRIDE_DIR and SAPTUNE_EXTRA_DIR or
+// the command line options '--note-dir', '--override-dir' and '--extra-dir'
+func setupTuningDirectories() {
+	NoteTuningSheets = tuningDirectory("SAPTUNE_NOTE_DIR", "note-dir", NoteTuningSheets)
+	OverrideTuningSheets = tuningDirectory("SAPTUNE_OVERRIDE_DIR", "override-dir", OverrideTuningSheets)
+	ExtraTuningSheets = tuningDirectory("SAPTUNE_EXTRA_DIR", "extra-dir", ExtraTuningSheets)
+	note.OverrideTuningSheets = OverrideTuningSheets
+	note.OverrideLayers = OverrideLayers
+}
+
+// overrideLayers returns the override directories listed in OVERRIDE_LAYERS
+// of /etc/saptune/sysconfig, each with a trailing '/'
+func overrideLayers(value string) []string {
+	dirs := make([]string, 0)
+	for _, dir := range strings.Fields(value) {
+		if !strings.HasSuffix(dir, "/") {
+			dir = dir + "/"
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// colorize returns the text surrounded by the given colour escape sequence
+// or the plain text, if colour output is switched off
+func colorize(text, color string) string {
+	if noColor {
+		return text
+	}
+	return color + text + resetTextColor
+}
+
+// setupColorOutput switches off colour output, if the environment variable
+// NO_COLOR is set, the command line option '--no-color' is used or stdout
+// is not a terminal
+func setupColorOutput() {
+	if os.Getenv("NO_COLOR") != "" || cliFlag("no-color") {
+		noColor = true
+		return
+	}
+	if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		noColor = true
+	}
+}
+
+// minTableValueWidth is the minimal width of a value column of the verify
+// and simulate table, to which long values are truncated
+const minTableValueWidth = 12
+
+// setupTableWidth limits the width of the verify and simulate table to the
+// width of the terminal, if the report is printed to a terminal and the
+// command line option '--wide' is not used
+func setupTableWidth() {
+	if cliFlag("wide") || cliFlag("output-file") {
+		return
+	}
+	tableWidth = terminalWidth()
+}
+
+// terminalWidth returns the number of columns of the terminal connected to
+// stdout. The environment variable COLUMNS takes precedence. 0 is returned,
+// if stdout is not a terminal
+func terminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return 0
+	}
+	ws := struct{ Row, Col, Xpixel, Ypixel uint16 }{}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
+
 var tuneApp *app.App                             // application configuration and tuning states
 var tuningOptions note.TuningOptions             // Collection of tuning options from SAP notes and 3rd party vendors.
 var footnote1 = footnote1X86                     // set 'unsupported' footnote regarding the architecture
 var debugSwitch = os.Getenv("SAPTUNE_DEBUG")     // Switch Debug on ("1") or off ("0" - default)
 var verboseSwitch = os.Getenv("SAPTUNE_VERBOSE") // Switch verbose mode on ("on" - default) or off ("off")
+var skipDaemonReminder = false                   // suppress the reminder to start the saptune daemon
+var applyStartsDaemon = false                    // 'saptune note apply' enables and starts the saptune daemon
 var solutionSelector = runtime.GOARCH
+var noColor = false        // Switch colour output off
+var outputFormat = ""      // output format requested by the command line option '--format'
+var explainVerify = false  // print the explanation of deviating parameters during verify
+var verifyParam = ""       // verify only this parameter across all enabled notes
+var verifyParamPrefix = "" // verify only the parameters matching this prefix or glob pattern
+var verifySince = ""       // verify shows only the parameters, whose compliance changed since the last verify
+var verifyBaseline = ""    // verify compares the system against the expected values of this baseline file
+var footnotesFormat = ""   // format of the footnotes requested by the command line option '--footnotes'
+var tableWidth = 0         // maximum width of the verify and simulate table, 0 for unlimited
+var verifyDiffOnly = false // verify prints only the deviating parameters
+var verifyParanoid = false // verify checks the ownership and permissions of the files of the notes, too
+
+// verifyIgnoreConf is the ignore list of verify set by VERIFY_IGNORE in
+// /etc/sysconfig/saptune, see verifyIgnoreList
+var verifyIgnoreConf = ""
+
+// watchChanged contains per note the parameters, whose compliance changed
+// since the previous draw of 'saptune note verify --watch'. The rows of
+// these parameters are highlighted in the verify table
+var watchChanged = map[string]map[string]bool{}
+
+// cpuIdleStates reads the idle states of the cpus for the details of the
+// footnote regarding the cpu idle state settings
+var cpuIdleStates = system.GetCPUIdleStates
+
+// blockDeviceSchedulers reads the schedulers of a block device for the
+// details of the footnote regarding the unsupported schedulers
+var blockDeviceSchedulers = system.GetBlockDeviceSchedulers
+
+// saptuneBuildVersion is the version of the saptune binary. It is set during
+// the build by '-ldflags "-X main.saptuneBuildVersion=<version>"'
+var saptuneBuildVersion = ""
+
+// directories containing the Note definition files. They can be changed by
+// environment variables or command line options, see setupTuningDirectories
+var NoteTuningSheets = "/usr/share/saptune/notes/"
+var OverrideTuningSheets = "/etc/saptune/override/"
+var OverrideLayers = []string{}               // OverrideLayers are the additional override directories like a site-wide one, merged below OverrideTuningSheets
+var ExtraTuningSheets = "/etc/saptune/extra/" // ExtraTuningSheets is a directory located on file system for external parties to place their tuning option files.
 
 func main() {
 	if runtime.GOARCH == "ppc64le" {
@@ -103,8 +474,12 @@ func main() {
 	}
 
 	// get saptune version
-	sconf, err := txtparser.ParseSysconfigFile("/etc/sysconfig/saptune", true)
-	if err != nil {
+	// a missing file must not block 'version', 'help' and 'setup'
+	sconf, err := txtparser.ParseSysconfigFile(app.SysconfigSaptuneFile, false)
+	sysconfigMissing := os.IsNotExist(err)
+	if sysconfigMissing {
+		sconf, _ = txtparser.ParseSysconfig("")
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Unable to read file '/etc/sysconfig/saptune': %v\n", err)
 		os.Exit(1)
 	}
@@ -116,12 +491,59 @@ func main() {
 	if verboseSwitch == "" {
 		verboseSwitch = sconf.GetString("VERBOSE", "on")
 	}
+	skipDaemonReminder = sconf.GetBool("SKIP_DAEMON_REMINDER", false)
+	applyStartsDaemon = sconf.GetBool("NOTE_APPLY_START_DAEMON", false)
+	OverrideLayers = overrideLayers(sconf.GetString("OVERRIDE_LAYERS", ""))
+	timeout, err := commandTimeout(sconf.GetInt("COMMAND_TIMEOUT", 90))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	system.CommandTimeout = timeout
+	note.ExtraNotesPrecedence = sconf.GetBool("EXTRA_NOTES_PRECEDENCE", false)
+	if err := note.SetExtraNotesChecksum(sconf.GetString("EXTRA_NOTES_CHECKSUM", "warn")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Wrong value for EXTRA_NOTES_CHECKSUM in file '/etc/sysconfig/saptune': %v\n", err)
+		os.Exit(1)
+	}
 
-	if arg1 := cliArg(1); arg1 == "" || arg1 == "help" || arg1 == "--help" {
+	if arg1 := cliArg(1); arg1 == "version" || cliFlag("version") {
+		if cliFlag("detailed") {
+			setupTuningDirectories()
+			VersionActionDetailed(os.Stdout, saptuneVersion)
+			os.Exit(0)
+		}
+		if sysconfigMissing {
+			fmt.Printf("current active saptune version is unknown\n")
+			fmt.Fprint(os.Stderr, missingSysconfigHint(app.SysconfigSaptuneFile))
+			os.Exit(0)
+		}
+		fmt.Printf("current active saptune version is '%s'\n", saptuneVersion)
+		os.Exit(0)
+	}
+	if arg1 := cliArg(1); arg1 == "" || arg1 == "help" || cliFlag("help") {
 		PrintHelpAndExit(0)
 	}
-	if arg1 := cliArg(1); arg1 == "version" || arg1 == "--version" {
-		fmt.Printf("current active saptune version is '%s'\n", saptuneVersion)
+	setupColorOutput()
+	outputFormat = cliFlagValue("format")
+	explainVerify = cliFlag("explain")
+	verifyDiffOnly = cliFlag("diff-only")
+	verifyParanoid = cliFlag("paranoid")
+	verifyParam = cliFlagValue("param")
+	verifyParamPrefix = cliFlagValue("param-prefix")
+	verifyBaseline = cliFlagValue("baseline")
+	verifySince = cliFlagValue("since")
+	footnotesFormat = cliFlagValue("footnotes")
+	verifyIgnoreConf = sconf.GetString("VERIFY_IGNORE", "")
+	setupTuningDirectories()
+
+	if cliArg(1) == "completion" {
+		// hidden command generating the shell completion, which
+		// needs to work without super user privilege
+		selector := runtime.GOARCH
+		if system.IsPagecacheAvailable() {
+			selector = selector + "_PC"
+		}
+		exitOnError(CompletionAction(os.Stdout, cliArg(2), cliFlagValue("complete"), note.GetTuningOptions(NoteTuningSheets, ExtraTuningSheets), solution.GetSortedSolutionNames(selector)))
 		os.Exit(0)
 	}
 
@@ -131,8 +553,52 @@ func main() {
 		os.Exit(1)
 	}
 
+	setupTableWidth()
+
 	// activate logging
-	system.LogInit(logFile, debugSwitch, verboseSwitch)
+	// the log file and the format of the log lines can be changed in
+	// /etc/sysconfig/saptune to ship the saptune log separately
+	if err := system.SetLogFormat(sconf.GetString("LOG_FORMAT", "text")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Wrong value for LOG_FORMAT in file '/etc/sysconfig/saptune': %v\n", err)
+		os.Exit(1)
+	}
+	logNoteID := ""
+	if cliArg(1) == "note" {
+		logNoteID = cliArg(3)
+	}
+	// the log messages can be sent additionally to the systemd journal
+	system.SetLogJournal(sconf.GetBool("LOG_JOURNAL", false))
+	system.SetLogContext(strings.TrimSpace(cliArg(1)+" "+cliArg(2)), logNoteID)
+	system.LogInit(sconf.GetString("LOG_FILE", logFile), debugSwitch, verboseSwitch)
+	if sections := note.RegisteredSections(); len(sections) != 0 {
+		system.DebugLog("section handlers registered for: %s", strings.Join(sections, " "))
+	}
+
+	if cliArg(1) == "setup" {
+		exitOnError(SetupAction(os.Stdout, app.SysconfigSaptuneFile, &app.State{}))
+		os.Exit(0)
+	}
+	if cliArg(1) == "check" {
+		// the checks need to run even if the system is not
+		// supported or saptune is not configured correctly
+		exitOnError(CheckAction(os.Stdout, saptuneVersion))
+		os.Exit(0)
+	}
+	if sysconfigMissing {
+		fmt.Fprint(os.Stderr, missingSysconfigHint(app.SysconfigSaptuneFile))
+		os.Exit(1)
+	}
+
+	if cliArg(1) == "migrate" {
+		// needs to work with saptune version 1, which is handled
+		// by saptune_v1 otherwise
+		selector := solutionSelector
+		if system.IsPagecacheAvailable() {
+			selector = selector + "_PC"
+		}
+		exitOnError(MigrateAction(os.Stdout, app.SysconfigSaptuneFile, note.GetTuningOptions(NoteTuningSheets, ExtraTuningSheets), solution.AllSolutions[selector], &app.State{}, daemonActive(), cliFlag("commit")))
+		os.Exit(0)
+	}
 
 	switch saptuneVersion {
 	case "1":
@@ -163,8 +629,12 @@ func main() {
 	// Initialise application configuration and tuning procedures
 	tuningOptions = note.GetTuningOptions(NoteTuningSheets, ExtraTuningSheets)
 	tuneApp = app.InitialiseApp("", "", tuningOptions, archSolutions)
+	// Note definitions applied from stdin
+	tuneApp.LoadTransientNotes()
+	// apply parameter values outside of the bounds of the notes
+	tuneApp.IgnoreBounds = cliFlag("ignore-bounds")
 
-	checkUpdateLeftOvers()
+	exitOnError(checkUpdateLeftOvers())
 
 	switch cliArg(1) {
 	case "daemon":
@@ -174,798 +644,5475 @@ func main() {
 	case "solution":
 		SolutionAction(cliArg(2), cliArg(3))
 	case "revert":
-		RevertAction(os.Stdout, cliArg(2), tuneApp)
+		exitOnError(RevertAction(os.Stdout, cliArg(2), cliArg(3), cliFlag("quiet"), cliFlag("keep-solutions"), tuneApp))
+	case "status":
+		exitOnError(StatusAction(os.Stdout, outputFormat, tuneApp))
+	case "snapshot":
+		exitOnError(SnapshotAction(os.Stdout, cliArg(2), cliArg(3), tuneApp))
+	case "history":
+		exitOnError(HistoryAction(os.Stdout, cliFlagValue("since"), cliFlag("json"), tuneApp))
+	case "support":
+		noColor = true
+		exitOnError(reportAction(func(writer io.Writer) error {
+			return SupportAction(writer, cliFlagValue("tarball"), cliFlag("redact"), tuneApp)
+		}))
+	case "serve":
+		exitOnError(ServeAction(cliFlagValue("listen"), func() *app.App {
+			return app.InitialiseApp("", "", tuningOptions, archSolutions)
+		}))
 	default:
 		PrintHelpAndExit(1)
 	}
 }
 
+// CompletionAction prints the completion script for the shell or, with the
+// option '--complete', the Note IDs or solution names one per line for the
+// dynamic completion
+func CompletionAction(writer io.Writer, shell, complete string, tOptions note.TuningOptions, solNames []string) error {
+	switch {
+	case complete == "note":
+		for _, noteID := range tOptions.GetSortedIDs() {
+			fmt.Fprintln(writer, noteID)
+		}
+	case complete == "solution":
+		for _, solName := range solNames {
+			fmt.Fprintln(writer, solName)
+		}
+	case complete != "":
+		return newExitError("Unsupported value '%s' of option '--complete'. Supported are: note, solution", complete)
+	case shell == "bash":
+		io.WriteString(writer, bashCompletionScript)
+	case shell == "zsh":
+		// zsh uses the bash completion by its compatibility layer
+		io.WriteString(writer, "#compdef saptune\n\nautoload -U +X bashcompinit && bashcompinit\n\n"+bashCompletionScript)
+	default:
+		return newExitError("Unsupported shell '%s'. Supported shells are: bash, zsh", shell)
+	}
+	return nil
+}
+
+// bashCompletionScript is the bash completion script of saptune. The
+// completion script shipped with the package is generated from it by
+// 'saptune completion bash', so that both do not drift apart. The Note IDs
+// and solution names are completed by 'saptune completion --complete=...'
+const bashCompletionScript = `# v1.2
+#
+# generated by 'saptune completion bash', do not edit
+#
+#   saptune daemon [ start | status | stop ]
+#   saptune daemon start [--wait[=TIMEOUT]]
+#   saptune daemon start --dry-run
+#   saptune daemon status --json
+#   saptune daemon status --quiet
+#   saptune daemon reload [--ignore-bounds]
+#   saptune note [ list | verify ]
+#   saptune note apply [--with-requirements] [--ttl DURATION] [--ignore-bounds] [--note TEXT] [--start-daemon] NoteID
+#   saptune note apply --simulate-first [--yes] NoteID
+#   saptune note apply --refresh [--ignore-bounds] NoteID
+#   saptune note apply --only=ParameterName,... [--ignore-bounds] [--note TEXT] [--start-daemon] NoteID
+#   saptune note apply-url URL
+#   saptune note apply [--stdin | -] [--persist] [--ttl DURATION] [--ignore-bounds] [--note TEXT] < NoteDefinition
+#   saptune note simulate --all
+#   saptune note list [--verbose] [--enabled-only|--solution-only|--override-only|--applied-only]
+#   saptune note list --json [--enabled-only|--solution-only|--override-only|--applied-only]
+#   saptune note search Text
+#   saptune note [ apply | simulate | verify | customise | revert | create | show ] NoteID
+#   saptune note show [--raw] NoteID
+#   saptune note customise NoteID --set parameter=value [--set parameter=value ...]
+#   saptune note customise NoteID --wizard
+#   saptune note [ enable | disable ] NoteID
+#   saptune note diff NoteID1 NoteID2
+#   saptune note validate NoteID
+#   saptune note export NoteID [FILE]
+#   saptune note conflicts
+#   saptune note move NoteID [ before | after ] OtherNoteID
+#   saptune note rename NoteID NewNoteID
+#   saptune note delete [--yes] NoteID
+#   saptune note revert NoteID ParameterName
+#   saptune note revert NoteID --to-default
+#   saptune note verify [--format=prometheus|csv|nagios] [--explain] [--diff-only] [--paranoid] [--ignore=NoteID:Parameter[,...]] [NoteID]
+#   saptune note verify --param ParameterName
+#   saptune note verify --param-prefix Prefix [--explain] [--diff-only] [NoteID]
+#   saptune note verify --since last [--explain] [--diff-only] [NoteID]
+#   saptune note verify --baseline FILE [--format=prometheus|csv|nagios] [--explain] [--diff-only]
+#   saptune note verify --watch[=SECONDS] [--param-prefix Prefix] [--diff-only] [NoteID]
+#   saptune [ note | solution ] [ verify | simulate ] --footnotes=json [NoteID|SolutionName]
+#   saptune [ note | solution ] [ verify | simulate ] --wide [NoteID|SolutionName]
+#   saptune solution [ list | verify ]
+#   saptune solution list --notes
+#   saptune solution [ apply | simulate | verify | revert ] SolutionName
+#   saptune solution revert --all
+#   saptune solution apply SolutionName [--only=NoteID,... | --except=NoteID,...] [--ignore-bounds]
+#   saptune solution verify [--format=prometheus|csv|nagios] [--explain] [--diff-only] [--paranoid] [--ignore=NoteID:Parameter[,...]] [SolutionName]
+#   saptune solution create SolutionName NoteID...
+#   saptune solution show SolutionName
+#   saptune solution diff SolutionName SolutionName
+#   saptune revert all [--quiet] [--keep-solutions]
+#   saptune revert tag TagName
+#   saptune status [--format=json]
+#   saptune serve --listen=[ADDRESS]:PORT
+#   saptune snapshot [ save | diff ] SnapshotName
+#   saptune history [--json] [--since=DATE]
+#   saptune support [--tarball=FILE] [--redact]
+#   saptune check
+#   saptune setup
+#   saptune migrate [--commit]
+#   saptune version [--detailed]
+#   saptune --version
+#   saptune help
+
+_saptune() {
+    local cur prev opts base pattern
+
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    
+    case ${COMP_CWORD} in 
+
+        1)  opts="daemon solution note revert status serve snapshot history support check setup migrate version --version help"
+            ;;
+        
+        2)  case "${prev}" in
+                daemon)     opts="start status stop reload"
+                            ;;
+                solution)   opts="list verify apply simulate revert create show diff"
+                            ;;
+                note)       opts="list search verify apply apply-url simulate customise revert create show diff validate export conflicts move rename delete enable disable"
+                            ;;
+		revert)	    opts="all tag"	
+			    ;;
+                snapshot)   opts="save diff"
+                            ;;
+                history)    opts="--json --since="
+                            ;;
+                serve)      opts="--listen="
+                            ;;
+                support)    opts="--tarball= --redact"
+                            ;;
+                version)    opts="--detailed"
+                            ;;
+                migrate)    opts="--commit"
+                            ;;
+                *)          ;;
+            esac
+            ;;
+
+        3)  case "${prev}" in
+                apply|simulate|verify|customise|revert|create|show|diff|validate|export|move|rename|delete|enable|disable|save)
+                        case "${COMP_WORDS[COMP_CWORD-2]}" in
+                            note)       opts=$(saptune completion --complete=note 2>/dev/null | tr '\n' ' ')
+                                        [ "${prev}" == "rename" -o "${prev}" == "delete" ] && opts=$(find /etc/saptune/extra/ -name '*.conf' -printf '%f\n' | cut -d '-' -f 1 | sed 's/\.conf$//' | tr '\n' ' ')
+                                        [ "${prev}" == "delete" ] && opts="--yes ${opts}"
+                                        [ "${prev}" == "simulate" ] && opts="--all ${opts}"
+                                        [ "${prev}" == "verify" ] && opts="--watch --ignore ${opts}"
+                                        [ "${prev}" == "apply" ] && opts="--with-requirements --ttl --simulate-first --yes --ignore-bounds --note --stdin --persist --start-daemon --refresh --only ${opts}"
+                                        [ "${prev}" == "search" ] && opts=""
+                                        ;;
+                            solution)   opts=$(saptune completion --complete=solution 2>/dev/null | tr '\n' ' ')
+                                        [ "${prev}" == "revert" ] && opts="--all ${opts}"
+                                        ;;
+                            snapshot)   opts=$(ls -1q /var/lib/saptune/snapshots/ 2>/dev/null | tr '\n' ' ')
+                                        ;;
+                        esac
+			;;
+                all)    opts="--quiet --keep-solutions"
+                        ;;
+                start)  opts="--wait --dry-run"
+                        ;;
+                status) [ "${COMP_WORDS[COMP_CWORD-2]}" == "daemon" ] && opts="--json --quiet"
+                        ;;
+                list)   case "${COMP_WORDS[COMP_CWORD-2]}" in
+                            note)   opts="--verbose --json --enabled-only --solution-only --override-only --applied-only"
+                                    ;;
+                            solution)   opts="--notes"
+                                    ;;
+                        esac
+                        ;;
+                *)  return 0
+                    ;;
+            esac 
+	    ;;
+
+        4)  if [ "${COMP_WORDS[1]}" == "note" ]; then
+                case "${COMP_WORDS[2]}" in
+                    revert)     opts="--to-default"
+                                ;;
+                    customise)  opts="--set --wizard"
+                                ;;
+                esac
+            fi
+            ;;
+
+        *)  return 0
+            ;;
+    esac
+
+    COMPREPLY=($(compgen -W "${opts}" -- ${cur}))  
+    return 0
+}
+
+complete -F _saptune saptune
+
+`
+
+// reportAction runs an action printing a verify, simulate or support report.
+// With the command line option '--output-file' the report is written to
+// this file instead of stdout, status and error messages are not affected.
+// The file is opened only for these actions
+func reportAction(action func(writer io.Writer) error) error {
+	if !cliFlag("output-file") {
+		return action(os.Stdout)
+	}
+	outFile, err := openOutputFile(cliFlagValue("output-file"))
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+	// no color escape sequences in a file
+	noColor = true
+	return action(outFile)
+}
+
+// openOutputFile creates or truncates the file of the command line option
+// '--output-file', which receives the verify and simulate reports.
+// Symbolic links and existing files, which are no regular files, are
+// refused, so that a report does not overwrite an unexpected file
+func openOutputFile(fileName string) (*os.File, error) {
+	if fileName == "" {
+		return nil, fmt.Errorf("missing file name for option '--output-file'")
+	}
+	if info, err := os.Lstat(fileName); err == nil && !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("Failed to open the output file '%s' - not a regular file", fileName)
+	}
+	outFile, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NOFOLLOW, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the output file '%s' - %v", fileName, err)
+	}
+	// the file may have been replaced after the check above
+	if info, err := outFile.Stat(); err != nil || !info.Mode().IsRegular() {
+		outFile.Close()
+		return nil, fmt.Errorf("Failed to open the output file '%s' - not a regular file", fileName)
+	}
+	return outFile, nil
+}
+
 // checkUpdateLeftOvers checks for left over files from the migration of
 // saptune version 1 to saptune version 2
-func checkUpdateLeftOvers() {
+func checkUpdateLeftOvers() error {
 	// check for the /etc/tuned/saptune/tuned.conf file created during
 	// the package update from saptune v1 to saptune v2
 	// give a Warning but go ahead tuning the system
-	if system.CheckForPattern("/etc/tuned/saptune/tuned.conf", "#stv1tov2#") {
+	if system.CheckForPattern(saptuneV1TunedConf, "#stv1tov2#") {
 		system.WarningLog("found file '/etc/tuned/saptune/tuned.conf' left over from the migration of saptune version 1 to saptune version 2. Please check and remove this file as it may work against the settings of some SAP Notes. For more information refer to the man page saptune-migrate(7)")
 	}
 
+	// check if the Note definitions of applied notes have changed since
+	// the notes were applied, e.g. by a package update
+	if tuneApp != nil {
+		for _, noteID := range tuneApp.ChangedNoteDefinitions() {
+			system.WarningLog("the Note definition of the applied note '%s' has changed since the note was applied. Please revert and apply the note again ('saptune note revert %s', 'saptune note apply %s') to use the new definition.", noteID, noteID, noteID)
+		}
+	}
+
+	// check if the apply of notes was interrupted, e.g. because saptune
+	// was killed, so the system may be tuned only partially
+	if tuneApp != nil {
+		for _, noteID := range tuneApp.InterruptedNotes() {
+			system.WarningLog("the apply of note '%s' was interrupted, so the system may be tuned only partially for this note. Please run 'saptune note apply %s' to finish applying the note or 'saptune note revert %s' to revert the changes already made.", noteID, noteID, noteID)
+		}
+	}
+
 	// check if old solution or notes are applied
 	if tuneApp != nil && (len(tuneApp.NoteApplyOrder) == 0 && (len(tuneApp.TuneForNotes) != 0 || len(tuneApp.TuneForSolutions) != 0)) {
-		errorExit("There are 'old' solutions or notes defined in file '/etc/sysconfig/saptune'. Seems there were some steps missed during the migration from saptune version 1 to version 2. Please check. Refer to saptune-migrate(7) for more information")
+		return newExitError("There are 'old' solutions or notes defined in file '/etc/sysconfig/saptune'. Seems there were some steps missed during the migration from saptune version 1 to version 2. Please check. Refer to saptune-migrate(7) for more information")
 	}
+	return nil
 }
 
-// RevertAction Revert all notes and solutions
-func RevertAction(writer io.Writer, actionName string, tuneApp *app.App) {
-	if actionName != "all" {
-		PrintHelpAndExit(1)
-	}
-	fmt.Fprintf(writer, "Reverting all notes and solutions, this may take some time...\n")
-	if err := tuneApp.RevertAll(true); err != nil {
-		errorExit("Failed to revert notes: %v", err)
-		//panic(err)
-	}
-	fmt.Fprintf(writer, "Parameters tuned by the notes and solutions have been successfully reverted.\n")
+// saptuneStatus summarises the state of the daemon, the enabled solutions
+// and notes and the compliance of the system
+type saptuneStatus struct {
+	DaemonRunning     bool             `json:"daemonRunning"`
+	TunedProfile      string           `json:"tunedProfile"`
+	ProfileCorrect    bool             `json:"profileCorrect"`
+	Solutions         []string         `json:"solutions"`
+	Notes             []string         `json:"notes"`
+	NoteApplyOrder    []string         `json:"noteApplyOrder"`
+	Compliant         bool             `json:"compliant"`
+	NotesCompliance   map[string]bool  `json:"notesCompliance"`
+	DeviationSeverity string           `json:"deviationSeverity,omitempty"`
+	Deviations        []paramDeviation `json:"deviations,omitempty"`
+	complianceScore
 }
 
-// DaemonAction handles daemon actions like start, stop, status asm.
-func DaemonAction(actionName string) {
-	switch actionName {
-	case "start":
-		DaemonActionStart()
-	case "apply":
-		// This action name is only used by tuned script, hence it is not advertised to end user.
-		if err := tuneApp.TuneAll(); err != nil {
-			panic(err)
-		}
-	case "status":
-		DaemonActionStatus()
-	case "stop":
-		DaemonActionStop()
-	case "revert":
-		// This action name is only used by tuned script, hence it is not advertised to end user.
-		if err := tuneApp.RevertAll(false); err != nil {
-			panic(err)
+// paramDeviation describes a deviating parameter together with its severity
+// in the json output of the status
+type paramDeviation struct {
+	Note      string `json:"note"`
+	Parameter string `json:"parameter"`
+	Severity  string `json:"severity"`
+}
+
+// paramDeviations returns the deviating parameters of the comparisons
+// sorted by note and parameter. Ignored deviations are not included
+func paramDeviations(comparisons map[string]map[string]note.FieldComparison) []paramDeviation {
+	deviations := make([]paramDeviation, 0)
+	for _, skey := range sortNoteComparisonsOutput(comparisons) {
+		keyFields := strings.Split(skey, "§")
+		comparison, _, _ := getNoteFieldValues(comparisons, keyFields[0], keyFields[1])
+		if comparison.ReflectMapKey == "reminder" || comparison.MatchExpectation {
+			continue
 		}
-	default:
-		PrintHelpAndExit(1)
+		deviations = append(deviations, paramDeviation{Note: keyFields[0], Parameter: comparison.ReflectMapKey, Severity: paramSeverity(comparison)})
 	}
+	return deviations
 }
 
-// DaemonActionStart starts the tuned service
-func DaemonActionStart() {
-	fmt.Println("Starting daemon (tuned.service), this may take several seconds...")
-	system.SystemctlDisableStop(SapconfService) // do not error exit on failure
-	if err := system.TunedAdmProfile("saptune"); err != nil {
-		errorExit("%v", err)
+// collectStatus collects the status information of saptune and verifies
+// the system against all enabled notes
+func collectStatus(tuneApp *app.App, ignore map[string]map[string]bool) (saptuneStatus, error) {
+	status := saptuneStatus{
+		DaemonRunning:   system.SystemctlIsRunning(TunedService),
+		TunedProfile:    system.GetTunedProfile(),
+		Solutions:       tuneApp.TuneForSolutions,
+		Notes:           tuneApp.TuneForNotes,
+		NoteApplyOrder:  tuneApp.NoteApplyOrder,
+		Compliant:       true,
+		NotesCompliance: make(map[string]bool),
+		complianceScore: computeComplianceScore(nil),
+	}
+	status.ProfileCorrect = status.TunedProfile == TunedProfileName
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		return status, nil
 	}
-	if err := system.SystemctlEnableStart(TunedService); err != nil {
-		errorExit("%v", err)
+	unsatisfiedNotes, comparisons, err := tuneApp.VerifyAll()
+	if err != nil {
+		return status, err
 	}
-	// Check tuned profile
-	if system.GetTunedAdmProfile() != TunedProfileName {
-		_ = system.ErrorLog("tuned.service profile is incorrect. Please check tuned logs for more information")
-		// defined exit value needed for yast module
-		os.Exit(exitTunedWrongProfile)
+	unsatisfiedNotes = ignoreDeviations(comparisons, unsatisfiedNotes, ignore)
+	for noteID := range comparisons {
+		status.NotesCompliance[noteID] = true
 	}
-	// tuned then calls `saptune daemon apply`
-	fmt.Println("Daemon (tuned.service) has been enabled and started.")
-	if len(tuneApp.TuneForSolutions) == 0 && len(tuneApp.TuneForNotes) == 0 {
-		fmt.Println("Your system has not yet been tuned. Please visit `saptune note` and `saptune solution` to start tuning.")
+	for _, noteID := range unsatisfiedNotes {
+		status.NotesCompliance[noteID] = false
+		status.Compliant = false
 	}
+	if !status.Compliant {
+		status.DeviationSeverity = deviationSeverity(comparisons)
+		status.Deviations = paramDeviations(comparisons)
+	}
+	status.complianceScore = computeComplianceScore(comparisons)
+	return status, nil
 }
 
-// DaemonActionStatus checks the status of the tuned service
-func DaemonActionStatus() {
-	// Check daemon
-	if system.SystemctlIsRunning(TunedService) {
-		fmt.Println("Daemon (tuned.service) is running.")
-	} else {
-		fmt.Fprintln(os.Stderr, "Daemon (tuned.service) is stopped. If you wish to start the daemon, run `saptune daemon start`.")
-		os.Exit(exitTunedStopped)
+// StatusAction prints a summary of the daemon status, the enabled solutions
+// and notes and the compliance of the system
+func StatusAction(writer io.Writer, format string, tuneApp *app.App) error {
+	ignore, err := verifyIgnoreList()
+	if err != nil {
+		return err
 	}
-	// Check tuned profile
-	if system.GetTunedProfile() != TunedProfileName {
-		fmt.Fprintln(os.Stderr, "tuned.service profile is incorrect. If you wish to correct it, run `saptune daemon start`.")
-		os.Exit(exitTunedWrongProfile)
+	status, err := collectStatus(tuneApp, ignore)
+	if err != nil {
+		return newExitError("Failed to inspect the current system: %v", err)
 	}
-	// Check for any enabled note/solution
-	if len(tuneApp.TuneForSolutions) > 0 || len(tuneApp.TuneForNotes) > 0 {
-		fmt.Println("The system has been tuned for the following solutions and notes:")
-		for _, sol := range tuneApp.TuneForSolutions {
-			fmt.Println("\t" + sol)
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return newExitError("Failed to create the json output: %v", err)
 		}
-		for _, noteID := range tuneApp.TuneForNotes {
-			fmt.Println("\t" + noteID)
+		fmt.Fprintf(writer, "%s\n", string(content))
+	case "":
+		yesNo := map[bool]string{true: "yes", false: "no"}
+		daemonState := "stopped"
+		if status.DaemonRunning {
+			daemonState = "running"
 		}
-	} else {
-		fmt.Fprintln(os.Stderr, "Your system has not yet been tuned. Please visit `saptune note` and `saptune solution` to start tuning.")
-		os.Exit(exitNotTuned)
+		fmt.Fprintf(writer, "\nsaptune status:\n\n")
+		fmt.Fprintf(writer, "   daemon (%s):   %s\n", TunedService, daemonState)
+		fmt.Fprintf(writer, "   tuned profile:            '%s' (correct: %s)\n", status.TunedProfile, yesNo[status.ProfileCorrect])
+		fmt.Fprintf(writer, "   enabled solutions:        %s\n", strings.Join(status.Solutions, " "))
+		fmt.Fprintf(writer, "   enabled notes:            %s\n", strings.Join(status.Notes, " "))
+		fmt.Fprintf(writer, "   order of applied notes:   %s\n", strings.Join(status.NoteApplyOrder, " "))
+		fmt.Fprintf(writer, "   system compliant:         %s\n", yesNo[status.Compliant])
+		fmt.Fprintf(writer, "   compliance score:         %d/%d parameters (%.1f%%)\n", status.complianceScore.Compliant, status.Parameters, status.Percent)
+		for _, noteID := range status.NoteApplyOrder {
+			if compliant, ok := status.NotesCompliance[noteID]; ok {
+				fmt.Fprintf(writer, "      %-22s%s\n", noteID+":", yesNo[compliant])
+			}
+		}
+		fmt.Fprintf(writer, "\n")
+	default:
+		return newExitError("Unknown output format '%s'. Supported formats are: json", format)
 	}
+	return nil
 }
 
-// DaemonActionStop stops the tuned service
-func DaemonActionStop() {
-	fmt.Println("Stopping daemon (tuned.service), this may take several seconds...")
-	if err := system.TunedAdmOff(); err != nil {
-		errorExit("%v", err)
+// ServeAction runs a HTTP server in the foreground, which provides the
+// saptune status for monitoring tools. It only returns on error
+func ServeAction(listen string, loadApp func() *app.App) error {
+	if listen == "" {
+		return usageError()
 	}
-	if err := system.SystemctlDisableStop(TunedService); err != nil {
-		errorExit("%v", err)
+	if os.Geteuid() != 0 {
+		return newExitError("Refusing to serve the saptune status on '%s' without root privilege.", listen)
 	}
-	// tuned then calls `saptune daemon revert`
-	fmt.Println("Daemon (tuned.service) has been disabled and stopped.")
-	fmt.Println("All tuned parameters have been reverted to default.")
+	server := &http.Server{
+		Addr:         listen,
+		Handler:      newStatusServeMux(loadApp),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 5 * time.Minute,
+	}
+	system.InfoLog("Serving the saptune status on '%s'", listen)
+	if err := server.ListenAndServe(); err != nil {
+		return newExitError("Failed to serve the saptune status on '%s': %v", listen, err)
+	}
+	return nil
 }
 
-// PrintNoteFields Print mismatching fields in the note comparison result.
-//func PrintNoteFields(header string, noteComparisons map[string]map[string]note.FieldComparison, printComparison bool) {
-func PrintNoteFields(writer io.Writer, header string, noteComparisons map[string]map[string]note.FieldComparison, printComparison bool) {
-
-	// initialise
-	compliant := "yes"
-	printHead := ""
-	noteField := ""
-	footnote := make([]string, 5, 5)
-	reminder := make(map[string]string)
-	override := ""
-	comment := ""
-	hasDiff := false
-
-	// sort output
-	sortkeys := sortNoteComparisonsOutput(noteComparisons)
-
-	// setup table format values
-	fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, format := setupTableFormat(sortkeys, noteField, noteComparisons, printComparison)
-
-	// print
-	noteID := ""
-	for _, skey := range sortkeys {
-		comment = ""
-		keyFields := strings.Split(skey, "§")
-		key := keyFields[1]
-		printHead = ""
-		if keyFields[0] != noteID {
-			if noteID == "" {
-				printHead = "yes"
-			}
-			noteID = keyFields[0]
-			//noteField = fmt.Sprintf("%s, %s", noteID, txtparser.GetINIFileVersion(noteComparisons[noteID]["ConfFilePath"].ActualValue.(string)))
-			noteField = fmt.Sprintf("%s, %s", noteID, txtparser.GetINIFileVersionSectionEntry(noteComparisons[noteID]["ConfFilePath"].ActualValue.(string), "version"))
+// newStatusServeMux returns the read-only endpoints of 'saptune serve'.
+// '/status' returns the same information as 'saptune status --format=json',
+// '/healthz' only reports, that the server is alive.
+// The configuration is loaded again for each request, so that enabling or
+// disabling notes is visible without restarting the server
+func newStatusServeMux(loadApp func() *app.App) *http.ServeMux {
+	// verify the system only once at a time
+	var statusLock sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !readOnlyRequest(w, r) {
+			return
 		}
-
-		override = strings.Replace(noteComparisons[noteID][fmt.Sprintf("%s[%s]", "OverrideParams", key)].ExpectedValueJS, "\t", " ", -1)
-		comparison := noteComparisons[noteID][fmt.Sprintf("%s[%s]", "SysctlParams", key)]
-		if comparison.ReflectMapKey == "reminder" {
-			reminder[noteID] = reminder[noteID] + comparison.ExpectedValueJS
-			continue
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if !readOnlyRequest(w, r) {
+			return
 		}
-		if !comparison.MatchExpectation {
-			hasDiff = true
-			compliant = "no "
-		} else {
-			compliant = "yes"
+		ignore, err := verifyIgnoreList()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
-
-		// check inform map for special settings
-		inform := ""
-		if noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ActualValue != nil {
-			inform = noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ActualValue.(string)
-			if inform == "" && noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ExpectedValue != nil {
-				inform = noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ExpectedValue.(string)
-			}
+		statusLock.Lock()
+		status, err := collectStatus(loadApp(), ignore)
+		statusLock.Unlock()
+		if err != nil {
+			_ = system.ErrorLog("Failed to inspect the current system: %v", err)
+			http.Error(w, fmt.Sprintf("failed to inspect the current system: %v", err), http.StatusInternalServerError)
+			return
+		}
+		content, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to create the json output: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, "%s\n", string(content))
+	})
+	return mux
+}
+
+// readOnlyRequest rejects all requests, which are not GET or HEAD requests
+func readOnlyRequest(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+// VersionActionDetailed prints the version of saptune together with
+// information about the binary and the system, which is useful for bug
+// reports
+func VersionActionDetailed(writer io.Writer, saptuneVersion string) {
+	buildVersion := saptuneBuildVersion
+	if buildVersion == "" {
+		buildVersion = "unknown"
+	}
+	selector := solutionSelector
+	pagecache := system.IsPagecacheAvailable()
+	if pagecache {
+		selector = selector + "_PC"
+	}
+	saptuneNotes := 0
+	extraNotes := 0
+	for _, aNote := range note.GetTuningOptions(NoteTuningSheets, ExtraTuningSheets) {
+		if iniNote, ok := aNote.(note.INISettings); ok && strings.HasPrefix(iniNote.ConfFilePath, ExtraTuningSheets) {
+			extraNotes++
+		} else {
+			saptuneNotes++
+		}
+	}
+	yesNo := map[bool]string{true: "yes", false: "no"}
+	fmt.Fprintf(writer, "current active saptune version is '%s'\n\n", saptuneVersion)
+	fmt.Fprintf(writer, "   binary version:           %s\n", buildVersion)
+	fmt.Fprintf(writer, "   go version:               %s\n", runtime.Version())
+	fmt.Fprintf(writer, "   architecture:             %s\n", runtime.GOARCH)
+	fmt.Fprintf(writer, "   solution selector:        %s\n", selector)
+	fmt.Fprintf(writer, "   page cache available:     %s\n", yesNo[pagecache])
+	fmt.Fprintf(writer, "   Note definitions:         %d from %s\n", saptuneNotes, NoteTuningSheets)
+	fmt.Fprintf(writer, "   vendor Note definitions:  %d from %s\n", extraNotes, ExtraTuningSheets)
+}
+
+// preflightCheck is a single check of the 'saptune check' action. The check
+// function returns, if the check passed, a description of the result and a
+// hint how to fix the problem
+type preflightCheck struct {
+	name      string
+	mandatory bool
+	check     func() (bool, string, string)
+}
+
+// preflightChecks returns the checks, which are needed to decide, if the
+// system is ready to be tuned by saptune
+func preflightChecks(saptuneVersion string) []preflightCheck {
+	checks := []preflightCheck{
+		{"saptune version", true, func() (bool, string, string) {
+			if _, err := os.Stat(app.SysconfigSaptuneFile); os.IsNotExist(err) {
+				return false, "'/etc/sysconfig/saptune' is missing", "run 'saptune setup' to create the file with the default values"
+			}
+			if saptuneVersion != "2" {
+				return false, fmt.Sprintf("SAPTUNE_VERSION in '/etc/sysconfig/saptune' is '%s', but needs to be '2'", saptuneVersion), "migrate to saptune version 2 with 'saptune migrate' as described in saptune-migrate(7)"
+			}
+			return true, "SAPTUNE_VERSION in '/etc/sysconfig/saptune' is '2'", ""
+		}},
+		{"tuned", true, func() (bool, string, string) {
+			if !system.CmdIsAvailable("/usr/sbin/tuned") {
+				return false, "tuned is not installed", "install the package 'tuned'"
+			}
+			return true, "tuned is installed", ""
+		}},
+		{"sapconf", true, func() (bool, string, string) {
+			if system.SystemctlIsRunning(SapconfService) {
+				return false, fmt.Sprintf("%s is running and conflicts with saptune", SapconfService), fmt.Sprintf("stop and disable sapconf with 'systemctl disable --now %s'", SapconfService)
+			}
+			return true, fmt.Sprintf("%s is not running", SapconfService), ""
+		}},
+		{"architecture", true, func() (bool, string, string) {
+			selector := runtime.GOARCH
+			if system.IsPagecacheAvailable() {
+				selector = selector + "_PC"
+			}
+			if _, exist := solution.AllSolutions[selector]; !exist {
+				return false, fmt.Sprintf("the system architecture (%s) is not supported", selector), "saptune supports the architectures amd64 and ppc64le"
+			}
+			return true, fmt.Sprintf("the system architecture (%s) is supported", selector), ""
+		}},
+	}
+	type checkedDir struct {
+		dirName   string
+		mandatory bool
+	}
+	dirs := []checkedDir{{NoteTuningSheets, true}, {ExtraTuningSheets, false}}
+	for _, dirName := range note.OverrideDirs() {
+		// the override layers are optional like the host override dir
+		dirs = append(dirs, checkedDir{dirName, false})
+	}
+	for _, dir := range dirs {
+		dirName := dir.dirName
+		checks = append(checks, preflightCheck{"directory " + dirName, dir.mandatory, func() (bool, string, string) {
+			if _, err := ioutil.ReadDir(dirName); err != nil {
+				return false, fmt.Sprintf("unable to read directory '%s' - %v", dirName, err), "check the existence and the permissions of the directory"
+			}
+			return true, fmt.Sprintf("directory '%s' is readable", dirName), ""
+		}})
+	}
+	return append(checks, extraNoteChecksumChecks(ExtraTuningSheets)...)
+}
+
+// extraNoteChecksumChecks returns a check of the checksum for each vendor or
+// customer specific Note definition file. A missing checksum file is only
+// reported, as the checksum files are optional
+func extraNoteChecksumChecks(extraDir string) []preflightCheck {
+	checks := []preflightCheck{}
+	files, _ := ioutil.ReadDir(extraDir)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".conf") {
+			continue
+		}
+		fileName := path.Join(extraDir, file.Name())
+		checks = append(checks, preflightCheck{"checksum " + file.Name(), false, func() (bool, string, string) {
+			state, err := note.VerifyChecksum(fileName)
+			switch state {
+			case note.ChecksumValid:
+				return true, fmt.Sprintf("checksum of '%s' is valid", fileName), ""
+			case note.ChecksumMissing:
+				if note.ChecksumRequired() {
+					return false, fmt.Sprintf("no checksum file '%s%s' available, the file is ignored", fileName, note.ChecksumSuffix), fmt.Sprintf("create the checksum file with 'sha256sum %s > %s%s'", fileName, fileName, note.ChecksumSuffix)
+				}
+				return true, fmt.Sprintf("no checksum file '%s%s' available", fileName, note.ChecksumSuffix), ""
+			}
+			return false, fmt.Sprintf("%v", err), fmt.Sprintf("check '%s' for unwanted changes and update the checksum with 'sha256sum %s > %s%s'", fileName, fileName, fileName, note.ChecksumSuffix)
+		}})
+	}
+	return checks
+}
+
+// printPreflightChecks runs the checks and prints the results. It returns
+// the number of failed mandatory checks.
+// A failed optional check is reported as warning
+func printPreflightChecks(writer io.Writer, checks []preflightCheck) int {
+	failed := 0
+	fmt.Fprintf(writer, "\nsaptune preflight checks:\n\n")
+	for _, chk := range checks {
+		passed, result, hint := chk.check()
+		state := "PASS"
+		if !passed && chk.mandatory {
+			state = "FAIL"
+			failed++
+		} else if !passed {
+			state = "WARN"
+		}
+		fmt.Fprintf(writer, "   [%s] %s: %s\n", state, chk.name, result)
+		if !passed && hint != "" {
+			fmt.Fprintf(writer, "          hint: %s\n", hint)
+		}
+	}
+	fmt.Fprintf(writer, "\n")
+	return failed
+}
+
+// CheckAction checks, if the system is ready to be tuned by saptune and
+// exits with 1, if one of the mandatory checks failed
+func CheckAction(writer io.Writer, saptuneVersion string) error {
+	if failed := printPreflightChecks(writer, preflightChecks(saptuneVersion)); failed != 0 {
+		return newExitError("%d mandatory check(s) failed.", failed)
+	}
+	fmt.Fprintf(writer, "All mandatory checks passed.\n")
+	return nil
+}
+
+// defaultSysconfig are the keys and default values of the saptune
+// configuration file created by 'saptune setup'
+var defaultSysconfig = []struct {
+	key   string
+	value string
+}{
+	{"TUNE_FOR_SOLUTIONS", ""},
+	{"TUNE_FOR_NOTES", ""},
+	{"NOTE_APPLY_ORDER", ""},
+	{"SOLUTION_EXCLUDED_NOTES", ""},
+	{"NOTE_VERSION_PINS", ""},
+	{"NOTE_VERSION_PIN_MODE", "warn"},
+	{"SAPTUNE_VERSION", "2"},
+	{"LOG_FILE", logFile},
+	{"LOG_FORMAT", "text"},
+	{"LOG_JOURNAL", "no"},
+	{"SKIP_DAEMON_REMINDER", "no"},
+	{"NOTE_APPLY_START_DAEMON", "no"},
+	{"OVERRIDE_LAYERS", ""},
+	{"VERIFY_IGNORE", ""},
+	{"COMMAND_TIMEOUT", "90"},
+	{"EXTRA_NOTES_PRECEDENCE", "no"},
+	{"EXTRA_NOTES_CHECKSUM", "warn"},
+}
+
+// missingSysconfigHint returns the guidance printed, if the saptune
+// configuration file is missing
+func missingSysconfigHint(fileName string) string {
+	return fmt.Sprintf(`Error: The saptune configuration file '%s' is missing.
+Run 'saptune setup' to create the file with the default values or create
+the file manually with at least the following content:
+
+    SAPTUNE_VERSION="2"
+    TUNE_FOR_SOLUTIONS=""
+    TUNE_FOR_NOTES=""
+    NOTE_APPLY_ORDER=""
+
+'saptune setup' enables the notes, which are still applied according to
+their saved state, again. The solutions enabled before are not known
+anymore. Please enable them again with 'saptune solution apply'.
+`, fileName)
+}
+
+// SetupAction creates the saptune configuration file with the default
+// values, if the file is missing. An existing file is never overwritten.
+// The notes, which are still applied according to their saved state files,
+// are restored as enabled notes, so that they can be reverted later
+func SetupAction(writer io.Writer, fileName string, state *app.State) error {
+	if _, err := os.Stat(fileName); err == nil {
+		fmt.Fprintf(writer, "The configuration file '%s' already exists, nothing to do.\n", fileName)
+		return nil
+	}
+	applied, err := savedStateNotes(state)
+	if err != nil {
+		return newExitError("Failed to read the saved state of the applied notes: %v", err)
+	}
+	sconf, _ := txtparser.ParseSysconfig("")
+	for _, entry := range defaultSysconfig {
+		sconf.Set(entry.key, entry.value)
+	}
+	if len(applied) != 0 {
+		notes := append([]string{}, applied...)
+		sort.Strings(notes)
+		sconf.SetStrArray(app.TuneForNotesKey, notes)
+		sconf.SetStrArray(app.NoteApplyOrderKey, applied)
+	}
+	sconf.AllValues[0].LeadingComments = []string{"# created by 'saptune setup', see saptune(8) for the meaning of the values"}
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return newExitError("Failed to create the configuration file '%s': %v", fileName, err)
+	}
+	if err := ioutil.WriteFile(fileName, []byte(sconf.ToText()), 0644); err != nil {
+		return newExitError("Failed to create the configuration file '%s': %v", fileName, err)
+	}
+	fmt.Fprintf(writer, "The configuration file '%s' has been created with the default values.\n", fileName)
+	if len(applied) != 0 {
+		system.InfoLog("notes '%s' restored from their saved state", strings.Join(applied, " "))
+		fmt.Fprintf(writer, "The notes %s are still applied according to their saved state and have been enabled again. The order of the applied notes is taken from the modification time of their saved state files.\n", strings.Join(applied, " "))
+		fmt.Fprintf(writer, "The enabled solutions are not known anymore, the notes of a solution are enabled as single notes. Please check the notes with 'saptune note list' and revert them with 'saptune revert all', if needed.\n")
+		return nil
+	}
+	fmt.Fprintf(writer, "Please enable the solutions and notes again with 'saptune solution apply' and 'saptune note apply'.\n")
+	return nil
+}
+
+// savedStateNotes returns the notes with a saved state file sorted by the
+// modification time of the state files. This is the time the notes were
+// applied, unless the saved state was updated later, e.g. by a reload
+func savedStateNotes(state *app.State) ([]string, error) {
+	notes, err := state.List()
+	if err != nil {
+		return nil, err
+	}
+	mtime := make(map[string]time.Time, len(notes))
+	for _, noteID := range notes {
+		info, err := os.Stat(state.GetPathToNote(noteID))
+		if err != nil {
+			return nil, err
+		}
+		mtime[noteID] = info.ModTime()
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return mtime[notes[i]].Before(mtime[notes[j]])
+	})
+	return notes, nil
+}
+
+// saptuneV1TunedConf is the tuned profile created by the package update from
+// saptune version 1 to saptune version 2, see checkUpdateLeftOvers
+const saptuneV1TunedConf = "/etc/tuned/saptune/tuned.conf"
+
+// MigrateAction migrates the selection of solutions and notes of saptune
+// version 1 in the configuration file to saptune version 2. The notes of
+// the solution and the additional notes make up the order of applied
+// notes. Solutions and notes, which are not available in saptune version 2,
+// are dropped. Only one solution is supported by saptune version 2.
+// Without commit the result is only printed, with commit the configuration
+// file is written and SAPTUNE_VERSION is set to '2'. As long as saptune
+// version 1 still tunes the system, the migration is refused, the dry run
+// only warns about it
+func MigrateAction(writer io.Writer, fileName string, allNotes note.TuningOptions, allSolutions map[string]solution.Solution, state *app.State, daemonRunning, commit bool) error {
+	sconf, err := txtparser.ParseSysconfigFile(fileName, false)
+	if err != nil {
+		return newExitError("Unable to read file '%s': %v", fileName, err)
+	}
+	switch version := sconf.GetString("SAPTUNE_VERSION", ""); version {
+	case "2":
+		fmt.Fprintf(writer, "The configuration file '%s' is already used by saptune version 2, nothing to migrate.\n", fileName)
+		return nil
+	case "1":
+		break
+	default:
+		return newExitError("Wrong saptune version in file '%s': %s", fileName, version)
+	}
+	v1Solutions := sconf.GetStringArray(app.TuneForSolutionsKey, []string{})
+	v1Notes := sconf.GetStringArray(app.TuneForNotesKey, []string{})
+
+	fmt.Fprintf(writer, "Migration of the configuration file '%s' from saptune version 1 to saptune version 2:\n\n", fileName)
+	solutions := make([]string, 0, len(v1Solutions))
+	for _, solName := range v1Solutions {
+		if _, ok := allSolutions[solName]; !ok {
+			fmt.Fprintf(writer, "\tsolution %s is not available in saptune version 2 and is dropped\n", solName)
+			continue
+		}
+		solutions = append(solutions, solName)
+	}
+	if len(solutions) > 1 {
+		return newExitError("saptune version 2 supports only one solution, but the solutions %s are enabled. Please keep only one of them in %s of '%s' and add the notes of the others to %s, see saptune-migrate(7).", strings.Join(solutions, ", "), app.TuneForSolutionsKey, fileName, app.TuneForNotesKey)
+	}
+	applyOrder := make([]string, 0)
+	inOrder := func(noteID string) bool {
+		for _, id := range applyOrder {
+			if id == noteID {
+				return true
+			}
+		}
+		return false
+	}
+	for _, solName := range solutions {
+		for _, noteID := range allSolutions[solName] {
+			if _, ok := allNotes[noteID]; ok && !inOrder(noteID) {
+				applyOrder = append(applyOrder, noteID)
+			}
+		}
+	}
+	notes := make([]string, 0, len(v1Notes))
+	for _, noteID := range v1Notes {
+		if _, ok := allNotes[noteID]; !ok {
+			fmt.Fprintf(writer, "\tnote %s is not available in saptune version 2 and is dropped\n", noteID)
+			continue
+		}
+		if inOrder(noteID) {
+			// already covered by the solution
+			continue
+		}
+		notes = append(notes, noteID)
+		applyOrder = append(applyOrder, noteID)
+	}
+	sort.Strings(notes)
+	fmt.Fprintf(writer, "\t%s: '%s' -> '%s'\n", app.TuneForSolutionsKey, strings.Join(v1Solutions, " "), strings.Join(solutions, " "))
+	fmt.Fprintf(writer, "\t%s: '%s' -> '%s'\n", app.TuneForNotesKey, strings.Join(v1Notes, " "), strings.Join(notes, " "))
+	fmt.Fprintf(writer, "\t%s: '%s'\n", app.NoteApplyOrderKey, strings.Join(applyOrder, " "))
+	fmt.Fprintf(writer, "\tSAPTUNE_VERSION: '1' -> '2'\n\n")
+	v1Tuning, err := v1TuningLeftOvers(state, daemonRunning)
+	if err != nil {
+		return newExitError("Failed to read the saved state of the applied notes: %v", err)
+	}
+	if !commit {
+		if len(v1Tuning) != 0 {
+			fmt.Fprintf(writer, "Attention: saptune version 1 still tunes the system (%s). Please revert all solutions and notes and stop the daemon with saptune version 1 before the migration, see saptune-migrate(7).\n", strings.Join(v1Tuning, ", "))
+		}
+		fmt.Fprintf(writer, "This was a dry run, nothing has been changed. Use 'saptune migrate --commit' to write the changes.\n")
+		return nil
+	}
+	if len(v1Tuning) != 0 {
+		return newExitError("saptune version 1 still tunes the system (%s), so the configuration file '%s' is not migrated. Please revert all solutions and notes and stop the daemon with saptune version 1 first, see saptune-migrate(7).", strings.Join(v1Tuning, ", "), fileName)
+	}
+
+	sconf.SetStrArray(app.TuneForSolutionsKey, solutions)
+	sconf.SetStrArray(app.TuneForNotesKey, notes)
+	sconf.SetStrArray(app.NoteApplyOrderKey, applyOrder)
+	sconf.Set("SAPTUNE_VERSION", "2")
+	if err := ioutil.WriteFile(fileName, []byte(sconf.ToText()), 0644); err != nil {
+		return newExitError("Failed to write the configuration file '%s': %v", fileName, err)
+	}
+	system.InfoLog("configuration file '%s' migrated from saptune version 1 to saptune version 2", fileName)
+	fmt.Fprintf(writer, "The configuration file '%s' has been migrated to saptune version 2.\n", fileName)
+	fmt.Fprintf(writer, "Please check the solution and the notes with 'saptune solution list' and 'saptune note list' and run 'saptune daemon start' to tune the system with saptune version 2.\n")
+	if _, err := os.Stat(saptuneV1TunedConf); err == nil {
+		fmt.Fprintf(writer, "Please remove the directory '%s' left over from saptune version 1, see saptune-migrate(7).\n", path.Dir(saptuneV1TunedConf))
+	}
+	return nil
+}
+
+// v1TuningLeftOvers returns the reasons, why saptune version 1 still tunes
+// the system: the saptune profile of tuned is active or notes are still
+// applied according to their saved state files
+func v1TuningLeftOvers(state *app.State, daemonRunning bool) ([]string, error) {
+	reasons := make([]string, 0)
+	if daemonRunning {
+		reasons = append(reasons, fmt.Sprintf("the tuned profile '%s' is active", TunedProfileName))
+	}
+	applied, err := state.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) != 0 {
+		sort.Strings(applied)
+		reasons = append(reasons, fmt.Sprintf("the notes %s are still applied", strings.Join(applied, " ")))
+	}
+	return reasons, nil
+}
+
+// RevertAction Revert all notes and solutions or all notes with a tag
+func RevertAction(writer io.Writer, actionName, tag string, quiet, keepSolutions bool, tuneApp *app.App) error {
+	switch actionName {
+	case "all":
+		if keepSolutions {
+			return RevertActionKeepSolutions(writer, tuneApp)
+		}
+		var progress func(string, int, int)
+		if !quiet {
+			fmt.Fprintf(writer, "Reverting all notes and solutions, this may take some time...\n")
+			progress = func(noteID string, cnt, total int) {
+				fmt.Fprintf(writer, "reverting %s (%d/%d)...\n", noteID, cnt, total)
+			}
+		}
+		err := tuneApp.RevertAllWithProgress(true, progress)
+		recordHistory(tuneApp, "revert", "all", "all", err)
+		if err != nil {
+			return newExitError("Failed to revert notes: %v", err)
+			//panic(err)
+		}
+		fmt.Fprintf(writer, "Parameters tuned by the notes and solutions have been successfully reverted.\n")
+	case "tag":
+		return RevertActionTag(writer, tag, tuneApp)
+	default:
+		return usageError()
+	}
+	return nil
+}
+
+// RevertActionKeepSolutions reverts all manually enabled notes, which are
+// not part of an enabled solution. The solutions stay applied
+func RevertActionKeepSolutions(writer io.Writer, tuneApp *app.App) error {
+	reverted, preserved, err := tuneApp.RevertNotesKeepSolutions()
+	recordHistory(tuneApp, "revert --keep-solutions", "all", "all", err)
+	if err != nil {
+		return newExitError("Failed to revert notes: %v", err)
+	}
+	if len(reverted) == 0 {
+		fmt.Fprintf(writer, "No manually enabled notes outside of the enabled solutions found, nothing reverted.\n")
+	} else {
+		fmt.Fprintf(writer, "Parameters tuned by the notes %s have been successfully reverted.\n", strings.Join(reverted, " "))
+	}
+	if len(tuneApp.TuneForSolutions) != 0 {
+		fmt.Fprintf(writer, "Preserved solutions: %s\n", strings.Join(tuneApp.TuneForSolutions, " "))
+	}
+	if len(preserved) != 0 {
+		fmt.Fprintf(writer, "Preserved notes: %s\n", strings.Join(preserved, " "))
+	}
+	return nil
+}
+
+// RevertActionTag reverts all enabled notes carrying the given tag
+func RevertActionTag(writer io.Writer, tag string, tuneApp *app.App) error {
+	if tag == "" {
+		return usageError()
+	}
+	reverted, err := tuneApp.RevertTag(tag)
+	recordHistory(tuneApp, "revert", "tag", tag, err)
+	if err != nil {
+		return newExitError("Failed to revert the notes with tag '%s': %v", tag, err)
+	}
+	if len(reverted) == 0 {
+		fmt.Fprintf(writer, "No enabled notes with tag '%s' found.\n", tag)
+		return nil
+	}
+	fmt.Fprintf(writer, "Parameters tuned by the notes with tag '%s' have been successfully reverted: %s\n", tag, strings.Join(reverted, " "))
+	tuneApp.PrintNoteApplyOrder(writer)
+	return nil
+}
+
+// supportFile is a file collected by 'saptune support'
+type supportFile struct {
+	name    string
+	content []byte
+}
+
+// SupportAction collects the configuration of saptune and the state of the
+// system for bug reports. The collected files are written to the writer or,
+// with the option '--tarball', to a compressed tar archive.
+// With the option '--redact' the host names are replaced
+func SupportAction(writer io.Writer, tarball string, redact bool, tuneApp *app.App) error {
+	files := collectSupportFiles(tuneApp, app.SysconfigSaptuneFile)
+	if redact {
+		hostNames := []string{}
+		if host, err := os.Hostname(); err == nil {
+			hostNames = append(hostNames, host)
+		}
+		files = redactSupportFiles(files, hostNames)
+	}
+	if tarball == "" {
+		writeSupportText(writer, files)
+		return nil
+	}
+	if err := writeSupportTarball(tarball, files); err != nil {
+		return newExitError("%v", err)
+	}
+	fmt.Fprintf(writer, "The support information has been written to '%s'.\n", tarball)
+	return nil
+}
+
+// collectSupportFiles collects the saptune configuration file, the override
+// files, the extra Note definitions, the verify result of all enabled notes,
+// the annotations of the applied notes, the status of tuned and the kernel
+// and sysctl values used by the enabled notes
+func collectSupportFiles(tuneApp *app.App, sysconfigFile string) []supportFile {
+	files := make([]supportFile, 0)
+	addFile := func(fileName string) {
+		content, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			content = []byte(fmt.Sprintf("unable to read file - %v\n", err))
+		}
+		files = append(files, supportFile{fileName, content})
+	}
+	addFile(sysconfigFile)
+	for _, dir := range append(note.OverrideDirs(), ExtraTuningSheets) {
+		_, fileNames := system.ListDir(dir, "")
+		for _, fileName := range fileNames {
+			addFile(path.Join(dir, fileName))
+		}
+	}
+
+	verify := bytes.Buffer{}
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		fmt.Fprintln(&verify, "No notes or solutions enabled, nothing to verify.")
+	} else if unsatisfiedNotes, comparisons, err := tuneApp.VerifyAll(); err != nil {
+		fmt.Fprintf(&verify, "Failed to inspect the current system: %v\n", err)
+	} else {
+		PrintNoteFields(&verify, "NONE", comparisons, true)
+		tuneApp.PrintNoteApplyOrder(&verify)
+		fmt.Fprintf(&verify, "not compliant notes: %s\n", strings.Join(unsatisfiedNotes, " "))
+	}
+	files = append(files, supportFile{"saptune-verify", verify.Bytes()})
+
+	if annotated := tuneApp.AnnotatedNotes(); len(annotated) != 0 {
+		annotations := bytes.Buffer{}
+		for _, noteID := range annotated {
+			annotation, _ := tuneApp.NoteAnnotation(noteID)
+			fmt.Fprintf(&annotations, "%s: %s\n", noteID, annotation)
+		}
+		files = append(files, supportFile{"note-annotations", annotations.Bytes()})
+	}
+
+	tuned := fmt.Sprintf("%s running: %v\nactive tuned profile: %s\n", TunedService, system.SystemctlIsRunning(TunedService), system.GetTunedProfile())
+	files = append(files, supportFile{"tuned-status", []byte(tuned)})
+
+	kernel := bytes.Buffer{}
+	for _, fileName := range []string{"/proc/version", "/proc/cmdline"} {
+		content, _ := ioutil.ReadFile(fileName)
+		fmt.Fprintf(&kernel, "%s: %s\n", fileName, strings.TrimSpace(string(content)))
+	}
+	for _, key := range supportSysctlKeys(tuneApp) {
+		val, err := system.GetSysctlString(key)
+		if err != nil {
+			val = "NA"
+		}
+		fmt.Fprintf(&kernel, "%s = %s\n", key, strings.Join(strings.Fields(val), " "))
+	}
+	files = append(files, supportFile{"kernel-values", kernel.Bytes()})
+	return files
+}
+
+// supportSysctlKeys returns the sorted sysctl parameters of all enabled notes
+func supportSysctlKeys(tuneApp *app.App) []string {
+	keys := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, noteID := range tuneApp.NoteApplyOrder {
+		aNote, err := tuneApp.GetNoteByID(noteID)
+		if err != nil {
+			continue
+		}
+		iniNote, ok := aNote.(note.INISettings)
+		if !ok {
+			continue
+		}
+		ini, err := iniNote.ParseDefinition()
+		if err != nil {
+			continue
+		}
+		for _, param := range ini.AllValues {
+			if param.Section == note.INISectionSysctl && !seen[param.Key] {
+				seen[param.Key] = true
+				keys = append(keys, param.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// redactSupportFiles replaces the host names in the collected files
+func redactSupportFiles(files []supportFile, hostNames []string) []supportFile {
+	redacted := make([]supportFile, 0, len(files))
+	for _, file := range files {
+		content := file.content
+		for _, host := range hostNames {
+			if host == "" {
+				continue
+			}
+			content = bytes.Replace(content, []byte(host), []byte("<hostname>"), -1)
+			if short := strings.SplitN(host, ".", 2)[0]; short != host {
+				content = bytes.Replace(content, []byte(short), []byte("<hostname>"), -1)
+			}
+		}
+		redacted = append(redacted, supportFile{file.name, content})
+	}
+	return redacted
+}
+
+// writeSupportText writes the collected files one after the other, each
+// one headed by its name
+func writeSupportText(writer io.Writer, files []supportFile) {
+	for _, file := range files {
+		fmt.Fprintf(writer, "===== %s =====\n", file.name)
+		writer.Write(file.content)
+		if len(file.content) != 0 && file.content[len(file.content)-1] != '\n' {
+			fmt.Fprintf(writer, "\n")
+		}
+		fmt.Fprintf(writer, "\n")
+	}
+}
+
+// writeSupportTarball writes the collected files to a gzip compressed tar
+// archive below the directory 'saptune-support'
+func writeSupportTarball(fileName string, files []supportFile) error {
+	tarFile, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to create the archive '%s' - %v", fileName, err)
+	}
+	defer tarFile.Close()
+	gzWriter := gzip.NewWriter(tarFile)
+	tarWriter := tar.NewWriter(gzWriter)
+	now := time.Now()
+	for _, file := range files {
+		hdr := &tar.Header{
+			Name:    path.Join("saptune-support", file.name),
+			Mode:    0644,
+			Size:    int64(len(file.content)),
+			ModTime: now,
+		}
+		if err := tarWriter.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("Failed to write the archive '%s' - %v", fileName, err)
+		}
+		if _, err := tarWriter.Write(file.content); err != nil {
+			return fmt.Errorf("Failed to write the archive '%s' - %v", fileName, err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("Failed to write the archive '%s' - %v", fileName, err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("Failed to write the archive '%s' - %v", fileName, err)
+	}
+	return tarFile.Close()
+}
+
+// SnapshotAction handles snapshot actions like save and diff
+func SnapshotAction(writer io.Writer, actionName, name string, tuneApp *app.App) error {
+	if name == "" {
+		return usageError()
+	}
+	switch actionName {
+	case "save":
+		return SnapshotActionSave(writer, name, tuneApp)
+	case "diff":
+		return SnapshotActionDiff(writer, name, tuneApp)
+	}
+	return usageError()
+}
+
+// SnapshotActionSave saves the current values of the parameters of all
+// enabled notes and solutions to a snapshot
+func SnapshotActionSave(writer io.Writer, name string, tuneApp *app.App) error {
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		return newExitError("There are no notes or solutions enabled, nothing to save.")
+	}
+	cnt, err := tuneApp.SaveSnapshot(name)
+	if err != nil {
+		return newExitError("Failed to save snapshot '%s': %v", name, err)
+	}
+	fmt.Fprintf(writer, "Snapshot '%s' with the values of %d parameters saved to '%s'.\n", name, cnt, tuneApp.GetPathToSnapshot(name))
+	return nil
+}
+
+// SnapshotActionDiff prints the parameters, which have changed since the
+// snapshot was saved
+func SnapshotActionDiff(writer io.Writer, name string, tuneApp *app.App) error {
+	snapshot, changes, err := tuneApp.DiffSnapshot(name)
+	if err != nil {
+		return newExitError("Failed to compare the system with snapshot '%s': %v", name, err)
+	}
+	skeys := sortNoteComparisonsOutput(changes)
+	if len(skeys) == 0 {
+		fmt.Fprintf(writer, "\nNo parameters changed since snapshot '%s' was saved at %s.\n\n", name, snapshot.Created)
+		return nil
+	}
+	// setup table format values
+	fmtlen0, fmtlen2, fmtlen3, fmtlen4 := len("SAPNote"), len("Parameter"), len("Snapshot"), len("Actual")
+	for noteID, comparisons := range changes {
+		if len(noteID) > fmtlen0 {
+			fmtlen0 = len(noteID)
+		}
+		for _, comparison := range comparisons {
+			_, fmtlen2, fmtlen3, fmtlen4 = setWidthOfColums(comparison, 0, fmtlen2, fmtlen3, fmtlen4)
+		}
+	}
+	format := "   %-" + strconv.Itoa(fmtlen0) + "s | %-" + strconv.Itoa(fmtlen2) + "s | %-" + strconv.Itoa(fmtlen3) + "s | %s\n"
+	fmt.Fprintf(writer, "\nParameters changed since snapshot '%s' was saved at %s:\n\n", name, snapshot.Created)
+	fmt.Fprintf(writer, format, "SAPNote", "Parameter", "Snapshot", "Actual")
+	fmt.Fprintf(writer, "%s+%s+%s+%s\n", strings.Repeat("-", 3+fmtlen0+1), strings.Repeat("-", fmtlen2+2), strings.Repeat("-", fmtlen3+2), strings.Repeat("-", fmtlen4+1))
+	for _, skey := range skeys {
+		keyFields := strings.Split(skey, "§")
+		noteID, key := keyFields[0], keyFields[1]
+		for _, comparison := range changes[noteID] {
+			if comparison.ReflectMapKey == key {
+				fmt.Fprintf(writer, format, noteID, key, strings.Replace(comparison.ExpectedValueJS, "\t", " ", -1), strings.Replace(comparison.ActualValueJS, "\t", " ", -1))
+			}
+		}
+	}
+	fmt.Fprintf(writer, "\n")
+	return nil
+}
+
+// historyTimeFormats are the supported formats of the option '--since' of
+// 'saptune history'
+var historyTimeFormats = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// parseHistorySince parses the value of the option '--since' of
+// 'saptune history'. A value without time zone is taken as local time
+func parseHistorySince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range historyTimeFormats {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported date '%s', use 'YYYY-MM-DD' or 'YYYY-MM-DD hh:mm:ss'", since)
+}
+
+// HistoryAction prints the apply, revert and customise actions recorded in
+// the history file, optionally only those since the given date
+func HistoryAction(writer io.Writer, since string, asJSON bool, tuneApp *app.App) error {
+	sinceTime, err := parseHistorySince(since)
+	if err != nil {
+		return newExitError("Invalid value of option '--since': %v", err)
+	}
+	entries, err := tuneApp.ReadHistory(sinceTime)
+	if err != nil {
+		return newExitError("Failed to read the history file '%s': %v", tuneApp.GetPathToHistory(), err)
+	}
+	if asJSON {
+		content, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return newExitError("Failed to create the json output: %v", err)
+		}
+		fmt.Fprintf(writer, "%s\n", string(content))
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(writer, "\nNo actions recorded in the history.\n\n")
+		return nil
+	}
+	// setup table format values
+	fmtlen0, fmtlen1, fmtlen2, fmtlen3 := len("Time"), len("User"), len("Action"), len("Note/Solution")
+	for _, entry := range entries {
+		if len(entry.Time) > fmtlen0 {
+			fmtlen0 = len(entry.Time)
+		}
+		if len(entry.User) > fmtlen1 {
+			fmtlen1 = len(entry.User)
+		}
+		if len(entry.Action) > fmtlen2 {
+			fmtlen2 = len(entry.Action)
+		}
+		if len(entry.Type+" "+entry.ID) > fmtlen3 {
+			fmtlen3 = len(entry.Type + " " + entry.ID)
+		}
+	}
+	format := "   %-" + strconv.Itoa(fmtlen0) + "s | %-" + strconv.Itoa(fmtlen1) + "s | %-" + strconv.Itoa(fmtlen2) + "s | %-" + strconv.Itoa(fmtlen3) + "s | %s\n"
+	fmt.Fprintf(writer, "\n")
+	fmt.Fprintf(writer, format, "Time", "User", "Action", "Note/Solution", "Result")
+	fmt.Fprintf(writer, "%s+%s+%s+%s+%s\n", strings.Repeat("-", 3+fmtlen0+1), strings.Repeat("-", fmtlen1+2), strings.Repeat("-", fmtlen2+2), strings.Repeat("-", fmtlen3+2), strings.Repeat("-", len("Result")+1))
+	for _, entry := range entries {
+		fmt.Fprintf(writer, format, entry.Time, entry.User, entry.Action, entry.Type+" "+entry.ID, entry.Result)
+	}
+	fmt.Fprintf(writer, "\n")
+	return nil
+}
+
+// daemonReminderNeeded returns true, if the reminder to start the saptune
+// daemon needs to be printed. This is the case, if tuned is not running
+// with the saptune profile and the reminder is not switched off by
+// SKIP_DAEMON_REMINDER in /etc/sysconfig/saptune
+func daemonReminderNeeded() bool {
+	if skipDaemonReminder {
+		return false
+	}
+	return !daemonActive()
+}
+
+// daemonActive returns true, if tuned is running with the saptune profile
+func daemonActive() bool {
+	return system.SystemctlIsRunning(TunedService) && system.GetTunedProfile() == TunedProfileName
+}
+
+// DaemonAction handles daemon actions like start, stop, status asm.
+func DaemonAction(actionName string) {
+	switch actionName {
+	case "start":
+		exitOnError(DaemonActionStart())
+	case "apply":
+		// This action name is only used by tuned script, hence it is not advertised to end user.
+		if err := tuneApp.TuneAll(); err != nil {
+			panic(err)
+		}
+	case "status":
+		exitOnError(DaemonActionStatus(os.Stdout, cliFlag("json"), cliFlag("quiet"), tuneApp))
+	case "stop":
+		exitOnError(DaemonActionStop())
+	case "reload":
+		if !system.SystemctlIsRunning(TunedService) {
+			exitOnError(newExitError("Daemon (tuned.service) is stopped, so there is nothing to reload. If you wish to start the daemon, run `saptune daemon start`."))
+		}
+		exitOnError(DaemonActionReload(os.Stdout, tuneApp))
+	case "revert":
+		// This action name is only used by tuned script, hence it is not advertised to end user.
+		if err := tuneApp.RevertAll(false); err != nil {
+			panic(err)
+		}
+	default:
+		PrintHelpAndExit(1)
+	}
+}
+
+// DaemonActionReload re-reads the Note definition and override files and
+// sets only the parameters of the applied notes, whose expected value
+// changed, without a revert and re-apply of all notes
+func DaemonActionReload(writer io.Writer, tuneApp *app.App) error {
+	fmt.Fprintln(writer, "Reloading the Note definition and override files of the applied notes...")
+	reloaded, err := tuneApp.ReloadNotes()
+	recordHistory(tuneApp, "reload", "all", "all", err)
+	for _, param := range reloaded {
+		fmt.Fprintf(writer, "\tnote %s: parameter '%s' changed from '%s' to '%s'\n", param.NoteID, param.Param, param.OldValue, param.NewValue)
+	}
+	if err != nil {
+		return newExitError("Failed to reload the notes: %v", err)
+	}
+	if len(reloaded) == 0 {
+		fmt.Fprintln(writer, "All parameters already conform to the Note definitions, nothing to update.")
+	} else {
+		fmt.Fprintf(writer, "%d parameter(s) updated.\n", len(reloaded))
+	}
+	return nil
+}
+
+// daemonExecutor executes the steps of 'saptune daemon start'
+type daemonExecutor interface {
+	DisableStop(service string) error
+	SetProfile(profile string) error
+	EnableStart(service string) error
+}
+
+// systemExecutor executes the steps of 'saptune daemon start' on the system
+type systemExecutor struct{}
+
+// DisableStop disables and stops the service
+func (systemExecutor) DisableStop(service string) error {
+	return system.SystemctlDisableStop(service)
+}
+
+// SetProfile activates the tuned profile
+func (systemExecutor) SetProfile(profile string) error {
+	return system.TunedAdmProfile(profile)
+}
+
+// EnableStart enables and starts the service
+func (systemExecutor) EnableStart(service string) error {
+	return system.SystemctlEnableStart(service)
+}
+
+// dryRunExecutor only reports the steps of 'saptune daemon start' together
+// with the current state of the system, nothing is changed
+type dryRunExecutor struct {
+	writer        io.Writer
+	isRunning     func(service string) bool
+	activeProfile func() string
+}
+
+// serviceState returns the current state of the service
+func (exe dryRunExecutor) serviceState(service string) string {
+	if exe.isRunning(service) {
+		return "running"
+	}
+	return "not running"
+}
+
+// DisableStop reports, that the service would be disabled and stopped
+func (exe dryRunExecutor) DisableStop(service string) error {
+	fmt.Fprintf(exe.writer, "Would disable and stop %s (currently %s).\n", service, exe.serviceState(service))
+	return nil
+}
+
+// SetProfile reports, that the tuned profile would be activated
+func (exe dryRunExecutor) SetProfile(profile string) error {
+	current := exe.activeProfile()
+	if current == "" {
+		current = "none"
+	}
+	fmt.Fprintf(exe.writer, "Would set the tuned profile to '%s' (currently '%s').\n", profile, current)
+	return nil
+}
+
+// EnableStart reports, that the service would be enabled and started
+func (exe dryRunExecutor) EnableStart(service string) error {
+	fmt.Fprintf(exe.writer, "Would enable and start %s (currently %s).\n", service, exe.serviceState(service))
+	return nil
+}
+
+// startDaemon stops sapconf, sets the tuned profile and starts tuned by
+// the given executor
+func startDaemon(exe daemonExecutor) error {
+	exe.DisableStop(SapconfService) // do not error exit on failure
+	if err := exe.SetProfile(TunedProfileName); err != nil {
+		return err
+	}
+	return exe.EnableStart(TunedService)
+}
+
+// DaemonActionStartDryRun reports the services, which 'saptune daemon start'
+// would stop and start, the tuned profile it would set and the notes, which
+// tuned would apply, without changing the system
+func DaemonActionStartDryRun(writer io.Writer, exe daemonExecutor, tuneApp *app.App) error {
+	fmt.Fprintln(writer, "Dry run of 'saptune daemon start', the system is not changed.")
+	if err := startDaemon(exe); err != nil {
+		return newExitError("%v", err)
+	}
+	order, err := tuneApp.DaemonApplyOrder()
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	if len(order) == 0 {
+		fmt.Fprintln(writer, "No notes or solutions enabled, tuned.service would not apply any note.")
+		return nil
+	}
+	if len(tuneApp.TuneForSolutions) != 0 {
+		fmt.Fprintf(writer, "Enabled solutions: %s\n", strings.Join(tuneApp.TuneForSolutions, " "))
+	}
+	fmt.Fprintln(writer, "tuned.service would apply the following notes in this order:")
+	for _, noteID := range order {
+		applied := ""
+		if tuneApp.IsNoteApplied(noteID) {
+			applied = " (already applied)"
+		}
+		fmt.Fprintf(writer, "\t%s%s\n", noteID, applied)
+	}
+	return nil
+}
+
+// DaemonActionStart starts the tuned service
+func DaemonActionStart() error {
+	if cliFlag("dry-run") {
+		return DaemonActionStartDryRun(os.Stdout, dryRunExecutor{writer: os.Stdout, isRunning: system.SystemctlIsRunning, activeProfile: system.GetTunedAdmProfile}, tuneApp)
+	}
+	fmt.Println("Starting daemon (tuned.service), this may take several seconds...")
+	if err := startDaemon(systemExecutor{}); err != nil {
+		return newExitError("%v", err)
+	}
+	timeout, wait, err := daemonWaitTimeout()
+	if err != nil {
+		return err
+	}
+	if wait {
+		// tuned applies the profile asynchronously, so wait until
+		// the profile is active and the system conforms to the
+		// enabled notes
+		fmt.Printf("Waiting up to %v for tuned.service to apply the tuning...\n", timeout)
+		if !waitForTuning(timeout, daemonWaitInterval, func() bool { return tuningConverged(tuneApp) }) {
+			_ = system.ErrorLog("tuned.service did not apply the saptune tuning within %v. Please check tuned logs for more information", timeout)
+			// defined exit value needed for yast module
+			return &ExitError{Code: exitTunedWrongProfile}
+		}
+		fmt.Println("Daemon (tuned.service) has been enabled and started. The system is tuned.")
+	} else if system.GetTunedAdmProfile() != TunedProfileName {
+		// Check tuned profile
+		_ = system.ErrorLog("tuned.service profile is incorrect. Please check tuned logs for more information")
+		// defined exit value needed for yast module
+		return &ExitError{Code: exitTunedWrongProfile}
+	} else {
+		// tuned then calls `saptune daemon apply`
+		fmt.Println("Daemon (tuned.service) has been enabled and started.")
+	}
+	if len(tuneApp.TuneForSolutions) == 0 && len(tuneApp.TuneForNotes) == 0 {
+		fmt.Println("Your system has not yet been tuned. Please visit `saptune note` and `saptune solution` to start tuning.")
+	}
+	return nil
+}
+
+// daemonWaitTimeout returns the timeout of the option '--wait[=seconds]'
+// and if the option is specified
+func daemonWaitTimeout() (time.Duration, bool, error) {
+	if !cliFlag("wait") {
+		return 0, false, nil
+	}
+	value := cliFlagValue("wait")
+	if value == "" {
+		return defaultWaitTimeout, true, nil
+	}
+	secs, err := strconv.Atoi(value)
+	if err != nil || secs <= 0 {
+		return 0, true, newExitError("Wrong value '%s' for option '--wait', expected the timeout in seconds.", value)
+	}
+	return time.Duration(secs) * time.Second, true, nil
+}
+
+// waitForTuning calls converged every interval until it returns true or
+// the timeout elapses. Returns false, if the timeout elapsed
+func waitForTuning(timeout, interval time.Duration, converged func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if converged() {
+			return true
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return false
+		}
+		time.Sleep(interval)
+	}
+}
+
+// tuningConverged returns true, if tuned uses the saptune profile and the
+// system conforms to all enabled notes
+func tuningConverged(tuneApp *app.App) bool {
+	if system.GetTunedAdmProfile() != TunedProfileName {
+		return false
+	}
+	unsatisfiedNotes, _, err := tuneApp.VerifyAll()
+	return err == nil && len(unsatisfiedNotes) == 0
+}
+
+// DaemonActionStatus checks the status of the tuned service. The state is
+// reported by the exit code of the returned error: exitTunedStopped,
+// exitTunedWrongProfile or exitNotTuned. The error does not carry a
+// message, as the reason is already printed to stderr. With quiet nothing
+// is printed at all, e.g. for health checks, which only need the exit code.
+// With asJSON the status is printed by DaemonActionStatusJSON instead
+func DaemonActionStatus(writer io.Writer, asJSON, quiet bool, tuneApp *app.App) error {
+	if asJSON && quiet {
+		return newExitError("The option '--json' can not be used together with the option '--quiet'.")
+	}
+	if asJSON {
+		return DaemonActionStatusJSON(writer, tuneApp)
+	}
+	errWriter := io.Writer(os.Stderr)
+	if quiet {
+		writer = ioutil.Discard
+		errWriter = ioutil.Discard
+	}
+	// Check daemon
+	if system.SystemctlIsRunning(TunedService) {
+		fmt.Fprintln(writer, "Daemon (tuned.service) is running.")
+	} else {
+		fmt.Fprintln(errWriter, "Daemon (tuned.service) is stopped. If you wish to start the daemon, run `saptune daemon start`.")
+		return &ExitError{Code: exitTunedStopped}
+	}
+	// Check tuned profile
+	profile := activeTunedProfile()
+	if profile != TunedProfileName {
+		fmt.Fprint(errWriter, tunedProfileConflict(profile, system.SystemctlIsRunning(SapconfService)))
+		return &ExitError{Code: exitTunedWrongProfile}
+	}
+	// Check for any enabled note/solution
+	if len(tuneApp.TuneForSolutions) == 0 && len(tuneApp.TuneForNotes) == 0 {
+		fmt.Fprintln(errWriter, "Your system has not yet been tuned. Please visit `saptune note` and `saptune solution` to start tuning.")
+		return &ExitError{Code: exitNotTuned}
+	}
+	fmt.Fprintln(writer, "The system has been tuned for the following solutions and notes:")
+	for _, sol := range tuneApp.TuneForSolutions {
+		fmt.Fprintln(writer, "\t"+sol)
+	}
+	for _, noteID := range tuneApp.TuneForNotes {
+		fmt.Fprintln(writer, "\t"+noteID)
+	}
+	return nil
+}
+
+// activeTunedProfile returns the name of the active tuned profile
+func activeTunedProfile() string {
+	profile := system.GetTunedProfile()
+	if profile == "" {
+		// file /etc/tuned/active_profile not available, ask tuned
+		profile = system.GetTunedAdmProfile()
+	}
+	return profile
+}
+
+// daemonStatus is the status of the daemon printed by
+// 'saptune daemon status --json'
+type daemonStatus struct {
+	Running           bool     `json:"running"`
+	ProfileCorrect    bool     `json:"profileCorrect"`
+	ActiveProfile     string   `json:"activeProfile"`
+	TunedForSolutions []string `json:"tunedForSolutions"`
+	TunedForNotes     []string `json:"tunedForNotes"`
+}
+
+// DaemonActionStatusJSON prints the status of the daemon in JSON format.
+// Contrary to DaemonActionStatus the state is not reported by the exit
+// code, so saptune exits with 0 unless an error occurs
+func DaemonActionStatusJSON(writer io.Writer, tuneApp *app.App) error {
+	status := daemonStatus{
+		Running:           system.SystemctlIsRunning(TunedService),
+		ActiveProfile:     activeTunedProfile(),
+		TunedForSolutions: append([]string{}, tuneApp.TuneForSolutions...),
+		TunedForNotes:     append([]string{}, tuneApp.TuneForNotes...),
+	}
+	status.ProfileCorrect = status.ActiveProfile == TunedProfileName
+	content, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return newExitError("Failed to create the json output: %v", err)
+	}
+	fmt.Fprintf(writer, "%s\n", string(content))
+	return nil
+}
+
+// tunedProfileConflict describes, why the active tuned profile is not the
+// one of saptune, so that the operator understands who is fighting saptune
+func tunedProfileConflict(profile string, sapconfRunning bool) string {
+	msg := "tuned.service profile is incorrect. "
+	if profile == "" {
+		msg = msg + fmt.Sprintf("No tuned profile is active instead of '%s'.\n", TunedProfileName)
+	} else {
+		msg = msg + fmt.Sprintf("The active tuned profile is '%s' instead of '%s', so tuned applies the settings of profile '%s'.\n", profile, TunedProfileName, profile)
+	}
+	if sapconfRunning {
+		msg = msg + fmt.Sprintf("%s is running and tunes the system, too. saptune and sapconf must not be used at the same time.\n", SapconfService)
+	} else {
+		msg = msg + fmt.Sprintf("%s is not running.\n", SapconfService)
+	}
+	return msg + "If you wish to correct it, run `saptune daemon start`.\n"
+}
+
+// DaemonActionStop stops the tuned service
+func DaemonActionStop() error {
+	fmt.Println("Stopping daemon (tuned.service), this may take several seconds...")
+	if err := system.TunedAdmOff(); err != nil {
+		return newExitError("%v", err)
+	}
+	if err := system.SystemctlDisableStop(TunedService); err != nil {
+		return newExitError("%v", err)
+	}
+	// tuned then calls `saptune daemon revert`
+	fmt.Println("Daemon (tuned.service) has been disabled and stopped.")
+	fmt.Println("All tuned parameters have been reverted to default.")
+	return nil
+}
+
+// PrintNoteFields Print mismatching fields in the note comparison result.
+//func PrintNoteFields(header string, noteComparisons map[string]map[string]note.FieldComparison, printComparison bool) {
+func PrintNoteFields(writer io.Writer, header string, noteComparisons map[string]map[string]note.FieldComparison, printComparison bool) {
+
+	// initialise
+	compliant := "yes"
+	printHead := ""
+	noteField := ""
+	footnote := make([]string, 8, 8)
+	reminder := make(map[string]string)
+	comment := ""
+	hasDiff := false
+	explanations := make(map[string]map[string]string)
+
+	// sort output
+	sortkeys := sortNoteComparisonsOutput(noteComparisons)
+	if verifyDiffOnly && printComparison {
+		sortkeys = deviatingSortKeys(sortkeys, noteComparisons)
+	}
+
+	// setup table format values
+	fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, format := setupTableFormat(sortkeys, noteField, noteComparisons, printComparison)
+	fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, format = fitTableWidth(tableWidth, printComparison, fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4)
+
+	// print
+	noteID := ""
+	for _, skey := range sortkeys {
+		comment = ""
+		keyFields := strings.Split(skey, "§")
+		key := keyFields[1]
+		printHead = ""
+		if keyFields[0] != noteID {
+			if noteID == "" {
+				printHead = "yes"
+			}
+			noteID = keyFields[0]
+			//noteField = fmt.Sprintf("%s, %s", noteID, txtparser.GetINIFileVersion(noteComparisons[noteID]["ConfFilePath"].ActualValue.(string)))
+			noteField = fmt.Sprintf("%s, %s", noteID, txtparser.GetINIFileVersionSectionEntry(noteComparisons[noteID]["ConfFilePath"].ActualValue.(string), "version"))
+		}
+
+		comparison, override, inform := getNoteFieldValues(noteComparisons, noteID, key)
+		if comparison.ReflectMapKey == "reminder" {
+			reminder[noteID] = reminder[noteID] + comparison.ExpectedValueJS
+			continue
+		}
+		if !comparison.MatchExpectation {
+			hasDiff = true
+			compliant = "no "
+			if comparison.Severity != "" {
+				compliant = fmt.Sprintf("no (%s)", comparison.Severity)
+			}
+		} else if comparison.Ignored {
+			compliant = "ignored"
+		} else {
+			compliant = "yes"
 		}
 
 		// prepare footnote
 		compliant, comment, footnote = prepareFootnote(comparison, compliant, comment, inform, footnote)
 
-		// print table header
-		if printHead != "" {
-			printHeadline(writer, header, noteID, tuningOptions)
-			printTableHeader(writer, format, fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, printComparison)
+		// print table header
+		if printHead != "" {
+			printHeadline(writer, header, noteID, tuningOptions)
+			printTableHeader(writer, format, fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, printComparison)
+		}
+
+		// print table body
+		if printComparison {
+			// verify
+			row := fmt.Sprintf(format, noteField, comparison.ReflectMapKey, truncateValue(expectedValueOf(comparison, inform), fmtlen2), truncateValue(override, fmtlen3), truncateValue(strings.Replace(comparison.ActualValueJS, "\t", " ", -1), fmtlen4), compliant)
+			if comparison.Severity != "" && !comparison.MatchExpectation {
+				row = colorizeSeverity(row, comparison.Severity)
+			}
+			if watchChanged[noteID][comparison.ReflectMapKey] {
+				row = colorize(strings.TrimSuffix(row, "\n")+" <-- changed", setBoldText) + "\n"
+			}
+			fmt.Fprint(writer, row)
+			if explainVerify && !comparison.MatchExpectation {
+				if _, ok := explanations[noteID]; !ok {
+					includeFiles, _ := noteComparisons[noteID]["IncludeFiles"].ActualValue.([]string)
+					explanations[noteID] = note.INISettings{ConfFilePath: noteComparisons[noteID]["ConfFilePath"].ActualValue.(string), ID: noteID, IncludeFiles: includeFiles}.ParamComments()
+				}
+				printExplanation(writer, explanations[noteID][comparison.ReflectMapKey], fmtlen0)
+			}
+		} else {
+			// simulate
+			fmt.Fprintf(writer, format, comparison.ReflectMapKey, truncateValue(strings.Replace(comparison.ActualValueJS, "\t", " ", -1), fmtlen2), truncateValue(expectedValueOf(comparison, inform), fmtlen3), truncateValue(override, fmtlen4), comment)
+		}
+	}
+	if verifyDiffOnly && printComparison && !hasDiff {
+		fmt.Fprintf(writer, "No deviating parameters found.\n")
+	}
+	// print footer
+	printTableFooter(writer, header, footnote, reminder, hasDiff)
+}
+
+// colorizeSeverity colours a row of the verify table of a deviating
+// parameter according to the severity of the parameter. Rows of severity
+// 'info' are not coloured
+func colorizeSeverity(row, severity string) string {
+	switch severity {
+	case txtparser.SeverityCritical:
+		return colorize(strings.TrimSuffix(row, "\n"), setRedText) + "\n"
+	case txtparser.SeverityWarning:
+		return colorize(strings.TrimSuffix(row, "\n"), setYellowText) + "\n"
+	}
+	return row
+}
+
+// paramSeverity returns the severity of a deviation of the parameter of the
+// comparison. Parameters without severity in the Note definition are
+// 'critical'
+func paramSeverity(comparison note.FieldComparison) string {
+	if comparison.Severity == "" {
+		return txtparser.SeverityCritical
+	}
+	return comparison.Severity
+}
+
+// deviationSeverity returns the highest severity of the deviating
+// parameters. Parameters without severity in the Note definition are
+// 'critical'. Returns an empty string, if no parameter deviates
+func deviationSeverity(comparisons map[string]map[string]note.FieldComparison) string {
+	highest := ""
+	for _, noteComparisons := range comparisons {
+		for _, comparison := range noteComparisons {
+			if comparison.ReflectFieldName != "SysctlParams" || comparison.ReflectMapKey == "" || comparison.ReflectMapKey == "reminder" || comparison.MatchExpectation {
+				continue
+			}
+			severity := paramSeverity(comparison)
+			if highest == "" || txtparser.SeverityRank(severity) > txtparser.SeverityRank(highest) {
+				highest = severity
+			}
+		}
+	}
+	return highest
+}
+
+// deviationError handles the deviation of the parameters listed in the
+// verify table according to their highest severity. A 'critical' deviation
+// or 'warning' returns an error with exit code exitNotCompliant. Deviations
+// of severity 'info' only are logged as warning and do not return an error
+func deviationError(comparisons map[string]map[string]note.FieldComparison, template string, stuff ...interface{}) error {
+	message := strings.TrimSpace(fmt.Sprintf(template, stuff...))
+	switch deviationSeverity(comparisons) {
+	case txtparser.SeverityInfo:
+		system.WarningLog("%s Only parameters of severity 'info' deviate, so the system is considered compliant.", message)
+		return nil
+	case txtparser.SeverityWarning:
+		return &ExitError{Code: exitNotCompliant, Message: message + " Only parameters of severity 'warning' deviate."}
+	default:
+		return &ExitError{Code: exitNotCompliant, Message: message}
+	}
+}
+
+// deviatingSortKeys returns only the sort keys of the parameters, which do
+// not match the expected values, including the ignored deviations. The
+// reminder entries are kept
+func deviatingSortKeys(skeys []string, noteCompare map[string]map[string]note.FieldComparison) []string {
+	dkeys := make([]string, 0, len(skeys))
+	for _, skey := range skeys {
+		keyFields := strings.Split(skey, "§")
+		if keyFields[1] == "reminder" {
+			dkeys = append(dkeys, skey)
+			continue
+		}
+		if comparison := noteCompare[keyFields[0]][fmt.Sprintf("%s[%s]", "SysctlParams", keyFields[1])]; !comparison.MatchExpectation || comparison.Ignored {
+			dkeys = append(dkeys, skey)
+		}
+	}
+	return dkeys
+}
+
+// complianceScore summarises the compliance of the parameters of a verify
+type complianceScore struct {
+	Parameters     int     `json:"parametersChecked"`
+	Compliant      int     `json:"parametersCompliant"`
+	Deviating      int     `json:"parametersDeviating"`
+	Ignored        int     `json:"parametersIgnored"`
+	DeviatingNotes int     `json:"notesDeviating"`
+	Percent        float64 `json:"compliancePercent"`
+}
+
+// computeComplianceScore counts the compliant and the deviating parameters
+// and the notes with deviating parameters of the verify comparisons.
+// Reminder entries, parameters not managed by a partially applied note and
+// ignored deviations are not counted, the ignored deviations are reported
+// separately. Without parameters the score is 100 percent
+func computeComplianceScore(comparisons map[string]map[string]note.FieldComparison) complianceScore {
+	score := complianceScore{Percent: 100}
+	deviatingNotes := make(map[string]bool)
+	for _, skey := range sortNoteComparisonsOutput(comparisons) {
+		keyFields := strings.Split(skey, "§")
+		comparison, override, _ := getNoteFieldValues(comparisons, keyFields[0], keyFields[1])
+		if comparison.ReflectMapKey == "" || comparison.ReflectMapKey == "reminder" || override == note.NotManaged {
+			continue
+		}
+		if comparison.Ignored {
+			score.Ignored++
+			continue
+		}
+		score.Parameters++
+		if comparison.MatchExpectation {
+			score.Compliant++
+		} else {
+			score.Deviating++
+			deviatingNotes[keyFields[0]] = true
+		}
+	}
+	score.DeviatingNotes = len(deviatingNotes)
+	if score.Parameters != 0 {
+		score.Percent = math.Round(float64(score.Compliant)*1000/float64(score.Parameters)) / 10
+	}
+	return score
+}
+
+// String returns the compliance score as summary line of a verify
+func (score complianceScore) String() string {
+	notes := "notes"
+	if score.DeviatingNotes == 1 {
+		notes = "note"
+	}
+	summary := fmt.Sprintf("Compliance: %d/%d parameters (%.1f%%), %d deviating across %d %s", score.Compliant, score.Parameters, score.Percent, score.Deviating, score.DeviatingNotes, notes)
+	if score.Ignored != 0 {
+		summary = summary + fmt.Sprintf(", %d ignored", score.Ignored)
+	}
+	return summary
+}
+
+// verifyIgnoreList returns per note the parameters, whose deviation is
+// accepted. They are set by VERIFY_IGNORE in /etc/sysconfig/saptune and
+// the command line option '--ignore'
+func verifyIgnoreList() (map[string]map[string]bool, error) {
+	ignoreList := append([]string{verifyIgnoreConf}, cliFlagValues("ignore")...)
+	ignore, err := parseVerifyIgnore(strings.Join(ignoreList, " "))
+	if err != nil {
+		return nil, newExitError("Wrong value for VERIFY_IGNORE in file '/etc/sysconfig/saptune' or for option '--ignore': %v", err)
+	}
+	return ignore, nil
+}
+
+// parseVerifyIgnore parses the ignore list of verify. The entries
+// 'NoteID:ParameterName' are separated by blanks or commas
+func parseVerifyIgnore(list string) (map[string]map[string]bool, error) {
+	ignore := make(map[string]map[string]bool)
+	entries := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, entry := range entries {
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("wrong entry '%s' in the ignore list of verify, expected 'NoteID:ParameterName'", entry)
+		}
+		if _, ok := ignore[fields[0]]; !ok {
+			ignore[fields[0]] = make(map[string]bool)
+		}
+		ignore[fields[0]][fields[1]] = true
+	}
+	return ignore, nil
+}
+
+// ignoreDeviations marks the deviating parameters found in the ignore list
+// as compliant and ignored, so they do not count for the conformance
+// verdict, but are still shown in the verify table. Returns the notes,
+// which still deviate
+func ignoreDeviations(comparisons map[string]map[string]note.FieldComparison, unsatisfiedNotes []string, ignore map[string]map[string]bool) []string {
+	if len(ignore) == 0 {
+		return unsatisfiedNotes
+	}
+	for noteID, noteComparisons := range comparisons {
+		for key, comparison := range noteComparisons {
+			if comparison.ReflectFieldName != "SysctlParams" || comparison.MatchExpectation || !ignoredParam(ignore[noteID], comparison.ReflectMapKey) {
+				continue
+			}
+			comparison.MatchExpectation = true
+			comparison.Ignored = true
+			noteComparisons[key] = comparison
+			system.InfoLog("deviation of parameter '%s' of note '%s' ignored", comparison.ReflectMapKey, noteID)
+		}
+	}
+	unsatisfied := make([]string, 0, len(unsatisfiedNotes))
+	for _, noteID := range unsatisfiedNotes {
+		for _, comparison := range comparisons[noteID] {
+			if comparison.ReflectFieldName == "SysctlParams" && !comparison.MatchExpectation {
+				unsatisfied = append(unsatisfied, noteID)
+				break
+			}
+		}
+	}
+	return unsatisfied
+}
+
+// blockParamDevice breaks up a parameter of the [block] section into the
+// parameter name and the block device, e.g. 'IO_SCHEDULER_sda'
+var blockParamDevice = regexp.MustCompile(`^(IO_SCHEDULER|NRREQ)_(.+)$`)
+
+// ignoredParam returns true, if the parameter is part of the ignore list of
+// a note. The parameters of the [block] section are ignored for a single
+// block device, e.g. 'IO_SCHEDULER_sda', or for all block devices by their
+// name without the block device, e.g. 'IO_SCHEDULER'
+func ignoredParam(params map[string]bool, key string) bool {
+	if params[key] {
+		return true
+	}
+	if fields := blockParamDevice.FindStringSubmatch(key); fields != nil {
+		return params[fields[1]]
+	}
+	return false
+}
+
+// getNoteFieldValues returns the comparison, the override value and the
+// inform value of a parameter of a Note, as shown in the verify table
+func getNoteFieldValues(noteComparisons map[string]map[string]note.FieldComparison, noteID, key string) (note.FieldComparison, string, string) {
+	override := strings.Replace(noteComparisons[noteID][fmt.Sprintf("%s[%s]", "OverrideParams", key)].ExpectedValueJS, "\t", " ", -1)
+	comparison := noteComparisons[noteID][fmt.Sprintf("%s[%s]", "SysctlParams", key)]
+
+	// check inform map for special settings
+	inform := ""
+	if noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ActualValue != nil {
+		inform = noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ActualValue.(string)
+		if inform == "" && noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ExpectedValue != nil {
+			inform = noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ExpectedValue.(string)
+		}
+	}
+	return comparison, override, inform
+}
+
+// expectedValueOf returns the expected value of a parameter as shown in the
+// verify and simulate table. If the value is calculated from a formula,
+// the formula is added to the value
+func expectedValueOf(comparison note.FieldComparison, inform string) string {
+	expected := strings.Replace(comparison.ExpectedValueJS, "\t", " ", -1)
+	if note.IsSysctlFormula(inform) {
+		expected = fmt.Sprintf("%s (%s)", expected, inform)
+	}
+	return expected
+}
+
+// printExplanation prints the explanation of a parameter value found in
+// the Note definition file beneath the table row of the parameter
+func printExplanation(writer io.Writer, explanation string, col0 int) {
+	if explanation == "" {
+		explanation = "no explanation available in the Note definition file"
+	}
+	for _, line := range strings.Split(explanation, "\n") {
+		fmt.Fprintf(writer, "   %-"+strconv.Itoa(col0)+"s | -> %s\n", "", line)
+	}
+}
+
+// sortNoteComparisonsOutput sorts the output of the Note comparison
+// the reminder section should be the last one
+func sortNoteComparisonsOutput(noteCompare map[string]map[string]note.FieldComparison) []string {
+	skeys := make([]string, 0, len(noteCompare))
+	rkeys := make([]string, 0, len(noteCompare))
+	// sort output
+	for noteID, comparisons := range noteCompare {
+		for _, comparison := range comparisons {
+			if comparison.ReflectFieldName == "Inform" {
+				// skip inform map to avoid double entries in verify table
+				continue
+			}
+			if len(comparison.ReflectMapKey) != 0 && comparison.ReflectFieldName != "OverrideParams" {
+				if comparison.ReflectMapKey != "reminder" {
+					skeys = append(skeys, noteID+"§"+comparison.ReflectMapKey)
+				} else {
+					rkeys = append(rkeys, noteID+"§"+comparison.ReflectMapKey)
+				}
+			}
+		}
+	}
+	sort.Strings(skeys)
+	sort.Strings(rkeys)
+	for _, rem := range rkeys {
+		skeys = append(skeys, rem)
+	}
+	return skeys
+}
+
+// setupTableFormat sets the format of the table columns dependent on the content
+func setupTableFormat(skeys []string, noteField string, noteCompare map[string]map[string]note.FieldComparison, printComp bool) (int, int, int, int, int, string) {
+	var fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4 int
+	format := "\t%s : %s\n"
+	// define start values for the column width
+	if printComp {
+		// verify
+		fmtlen0 = 16
+		fmtlen1 = 12
+		fmtlen2 = 9
+		fmtlen3 = 9
+		fmtlen4 = 7
+	} else {
+		// simulate
+		fmtlen1 = 12
+		fmtlen2 = 10
+		fmtlen3 = 15
+		fmtlen4 = 9
+	}
+
+	for _, skey := range skeys {
+		keyFields := strings.Split(skey, "§")
+		noteID := keyFields[0]
+		comparisons := noteCompare[noteID]
+		for _, comparison := range comparisons {
+			if comparison.ReflectMapKey == "reminder" {
+				continue
+			}
+			// expected values calculated from a formula are shown
+			// together with the formula
+			explen := 0
+			if comparison.ReflectFieldName == "SysctlParams" {
+				_, _, inform := getNoteFieldValues(noteCompare, noteID, comparison.ReflectMapKey)
+				explen = len(expectedValueOf(comparison, inform))
+			}
+			if printComp {
+				// verify
+				if len(noteField) > fmtlen0 {
+					fmtlen0 = len(noteField)
+				}
+				// 3:override, 1:mapkey, 2:expval, 4:actval
+				fmtlen3, fmtlen1, fmtlen2, fmtlen4 = setWidthOfColums(comparison, fmtlen3, fmtlen1, fmtlen2, fmtlen4)
+				if explen > fmtlen2 {
+					fmtlen2 = explen
+				}
+				format = tableFormat(printComp, fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4)
+			} else {
+				// simulate
+				// 4:override, 1:mapkey, 3:expval, 2:actval
+				fmtlen4, fmtlen1, fmtlen3, fmtlen2 = setWidthOfColums(comparison, fmtlen4, fmtlen1, fmtlen3, fmtlen2)
+				if explen > fmtlen3 {
+					fmtlen3 = explen
+				}
+				format = tableFormat(printComp, fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4)
+			}
+		}
+	}
+	return fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, format
+}
+
+// tableFormat returns the format of a row of the verify or simulate table
+// for the given column widths
+func tableFormat(printComp bool, col0, col1, col2, col3, col4 int) string {
+	if printComp {
+		// verify
+		return "   %-" + strconv.Itoa(col0) + "s | %-" + strconv.Itoa(col1) + "s | %-" + strconv.Itoa(col2) + "s | %-" + strconv.Itoa(col3) + "s | %-" + strconv.Itoa(col4) + "s | %2s\n"
+	}
+	// simulate
+	return "   %-" + strconv.Itoa(col1) + "s | %-" + strconv.Itoa(col2) + "s | %-" + strconv.Itoa(col3) + "s | %-" + strconv.Itoa(col4) + "s | %2s\n"
+}
+
+// fitTableWidth reduces the width of the value columns (2, 3 and 4) of the
+// verify or simulate table, until the table fits into 'width'. The widest
+// value column is reduced first, but not below minTableValueWidth.
+// A width of 0 means unlimited
+func fitTableWidth(width int, printComp bool, col0, col1, col2, col3, col4 int) (int, int, int, int, int, string) {
+	if width > 0 {
+		// separators, indentation and the column 'Compliant' or
+		// 'Comment'
+		total := col1 + col2 + col3 + col4 + 22
+		if printComp {
+			total = col0 + col1 + col2 + col3 + col4 + 27
+		}
+		for total > width {
+			widest := &col2
+			if col3 > *widest {
+				widest = &col3
+			}
+			if col4 > *widest {
+				widest = &col4
+			}
+			if *widest <= minTableValueWidth {
+				break
+			}
+			*widest--
+			total--
+		}
+	}
+	return col0, col1, col2, col3, col4, tableFormat(printComp, col0, col1, col2, col3, col4)
+}
+
+// truncateValue shortens a value, which is longer than the width of its
+// column, and marks the truncation with an ellipsis
+func truncateValue(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width || width < 1 {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// printHeadline prints a headline for the table
+func printHeadline(writer io.Writer, header, id string, tuningOpts note.TuningOptions) {
+	if header != "NONE" {
+		nName := ""
+		if len(tuningOptions) > 0 {
+			nName = tuningOptions[id].Name()
+		}
+		fmt.Fprintf(writer, "\n%s - %s \n\n", id, nName)
+	}
+}
+
+// printTableHeader prints the header of the table
+func printTableHeader(writer io.Writer, format string, col0, col1, col2, col3, col4 int, printComp bool) {
+	if printComp {
+		// verify
+		fmt.Fprintf(writer, format, "SAPNote, Version", "Parameter", "Expected", "Override", "Actual", "Compliant")
+		for i := 0; i < col0+col1+col2+col3+col4+28; i++ {
+			if i == 3+col0+1 || i == 3+col0+3+col1+1 || i == 3+col0+3+col1+4+col2 || i == 3+col0+3+col1+4+col2+2+col3+1 || i == 3+col0+3+col1+4+col2+2+col3+3+col4+1 {
+				fmt.Fprintf(writer, "+")
+			} else {
+				fmt.Fprintf(writer, "-")
+			}
+		}
+		fmt.Fprintf(writer, "\n")
+	} else {
+		// simulate
+		fmt.Fprintf(writer, format, "Parameter", "Value set", "Value expected", "Override", "Comment")
+		for i := 0; i < col1+col2+col3+col4+28; i++ {
+			if i == 3+col1+1 || i == 3+col1+3+col2+1 || i == 3+col1+3+col2+3+col3+1 || i == 3+col1+3+col2+3+col3+3+col4+1 {
+				fmt.Fprintf(writer, "+")
+			} else {
+				fmt.Fprintf(writer, "-")
+			}
+		}
+		fmt.Fprintf(writer, "\n")
+	}
+}
+
+// parameterFootnotes returns the numbers of the footnotes, which apply to
+// the parameter of the comparison
+func parameterFootnotes(comparison note.FieldComparison, inform string) []int {
+	footnotes := []int{}
+	switch comparison.ActualValue {
+	case "all:none":
+		footnotes = append(footnotes, 1)
+	case "NA":
+		footnotes = append(footnotes, 2)
+	}
+	switch {
+	case comparison.GrubState == note.GrubStateReboot:
+		footnotes = append(footnotes, 7)
+	case comparison.GrubState == note.GrubStateMissing:
+		footnotes = append(footnotes, 8)
+	case strings.Contains(comparison.ReflectMapKey, "rpm") || strings.Contains(comparison.ReflectMapKey, "grub"):
+		footnotes = append(footnotes, 3)
+	}
+
+	// check inform map for special settings
+	// ANGI: future - check for 'nil', if using noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ActualValue.(string) in general
+	if comparison.ReflectMapKey == "force_latency" && inform == "hasDiffs" {
+		footnotes = append(footnotes, 4)
+	}
+	var isSched = regexp.MustCompile(`^IO_SCHEDULER_\w+$`)
+	if isSched.MatchString(comparison.ReflectMapKey) && inform == "NA" {
+		footnotes = append(footnotes, 5)
+	}
+	if comparison.NotApplicable != "" {
+		// the deviation is only informational, the parameter can
+		// not be set in the virtualization environment, but stays
+		// non-compliant
+		footnotes = append(footnotes, 6)
+	}
+	return footnotes
+}
+
+// footnoteText returns the text of the footnote with the given number as
+// printed below the table
+func footnoteText(footnote int, comparison note.FieldComparison) string {
+	switch footnote {
+	case 1:
+		return footnote1
+	case 2:
+		return footnote2
+	case 3:
+		return footnote3
+	case 4:
+		if detail := cpuIdleStateDetail(comparison.ExpectedValueJS); len(detail) != 0 {
+			return footnote4 + ":\n      " + strings.Join(detail, "\n      ")
+		}
+		return footnote4
+	case 5:
+		if detail := schedulerDetail(comparison); detail != "" {
+			return footnote5 + ":\n      " + detail
+		}
+		return footnote5
+	case 6:
+		return fmt.Sprintf(footnote6, comparison.NotApplicable)
+	case 7:
+		return footnote7
+	case 8:
+		return footnote8
+	}
+	return ""
+}
+
+// cpuIdleStateDetail returns a line for every cpu idle state, whose
+// setting differs from the expected force latency value, e.g.
+// 'cpu3: state2 (C6, latency 133) is enabled, expected disabled'
+func cpuIdleStateDetail(forceLatency string) []string {
+	detail := []string{}
+	if forceLatency == "" {
+		return detail
+	}
+	for _, state := range system.CPUIdleStateDiffs(cpuIdleStates(), forceLatency) {
+		actual, expected := "enabled", "disabled"
+		if state.Disabled {
+			actual, expected = expected, actual
+		}
+		name := ""
+		if state.Name != "" {
+			name = state.Name + ", "
+		}
+		detail = append(detail, fmt.Sprintf("%s: %s (%slatency %d) is %s, expected %s", state.CPU, state.State, name, state.Latency, actual, expected))
+	}
+	return detail
+}
+
+// schedulerDetail returns the expected schedulers of an IO_SCHEDULER
+// parameter together with the schedulers supported by the block device and
+// the scheduler currently used, e.g.
+// sda: expected 'noop, none', available 'mq-deadline kyber bfq', current 'bfq'
+func schedulerDetail(comparison note.FieldComparison) string {
+	bdev := strings.TrimPrefix(comparison.ReflectMapKey, "IO_SCHEDULER_")
+	if bdev == "" || bdev == comparison.ReflectMapKey {
+		return ""
+	}
+	available, current, err := blockDeviceSchedulers(bdev)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: expected '%s', available '%s', current '%s'", bdev, comparison.ExpectedValueJS, strings.Join(available, " "), current)
+}
+
+// footnoteMeaning returns the canonical meaning of the footnote with the
+// given number without the footnote mark and without details of the
+// parameter
+func footnoteMeaning(footnote int) string {
+	text := footnoteText(footnote, note.FieldComparison{})
+	if footnote == 6 {
+		text = strings.TrimSuffix(footnote6, " (%s)")
+	}
+	return strings.TrimPrefix(text, fmt.Sprintf("[%d] ", footnote))
+}
+
+// prepareFootnote prepares the content of the last column and the
+// corresponding footnotes
+func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform string, footnote []string) (string, string, []string) {
+	for _, fn := range parameterFootnotes(comparison, inform) {
+		mark := fmt.Sprintf("[%d]", fn)
+		switch fn {
+		case 4, 6:
+			compliant = "no " + mark
+		default:
+			compliant = compliant + " " + mark
+		}
+		comment = comment + " " + mark
+		text := footnoteText(fn, comparison)
+		if fn == 5 && strings.HasPrefix(footnote[fn-1], footnote5+":") && strings.HasPrefix(text, footnote5+":") {
+			// list the details of every block device
+			text = footnote[fn-1] + strings.TrimPrefix(text, footnote5+":")
+		}
+		footnote[fn-1] = text
+	}
+	return compliant, comment, footnote
+}
+
+// footnoteParameter describes a parameter in the json output of the
+// footnotes
+type footnoteParameter struct {
+	Note      string `json:"note"`
+	Parameter string `json:"parameter"`
+	Detail    string `json:"detail,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+}
+
+// footnoteEntry describes a footnote in the json output of the footnotes
+type footnoteEntry struct {
+	Footnote   int                 `json:"footnote"`
+	Meaning    string              `json:"meaning"`
+	Parameters []footnoteParameter `json:"parameters"`
+}
+
+// printFootnotesFormat prints the footnotes of the verify or simulate table
+// in the format requested by the command line option '--footnotes' instead
+// of the table. Returns false, if no format was requested
+func printFootnotesFormat(writer io.Writer, comparisons map[string]map[string]note.FieldComparison) (bool, error) {
+	switch footnotesFormat {
+	case "":
+		return false, nil
+	case "json":
+		return true, PrintFootnotesJSON(writer, comparisons)
+	}
+	return false, newExitError("Unsupported format '%s' for the footnotes. Supported format is: json", footnotesFormat)
+}
+
+// PrintFootnotesJSON prints the footnotes, which apply to the parameters of
+// the verify or simulate table, in json format. For every footnote the
+// canonical meaning and the parameters triggering the footnote are listed
+func PrintFootnotesJSON(writer io.Writer, comparisons map[string]map[string]note.FieldComparison) error {
+	params := make(map[int][]footnoteParameter)
+	for _, skey := range sortNoteComparisonsOutput(comparisons) {
+		keyFields := strings.Split(skey, "§")
+		comparison, _, inform := getNoteFieldValues(comparisons, keyFields[0], keyFields[1])
+		if comparison.ReflectMapKey == "reminder" {
+			continue
+		}
+		for _, fn := range parameterFootnotes(comparison, inform) {
+			param := footnoteParameter{Note: keyFields[0], Parameter: comparison.ReflectMapKey}
+			if !comparison.MatchExpectation {
+				param.Severity = paramSeverity(comparison)
+			}
+			switch fn {
+			case 4:
+				param.Detail = strings.Join(cpuIdleStateDetail(comparison.ExpectedValueJS), "; ")
+			case 5:
+				param.Detail = schedulerDetail(comparison)
+			case 6:
+				param.Detail = comparison.NotApplicable
+			}
+			params[fn] = append(params[fn], param)
+		}
+	}
+	footnotes := make([]footnoteEntry, 0, len(params))
+	for fn := 1; fn <= 8; fn++ {
+		if len(params[fn]) != 0 {
+			footnotes = append(footnotes, footnoteEntry{Footnote: fn, Meaning: footnoteMeaning(fn), Parameters: params[fn]})
+		}
+	}
+	content, err := json.MarshalIndent(footnotes, "", "  ")
+	if err != nil {
+		return newExitError("Failed to create the json output: %v", err)
+	}
+	fmt.Fprintf(writer, "%s\n", string(content))
+	return nil
+}
+
+// printTableFooter prints the footer of the table
+// footnotes and reminder section
+func printTableFooter(writer io.Writer, header string, footnote []string, reminder map[string]string, hasDiff bool) {
+	if header != "NONE" && !hasDiff {
+		fmt.Fprintf(writer, "\n   (no change)\n")
+	}
+	for _, fn := range footnote {
+		if fn != "" {
+			fmt.Fprintf(writer, "\n %s", fn)
+		}
+	}
+	fmt.Fprintf(writer, "\n\n")
+	for noteID, reminde := range reminder {
+		if reminde != "" {
+			reminderHead := fmt.Sprintf("Attention for SAP Note %s:\nHints or values not yet handled by saptune. So please read carefully, check and set manually, if needed:\n", noteID)
+			fmt.Fprintf(writer, "%s\n", colorize(reminderHead+reminde, setRedText))
+		}
+	}
+}
+
+// setWidthOfColums sets the width of the columns for verify and simulate
+// depending on the highest number of characters of the content to be
+// displayed
+// c1:override, c2:mapkey, c3:expval, c4:actval
+func setWidthOfColums(compare note.FieldComparison, c1, c2, c3, c4 int) (int, int, int, int) {
+	if len(compare.ReflectMapKey) != 0 {
+		if compare.ReflectFieldName == "OverrideParams" && len(compare.ActualValueJS) > c1 {
+			c1 = len(compare.ActualValueJS)
+			return c1, c2, c3, c4
+		}
+		if len(compare.ReflectMapKey) > c2 {
+			c2 = len(compare.ReflectMapKey)
+		}
+		if len(compare.ExpectedValueJS) > c3 {
+			c3 = len(compare.ExpectedValueJS)
+		}
+		if len(compare.ActualValueJS) > c4 {
+			c4 = len(compare.ActualValueJS)
+		}
+	}
+	return c1, c2, c3, c4
+}
+
+// VerifyAllParameters Verify that all system parameters do not deviate from any of the enabled solutions/notes.
+func VerifyAllParameters(writer io.Writer, tuneApp *app.App) error {
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		if done, err := printVerifyFormat(writer, map[string]map[string]note.FieldComparison{}, []string{}); done || err != nil {
+			return err
+		}
+		fmt.Fprintln(writer, "No notes or solutions enabled, nothing to verify.")
+		return nil
+	}
+	unsatisfiedNotes, comparisons, err := tuneApp.VerifyAll()
+	if err != nil {
+		return newExitError("Failed to inspect the current system: %v", err)
+	}
+	ignore, err := verifyIgnoreList()
+	if err != nil {
+		return err
+	}
+	unsatisfiedNotes = ignoreDeviations(comparisons, unsatisfiedNotes, ignore)
+	if done, err := verifySinceLast(writer, comparisons, tuneApp); done || err != nil {
+		return err
+	}
+	if verifyParamPrefix != "" {
+		if comparisons, unsatisfiedNotes, err = filterPrefixComparisons(comparisons, unsatisfiedNotes, verifyParamPrefix); err != nil {
+			return err
+		}
+	}
+	if done, err := printVerifyFormat(writer, comparisons, unsatisfiedNotes); done || err != nil {
+		return err
+	}
+	PrintNoteFields(writer, "NONE", comparisons, true)
+	printNoteAnnotations(writer, paramNoteOrder(comparisons, tuneApp.NoteApplyOrder), tuneApp)
+	insecure := verifyParanoidFiles(writer, comparisons, tuneApp)
+	tuneApp.PrintNoteApplyOrder(writer)
+	fmt.Fprintln(writer, computeComplianceScore(comparisons))
+	if len(unsatisfiedNotes) == 0 && verifyParamPrefix != "" {
+		fmt.Fprintf(writer, "The parameters matching '%s' conform to all of the enabled notes.\n", verifyParamPrefix)
+	} else if len(unsatisfiedNotes) == 0 {
+		fmt.Fprintln(writer, "The running system is currently well-tuned according to all of the enabled notes.")
+	} else if err := deviationError(comparisons, "The parameters listed above have deviated from SAP/SUSE recommendations."); err != nil {
+		return err
+	}
+	return insecureFilesError(insecure)
+}
+
+// VerifyParameter verifies a single parameter against all enabled notes,
+// which tune the parameter. Different values expected by the notes are
+// reported as conflict
+func VerifyParameter(writer io.Writer, param string, tuneApp *app.App) error {
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		fmt.Fprintln(writer, "No notes or solutions enabled, nothing to verify.")
+		return nil
+	}
+	unsatisfiedNotes, comparisons, err := tuneApp.VerifyAll()
+	if err != nil {
+		return newExitError("Failed to inspect the current system: %v", err)
+	}
+	ignore, err := verifyIgnoreList()
+	if err != nil {
+		return err
+	}
+	ignoreDeviations(comparisons, unsatisfiedNotes, ignore)
+	paramComparisons := filterParamComparisons(comparisons, param)
+	if len(paramComparisons) == 0 {
+		return newExitError("Parameter '%s' is not tuned by any of the enabled notes.", param)
+	}
+	noteIDs := paramNoteOrder(paramComparisons, tuneApp.NoteApplyOrder)
+	unsatisfiedNotes = make([]string, 0)
+	for _, noteID := range noteIDs {
+		if !paramComparisons[noteID][fmt.Sprintf("%s[%s]", "SysctlParams", param)].MatchExpectation {
+			unsatisfiedNotes = append(unsatisfiedNotes, noteID)
+		}
+	}
+	if done, err := printVerifyFormat(writer, paramComparisons, unsatisfiedNotes); done || err != nil {
+		return err
+	}
+	PrintNoteFields(writer, "NONE", paramComparisons, true)
+	printParamConflict(writer, param, paramComparisons, noteIDs, tuneApp.NoteApplyOrder)
+	if len(unsatisfiedNotes) == 0 {
+		fmt.Fprintf(writer, "The value of parameter '%s' conforms to all of the enabled notes.\n", param)
+		return nil
+	}
+	return deviationError(paramComparisons, "The value of parameter '%s' deviates from the notes %s.", param, strings.Join(unsatisfiedNotes, " "))
+}
+
+// filterParamComparisons returns the comparisons of a single parameter of
+// all notes. Notes, which do not tune the parameter, are left out. The
+// comparisons without map key like the Note definition file are kept
+func filterParamComparisons(comparisons map[string]map[string]note.FieldComparison, param string) map[string]map[string]note.FieldComparison {
+	filtered := make(map[string]map[string]note.FieldComparison)
+	for noteID, noteComparisons := range comparisons {
+		if _, ok := noteComparisons[fmt.Sprintf("%s[%s]", "SysctlParams", param)]; !ok {
+			continue
+		}
+		filtered[noteID] = make(map[string]note.FieldComparison)
+		for key, comparison := range noteComparisons {
+			if comparison.ReflectMapKey == param || comparison.ReflectMapKey == "" {
+				filtered[noteID][key] = comparison
+			}
+		}
+	}
+	return filtered
+}
+
+// verifyParanoidFiles checks with the option '--paranoid' the ownership and
+// the permissions of the files of the verified notes and prints the
+// problems found. It returns true, if problems were found
+func verifyParanoidFiles(writer io.Writer, comparisons map[string]map[string]note.FieldComparison, tuneApp *app.App) bool {
+	if !verifyParanoid {
+		return false
+	}
+	problems := paranoidFileProblems(comparisons, tuneApp)
+	if len(problems) == 0 {
+		fmt.Fprintf(writer, "\nThe Note definition, override and state files of the verified notes are owned by root and not writable by other users.\n")
+		return false
+	}
+	fmt.Fprintf(writer, "\nFiles with insecure ownership or permissions:\n")
+	for _, problem := range problems {
+		fmt.Fprintf(writer, "   %s\n", problem)
+	}
+	return true
+}
+
+// insecureFilesError returns an error with the exit code of a not compliant
+// system, if the option '--paranoid' found files with insecure ownership or
+// permissions
+func insecureFilesError(insecure bool) error {
+	if insecure {
+		return &ExitError{Code: exitNotCompliant, Message: "The files listed above have insecure ownership or permissions, they could be used to change the tuning."}
+	}
+	return nil
+}
+
+// paranoidFileProblems returns the problems found in the ownership and the
+// permissions of the Note definition files, the included Note definition
+// files, the override files and the saved state files of the notes and of
+// the directories containing these files, sorted by file name
+func paranoidFileProblems(comparisons map[string]map[string]note.FieldComparison, tuneApp *app.App) []string {
+	files := make(map[string]bool)
+	addFile := func(fileName string) {
+		if fileName != "" {
+			files[fileName] = true
+			files[path.Dir(fileName)] = true
+		}
+	}
+	for noteID, noteComparisons := range comparisons {
+		confFile, _ := noteComparisons["ConfFilePath"].ActualValue.(string)
+		addFile(confFile)
+		includeFiles, _ := noteComparisons["IncludeFiles"].ActualValue.([]string)
+		for _, includeFile := range includeFiles {
+			addFile(includeFile)
+		}
+		for _, overrideFile := range note.OverrideFiles(noteID) {
+			addFile(overrideFile)
+		}
+		addFile(tuneApp.State.GetPathToNote(noteID))
+	}
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	problems := make([]string, 0)
+	for _, fileName := range fileNames {
+		fileProblems, err := system.FilePermissionProblems(fileName)
+		if os.IsNotExist(err) {
+			// e.g. a note without override file
+			continue
+		} else if err != nil {
+			fileProblems = []string{err.Error()}
+		}
+		for _, problem := range fileProblems {
+			problems = append(problems, fmt.Sprintf("%s: %s", fileName, problem))
+		}
+	}
+	return problems
+}
+
+// printNoteAnnotations prints the annotations recorded, when the notes were
+// applied
+func printNoteAnnotations(writer io.Writer, noteIDs []string, tuneApp *app.App) {
+	printHead := true
+	for _, noteID := range noteIDs {
+		annotation, ok := tuneApp.NoteAnnotation(noteID)
+		if !ok {
+			continue
+		}
+		if printHead {
+			fmt.Fprintf(writer, "\nAnnotations of the applied notes:\n")
+			printHead = false
+		}
+		fmt.Fprintf(writer, "   %s: %s\n", noteID, annotation)
+	}
+}
+
+// filterPrefixComparisons returns the comparisons of the parameters, whose
+// names match the prefix or glob pattern, and those of the unsatisfied
+// notes, which do not conform to the matching parameters. Notes without
+// matching parameter are left out. The comparisons without map key like
+// the Note definition file are kept
+func filterPrefixComparisons(comparisons map[string]map[string]note.FieldComparison, unsatisfiedNotes []string, prefix string) (map[string]map[string]note.FieldComparison, []string, error) {
+	if _, err := path.Match(prefix, ""); err != nil {
+		return nil, nil, newExitError("Wrong value '%s' for option '--param-prefix': %v", prefix, err)
+	}
+	filtered := make(map[string]map[string]note.FieldComparison)
+	deviating := make(map[string]bool)
+	for noteID, noteComparisons := range comparisons {
+		matching := make(map[string]note.FieldComparison)
+		found := false
+		conforming := true
+		for key, comparison := range noteComparisons {
+			if comparison.ReflectMapKey == "" {
+				matching[key] = comparison
+				continue
+			}
+			if comparison.ReflectMapKey == "reminder" || !matchParamPrefix(comparison.ReflectMapKey, prefix) {
+				continue
+			}
+			matching[key] = comparison
+			if comparison.ReflectFieldName == "SysctlParams" {
+				found = true
+				if !comparison.MatchExpectation {
+					conforming = false
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+		filtered[noteID] = matching
+		deviating[noteID] = !conforming
+	}
+	if len(filtered) == 0 {
+		return nil, nil, newExitError("No parameter matching '%s' is tuned by the verified notes.", prefix)
+	}
+	unsatisfied := make([]string, 0, len(unsatisfiedNotes))
+	for _, noteID := range unsatisfiedNotes {
+		if deviating[noteID] {
+			unsatisfied = append(unsatisfied, noteID)
+		}
+	}
+	return filtered, unsatisfied, nil
+}
+
+// matchParamPrefix returns true, if the parameter name starts with the
+// prefix or, if the prefix contains wildcards, matches the glob pattern
+func matchParamPrefix(param, prefix string) bool {
+	if strings.ContainsAny(prefix, "*?[") {
+		matched, _ := path.Match(prefix, param)
+		return matched
+	}
+	return strings.HasPrefix(param, prefix)
+}
+
+// paramNoteOrder returns the IDs of the notes in the order they are
+// applied. Notes not found in the apply order follow sorted by ID
+func paramNoteOrder(comparisons map[string]map[string]note.FieldComparison, applyOrder []string) []string {
+	noteIDs := make([]string, 0, len(comparisons))
+	for _, noteID := range applyOrder {
+		if _, ok := comparisons[noteID]; ok {
+			noteIDs = append(noteIDs, noteID)
+		}
+	}
+	others := make([]string, 0)
+	for noteID := range comparisons {
+		found := false
+		for _, id := range noteIDs {
+			if id == noteID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			others = append(others, noteID)
+		}
+	}
+	sort.Strings(others)
+	return append(noteIDs, others...)
+}
+
+// printParamConflict prints the values of the parameter expected by the
+// notes, if the notes do not agree about the value. The value of the note
+// applied last is the one set on the system. Only the notes of the apply
+// order are applied, so the other notes never win
+func printParamConflict(writer io.Writer, param string, comparisons map[string]map[string]note.FieldComparison, noteIDs, applyOrder []string) {
+	values := make(map[string]bool)
+	conflict := ""
+	for _, noteID := range noteIDs {
+		comparison, _, inform := getNoteFieldValues(comparisons, noteID, param)
+		expected := expectedValueOf(comparison, inform)
+		values[expected] = true
+		conflict = conflict + fmt.Sprintf("   %s: %s\n", noteID, expected)
+	}
+	if len(values) < 2 {
+		return
+	}
+	conflict = fmt.Sprintf("The enabled notes expect different values for parameter '%s':\n", param) + conflict
+	winner := ""
+	for _, noteID := range applyOrder {
+		if _, ok := comparisons[noteID]; ok {
+			winner = noteID
+		}
+	}
+	if winner != "" {
+		conflict = conflict + fmt.Sprintf("The value of note %s, which is applied last, is set.", winner)
+	} else {
+		conflict = conflict + "None of these notes is applied, so none of the values is set."
+	}
+	fmt.Fprintf(writer, "%s\n\n", colorize(conflict, setRedText))
+}
+
+// verifySinceLast saves the compliance of the verified parameters as the
+// result of the last verify. With the command line option '--since last'
+// only the parameters, whose compliance changed since the previous verify,
+// are printed and true is returned. Parameters not verified before are
+// printed, if they are not compliant.
+func verifySinceLast(writer io.Writer, comparisons map[string]map[string]note.FieldComparison, tuneApp *app.App) (bool, error) {
+	if verifySince != "" && !strings.EqualFold(verifySince, "last") {
+		return false, newExitError("Unsupported value '%s' of option '--since'. Only 'last' is supported.", verifySince)
+	}
+	if verifySince != "" && outputFormat != "" {
+		return false, newExitError("The option '--since' can not be used together with the option '--format'.")
+	}
+	if verifySince != "" && footnotesFormat != "" {
+		return false, newExitError("The option '--since' can not be used together with the option '--footnotes'.")
+	}
+	last, err := tuneApp.ReadLastVerify()
+	if err != nil {
+		system.WarningLog("failed to read the result of the last verify from '%s' - %v", tuneApp.GetPathToLastVerify(), err)
+	}
+	// only a verify printing the table is the base of a later
+	// '--since last'. The machine readable formats are used by
+	// monitoring tools polling periodically, which would hide the
+	// changes from the user
+	if outputFormat == "" && footnotesFormat == "" {
+		if err := tuneApp.SaveLastVerify(comparisons); err != nil {
+			system.WarningLog("failed to save the result of the verify to '%s' - %v", tuneApp.GetPathToLastVerify(), err)
+		}
+	}
+	if verifySince == "" {
+		return false, nil
+	}
+	changes := app.ComplianceChanges(last, comparisons)
+	since := "the last verify at " + last.Created
+	if last.Created == "" {
+		since = "the last verify, no previous result found"
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(writer, "\nNo parameter changed its compliance since %s.\n", since)
+		return true, nil
+	}
+	fmt.Fprintf(writer, "\nParameters, whose compliance changed since %s:\n", since)
+	PrintNoteFields(writer, "NONE", changes, true)
+	if deviationSeverity(changes) != "" {
+		return true, deviationError(changes, "The parameters listed above have newly deviated from SAP/SUSE recommendations.")
+	}
+	fmt.Fprintln(writer, "All parameters listed above comply again.")
+	return true, nil
+}
+
+// printVerifyFormat prints the verify result in the machine readable format
+// requested by the command line option '--format'.
+// Returns false, if the default table output is requested.
+func printVerifyFormat(writer io.Writer, comparisons map[string]map[string]note.FieldComparison, unsatisfiedNotes []string) (bool, error) {
+	if footnotesFormat != "" && outputFormat != "" {
+		return false, newExitError("The option '--footnotes' can not be used together with the option '--format'.")
+	}
+	if done, err := printFootnotesFormat(writer, comparisons); err != nil {
+		return done, err
+	} else if done {
+		return true, formatDeviationError(comparisons, unsatisfiedNotes)
+	}
+	switch outputFormat {
+	case "":
+		return false, nil
+	case "prometheus":
+		PrintPrometheusMetrics(writer, comparisons, unsatisfiedNotes)
+	case "csv":
+		if err := PrintCSVVerifyResult(writer, comparisons); err != nil {
+			return true, err
+		}
+	case "nagios":
+		result, state := nagiosVerifyResult(comparisons, unsatisfiedNotes)
+		fmt.Fprintln(writer, result)
+		if state != nagiosOK {
+			// the state line is already printed
+			return true, &ExitError{Code: state}
+		}
+	default:
+		return false, newExitError("Unsupported output format '%s' for verify. Supported formats are: prometheus, csv, nagios", outputFormat)
+	}
+	return true, formatDeviationError(comparisons, unsatisfiedNotes)
+}
+
+// formatDeviationError returns the error of a deviating system like
+// deviationError, but without message, as the deviations are already part
+// of the output of the options '--format' and '--footnotes'
+func formatDeviationError(comparisons map[string]map[string]note.FieldComparison, unsatisfiedNotes []string) error {
+	if len(unsatisfiedNotes) == 0 {
+		return nil
+	}
+	if deviationSeverity(comparisons) == txtparser.SeverityInfo {
+		return nil
+	}
+	return &ExitError{Code: exitNotCompliant}
+}
+
+// nagiosVerifyResult returns the verify result as single status line with
+// the number of deviating parameters and notes as performance data and the
+// related state following the Nagios plugin convention
+func nagiosVerifyResult(comparisons map[string]map[string]note.FieldComparison, unsatisfiedNotes []string) (string, int) {
+	if len(comparisons) == 0 {
+		return "SAPTUNE WARNING - no notes or solutions enabled, nothing to verify | deviating_parameters=0 deviating_notes=0", nagiosWarning
+	}
+	deviations := 0
+	for _, skey := range sortNoteComparisonsOutput(comparisons) {
+		keyFields := strings.Split(skey, "§")
+		comparison, _, inform := getNoteFieldValues(comparisons, keyFields[0], keyFields[1])
+		if comparison.ReflectMapKey == "reminder" {
+			continue
+		}
+		if !comparison.MatchExpectation || (comparison.ReflectMapKey == "force_latency" && inform == "hasDiffs") {
+			deviations++
+		}
+	}
+	perfData := fmt.Sprintf("deviating_parameters=%d deviating_notes=%d", deviations, len(unsatisfiedNotes))
+	if deviations == 0 && len(unsatisfiedNotes) == 0 {
+		return fmt.Sprintf("SAPTUNE OK - the system conforms to %d notes | %s", len(comparisons), perfData), nagiosOK
+	}
+	notes := append([]string{}, unsatisfiedNotes...)
+	sort.Strings(notes)
+	switch deviationSeverity(comparisons) {
+	case txtparser.SeverityInfo:
+		return fmt.Sprintf("SAPTUNE OK - %d parameters of severity info deviate from the notes %s | %s", deviations, strings.Join(notes, " "), perfData), nagiosOK
+	case txtparser.SeverityWarning:
+		return fmt.Sprintf("SAPTUNE WARNING - %d parameters deviate from the notes %s | %s", deviations, strings.Join(notes, " "), perfData), nagiosWarning
+	}
+	return fmt.Sprintf("SAPTUNE CRITICAL - %d parameters deviate from the notes %s | %s", deviations, strings.Join(notes, " "), perfData), nagiosCritical
+}
+
+// escapePrometheusLabel escapes backslash, double quote and newline
+// characters in a label value of the Prometheus text format
+func escapePrometheusLabel(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return strings.Replace(value, "\n", `\n`, -1)
+}
+
+// PrintPrometheusMetrics prints the verify result as gauge metrics in the
+// Prometheus text format, suitable for the node_exporter textfile collector
+func PrintPrometheusMetrics(writer io.Writer, comparisons map[string]map[string]note.FieldComparison, unsatisfiedNotes []string) {
+	fmt.Fprintf(writer, "# HELP saptune_note_compliant Compliance of a parameter with the SAP Note (1 = compliant, 0 = deviating).\n")
+	fmt.Fprintf(writer, "# TYPE saptune_note_compliant gauge\n")
+	for _, skey := range sortNoteComparisonsOutput(comparisons) {
+		keyFields := strings.Split(skey, "§")
+		noteID := keyFields[0]
+		if keyFields[1] == "reminder" {
+			continue
+		}
+		comparison := comparisons[noteID][fmt.Sprintf("%s[%s]", "SysctlParams", keyFields[1])]
+		compliant := 0
+		if comparison.MatchExpectation {
+			compliant = 1
+		}
+		fmt.Fprintf(writer, "saptune_note_compliant{note=\"%s\",parameter=\"%s\"} %d\n", escapePrometheusLabel(noteID), escapePrometheusLabel(comparison.ReflectMapKey), compliant)
+	}
+	fmt.Fprintf(writer, "# HELP saptune_notes_deviating Number of SAP Notes the system deviates from.\n")
+	fmt.Fprintf(writer, "# TYPE saptune_notes_deviating gauge\n")
+	fmt.Fprintf(writer, "saptune_notes_deviating %d\n", len(unsatisfiedNotes))
+}
+
+// PrintCSVVerifyResult prints the verify result as comma separated values,
+// one line per parameter. Tabs inside the values are replaced by blanks as
+// in the verify table, values containing commas or quotes are quoted
+func PrintCSVVerifyResult(writer io.Writer, comparisons map[string]map[string]note.FieldComparison) error {
+	csvWriter := csv.NewWriter(writer)
+	_ = csvWriter.Write([]string{"NoteID", "Version", "Parameter", "Expected", "Override", "Actual", "Compliant"})
+	for _, skey := range sortNoteComparisonsOutput(comparisons) {
+		keyFields := strings.Split(skey, "§")
+		noteID := keyFields[0]
+		comparison, override, inform := getNoteFieldValues(comparisons, noteID, keyFields[1])
+		if comparison.ReflectMapKey == "reminder" {
+			continue
+		}
+		compliant := "yes"
+		if !comparison.MatchExpectation || (comparison.ReflectMapKey == "force_latency" && inform == "hasDiffs") {
+			compliant = "no"
+		}
+		version := txtparser.GetINIFileVersionSectionEntry(comparisons[noteID]["ConfFilePath"].ActualValue.(string), "version")
+		_ = csvWriter.Write([]string{noteID, version, comparison.ReflectMapKey, expectedValueOf(comparison, inform), override, strings.Replace(comparison.ActualValueJS, "\t", " ", -1), compliant})
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return newExitError("Failed to write the verify result - %v", err)
+	}
+	return nil
+}
+
+// NoteActionVerifyBaseline compares the system against the expected values
+// of a baseline file instead of the expected values of the Note definitions
+func NoteActionVerifyBaseline(writer io.Writer, fileName string, tuneApp *app.App) error {
+	conforming, comparisons, err := tuneApp.VerifyBaseline(fileName)
+	if err != nil {
+		return newExitError("Failed to test the current system against the baseline file: %v", err)
+	}
+	noteComp := map[string]map[string]note.FieldComparison{app.BaselineID: comparisons}
+	unsatisfiedNotes := []string{}
+	if !conforming {
+		unsatisfiedNotes = append(unsatisfiedNotes, app.BaselineID)
+	}
+	if done, err := printVerifyFormat(writer, noteComp, unsatisfiedNotes); done || err != nil {
+		return err
+	}
+	PrintNoteFields(writer, "NONE", noteComp, true)
+	if !conforming {
+		return deviationError(noteComp, "The parameters listed above have deviated from the baseline file '%s'.\n", fileName)
+	}
+	fmt.Fprintf(writer, "The system fully conforms to the baseline file '%s'.\n", fileName)
+	return nil
+}
+
+// NoteAction  Note actions like apply, revert, verify asm.
+func NoteAction(actionName, noteID string) {
+	switch actionName {
+	case "apply":
+		if noteID == "-" || cliFlag("stdin") {
+			exitOnError(NoteActionApplyStdin(os.Stdin, os.Stdout, cliFlag("persist"), tuneApp))
+			return
+		}
+		if cliFlag("refresh") {
+			exitOnError(NoteActionApplyRefresh(os.Stdout, noteID, tuneApp))
+			return
+		}
+		annotation, err := noteApplyAnnotation()
+		exitOnError(err)
+		if cliFlag("only") {
+			exitOnError(NoteActionApplyOnly(os.Stdout, noteID, cliNoteIDs("only"), annotation, tuneApp))
+			return
+		}
+		if cliFlag("simulate-first") {
+			apply, err := NoteActionSimulateFirst(os.Stdin, os.Stdout, noteID, cliFlag("yes"), stdinIsTerminal(), tuneApp)
+			exitOnError(err)
+			if !apply {
+				return
+			}
+		}
+		ttl, err := noteApplyTTL()
+		exitOnError(err)
+		exitOnError(NoteActionApply(os.Stdout, noteID, cliFlag("with-requirements"), ttl, annotation, tuneApp))
+	case "apply-url":
+		exitOnError(NoteActionApplyURL(os.Stdout, noteID, tuneApp))
+	case "list":
+		filter, err := noteListFilter()
+		exitOnError(err)
+		if cliFlag("json") {
+			exitOnError(NoteActionListJSON(os.Stdout, tuneApp, tuningOptions, filter))
+		} else {
+			NoteActionList(os.Stdout, tuneApp, tuningOptions, cliFlag("verbose"), filter)
+		}
+	case "search":
+		exitOnError(NoteActionSearch(os.Stdout, noteID, tuningOptions))
+	case "verify":
+		interval, watch, err := verifyWatchInterval()
+		exitOnError(err)
+		if watch {
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+			exitOnError(NoteActionVerifyWatch(os.Stdout, noteID, interval, stdoutIsTerminal(), stop, tuneApp))
+			return
+		}
+		exitOnError(reportAction(func(writer io.Writer) error {
+			return NoteActionVerify(writer, noteID, tuneApp)
+		}))
+	case "simulate":
+		exitOnError(reportAction(func(writer io.Writer) error {
+			if cliFlag("all") {
+				return NoteActionSimulateAll(writer, tuneApp)
+			}
+			return NoteActionSimulate(writer, noteID, tuneApp)
+		}))
+	case "customise":
+		if cliFlag("set") {
+			exitOnError(NoteActionCustomiseSet(os.Stdout, noteID, cliFlagValues("set"), tuneApp))
+		} else if cliFlag("wizard") {
+			exitOnError(NoteActionCustomiseWizard(os.Stdin, os.Stdout, noteID, tuneApp))
+		} else {
+			exitOnError(NoteActionCustomise(noteID))
+		}
+	case "create":
+		exitOnError(NoteActionCreate(noteID))
+	case "show":
+		exitOnError(NoteActionShow(os.Stdout, noteID, cliFlag("raw"), tuneApp))
+	case "revert":
+		if cliFlag("to-default") {
+			if cliArg(4) != "" {
+				exitOnError(newExitError("The option '--to-default' can not be used together with a parameter name."))
+			}
+			exitOnError(NoteActionRevertToDefault(os.Stdout, noteID, tuneApp))
+		} else if paramName := cliArg(4); paramName != "" {
+			exitOnError(NoteActionRevertParameter(os.Stdout, noteID, paramName, tuneApp))
+		} else {
+			exitOnError(NoteActionRevert(os.Stdout, noteID, tuneApp))
+		}
+	case "diff":
+		exitOnError(NoteActionDiff(os.Stdout, noteID, cliArg(4), tuneApp))
+	case "validate":
+		exitOnError(NoteActionValidate(os.Stdout, noteID, tuneApp))
+	case "export":
+		exitOnError(NoteActionExport(os.Stdout, noteID, cliArg(4), tuneApp))
+	case "conflicts":
+		exitOnError(NoteActionConflicts(os.Stdout, tuneApp))
+	case "move":
+		exitOnError(NoteActionMove(os.Stdout, noteID, cliArg(4), cliArg(5), tuneApp))
+	case "rename":
+		exitOnError(NoteActionRename(os.Stdout, noteID, cliArg(4), tuneApp))
+	case "delete":
+		exitOnError(NoteActionDelete(os.Stdin, os.Stdout, noteID, cliFlag("yes"), stdinIsTerminal(), tuneApp))
+	case "enable":
+		exitOnError(NoteActionEnable(os.Stdout, noteID, tuneApp))
+	case "disable":
+		exitOnError(NoteActionDisable(os.Stdout, noteID, tuneApp))
+	default:
+		PrintHelpAndExit(1)
+	}
+}
+
+// noteApplyTTL returns the time to live of the option '--ttl DURATION' or 0,
+// if the option is not specified
+func noteApplyTTL() (time.Duration, error) {
+	value := cliFlagValue("ttl")
+	if !cliFlag("ttl") {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, newExitError("Wrong value '%s' for option '--ttl', expected a duration like '30m' or '2h'.", value)
+	}
+	return ttl, nil
+}
+
+// commandTimeout returns the timeout of the calls of systemctl and
+// tuned-adm. The command line option '--timeout=DURATION' overrides the
+// value of COMMAND_TIMEOUT from /etc/sysconfig/saptune given in seconds
+func commandTimeout(sysconfigSecs int) (time.Duration, error) {
+	if !cliFlag("timeout") {
+		if sysconfigSecs < 0 {
+			return 0, fmt.Errorf("Wrong value '%d' for COMMAND_TIMEOUT in file '/etc/sysconfig/saptune', expected the number of seconds or 0.", sysconfigSecs)
+		}
+		return time.Duration(sysconfigSecs) * time.Second, nil
+	}
+	value := cliFlagValue("timeout")
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("Wrong value '%s' for option '--timeout', expected a duration like '30s' or '5m' or '0' to disable the timeout.", value)
+	}
+	return timeout, nil
+}
+
+// noteApplyAnnotation returns the annotation of the option '--note TEXT' or
+// an empty string, if the option is not specified
+func noteApplyAnnotation() (string, error) {
+	if !cliFlag("note") {
+		return "", nil
+	}
+	annotation := strings.TrimSpace(cliFlagValue("note"))
+	if annotation == "" {
+		return "", newExitError("Missing value for option '--note', expected a text like the number of the change ticket.")
+	}
+	return annotation, nil
+}
+
+// NoteActionApply applies Note parameter settings to the system.
+// A time to live > 0 applies the note temporarily, the note is reverted
+// automatically after this time. A non empty annotation is recorded with
+// the applied note
+func NoteActionApply(writer io.Writer, noteID string, withRequirements bool, ttl time.Duration, annotation string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	// Do not apply the note, if it was applied before
+	// Otherwise, the state file (serialised parameters) will be
+	// overwritten, and it will no longer be possible to revert the
+	// note to the state before it was tuned.
+	// An interrupted apply of the note is finished, the state file
+	// written before the interruption is kept.
+	_, err := os.Stat(tuneApp.State.GetPathToNote(noteID))
+	if err == nil && !tuneApp.IsNoteInterrupted(noteID) {
+		// state file for note already exists
+		// do not apply the note again, but record a new annotation
+		if annotation == "" {
+			system.InfoLog("note '%s' already applied. Nothing to do", noteID)
+			return nil
+		}
+		if err := tuneApp.AnnotateNote(noteID, annotation); err != nil {
+			return newExitError("Failed to record the annotation of note %s: %v", noteID, err)
+		}
+		recordHistory(tuneApp, "annotate", "note", noteID, nil)
+		fmt.Fprintf(writer, "The note %s is already applied, its annotation has been updated.\n", noteID)
+		return nil
+	}
+	unmet, err := tuneApp.UnmetRequirements(noteID)
+	if err != nil {
+		return newExitError("Failed to tune for note %s: %v", noteID, err)
+	}
+	if len(unmet) != 0 && withRequirements {
+		for _, reqID := range unmet {
+			err := tuneApp.TuneNote(reqID)
+			recordHistory(tuneApp, "apply", "note", reqID, err)
+			if err != nil {
+				return newExitError("Failed to tune for note %s required by note %s: %v", reqID, noteID, err)
+			}
+			fmt.Fprintf(writer, "The required note %s has been applied successfully.\n", reqID)
+		}
+	} else if len(unmet) != 0 {
+		fmt.Fprintf(writer, "Note %s requires the notes %s, which are not enabled.\n", noteID, strings.Join(unmet, " "))
+		fmt.Fprintf(writer, "Use 'saptune note apply --with-requirements %s' to apply them together with the note.\n", noteID)
+	}
+	if ttl > 0 {
+		err := tuneApp.TuneNoteTemporary(noteID, ttl)
+		recordHistory(tuneApp, "apply", "note", noteID, err)
+		if err != nil {
+			return newExitError("Failed to tune for note %s: %v", noteID, err)
+		}
+		fmt.Fprintf(writer, "The note has been applied successfully. It will be reverted automatically in %v.\n", ttl)
+	} else {
+		err := tuneApp.TuneNote(noteID)
+		recordHistory(tuneApp, "apply", "note", noteID, err)
+		if err != nil {
+			return newExitError("Failed to tune for note %s: %v", noteID, err)
+		}
+		fmt.Fprintf(writer, "The note has been applied successfully.\n")
+	}
+	if annotation != "" {
+		if err := tuneApp.AnnotateNote(noteID, annotation); err != nil {
+			return newExitError("Failed to record the annotation of note %s: %v", noteID, err)
+		}
+	}
+	if ttl == 0 && (applyStartsDaemon || cliFlag("start-daemon")) {
+		if !daemonActive() {
+			return applyStartDaemon(writer, systemExecutor{})
+		}
+	} else if daemonReminderNeeded() {
+		fmt.Fprintf(writer, "\nRemember: if you wish to automatically activate the solution's tuning options after a reboot,"+
+			"you must instruct saptune to configure \"tuned\" daemon by running:"+
+			"\n    saptune daemon start\n")
+	}
+	return nil
+}
+
+// NoteActionApplyOnly applies only the given parameters of a note. The other
+// parameters of the note are not managed by saptune, they are neither set
+// nor reverted and are shown as 'not managed' during verify
+func NoteActionApplyOnly(writer io.Writer, noteID string, params []string, annotation string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	for _, option := range []string{"ttl", "with-requirements", "simulate-first"} {
+		if cliFlag(option) {
+			return newExitError("The option '--only' can not be used together with the option '--%s'.", option)
+		}
+	}
+	if len(params) == 0 {
+		return newExitError("Missing parameter names for option '--only', expected e.g. '--only=kernel.shmmax,vm.swappiness'.")
+	}
+	err := tuneApp.TuneNoteParams(noteID, params)
+	recordHistory(tuneApp, "apply", "note", noteID, err)
+	if err != nil {
+		return newExitError("Failed to tune for note %s: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "The parameters %s of note %s have been applied successfully.\n", strings.Join(params, ", "), noteID)
+	fmt.Fprintf(writer, "All other parameters of the note are not managed by saptune.\n")
+	if annotation != "" {
+		if err := tuneApp.AnnotateNote(noteID, annotation); err != nil {
+			return newExitError("Failed to record the annotation of note %s: %v", noteID, err)
+		}
+	}
+	if applyStartsDaemon || cliFlag("start-daemon") {
+		if !daemonActive() {
+			return applyStartDaemon(writer, systemExecutor{})
+		}
+	} else if daemonReminderNeeded() {
+		fmt.Fprintf(writer, "\nRemember: if you wish to automatically activate the solution's tuning options after a reboot,"+
+			"you must instruct saptune to configure \"tuned\" daemon by running:"+
+			"\n    saptune daemon start\n")
+	}
+	return nil
+}
+
+// NoteActionApplyRefresh sets the parameters of an applied note again, which
+// deviate from the values of the note, e.g. after they were changed outside
+// of saptune. The saved state of the note is kept, so 'saptune note revert'
+// still restores the values from before the note was applied
+func NoteActionApplyRefresh(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	for _, option := range []string{"ttl", "note", "with-requirements", "simulate-first", "start-daemon", "only"} {
+		if cliFlag(option) {
+			return newExitError("The option '--refresh' can not be used together with the option '--%s'.", option)
+		}
+	}
+	refreshed, err := tuneApp.RefreshNote(noteID)
+	recordHistory(tuneApp, "refresh", "note", noteID, err)
+	for _, param := range refreshed {
+		fmt.Fprintf(writer, "\tparameter '%s' changed from '%s' to '%s'\n", param.Param, param.OldValue, param.NewValue)
+	}
+	if err != nil {
+		return newExitError("Failed to refresh note %s: %v", noteID, err)
+	}
+	if len(refreshed) == 0 {
+		fmt.Fprintf(writer, "All parameters already conform to note %s, nothing to refresh.\n", noteID)
+		return nil
+	}
+	fmt.Fprintf(writer, "%d parameter(s) of note %s set again. The values saved before the note was applied are kept for 'saptune note revert'.\n", len(refreshed), noteID)
+	return nil
+}
+
+// applyStartDaemon enables and starts tuned with the saptune profile after
+// a note was applied like 'saptune daemon start', so that the tuning is
+// restored after a reboot. This is requested by NOTE_APPLY_START_DAEMON in
+// /etc/sysconfig/saptune or by the option '--start-daemon'
+func applyStartDaemon(writer io.Writer, exe daemonExecutor) error {
+	fmt.Fprintf(writer, "\nEnabling and starting the daemon (tuned.service) with the tuned profile '%s', so that the tuning is restored after a reboot.\n", TunedProfileName)
+	fmt.Fprintf(writer, "This stops %s and applies all enabled notes and solutions at every system start like 'saptune daemon start'.\n", SapconfService)
+	if err := startDaemon(exe); err != nil {
+		return newExitError("Failed to start the daemon (tuned.service), the note is applied, but not restored after a reboot: %v", err)
+	}
+	system.InfoLog("daemon (tuned.service) enabled and started by 'saptune note apply'")
+	fmt.Fprintf(writer, "Daemon (tuned.service) has been enabled and started.\n")
+	return nil
+}
+
+// recordHistory records the apply, revert or customise action in the
+// history file. A failure to write the history is only logged, the action
+// itself is not affected
+func recordHistory(tuneApp *app.App, action, objType, objID string, actionErr error) {
+	if err := tuneApp.RecordHistory(action, objType, objID, actionErr); err != nil {
+		system.WarningLog("failed to record the %s of %s '%s' in the history file '%s' - %v", action, objType, objID, tuneApp.GetPathToHistory(), err)
+	}
+}
+
+// NoteActionApplyURL downloads a Note definition over https, stores it in
+// ExtraTuningSheets after a successful validation and applies the Note
+func NoteActionApplyURL(writer io.Writer, noteURL string, tuneApp *app.App) error {
+	if noteURL == "" {
+		return usageError()
+	}
+	noteID, problems, err := tuneApp.InstallRemoteNote(noteURL, ExtraTuningSheets)
+	for _, prob := range problems {
+		fmt.Fprintf(writer, "%s\n", prob)
+	}
+	if err != nil {
+		return newExitError("Failed to install the Note definition from '%s': %v", noteURL, err)
+	}
+	system.InfoLog("Note definition of note %s downloaded from '%s'", noteID, noteURL)
+	if tuningOptions != nil {
+		tuningOptions[noteID] = tuneApp.AllNotes[noteID]
+	}
+	fmt.Fprintf(writer, "Note definition of note %s downloaded from '%s' to '%s%s.conf'.\n", noteID, noteURL, ExtraTuningSheets, noteID)
+	return NoteActionApply(writer, noteID, false, 0, "", tuneApp)
+}
+
+// NoteActionApplyStdin reads a Note definition from stdin, registers it
+// with a transient Note ID after a successful validation and applies the
+// Note. The Note definition is only stored in ExtraTuningSheets, if
+// 'persist' is set
+func NoteActionApplyStdin(reader io.Reader, writer io.Writer, persist bool, tuneApp *app.App) error {
+	content, err := ioutil.ReadAll(io.LimitReader(reader, app.MaxRemoteNoteSize+1))
+	if err != nil {
+		return newExitError("Failed to read the Note definition from stdin: %v", err)
+	}
+	if len(content) > app.MaxRemoteNoteSize {
+		return newExitError("The Note definition read from stdin is larger than %d bytes", app.MaxRemoteNoteSize)
+	}
+	noteID, problems, err := tuneApp.InstallTransientNote(content, ExtraTuningSheets, persist)
+	for _, prob := range problems {
+		fmt.Fprintf(writer, "%s\n", prob)
+	}
+	if err != nil {
+		return newExitError("Failed to parse the Note definition: %v", err)
+	}
+	if tuningOptions != nil {
+		tuningOptions[noteID] = tuneApp.AllNotes[noteID]
+	}
+	if persist {
+		system.InfoLog("Note definition read from stdin stored as note %s", noteID)
+		fmt.Fprintf(writer, "Note definition read from stdin stored as note %s in '%s%s.conf'.\n", noteID, ExtraTuningSheets, noteID)
+	} else {
+		system.InfoLog("Note definition read from stdin registered as transient note %s", noteID)
+		fmt.Fprintf(writer, "Note definition read from stdin registered as transient note %s.\n", noteID)
+	}
+	ttl, err := noteApplyTTL()
+	if err != nil {
+		return err
+	}
+	annotation, err := noteApplyAnnotation()
+	if err != nil {
+		return err
+	}
+	return NoteActionApply(writer, noteID, false, ttl, annotation, tuneApp)
+}
+
+// NoteActionSimulateFirst shows the changes, which will be applied to the
+// system by the Note, and asks for confirmation before the Note is applied.
+// The confirmation is skipped, if 'assumeYes' is set. Without a terminal
+// to ask for confirmation saptune refuses to apply the Note.
+// It returns true, if the Note should be applied
+func NoteActionSimulateFirst(reader io.Reader, writer io.Writer, noteID string, assumeYes, interactive bool, tuneApp *app.App) (bool, error) {
+	if err := NoteActionSimulate(writer, noteID, tuneApp); err != nil {
+		return false, err
+	}
+	if assumeYes {
+		return true, nil
+	}
+	if !interactive {
+		return false, newExitError("Refusing to apply note %s without confirmation. Use the option '--yes' to apply the note non-interactively.", noteID)
+	}
+	fmt.Fprintf(writer, "Apply the note %s? [y/n]: ", noteID)
+	line, _ := bufio.NewReader(reader).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(line)) != "y" {
+		fmt.Fprintf(writer, "\nThe note %s has not been applied.\n", noteID)
+		return false, nil
+	}
+	return true, nil
+}
+
+// stdinIsTerminal returns true, if stdin is a terminal, so the user can be
+// asked for confirmation
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// stdoutIsTerminal returns true, if stdout is connected to a terminal
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// NoteActionList lists all available Note definitions
+func NoteActionList(writer io.Writer, tuneApp *app.App, tOptions note.TuningOptions, verbose bool, filter string) {
+	fmt.Fprintf(writer, "\n%s (+ denotes manually enabled notes, * denotes notes enabled by solutions, - denotes notes enabled by solutions but reverted manually later, O denotes override file exists for note, C denotes notes, which are only checked, but NOT set, S denotes notes, which are enabled, but not yet applied, X denotes notes, whose ID is used by more than one Note definition):\n", noteListHeading(filter))
+	solutionNoteIDs := tuneApp.GetSortedSolutionEnabledNotes()
+	for _, noteID := range tOptions.GetSortedIDs() {
+		noteObj := tOptions[noteID]
+		overrideFiles := note.OverrideFiles(noteID)
+		hasOverride := len(overrideFiles) != 0
+		i := sort.SearchStrings(solutionNoteIDs, noteID)
+		solutionEnabled := i < len(solutionNoteIDs) && solutionNoteIDs[i] == noteID
+		i = sort.SearchStrings(tuneApp.TuneForNotes, noteID)
+		manuallyEnabled := i < len(tuneApp.TuneForNotes) && tuneApp.TuneForNotes[i] == noteID
+		if !noteListFilterMatches(filter, manuallyEnabled, solutionEnabled, hasOverride, tuneApp.IsNoteApplied(noteID)) {
+			continue
+		}
+
+		format := "\t%s\t\t%s\n"
+		if len(noteID) >= 8 {
+			format = "\t%s\t%s\n"
+		}
+		if hasOverride {
+			format = " O" + format
+		}
+		checkOnly := false
+		if iniNote, ok := noteObj.(note.INISettings); ok {
+			if iniNote.ShadowFile != "" {
+				format = " X" + format
+			}
+			if iniNote.CheckOnly() {
+				checkOnly = true
+				format = " C" + format
+			}
+		}
+		if !checkOnly && tuneApp.PositionInNoteApplyOrder(noteID) >= 0 && !tuneApp.IsNoteApplied(noteID) {
+			// check only notes are never applied, so no state is saved
+			format = " S" + format
+		}
+		if solutionEnabled {
+			j := tuneApp.PositionInNoteApplyOrder(noteID)
+			if j < 0 { // noteID was reverted manually
+				format = " " + colorize("-"+format, setGreenText)
+			} else {
+				format = " " + colorize("*"+format, setGreenText)
+			}
+		} else if manuallyEnabled {
+			format = " " + colorize("+"+format, setGreenText)
+		}
+		fmt.Fprintf(writer, format, noteID, noteObj.Name())
+		if hasOverride && len(OverrideLayers) != 0 {
+			// show all override layers contributing to the note
+			fmt.Fprintf(writer, "\t\t\toverride files: %s\n", strings.Join(overrideFiles, " "))
+		}
+		if expiry, ok := tuneApp.NoteExpiry(noteID); ok {
+			fmt.Fprintf(writer, "\t\t\t%s\n", noteTTLInfo(expiry, time.Now()))
+		}
+		if source, ok := tuneApp.NoteSource(noteID); ok {
+			fmt.Fprintf(writer, "\t\t\tdownloaded from %s\n", source)
+		}
+		if pinned, actual, ok := tuneApp.NoteVersionPin(noteID); ok {
+			fmt.Fprintf(writer, "\t\t\t%s\n", noteVersionPinInfo(pinned, actual))
+		}
+		if params, ok := tuneApp.NoteParamSelection(noteID); ok {
+			fmt.Fprintf(writer, "\t\t\tonly parameters applied: %s\n", strings.Join(params, " "))
+		}
+		if verbose {
+			if annotation, ok := tuneApp.NoteAnnotation(noteID); ok {
+				fmt.Fprintf(writer, "\t\t\tAnnotation: %s\n", annotation)
+			}
+			if iniNote, ok := noteObj.(note.INISettings); ok {
+				if tags := iniNote.Tags(); len(tags) != 0 {
+					fmt.Fprintf(writer, "\t\t\tTags: %s\n", strings.Join(tags, " "))
+				}
+			}
+		}
+	}
+	tuneApp.PrintNoteApplyOrder(writer)
+	if daemonReminderNeeded() {
+		fmt.Fprintf(writer, "Remember: if you wish to automatically activate the solution's tuning options after a reboot,"+
+			"you must instruct saptune to configure \"tuned\" daemon by running:"+
+			"\n    saptune daemon start\n")
+	}
+}
+
+// noteListEntry describes a note in the json output of 'saptune note list'
+type noteListEntry struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Source          string   `json:"source"`
+	Enabled         bool     `json:"enabled"`
+	EnabledBy       string   `json:"enabledBy"`
+	AppliedPosition int      `json:"appliedPosition"`
+	OverrideExists  bool     `json:"overrideExists"`
+	OverrideFiles   []string `json:"overrideFiles,omitempty"`
+	Deprecated      bool     `json:"deprecated"`
+	SourceURL       string   `json:"sourceURL,omitempty"`
+	Annotation      string   `json:"annotation,omitempty"`
+	PinnedVersion   string   `json:"pinnedVersion,omitempty"`
+	ActualVersion   string   `json:"actualVersion,omitempty"`
+	OnlyParams      []string `json:"onlyParams,omitempty"`
+}
+
+// noteVersionPinInfo describes the pinned and the actual version of a
+// note for 'saptune note list'
+func noteVersionPinInfo(pinned, actual string) string {
+	if actual == "" {
+		actual = "unknown"
+	}
+	if pinned == actual {
+		return fmt.Sprintf("pinned to version %s", pinned)
+	}
+	return colorize(fmt.Sprintf("pinned to version %s, but the Note definition has version %s", pinned, actual), setRedText)
+}
+
+// NoteActionListJSON lists all available notes in json format for the
+// use by automation tools. The filter works like for NoteActionList
+func NoteActionListJSON(writer io.Writer, tuneApp *app.App, tOptions note.TuningOptions, filter string) error {
+	solutionNoteIDs := tuneApp.GetSortedSolutionEnabledNotes()
+	notes := make([]noteListEntry, 0, len(tOptions))
+	for _, noteID := range tOptions.GetSortedIDs() {
+		noteObj := tOptions[noteID]
+		overrideFiles := note.OverrideFiles(noteID)
+		hasOverride := len(overrideFiles) != 0
+		i := sort.SearchStrings(solutionNoteIDs, noteID)
+		solutionEnabled := i < len(solutionNoteIDs) && solutionNoteIDs[i] == noteID
+		i = sort.SearchStrings(tuneApp.TuneForNotes, noteID)
+		manuallyEnabled := i < len(tuneApp.TuneForNotes) && tuneApp.TuneForNotes[i] == noteID
+		if !noteListFilterMatches(filter, manuallyEnabled, solutionEnabled, hasOverride, tuneApp.IsNoteApplied(noteID)) {
+			continue
+		}
+		entry := noteListEntry{
+			ID:              noteID,
+			Name:            noteObj.Name(),
+			Source:          noteSource(noteObj, hasOverride),
+			AppliedPosition: tuneApp.PositionInNoteApplyOrder(noteID),
+			OverrideExists:  hasOverride,
+			OverrideFiles:   overrideFiles,
+			Deprecated:      noteIsDeprecated(noteID),
+		}
+		entry.SourceURL, _ = tuneApp.NoteSource(noteID)
+		entry.Annotation, _ = tuneApp.NoteAnnotation(noteID)
+		if pinned, actual, ok := tuneApp.NoteVersionPin(noteID); ok {
+			entry.PinnedVersion, entry.ActualVersion = pinned, actual
+		}
+		entry.OnlyParams, _ = tuneApp.NoteParamSelection(noteID)
+		if solutionEnabled && entry.AppliedPosition >= 0 {
+			// a note of a solution, which was reverted manually
+			// later, is no longer enabled
+			entry.Enabled, entry.EnabledBy = true, "solution"
+		} else if manuallyEnabled {
+			entry.Enabled, entry.EnabledBy = true, "manual"
+		}
+		notes = append(notes, entry)
+	}
+	content, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return newExitError("Failed to create the json output: %v", err)
+	}
+	fmt.Fprintf(writer, "%s\n", string(content))
+	return nil
+}
+
+// noteSource returns, where the definition of a note comes from:
+// 'override', if an override file changes the definition, 'extra' for a
+// vendor or customer specific definition and 'builtin' for the definitions
+// shipped with saptune
+func noteSource(noteObj note.Note, hasOverride bool) string {
+	if hasOverride {
+		return "override"
+	}
+	if iniNote, ok := noteObj.(note.INISettings); ok && strings.HasPrefix(iniNote.ConfFilePath, ExtraTuningSheets) {
+		return "extra"
+	}
+	return "builtin"
+}
+
+// noteIsDeprecated returns true, if the note is only part of deprecated
+// solutions
+func noteIsDeprecated(noteID string) bool {
+	deprecated := false
+	for solName, solNotes := range solution.AllSolutions[solutionSelector] {
+		for _, solNoteID := range solNotes {
+			if solNoteID != noteID {
+				continue
+			}
+			if _, ok := solution.DeprecSolutions[solutionSelector][solName]; !ok {
+				return false
+			}
+			deprecated = true
+		}
+	}
+	return deprecated
+}
+
+// NoteActionSearch lists all notes, whose name or whose Note definition
+// or override file contains the given text. The search is case-insensitive
+func NoteActionSearch(writer io.Writer, text string, tOptions note.TuningOptions) error {
+	if text == "" {
+		return usageError()
+	}
+	search := strings.ToLower(text)
+	found := 0
+	for _, noteID := range tOptions.GetSortedIDs() {
+		noteObj := tOptions[noteID]
+		fileNames := []string{}
+		if iniNote, ok := noteObj.(note.INISettings); ok {
+			fileNames = append(fileNames, iniNote.ConfFilePath)
+		}
+		fileNames = append(fileNames, note.OverrideFiles(noteID)...)
+		matches := make([]string, 0)
+		if strings.Contains(strings.ToLower(noteObj.Name()), search) {
+			matches = append(matches, "name")
+		}
+		for _, fileName := range fileNames {
+			content, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				system.WarningLog("Failed to read file '%s' - %v", fileName, err)
+				continue
+			}
+			if strings.Contains(strings.ToLower(string(content)), search) {
+				matches = append(matches, fileName)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		if found == 0 {
+			fmt.Fprintf(writer, "\nNotes matching '%s':\n", text)
+		}
+		found++
+		format := "\t%s\t\t%s\n"
+		if len(noteID) >= 8 {
+			format = "\t%s\t%s\n"
+		}
+		fmt.Fprintf(writer, format, noteID, noteObj.Name())
+		fmt.Fprintf(writer, "\t\t\tfound in: %s\n", strings.Join(matches, ", "))
+	}
+	if found == 0 {
+		fmt.Fprintf(writer, "\nNo notes found matching '%s'.\n\n", text)
+		return nil
+	}
+	fmt.Fprintf(writer, "\n")
+	return nil
+}
+
+// noteTTLInfo describes the remaining time to live of a temporarily
+// applied note
+func noteTTLInfo(expiry, now time.Time) string {
+	if !expiry.After(now) {
+		return "Temporarily applied, revert pending"
+	}
+	return fmt.Sprintf("Temporarily applied, reverted automatically in %v", expiry.Sub(now).Round(time.Second))
+}
+
+// noteListFilters contains the filters supported by 'saptune note list'.
+// The option '--<filter>-only' selects the filter
+var noteListFilters = []string{"enabled", "solution", "override", "applied"}
+
+// noteListFilter returns the filter selected on the command line for
+// 'saptune note list' or an empty string, if no filter is selected.
+// Only one filter is allowed at a time
+func noteListFilter() (string, error) {
+	filter := ""
+	for _, name := range noteListFilters {
+		if cliFlag(name + "-only") {
+			if filter != "" {
+				return "", newExitError("The options '--%s-only' and '--%s-only' can not be used together.", filter, name)
+			}
+			filter = name
+		}
+	}
+	return filter, nil
+}
+
+// noteListFilterMatches returns true, if a note with the given properties
+// needs to be printed by 'saptune note list' using the filter
+func noteListFilterMatches(filter string, manuallyEnabled, solutionEnabled, hasOverride, applied bool) bool {
+	switch filter {
+	case "enabled":
+		return manuallyEnabled
+	case "solution":
+		return solutionEnabled
+	case "override":
+		return hasOverride
+	case "applied":
+		return applied
+	}
+	return true
+}
+
+// noteListHeading returns the heading of the note list for the filter
+func noteListHeading(filter string) string {
+	switch filter {
+	case "enabled":
+		return "Manually enabled notes"
+	case "solution":
+		return "Notes enabled by solutions"
+	case "override":
+		return "Notes with override file"
+	case "applied":
+		return "Applied notes"
+	}
+	return "All notes"
+}
+
+// NoteActionEnable enables a Note without applying it. The Note will be
+// applied together with all other enabled notes by 'saptune daemon start'
+func NoteActionEnable(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	if tuneApp.PositionInNoteApplyOrder(noteID) >= 0 {
+		fmt.Fprintf(writer, "Note %s is already enabled.\n", noteID)
+		return nil
+	}
+	if err := tuneApp.EnableNote(noteID); err != nil {
+		return newExitError("Failed to enable note %s: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "Note %s has been enabled, but not yet applied.\n", noteID)
+	fmt.Fprintf(writer, "It will be applied together with all other enabled notes by 'saptune daemon start'.\n")
+	tuneApp.PrintNoteApplyOrder(writer)
+	return nil
+}
+
+// NoteActionDisable disables a Note, which is enabled, but not yet applied
+func NoteActionDisable(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	if err := tuneApp.DisableNote(noteID); err != nil {
+		return newExitError("Failed to disable note %s: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "Note %s has been disabled.\n", noteID)
+	tuneApp.PrintNoteApplyOrder(writer)
+	return nil
+}
+
+// NoteActionVerify compares all parameter settings from a Note definition
+// against the system settings
+func NoteActionVerify(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if verifyParam != "" && verifyParamPrefix != "" {
+		return newExitError("The option '--param' can not be used together with the option '--param-prefix'.")
+	}
+	if verifyParamPrefix != "" && verifySince != "" {
+		return newExitError("The option '--param-prefix' can not be used together with the option '--since'.")
+	}
+	if verifyBaseline != "" && (noteID != "" || verifyParam != "" || verifyParamPrefix != "" || verifySince != "") {
+		return newExitError("The option '--baseline' can not be used together with a Note ID or the options '--param', '--param-prefix' and '--since'.")
+	}
+	if verifyBaseline != "" {
+		return NoteActionVerifyBaseline(writer, verifyBaseline, tuneApp)
+	} else if noteID == "" && verifyParam != "" {
+		return VerifyParameter(writer, verifyParam, tuneApp)
+	} else if noteID == "" {
+		return VerifyAllParameters(writer, tuneApp)
+	}
+	// Check system parameters against the specified note, no matter the note has been tuned for or not.
+	conforming, comparisons, _, err := tuneApp.VerifyNote(noteID)
+	if err != nil {
+		return newExitError("Failed to test the current system against the specified note: %v", err)
+	}
+	noteComp := make(map[string]map[string]note.FieldComparison)
+	noteComp[noteID] = comparisons
+	unsatisfiedNotes := []string{}
+	if !conforming {
+		unsatisfiedNotes = append(unsatisfiedNotes, noteID)
+	}
+	ignore, err := verifyIgnoreList()
+	if err != nil {
+		return err
+	}
+	unsatisfiedNotes = ignoreDeviations(noteComp, unsatisfiedNotes, ignore)
+	conforming = len(unsatisfiedNotes) == 0
+	if done, err := verifySinceLast(writer, noteComp, tuneApp); done || err != nil {
+		return err
+	}
+	if verifyParamPrefix != "" {
+		var err error
+		if noteComp, unsatisfiedNotes, err = filterPrefixComparisons(noteComp, unsatisfiedNotes, verifyParamPrefix); err != nil {
+			return err
+		}
+		conforming = len(unsatisfiedNotes) == 0
+	}
+	if done, err := printVerifyFormat(writer, noteComp, unsatisfiedNotes); done || err != nil {
+		return err
+	}
+	PrintNoteFields(writer, "HEAD", noteComp, true)
+	printNoteAnnotations(writer, []string{noteID}, tuneApp)
+	insecure := verifyParanoidFiles(writer, noteComp, tuneApp)
+	tuneApp.PrintNoteApplyOrder(writer)
+	fmt.Fprintln(writer, computeComplianceScore(noteComp))
+	if !conforming {
+		if err := deviationError(noteComp, "The parameters listed above have deviated from the specified note.\n"); err != nil {
+			return err
+		}
+	} else if verifyParamPrefix != "" {
+		fmt.Fprintf(writer, "The parameters matching '%s' conform to the specified note.\n", verifyParamPrefix)
+	} else {
+		fmt.Fprintf(writer, "The system fully conforms to the specified note.\n")
+	}
+	return insecureFilesError(insecure)
+}
+
+// verifyWatchInterval returns the interval of the option '--watch[=seconds]'
+// and if the option is specified
+func verifyWatchInterval() (time.Duration, bool, error) {
+	if !cliFlag("watch") {
+		return 0, false, nil
+	}
+	value := cliFlagValue("watch")
+	if value == "" {
+		return defaultWatchInterval, true, nil
+	}
+	secs, err := strconv.Atoi(value)
+	if err != nil || secs <= 0 {
+		return 0, true, newExitError("Wrong value '%s' for option '--watch', expected the interval in seconds.", value)
+	}
+	return time.Duration(secs) * time.Second, true, nil
+}
+
+// NoteActionVerifyWatch verifies the note or, without Note ID, all enabled
+// notes every interval and redraws the verify table until a signal is
+// received from stop. The rows of the parameters, whose compliance changed
+// since the previous draw, are highlighted. With redraw the screen is
+// cleared before each draw
+func NoteActionVerifyWatch(writer io.Writer, noteID string, interval time.Duration, redraw bool, stop <-chan os.Signal, tuneApp *app.App) error {
+	if cliFlag("output-file") {
+		return newExitError("The option '--watch' can not be used together with the option '--output-file'.")
+	}
+	if outputFormat != "" || footnotesFormat != "" || verifySince != "" || verifyBaseline != "" || verifyParam != "" {
+		return newExitError("The option '--watch' can not be used together with the options '--format', '--footnotes', '--since', '--baseline' and '--param'.")
+	}
+	command := strings.TrimSpace("saptune note verify " + noteID)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer func() { watchChanged = map[string]map[string]bool{} }()
+	var previous map[string]map[string]note.FieldComparison
+	for {
+		comparisons, err := watchComparisons(noteID, tuneApp)
+		if err != nil {
+			return err
+		}
+		watchChanged = complianceChangedParams(previous, comparisons)
+		previous = comparisons
+		if redraw {
+			fmt.Fprint(writer, clearScreen)
+		}
+		fmt.Fprintf(writer, "Every %v: %s    %s\n\n", interval, command, time.Now().Format("2006-01-02 15:04:05"))
+		if len(comparisons) == 0 {
+			fmt.Fprintln(writer, "No notes or solutions enabled, nothing to verify.")
+		} else {
+			PrintNoteFields(writer, "NONE", comparisons, true)
+			fmt.Fprintln(writer, computeComplianceScore(comparisons))
+		}
+		if len(watchChanged) != 0 {
+			fmt.Fprintln(writer, "The parameters marked with '<-- changed' changed their compliance since the previous check.")
+		}
+		select {
+		case <-stop:
+			fmt.Fprintln(writer)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchComparisons returns the comparisons of the note or, without Note
+// ID, of all enabled notes for 'saptune note verify --watch'. With the
+// option '--param-prefix' only the matching parameters are returned
+func watchComparisons(noteID string, tuneApp *app.App) (map[string]map[string]note.FieldComparison, error) {
+	comparisons := make(map[string]map[string]note.FieldComparison)
+	if noteID == "" {
+		if len(tuneApp.NoteApplyOrder) == 0 {
+			return comparisons, nil
+		}
+		var err error
+		if _, comparisons, err = tuneApp.VerifyAll(); err != nil {
+			return nil, newExitError("Failed to inspect the current system: %v", err)
+		}
+	} else {
+		_, noteComparisons, _, err := tuneApp.VerifyNote(noteID)
+		if err != nil {
+			return nil, newExitError("Failed to test the current system against the specified note: %v", err)
+		}
+		comparisons[noteID] = noteComparisons
+	}
+	ignore, err := verifyIgnoreList()
+	if err != nil {
+		return nil, err
+	}
+	ignoreDeviations(comparisons, []string{}, ignore)
+	if verifyParamPrefix != "" {
+		if comparisons, _, err = filterPrefixComparisons(comparisons, []string{}, verifyParamPrefix); err != nil {
+			return nil, err
+		}
+	}
+	return comparisons, nil
+}
+
+// complianceChangedParams returns per note the parameters, whose compliance
+// differs between the previous and the current comparisons. Parameters not
+// found in the previous comparisons are not reported
+func complianceChangedParams(previous, current map[string]map[string]note.FieldComparison) map[string]map[string]bool {
+	changed := make(map[string]map[string]bool)
+	for noteID, noteComparisons := range current {
+		for key, comparison := range noteComparisons {
+			if comparison.ReflectMapKey == "" {
+				continue
+			}
+			before, ok := previous[noteID][key]
+			if !ok || before.MatchExpectation == comparison.MatchExpectation {
+				continue
+			}
+			if _, ok := changed[noteID]; !ok {
+				changed[noteID] = make(map[string]bool)
+			}
+			changed[noteID][comparison.ReflectMapKey] = true
+		}
+	}
+	return changed
+}
+
+// NoteActionSimulate shows all changes that will be applied to the system if
+// the Note will be applied.
+func NoteActionSimulate(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	// Run verify and print out all fields of the note
+	_, comparisons, _, err := tuneApp.VerifyNote(noteID)
+	if err != nil {
+		return newExitError("Failed to test the current system against the specified note: %v", err)
+	}
+	noteComp := make(map[string]map[string]note.FieldComparison)
+	noteComp[noteID] = comparisons
+	if done, err := printFootnotesFormat(writer, noteComp); done || err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "If you run `saptune note apply %s`, the following changes will be applied to your system:\n", noteID)
+	PrintNoteFields(writer, "HEAD", noteComp, false)
+	return nil
+}
+
+// NoteActionSimulateAll shows the changes, which will be applied to the
+// system for all enabled notes, grouped by note in the order the notes
+// are applied
+func NoteActionSimulateAll(writer io.Writer, tuneApp *app.App) error {
+	allComp := make(map[string]map[string]note.FieldComparison)
+	for _, noteID := range tuneApp.NoteApplyOrder {
+		_, comparisons, _, err := tuneApp.VerifyNote(noteID)
+		if err != nil {
+			return newExitError("Failed to test the current system against the note %s: %v", noteID, err)
+		}
+		allComp[noteID] = comparisons
+	}
+	if done, err := printFootnotesFormat(writer, allComp); done || err != nil {
+		return err
+	}
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		fmt.Fprintln(writer, "No notes or solutions enabled, nothing to simulate.")
+		return nil
+	}
+	fmt.Fprintf(writer, "If you run `saptune daemon start`, the following changes will be applied to your system:\n")
+	for _, noteID := range tuneApp.NoteApplyOrder {
+		noteComp := make(map[string]map[string]note.FieldComparison)
+		noteComp[noteID] = allComp[noteID]
+		PrintNoteFields(writer, "HEAD", noteComp, false)
+	}
+	return nil
+}
+
+// logOverrideLayers logs the override files of the other override layers,
+// as 'customise' only changes the host specific override file
+func logOverrideLayers(noteID, ovFileName string) {
+	layerFiles := make([]string, 0)
+	for _, fileName := range note.OverrideFiles(noteID) {
+		if path.Clean(fileName) != path.Clean(ovFileName) {
+			layerFiles = append(layerFiles, fileName)
+		}
+	}
+	if len(layerFiles) != 0 {
+		system.InfoLog("The override file '%s' is merged on top of the override files '%s' of the other override layers, which are not changed", ovFileName, strings.Join(layerFiles, "', '"))
+	}
+}
+
+// NoteActionCustomise creates an override file and allows to editing the Note
+// definition file
+func NoteActionCustomise(noteID string) error {
+	if noteID == "" {
+		return usageError()
+	}
+	if _, err := tuneApp.GetNoteByID(noteID); err != nil {
+		return newExitError("%v", err)
+	}
+	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		_, files := system.ListDir(ExtraTuningSheets, "")
+		for _, f := range files {
+			if strings.HasPrefix(f, noteID) {
+				fileName = fmt.Sprintf("%s%s", ExtraTuningSheets, f)
+			}
+		}
+		if _, err := os.Stat(fileName); os.IsNotExist(err) {
+			return newExitError("Note %s not found in %s or %s.", noteID, NoteTuningSheets, ExtraTuningSheets)
+		} else if err != nil {
+			return newExitError("Failed to read file '%s' - %v", fileName, err)
+		}
+	} else if err != nil {
+		return newExitError("Failed to read file '%s' - %v", fileName, err)
+	}
+	ovFileName := fmt.Sprintf("%s%s", OverrideTuningSheets, noteID)
+	logOverrideLayers(noteID, ovFileName)
+	if _, err := os.Stat(ovFileName); err == nil {
+		system.InfoLog("Note override file already exists, using file '%s' as base for editing", ovFileName)
+		fileName = ovFileName
+	} else if !os.IsNotExist(err) {
+		return newExitError("Failed to read file '%s' - %v", ovFileName, err)
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "/usr/bin/vim" // launch vim by default
+	}
+	changed, err := editOverrideFile(os.Stdin, os.Stdout, ovFileName, fileName, func(editFileName string) error {
+		cmd := exec.Command(editor, editFileName)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	})
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	if !changed {
+		system.InfoLog("Changes discarded, the override file of Note %s is left untouched.\n", noteID)
+		return nil
+	}
+	recordHistory(tuneApp, "customise", "note", noteID, nil)
+	i := tuneApp.PositionInNoteApplyOrder(noteID)
+	if i < 0 { // noteID not yet available
+		system.InfoLog("Do not forget to apply the just edited Note to get your changes to take effect\n")
+	} else { // noteID already applied
+		system.InfoLog("Your just edited Note is already applied. To get your changes to take effect, please 'revert' the Note and apply again.\n")
+	}
+	return nil
+}
+
+// editOverrideFile lets the user edit a copy of 'baseFileName'. The copy
+// replaces the override file only, if it is a valid Note definition, so an
+// invalid override file never becomes active. Otherwise the problems are
+// printed and the user can edit the copy again or discard the changes.
+// It returns true, if the override file was replaced
+func editOverrideFile(reader io.Reader, writer io.Writer, ovFileName, baseFileName string, edit func(string) error) (bool, error) {
+	content, err := ioutil.ReadFile(baseFileName)
+	if err != nil {
+		return false, fmt.Errorf("Failed to read file '%s' - %v", baseFileName, err)
+	}
+	if err := os.MkdirAll(path.Dir(ovFileName), 0755); err != nil {
+		return false, fmt.Errorf("Failed to create directory '%s' - %v", path.Dir(ovFileName), err)
+	}
+	// the copy is placed in the override directory, so that the
+	// rename is atomic
+	tmpFile, err := ioutil.TempFile(path.Dir(ovFileName), "."+path.Base(ovFileName)+".edit")
+	if err != nil {
+		return false, fmt.Errorf("Failed to create a copy of '%s' for editing - %v", baseFileName, err)
+	}
+	editFileName := tmpFile.Name()
+	defer os.Remove(editFileName)
+	_, err = tmpFile.Write(content)
+	if cerr := tmpFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return false, fmt.Errorf("Failed to write file '%s' - %v", editFileName, err)
+	}
+
+	answer := bufio.NewReader(reader)
+	for {
+		if err := edit(editFileName); err != nil {
+			return false, fmt.Errorf("Failed to launch editor for file '%s' - %v", editFileName, err)
+		}
+		edited, err := ioutil.ReadFile(editFileName)
+		if err != nil {
+			return false, fmt.Errorf("Failed to read file '%s' - %v", editFileName, err)
+		}
+		problems := note.ValidateNoteDefinition(ovFileName, string(edited))
+		if len(problems) == 0 {
+			break
+		}
+		fmt.Fprintf(writer, "\nThe edited override file is invalid:\n")
+		for _, prob := range problems {
+			fmt.Fprintf(writer, "%s\n", prob)
+		}
+		fmt.Fprintf(writer, "\nEdit the file again or discard the changes? [e/d]: ")
+		line, _ := answer.ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(line)) != "e" {
+			fmt.Fprintf(writer, "\n")
+			return false, nil
+		}
+	}
+	if err := os.Chmod(editFileName, 0644); err != nil {
+		return false, fmt.Errorf("Failed to change the permissions of file '%s' - %v", editFileName, err)
+	}
+	if err := os.Rename(editFileName, ovFileName); err != nil {
+		return false, fmt.Errorf("Failed to replace file '%s' - %v", ovFileName, err)
+	}
+	return true, nil
+}
+
+// NoteActionCustomiseSet sets the given 'parameter=value' pairs in the
+// override file of a Note without starting an editor. The parameters need
+// to be defined in the Note definition file. Entries of an existing
+// override file, which are not mentioned, are preserved
+func NoteActionCustomiseSet(writer io.Writer, noteID string, settings []string, tuneApp *app.App) error {
+	if noteID == "" || len(settings) == 0 {
+		return usageError()
+	}
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return newExitError("Note %s is not based on a Note definition file.", noteID)
+	}
+	// the parameters are matched as written in the Note definition file
+	// and the files it includes, not as expanded by the parser
+	definitions, err := noteDefinitions(iniNote)
+	if err != nil {
+		return newExitError("Failed to read the definition of Note %s - %v", noteID, err)
+	}
+	ovFileName := fmt.Sprintf("%s%s", OverrideTuningSheets, noteID)
+	logOverrideLayers(noteID, ovFileName)
+	override, err := ioutil.ReadFile(ovFileName)
+	if err != nil && !os.IsNotExist(err) {
+		return newExitError("Failed to read file '%s' - %v", ovFileName, err)
+	}
+	content, err := customiseOverride(string(override), definitions, settings)
+	if err != nil {
+		return newExitError("Failed to customise Note %s - %v", noteID, err)
+	}
+	if problems := note.ValidateNoteDefinition(ovFileName, content); len(problems) != 0 {
+		for _, prob := range problems {
+			fmt.Fprintf(writer, "%s\n", prob)
+		}
+		return newExitError("The override file of Note %s would be invalid, nothing changed.", noteID)
+	}
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		return newExitError("Failed to create directory '%s' - %v", OverrideTuningSheets, err)
+	}
+	err = ioutil.WriteFile(ovFileName, []byte(content), 0644)
+	recordHistory(tuneApp, "customise", "note", noteID, err)
+	if err != nil {
+		return newExitError("Failed to write file '%s' - %v", ovFileName, err)
+	}
+	fmt.Fprintf(writer, "The override file '%s' of Note %s has been updated.\n", ovFileName, noteID)
+	if tuneApp.PositionInNoteApplyOrder(noteID) < 0 {
+		system.InfoLog("Do not forget to apply the just customised Note to get your changes to take effect\n")
+	} else {
+		system.InfoLog("Your just customised Note is already applied. To get your changes to take effect, please 'revert' the Note and apply again.\n")
+	}
+	return nil
+}
+
+// NoteActionCustomiseWizard guides through the parameters of a Note. For
+// every parameter the current system value and the recommended value are
+// shown and the user can keep the recommended value or enter a new one.
+// The changed parameters are written to the override file like by
+// NoteActionCustomiseSet without starting an editor
+func NoteActionCustomiseWizard(reader io.Reader, writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return newExitError("Note %s is not based on a Note definition file.", noteID)
+	}
+	// the parameters are offered as written in the Note definition file
+	// and the files it includes, so that they match during the write of
+	// the override file
+	definitions, err := noteDefinitions(iniNote)
+	if err != nil {
+		return newExitError("Failed to read the definition of Note %s - %v", noteID, err)
+	}
+	// only read the current values, do not create saved state files
+	current, err := iniNote.SetValuesToApply([]string{"verify"}).Initialise()
+	if err != nil {
+		return newExitError("Failed to read the current values of Note %s - %v", noteID, err)
+	}
+	recommended, err := current.Optimise()
+	if err != nil {
+		return newExitError("Failed to calculate the recommended values of Note %s - %v", noteID, err)
+	}
+	currentValues := current.(note.INISettings).SysctlParams
+	recommendedValues := recommended.(note.INISettings).SysctlParams
+
+	fmt.Fprintf(writer, "Customising Note %s\n", noteID)
+	fmt.Fprintf(writer, "Press Enter to keep the recommended value, enter a new value to change it or '-' to leave the parameter untouched.\n")
+	answer := bufio.NewReader(reader)
+	settings := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, param := range definitionParams(definitions) {
+		if seen[param.key] {
+			continue
+		}
+		seen[param.key] = true
+		recommendedValue := wizardValue(recommendedValues, param)
+		fmt.Fprintf(writer, "\n[%s] %s\n", param.section, param.name)
+		fmt.Fprintf(writer, "    current value:     %s\n", wizardValue(currentValues, param))
+		fmt.Fprintf(writer, "    recommended value: %s\n", recommendedValue)
+		fmt.Fprintf(writer, "New value: ")
+		line, err := answer.ReadString('\n')
+		value := strings.TrimSpace(line)
+		switch {
+		case value == "-":
+			settings = append(settings, param.name+" =")
+		case value != "" && value != recommendedValue && value != param.value:
+			settings = append(settings, param.name+" = "+value)
+		}
+		if err != nil {
+			// no more input, keep the remaining parameters
+			fmt.Fprintf(writer, "\n")
+			break
+		}
+	}
+	if len(settings) == 0 {
+		fmt.Fprintf(writer, "\nNo parameter changed, the override file of Note %s is left untouched.\n", noteID)
+		return nil
+	}
+	fmt.Fprintf(writer, "\nThe following parameters will be written to the override file of Note %s:\n", noteID)
+	for _, set := range settings {
+		fmt.Fprintf(writer, "    %s\n", set)
+	}
+	fmt.Fprintf(writer, "Write the override file? [y/n]: ")
+	line, _ := answer.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(line)) != "y" {
+		fmt.Fprintf(writer, "\nChanges discarded, the override file of Note %s is left untouched.\n", noteID)
+		return nil
+	}
+	return NoteActionCustomiseSet(writer, noteID, settings, tuneApp)
+}
+
+// wizardValue returns the value of a parameter of the Note definition file
+// from the values read or calculated by the parser. A parameter expanded by
+// the parser to several parameters, like the [block] parameters to one per
+// block device, shows the values of all of them.
+func wizardValue(values map[string]string, param definitionParam) string {
+	if value, ok := values[param.key]; ok {
+		return value
+	}
+	prefix, suffix := "", ""
+	switch param.section {
+	case note.INISectionBlock:
+		prefix = strings.SplitN(param.key, "[", 2)[0] + "_"
+	case note.INISectionSysctl:
+		if fields := strings.SplitN(param.key, note.SysctlInterfacePlaceholder, 2); len(fields) == 2 {
+			prefix, suffix = fields[0], fields[1]
+		}
+	}
+	if prefix == "" {
+		return ""
+	}
+	keys := make([]string, 0)
+	for key := range values {
+		if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	expanded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		expanded = append(expanded, fmt.Sprintf("%s (%s)", values[key], key))
+	}
+	return strings.Join(expanded, ", ")
+}
+
+// noteDefinitions returns the raw content of the Note definition file and
+// of the files it includes, the included files first
+func noteDefinitions(iniNote note.INISettings) ([]string, error) {
+	definitions := make([]string, 0, len(iniNote.IncludeFiles)+1)
+	for _, fileName := range append(append([]string{}, iniNote.IncludeFiles...), iniNote.ConfFilePath) {
+		content, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, string(content))
+	}
+	return definitions, nil
+}
+
+// definitionParam is a parameter as written in a Note definition file
+type definitionParam struct {
+	section, key, name, operator, value string
+}
+
+// definitionParams returns the parameters of the raw Note definitions, which
+// can be customised, in the order they are written. 'name' is the parameter
+// name used in an override file, 'key' the one used by the parser.
+func definitionParams(definitions []string) []definitionParam {
+	params := make([]definitionParam, 0)
+	for _, definition := range definitions {
+		section := ""
+		for _, line := range strings.Split(definition, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if line[0] == '[' {
+				section, _ = txtparser.SplitSectionName(strings.Trim(line, "[]"))
+				continue
+			}
+			switch section {
+			case note.INISectionRpm, note.INISectionReminder, note.INISectionVersion, "tags", "requires", "include", "bounds", "severity":
+				continue
+			}
+			if key, operator := noteLineParam(section, line); key != "" {
+				value := ""
+				if fields := strings.SplitN(line, operator, 2); operator != "" && len(fields) == 2 {
+					value = strings.Trim(strings.TrimSpace(fields[1]), "\"'")
+				}
+				params = append(params, definitionParam{section, key, strings.TrimPrefix(key, "grub:"), operator, value})
+			}
+		}
+	}
+	return params
+}
+
+// customiseOverride sets the 'parameter=value' pairs in the content of an
+// override file. The parameters are matched against the parameter lines of
+// the given contents of the Note definition file and its included files,
+// the section and the operator of a parameter are taken from there.
+// Existing lines of the parameters are replaced, new parameters are added
+// at the end of their section. An empty value marks the parameter as
+// 'untouched'
+func customiseOverride(override string, definitions []string, settings []string) (string, error) {
+	type setting struct {
+		section, key, line string
+	}
+	params := definitionParams(definitions)
+	pending := make([]setting, 0, len(settings))
+	for _, set := range settings {
+		fields := strings.SplitN(set, "=", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) == "" {
+			return "", fmt.Errorf("wrong parameter setting '%s', expected 'parameter=value'", set)
+		}
+		param, value := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		found := false
+		for _, def := range params {
+			if def.name == param || def.key == param {
+				pending = append(pending, setting{def.section, def.key, strings.TrimSpace(fmt.Sprintf("%s %s %s", def.name, def.operator, value))})
+				found = true
+				break
+			}
+		}
+		// the last setting of a parameter wins
+		for i := 0; found && i < len(pending)-1; i++ {
+			if pending[i].key == pending[len(pending)-1].key {
+				pending = append(pending[:i], pending[i+1:]...)
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("parameter '%s' is not defined in the Note definition file", param)
+		}
+	}
+
+	// replace the lines of parameters, which are already available
+	lines := strings.Split(strings.TrimRight(override, "\n"), "\n")
+	if override == "" {
+		lines = []string{}
+	}
+	lastLine := make(map[string]int)
+	section := ""
+	for idx, line := range lines {
+		tline := strings.TrimSpace(line)
+		if len(tline) == 0 {
+			continue
+		}
+		if tline[0] == '[' {
+			section = strings.Trim(tline, "[]")
+			lastLine[section] = idx
+			continue
+		}
+		lastLine[section] = idx
+		if strings.HasPrefix(tline, "#") {
+			continue
+		}
+		key := noteLineKey(section, tline)
+		for i := 0; i < len(pending); i++ {
+			if pending[i].section == section && pending[i].key == key {
+				lines[idx] = pending[i].line
+				pending = append(pending[:i], pending[i+1:]...)
+				i--
+			}
+		}
+	}
+
+	// add the remaining parameters to their sections
+	addLines := make(map[string][]string)
+	newSections := make([]string, 0)
+	for _, set := range pending {
+		if _, ok := lastLine[set.section]; !ok && len(addLines[set.section]) == 0 {
+			newSections = append(newSections, set.section)
+		}
+		addLines[set.section] = append(addLines[set.section], set.line)
+	}
+	result := make([]string, 0, len(lines)+len(pending))
+	section = ""
+	for idx, line := range lines {
+		result = append(result, line)
+		if tline := strings.TrimSpace(line); len(tline) != 0 && tline[0] == '[' {
+			section = strings.Trim(tline, "[]")
+		}
+		if lastLine[section] == idx {
+			result = append(result, addLines[section]...)
+		}
+	}
+	for _, sect := range newSections {
+		if len(result) != 0 {
+			result = append(result, "")
+		}
+		result = append(result, "["+sect+"]")
+		result = append(result, addLines[sect]...)
+	}
+	return strings.Join(result, "\n") + "\n", nil
+}
+
+// NoteActionCreate helps the customer to create an own Note definition
+func NoteActionCreate(noteID string) error {
+	if noteID == "" {
+		return usageError()
+	}
+	if _, err := tuneApp.GetNoteByID(noteID); err == nil {
+		return newExitError("Note '%s' already exists. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, noteID)
+	}
+	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
+	if _, err := os.Stat(fileName); err == nil {
+		return newExitError("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, NoteTuningSheets, noteID)
+	}
+	extraFileName := fmt.Sprintf("%s%s.conf", ExtraTuningSheets, noteID)
+	if _, err := os.Stat(extraFileName); err == nil {
+		return newExitError("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, ExtraTuningSheets, noteID)
+	}
+	templateFile := "/usr/share/saptune/NoteTemplate.conf"
+	//if _, err := os.Stat(extraFileName); os.IsNotExist(err) {
+	//copy template file
+	err := system.CopyFile(templateFile, extraFileName)
+	if err != nil {
+		return newExitError("Problems while copying '%s' to '%s' - %v", templateFile, extraFileName, err)
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "/usr/bin/vim" // launch vim by default
+	}
+	if err := syscall.Exec(editor, []string{editor, extraFileName}, os.Environ()); err != nil {
+		return newExitError("Failed to start launch editor %s: %v", editor, err)
+	}
+	return nil
+}
+
+// NoteActionShow shows the content of the Note definition file.
+// If an override file exists for the Note, the values of the override file
+// are shown instead of the values of the Note definition file and these
+// lines are marked with 'O'. With 'raw' the Note definition file is shown
+// unchanged
+func NoteActionShow(writer io.Writer, noteID string, raw bool, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		_, files := system.ListDir(ExtraTuningSheets, "")
+		for _, f := range files {
+			if strings.HasPrefix(f, noteID) {
+				fileName = fmt.Sprintf("%s%s", ExtraTuningSheets, f)
+			}
+		}
+		if _, err := os.Stat(fileName); os.IsNotExist(err) {
+			return newExitError("Note %s not found in %s or %s.", noteID, NoteTuningSheets, ExtraTuningSheets)
+		} else if err != nil {
+			return newExitError("Failed to read file '%s' - %v", fileName, err)
+		}
+	} else if err != nil {
+		return newExitError("Failed to read file '%s' - %v", fileName, err)
+	}
+	cont, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return newExitError("Failed to read file '%s' - %v", fileName, err)
+	}
+	content := string(cont)
+	includeFiles := []string{}
+	if iniNote, ok := aNote.(note.INISettings); ok && !raw {
+		includeFiles = iniNote.IncludeFiles
+	}
+	if len(includeFiles) != 0 {
+		content, err = resolveNoteIncludes(content, includeFiles)
+		if err != nil {
+			return newExitError("Failed to resolve the includes of Note %s - %v", noteID, err)
+		}
+	}
+	overrideFiles := note.OverrideFiles(noteID)
+	if raw || len(overrideFiles) == 0 {
+		if len(includeFiles) != 0 {
+			fmt.Fprintf(writer, "\nContent of Note %s with the parameters of the included Notes:\n%s\n", noteID, content)
+		} else {
+			fmt.Fprintf(writer, "\nContent of Note %s:\n%s\n", noteID, content)
+		}
+		return nil
+	}
+	// the lines of a later override layer replace the ones of an earlier
+	ocont := ""
+	for _, overrideFile := range overrideFiles {
+		layer, err := ioutil.ReadFile(overrideFile)
+		if err != nil {
+			return newExitError("Failed to read file '%s' - %v", overrideFile, err)
+		}
+		ocont = ocont + string(layer) + "\n"
+	}
+	if len(overrideFiles) == 1 {
+		fmt.Fprintf(writer, "\nContent of Note %s with the values of the override file '%s' (O denotes lines taken from the override file):\n", noteID, overrideFiles[0])
+	} else {
+		fmt.Fprintf(writer, "\nContent of Note %s with the values of the override files '%s' (O denotes lines taken from the override files):\n", noteID, strings.Join(overrideFiles, "', '"))
+	}
+	for _, line := range resolveNoteOverride(content, ocont) {
+		fmt.Fprintf(writer, "%s\n", line)
+	}
+	return nil
+}
+
+// resolveNoteOverride layers the parameter lines of an override file on top
+// of the content of a Note definition file. Lines taken from the override
+// file are prefixed with 'O', all other non-empty lines are indented
+func resolveNoteOverride(content, override string) []string {
+	overrideLines := make(map[string]map[string]string)
+	overrideCheckOnly := false
+	section := ""
+	for _, line := range strings.Split(override, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line[0] == '[' {
+			section = strings.Trim(line, "[]")
+			if section == note.INISectionCheckOnly {
+				overrideCheckOnly = true
+			}
+			continue
+		}
+		if key := noteLineKey(section, line); key != "" {
+			if overrideLines[section] == nil {
+				overrideLines[section] = make(map[string]string)
+			}
+			overrideLines[section][key] = line
 		}
+	}
 
-		// print table body
-		if printComparison {
-			// verify
-			fmt.Fprintf(writer, format, noteField, comparison.ReflectMapKey, strings.Replace(comparison.ExpectedValueJS, "\t", " ", -1), override, strings.Replace(comparison.ActualValueJS, "\t", " ", -1), compliant)
-		} else {
-			// simulate
-			fmt.Fprintf(writer, format, comparison.ReflectMapKey, strings.Replace(comparison.ActualValueJS, "\t", " ", -1), strings.Replace(comparison.ExpectedValueJS, "\t", " ", -1), override, comment)
+	resolved := make([]string, 0)
+	section = ""
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		tline := strings.TrimSpace(line)
+		if len(tline) == 0 {
+			resolved = append(resolved, "")
+			continue
+		}
+		if tline[0] == '[' {
+			section = strings.Trim(tline, "[]")
+			if section == note.INISectionCheckOnly {
+				overrideCheckOnly = false
+			}
+		} else if !strings.HasPrefix(tline, "#") {
+			key := noteLineKey(section, tline)
+			oline, ok := overrideLines[section][key]
+			if !ok && section == note.INISectionService {
+				// override files may name services without
+				// the suffix '.service'
+				oline, ok = overrideLines[section][strings.TrimSuffix(key, ".service")]
+			}
+			if ok {
+				resolved = append(resolved, "O "+oline)
+				continue
+			}
 		}
+		resolved = append(resolved, "  "+line)
 	}
-	// print footer
-	printTableFooter(writer, header, footnote, reminder, hasDiff)
+	if overrideCheckOnly {
+		resolved = append(resolved, "", fmt.Sprintf("O [%s]", note.INISectionCheckOnly))
+	}
+	return resolved
 }
 
-// sortNoteComparisonsOutput sorts the output of the Note comparison
-// the reminder section should be the last one
-func sortNoteComparisonsOutput(noteCompare map[string]map[string]note.FieldComparison) []string {
-	skeys := make([]string, 0, len(noteCompare))
-	rkeys := make([]string, 0, len(noteCompare))
-	// sort output
-	for noteID, comparisons := range noteCompare {
-		for _, comparison := range comparisons {
-			if comparison.ReflectFieldName == "Inform" {
-				// skip inform map to avoid double entries in verify table
+// resolveNoteIncludes appends the parameter lines of the included Note
+// definition files to the content of a Note definition file. Parameters
+// redefined by the Note or by a later included file are left out, as their
+// values are not used
+func resolveNoteIncludes(content string, includeFiles []string) (string, error) {
+	defined := make(map[string]bool)
+	addKeys := func(cont string) {
+		section := ""
+		for _, line := range strings.Split(cont, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
 				continue
 			}
-			if len(comparison.ReflectMapKey) != 0 && comparison.ReflectFieldName != "OverrideParams" {
-				if comparison.ReflectMapKey != "reminder" {
-					skeys = append(skeys, noteID+"§"+comparison.ReflectMapKey)
-				} else {
-					rkeys = append(rkeys, noteID+"§"+comparison.ReflectMapKey)
-				}
+			if line[0] == '[' {
+				section = strings.Trim(line, "[]")
+			} else if key := noteLineKey(section, line); key != "" {
+				defined[section+"§"+key] = true
 			}
 		}
 	}
-	sort.Strings(skeys)
-	for _, rem := range rkeys {
-		skeys = append(skeys, rem)
+	addKeys(content)
+	blocks := make([]string, len(includeFiles))
+	for cnt := len(includeFiles) - 1; cnt >= 0; cnt-- {
+		incCont, err := ioutil.ReadFile(includeFiles[cnt])
+		if err != nil {
+			return content, err
+		}
+		block := ""
+		section := ""
+		sectionPrinted := false
+		for _, line := range strings.Split(string(incCont), "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if line[0] == '[' {
+				section = strings.Trim(line, "[]")
+				sectionPrinted = false
+				continue
+			}
+			switch section {
+			case note.INISectionVersion, note.INISectionReminder, note.INISectionCheckOnly, note.INISectionTags, note.INISectionRequires, note.INISectionInclude:
+				// only the parameter sections are inherited
+				continue
+			}
+			if key := noteLineKey(section, line); key == "" || defined[section+"§"+key] {
+				continue
+			}
+			if !sectionPrinted {
+				block = block + fmt.Sprintf("[%s]\n", section)
+				sectionPrinted = true
+			}
+			block = block + line + "\n"
+		}
+		addKeys(string(incCont))
+		if block != "" {
+			blocks[cnt] = fmt.Sprintf("\n# included from '%s'\n%s", includeFiles[cnt], block)
+		}
 	}
-	return skeys
+	return strings.TrimRight(content, "\n") + "\n" + strings.Join(blocks, ""), nil
 }
 
-// setupTableFormat sets the format of the table columns dependent on the content
-func setupTableFormat(skeys []string, noteField string, noteCompare map[string]map[string]note.FieldComparison, printComp bool) (int, int, int, int, int, string) {
-	var fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4 int
-	format := "\t%s : %s\n"
-	// define start values for the column width
-	if printComp {
-		// verify
-		fmtlen0 = 16
-		fmtlen1 = 12
-		fmtlen2 = 9
-		fmtlen3 = 9
-		fmtlen4 = 7
-	} else {
-		// simulate
-		fmtlen1 = 12
-		fmtlen2 = 10
-		fmtlen3 = 15
-		fmtlen4 = 9
+// noteLineKey returns the parameter name of a line of a Note definition or
+// override file the same way as txtparser.ParseINI identifies the parameter
+func noteLineKey(section, line string) string {
+	key, _ := noteLineParam(section, line)
+	return key
+}
+
+// noteLineParam returns the parameter name and the operator of a line of a
+// Note definition or override file
+func noteLineParam(section, line string) (string, string) {
+	switch section {
+	case note.INISectionRpm:
+		if fields := strings.Fields(line); len(fields) != 0 {
+			return "rpm:" + fields[0], ""
+		}
+		return "", ""
+	case note.INISectionGrub:
+		if kov := txtparser.RegexKeyOperatorValue.FindStringSubmatch(line); kov != nil {
+			return "grub:" + kov[1], kov[2]
+		}
+		return "grub:" + line, "="
+	case note.INISectionBlock:
+		if kopv := txtparser.RegexBlockDevicePattern.FindStringSubmatch(line); kopv != nil {
+			return fmt.Sprintf("%s[%s]", kopv[1], strings.Join(strings.Fields(kopv[2]), " ")), kopv[3]
+		}
+	case note.INISectionSystemd:
+		if kupv := txtparser.RegexSystemdProperty.FindStringSubmatch(line); kupv != nil {
+			return kupv[1] + ":" + kupv[2], kupv[3]
+		}
 	}
+	if kov := txtparser.RegexKeyOperatorValue.FindStringSubmatch(line); kov != nil {
+		return kov[1], kov[2]
+	}
+	return "", ""
+}
 
-	for _, skey := range skeys {
-		keyFields := strings.Split(skey, "§")
-		noteID := keyFields[0]
-		comparisons := noteCompare[noteID]
-		for _, comparison := range comparisons {
-			if comparison.ReflectMapKey == "reminder" {
-				continue
-			}
-			if printComp {
-				// verify
-				if len(noteField) > fmtlen0 {
-					fmtlen0 = len(noteField)
-				}
-				// 3:override, 1:mapkey, 2:expval, 4:actval
-				fmtlen3, fmtlen1, fmtlen2, fmtlen4 = setWidthOfColums(comparison, fmtlen3, fmtlen1, fmtlen2, fmtlen4)
-				format = "   %-" + strconv.Itoa(fmtlen0) + "s | %-" + strconv.Itoa(fmtlen1) + "s | %-" + strconv.Itoa(fmtlen2) + "s | %-" + strconv.Itoa(fmtlen3) + "s | %-" + strconv.Itoa(fmtlen4) + "s | %2s\n"
-			} else {
-				// simulate
-				// 4:override, 1:mapkey, 3:expval, 2:actval
-				fmtlen4, fmtlen1, fmtlen3, fmtlen2 = setWidthOfColums(comparison, fmtlen4, fmtlen1, fmtlen3, fmtlen2)
-				format = "   %-" + strconv.Itoa(fmtlen1) + "s | %-" + strconv.Itoa(fmtlen2) + "s | %-" + strconv.Itoa(fmtlen3) + "s | %-" + strconv.Itoa(fmtlen4) + "s | %2s\n"
-			}
+// NoteActionExport writes a self-contained Note definition of the Note,
+// which contains the parameters of the included Notes and the values of the
+// override files, to the file or, if no file is given, to the writer. The
+// file can be placed in /etc/saptune/extra of another host
+func NoteActionExport(writer io.Writer, noteID, fileName string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	content, err := exportNote(noteID, tuneApp)
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	if fileName == "" {
+		fmt.Fprint(writer, content)
+		return nil
+	}
+	if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+		return newExitError("Failed to write file '%s' - %v", fileName, err)
+	}
+	fmt.Fprintf(writer, "Note %s has been exported to '%s'.\nCopy the file to '%s%s.conf' on the target host to use it there.\n", noteID, fileName, ExtraTuningSheets, noteID)
+	return nil
+}
+
+// exportNote returns the flattened Note definition of the Note. The
+// parameters of the included Notes are appended, the values of the override
+// files replace the values of the Note definition and the section
+// '[include]' is left out. Parameters left untouched by an override file
+// are commented out. The result is validated like a Note definition file
+func exportNote(noteID string, tuneApp *app.App) (string, error) {
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		return "", err
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return "", fmt.Errorf("Note %s has no Note definition file, nothing to export", noteID)
+	}
+	cont, err := ioutil.ReadFile(iniNote.ConfFilePath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read file '%s' - %v", iniNote.ConfFilePath, err)
+	}
+	content := string(cont)
+	if len(iniNote.IncludeFiles) != 0 {
+		if content, err = resolveNoteIncludes(content, iniNote.IncludeFiles); err != nil {
+			return "", fmt.Errorf("Failed to resolve the includes of Note %s - %v", noteID, err)
 		}
 	}
-	return fmtlen0, fmtlen1, fmtlen2, fmtlen3, fmtlen4, format
+	overrideFiles := note.OverrideFiles(noteID)
+	ocont := ""
+	for _, overrideFile := range overrideFiles {
+		layer, err := ioutil.ReadFile(overrideFile)
+		if err != nil {
+			return "", fmt.Errorf("Failed to read file '%s' - %v", overrideFile, err)
+		}
+		ocont = ocont + string(layer) + "\n"
+	}
+
+	buildVersion := saptuneBuildVersion
+	if buildVersion == "" {
+		buildVersion = "unknown"
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	exported := fmt.Sprintf("# Note %s exported by saptune %s on host %s at %s\n", noteID, buildVersion, host, time.Now().Format(time.RFC3339))
+	exported = exported + fmt.Sprintf("# Note definition file: %s\n", iniNote.ConfFilePath)
+	for _, includeFile := range iniNote.IncludeFiles {
+		exported = exported + fmt.Sprintf("# included Note definition file: %s\n", includeFile)
+	}
+	for _, overrideFile := range overrideFiles {
+		exported = exported + fmt.Sprintf("# override file: %s\n", overrideFile)
+	}
+	exported = exported + "\n"
+	section := ""
+	for _, line := range resolveNoteOverride(content, ocont) {
+		// strip the markers of resolveNoteOverride
+		fromOverride := strings.HasPrefix(line, "O ")
+		if len(line) >= 2 {
+			line = line[2:]
+		}
+		tline := strings.TrimSpace(line)
+		if strings.HasPrefix(tline, "[") {
+			section, _ = txtparser.SplitSectionName(strings.Trim(tline, "[]"))
+		}
+		if section == note.INISectionInclude {
+			continue
+		}
+		if kov := txtparser.RegexKeyOperatorValue.FindStringSubmatch(tline); fromOverride && kov != nil && kov[3] == "" {
+			exported = exported + "# untouched by the override file: " + line + "\n"
+			continue
+		}
+		exported = exported + line + "\n"
+	}
+	if problems := note.ValidateNoteDefinition(noteID, exported); len(problems) != 0 {
+		msgs := make([]string, 0, len(problems))
+		for _, prob := range problems {
+			msgs = append(msgs, prob.String())
+		}
+		return "", fmt.Errorf("The exported definition of Note %s is not valid:\n%s", noteID, strings.Join(msgs, "\n"))
+	}
+	return exported, nil
 }
 
-// printHeadline prints a headline for the table
-func printHeadline(writer io.Writer, header, id string, tuningOpts note.TuningOptions) {
-	if header != "NONE" {
-		nName := ""
-		if len(tuningOptions) > 0 {
-			nName = tuningOptions[id].Name()
+// NoteActionValidate checks the Note definition file and the related
+// override file of a Note for syntax errors, unknown parameters and wrong
+// values without touching the system
+func NoteActionValidate(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		return newExitError("%v", err)
+	}
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return newExitError("Note %s has no Note definition file, nothing to validate.", noteID)
+	}
+	fileNames := append([]string{iniNote.ConfFilePath}, note.OverrideFiles(noteID)...)
+	problemCnt := 0
+	for _, fileName := range fileNames {
+		problems, err := note.ValidateNoteFile(fileName)
+		if err != nil {
+			return newExitError("Failed to read file '%s' - %v", fileName, err)
 		}
-		fmt.Fprintf(writer, "\n%s - %s \n\n", id, nName)
+		for _, prob := range problems {
+			fmt.Fprintf(writer, "%s\n", prob)
+		}
+		problemCnt = problemCnt + len(problems)
 	}
+	if problemCnt != 0 {
+		return newExitError("Found %d problem(s) in the definition of Note %s.", problemCnt, noteID)
+	}
+	fmt.Fprintf(writer, "The definition of Note %s is valid (%s).\n", noteID, strings.Join(fileNames, ", "))
+	return nil
 }
 
-// printTableHeader prints the header of the table
-func printTableHeader(writer io.Writer, format string, col0, col1, col2, col3, col4 int, printComp bool) {
-	if printComp {
-		// verify
-		fmt.Fprintf(writer, format, "SAPNote, Version", "Parameter", "Expected", "Override", "Actual", "Compliant")
-		for i := 0; i < col0+col1+col2+col3+col4+28; i++ {
-			if i == 3+col0+1 || i == 3+col0+3+col1+1 || i == 3+col0+3+col1+4+col2 || i == 3+col0+3+col1+4+col2+2+col3+1 || i == 3+col0+3+col1+4+col2+2+col3+3+col4+1 {
-				fmt.Fprintf(writer, "+")
-			} else {
-				fmt.Fprintf(writer, "-")
+// NoteActionDiff compares the parameter values of two Note definitions and
+// prints the parameters, which differ
+func NoteActionDiff(writer io.Writer, noteID1, noteID2 string, tuneApp *app.App) error {
+	if noteID1 == "" || noteID2 == "" {
+		return usageError()
+	}
+	params1, err := getNoteDefinedParams(noteID1, tuneApp)
+	if err != nil {
+		return err
+	}
+	params2, err := getNoteDefinedParams(noteID2, tuneApp)
+	if err != nil {
+		return err
+	}
+
+	// collect the union of the sections and parameters of both notes.
+	// A parameter defined in both notes, but in different sections, is
+	// listed for each section
+	keySections := make(map[string]map[string]bool)
+	for _, params := range []map[string]map[string]string{params1, params2} {
+		for section, sectionParams := range params {
+			for key := range sectionParams {
+				if keySections[key] == nil {
+					keySections[key] = make(map[string]bool)
+				}
+				keySections[key][section] = true
 			}
 		}
-		fmt.Fprintf(writer, "\n")
-	} else {
-		// simulate
-		fmt.Fprintf(writer, format, "Parameter", "Value set", "Value expected", "Override", "Comment")
-		for i := 0; i < col1+col2+col3+col4+28; i++ {
-			if i == 3+col1+1 || i == 3+col1+3+col2+1 || i == 3+col1+3+col2+3+col3+1 || i == 3+col1+3+col2+3+col3+3+col4+1 {
-				fmt.Fprintf(writer, "+")
-			} else {
-				fmt.Fprintf(writer, "-")
+	}
+	diffs := make([]paramDiff, 0)
+	for key, sections := range keySections {
+		for section := range sections {
+			val1, ok1 := params1[section][key]
+			val2, ok2 := params2[section][key]
+			if ok1 == ok2 && val1 == val2 {
+				continue
+			}
+			name := key
+			if len(sections) > 1 {
+				name = fmt.Sprintf("[%s] %s", section, key)
 			}
+			diffs = append(diffs, paramDiff{name: name, value1: strings.Replace(val1, "\t", " ", -1), value2: strings.Replace(val2, "\t", " ", -1)})
 		}
-		fmt.Fprintf(writer, "\n")
 	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].name < diffs[j].name })
+
+	fmt.Fprintf(writer, "\nDifferences between Note %s and Note %s:\n\n", noteID1, noteID2)
+	printParamDiffTable(writer, diffs, noteID1, noteID2)
+	return nil
 }
 
-// prepareFootnote prepares the content of the last column and the
-// corresponding footnotes
-func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform string, footnote []string) (string, string, []string) {
-	switch comparison.ActualValue {
-	case "all:none":
-		compliant = compliant + " [1]"
-		comment = comment + " [1]"
-		footnote[0] = footnote1
-	case "NA":
-		compliant = compliant + " [2]"
-		comment = comment + " [2]"
-		footnote[1] = footnote2
+// paramDiff is a row of the table of differing parameter values
+type paramDiff struct {
+	name   string
+	value1 string
+	value2 string
+}
+
+// printParamDiffTable prints the differing parameter values as table
+func printParamDiffTable(writer io.Writer, diffs []paramDiff, head1, head2 string) {
+	if len(diffs) == 0 {
+		fmt.Fprintf(writer, "   (no difference)\n\n")
+		return
+	}
+	// setup table format values
+	fmtlen1, fmtlen2, fmtlen3 := len("Parameter"), len(head1), len(head2)
+	for _, diff := range diffs {
+		if len(diff.name) > fmtlen1 {
+			fmtlen1 = len(diff.name)
+		}
+		if len(diff.value1) > fmtlen2 {
+			fmtlen2 = len(diff.value1)
+		}
+		if len(diff.value2) > fmtlen3 {
+			fmtlen3 = len(diff.value2)
+		}
 	}
-	if strings.Contains(comparison.ReflectMapKey, "rpm") || strings.Contains(comparison.ReflectMapKey, "grub") {
-		compliant = compliant + " [3]"
-		comment = comment + " [3]"
-		footnote[2] = footnote3
+	format := "   %-" + strconv.Itoa(fmtlen1) + "s | %-" + strconv.Itoa(fmtlen2) + "s | %-" + strconv.Itoa(fmtlen3) + "s\n"
+	fmt.Fprintf(writer, format, "Parameter", head1, head2)
+	fmt.Fprintf(writer, "%s+%s+%s\n", strings.Repeat("-", 3+fmtlen1+1), strings.Repeat("-", fmtlen2+2), strings.Repeat("-", fmtlen3+1))
+	for _, diff := range diffs {
+		fmt.Fprintf(writer, format, diff.name, diff.value1, diff.value2)
 	}
+	fmt.Fprintf(writer, "\n")
+}
 
-	// check inform map for special settings
-	// ANGI: future - check for 'nil', if using noteComparisons[noteID][fmt.Sprintf("%s[%s]", "Inform", comparison.ReflectMapKey)].ActualValue.(string) in general
-	if comparison.ReflectMapKey == "force_latency" && inform == "hasDiffs" {
-		compliant = "no [4]"
-		comment = comment + " [4]"
-		footnote[3] = footnote4
+// getNoteDefinedParams returns the parameter values defined by a Note
+// definition per section, including the values from an override file
+func getNoteDefinedParams(noteID string, tuneApp *app.App) (map[string]map[string]string, error) {
+	aNote, err := tuneApp.GetNoteByID(noteID)
+	if err != nil {
+		return nil, newExitError("%v", err)
 	}
-	var isSched = regexp.MustCompile(`^IO_SCHEDULER_\w+$`)
-	if isSched.MatchString(comparison.ReflectMapKey) && inform == "NA" {
-		compliant = compliant + " [5]"
-		comment = comment + " [5]"
-		footnote[4] = footnote5
+	iniNote, ok := aNote.(note.INISettings)
+	if !ok {
+		return nil, newExitError("Note %s is not based on a Note definition file.", noteID)
 	}
-	return compliant, comment, footnote
+	params, err := iniNote.DefinedSectionParams()
+	if err != nil {
+		return nil, newExitError("Failed to read the definition of Note %s - %v", noteID, err)
+	}
+	return params, nil
 }
 
-// printTableFooter prints the footer of the table
-// footnotes and reminder section
-func printTableFooter(writer io.Writer, header string, footnote []string, reminder map[string]string, hasDiff bool) {
-	if header != "NONE" && !hasDiff {
-		fmt.Fprintf(writer, "\n   (no change)\n")
+// NoteActionConflicts prints all parameters, which are set to different
+// values by more than one of the enabled notes, together with the note,
+// whose value wins because of the current apply order
+func NoteActionConflicts(writer io.Writer, tuneApp *app.App) error {
+	conflicts, err := tuneApp.NoteConflicts()
+	if err != nil {
+		return newExitError("%v", err)
 	}
-	for _, fn := range footnote {
-		if fn != "" {
-			fmt.Fprintf(writer, "\n %s", fn)
+	if len(conflicts) == 0 {
+		fmt.Fprintf(writer, "\nNo conflicting parameters found in the enabled notes.\n\n")
+		return nil
+	}
+	// setup table format values
+	fmtlen1, fmtlen2, fmtlen3 := len("Parameter"), len("Note"), len("Value")
+	for _, conflict := range conflicts {
+		if len(conflict.Parameter) > fmtlen1 {
+			fmtlen1 = len(conflict.Parameter)
+		}
+		for _, noteID := range conflict.NoteIDs {
+			if len(noteID) > fmtlen2 {
+				fmtlen2 = len(noteID)
+			}
+			if len(conflict.Values[noteID]) > fmtlen3 {
+				fmtlen3 = len(conflict.Values[noteID])
+			}
 		}
 	}
-	fmt.Fprintf(writer, "\n\n")
-	for noteID, reminde := range reminder {
-		if reminde != "" {
-			reminderHead := fmt.Sprintf("Attention for SAP Note %s:\nHints or values not yet handled by saptune. So please read carefully, check and set manually, if needed:\n", noteID)
-			fmt.Fprintf(writer, "%s\n", setRedText+reminderHead+reminde+resetTextColor)
+	format := "   %-" + strconv.Itoa(fmtlen1) + "s | %-" + strconv.Itoa(fmtlen2) + "s | %-" + strconv.Itoa(fmtlen3) + "s | %s\n"
+	fmt.Fprintf(writer, "\nParameters set to different values by the enabled notes:\n\n")
+	fmt.Fprintf(writer, format, "Parameter", "Note", "Value", "Wins")
+	fmt.Fprintf(writer, "%s+%s+%s+%s\n", strings.Repeat("-", 3+fmtlen1+1), strings.Repeat("-", fmtlen2+2), strings.Repeat("-", fmtlen3+2), strings.Repeat("-", 5))
+	for _, conflict := range conflicts {
+		param := conflict.Parameter
+		for _, noteID := range conflict.NoteIDs {
+			wins := ""
+			if noteID == conflict.Winner {
+				wins = "yes"
+			}
+			fmt.Fprintf(writer, format, param, noteID, strings.Replace(conflict.Values[noteID], "\t", " ", -1), wins)
+			// print the parameter name only in the first line
+			param = ""
 		}
 	}
+	fmt.Fprintf(writer, "\nThe value of the note applied last wins.")
+	tuneApp.PrintNoteApplyOrder(writer)
+	return nil
 }
 
-// setWidthOfColums sets the width of the columns for verify and simulate
-// depending on the highest number of characters of the content to be
-// displayed
-// c1:override, c2:mapkey, c3:expval, c4:actval
-func setWidthOfColums(compare note.FieldComparison, c1, c2, c3, c4 int) (int, int, int, int) {
-	if len(compare.ReflectMapKey) != 0 {
-		if compare.ReflectFieldName == "OverrideParams" && len(compare.ActualValueJS) > c1 {
-			c1 = len(compare.ActualValueJS)
-			return c1, c2, c3, c4
-		}
-		if len(compare.ReflectMapKey) > c2 {
-			c2 = len(compare.ReflectMapKey)
+// NoteActionMove changes the position of a Note in the order of applied
+// notes to directly 'before' or 'after' another Note
+func NoteActionMove(writer io.Writer, noteID, where, refNoteID string, tuneApp *app.App) error {
+	if noteID == "" || where == "" || refNoteID == "" {
+		return usageError()
+	}
+	if err := tuneApp.MoveNoteInApplyOrder(noteID, where, refNoteID); err != nil {
+		return newExitError("Failed to move Note %s: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "Note %s moved %s Note %s.\n", noteID, where, refNoteID)
+	tuneApp.PrintNoteApplyOrder(writer)
+	fmt.Fprintf(writer, "The new order takes effect the next time the enabled notes are applied.\n")
+	return nil
+}
+
+// NoteActionRename renames a vendor or customer specific Note definition
+// from ExtraTuningSheets including its override file and the references to
+// the Note in the configuration
+func NoteActionRename(writer io.Writer, noteID, newNoteID string, tuneApp *app.App) error {
+	if noteID == "" || newNoteID == "" {
+		return usageError()
+	}
+	if err := tuneApp.RenameNote(noteID, newNoteID, ExtraTuningSheets, OverrideTuningSheets); err != nil {
+		return newExitError("Failed to rename Note %s: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "Note %s has been renamed to %s.\n", noteID, newNoteID)
+	tuneApp.PrintNoteApplyOrder(writer)
+	return nil
+}
+
+// NoteActionDelete removes the Note definition file of a vendor or customer
+// specific Note from ExtraTuningSheets and its override file after asking
+// for confirmation. In contrast to 'revert' the Note is no longer available
+// afterwards. The confirmation is skipped, if 'assumeYes' is set
+func NoteActionDelete(reader io.Reader, writer io.Writer, noteID string, assumeYes, interactive bool, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	files, err := tuneApp.DeletableNoteFiles(noteID, ExtraTuningSheets, OverrideTuningSheets)
+	if err != nil {
+		return newExitError("Failed to delete Note %s: %v", noteID, err)
+	}
+	if !assumeYes {
+		if !interactive {
+			return newExitError("Refusing to delete note %s without confirmation. Use the option '--yes' to delete the note non-interactively.", noteID)
 		}
-		if len(compare.ExpectedValueJS) > c3 {
-			c3 = len(compare.ExpectedValueJS)
+		fmt.Fprintf(writer, "The following files will be deleted:\n")
+		for _, fileName := range files {
+			fmt.Fprintf(writer, "    %s\n", fileName)
 		}
-		if len(compare.ActualValueJS) > c4 {
-			c4 = len(compare.ActualValueJS)
+		fmt.Fprintf(writer, "Delete the note %s? [y/n]: ", noteID)
+		line, _ := bufio.NewReader(reader).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(line)) != "y" {
+			fmt.Fprintf(writer, "\nThe note %s has not been deleted.\n", noteID)
+			return nil
 		}
 	}
-	return c1, c2, c3, c4
+	if err := tuneApp.DeleteNote(noteID, ExtraTuningSheets, OverrideTuningSheets); err != nil {
+		return newExitError("Failed to delete Note %s: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "Note %s has been deleted.\n", noteID)
+	return nil
 }
 
-// VerifyAllParameters Verify that all system parameters do not deviate from any of the enabled solutions/notes.
-func VerifyAllParameters() {
-	if len(tuneApp.NoteApplyOrder) == 0 {
-		fmt.Println("No notes or solutions enabled, nothing to verify.")
-	} else {
-		unsatisfiedNotes, comparisons, err := tuneApp.VerifyAll()
-		if err != nil {
-			errorExit("Failed to inspect the current system: %v", err)
-		}
-		PrintNoteFields(os.Stdout, "NONE", comparisons, true)
-		tuneApp.PrintNoteApplyOrder(os.Stdout)
-		if len(unsatisfiedNotes) == 0 {
-			fmt.Println("The running system is currently well-tuned according to all of the enabled notes.")
-		} else {
-			errorExit("The parameters listed above have deviated from SAP/SUSE recommendations.")
-		}
+// NoteActionRevert reverts all parameter settings of a Note back to the
+// state before 'apply'
+func NoteActionRevert(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	err := tuneApp.RevertNote(noteID, true)
+	recordHistory(tuneApp, "revert", "note", noteID, err)
+	if err != nil {
+		return newExitError("Failed to revert note %s: %v", noteID, err)
 	}
+	fmt.Fprintf(writer, "Parameters tuned by the note have been successfully reverted.\n")
+	fmt.Fprintf(writer, "Please note: the reverted note may still show up in list of enabled notes, if an enabled solution refers to it.\n")
+	return nil
 }
 
-// NoteAction  Note actions like apply, revert, verify asm.
-func NoteAction(actionName, noteID string) {
+// NoteActionRevertParameter reverts a single parameter of an applied Note to
+// the value it had before the Note was applied
+func NoteActionRevertParameter(writer io.Writer, noteID, paramName string, tuneApp *app.App) error {
+	err := tuneApp.RevertNoteParameter(noteID, paramName)
+	recordHistory(tuneApp, "revert parameter "+paramName, "note", noteID, err)
+	if err != nil {
+		return newExitError("Failed to revert parameter '%s' of note %s: %v", paramName, noteID, err)
+	}
+	fmt.Fprintf(writer, "Parameter '%s' tuned by the note %s has been successfully reverted.\n", paramName, noteID)
+	fmt.Fprintf(writer, "Please note: the note is still enabled, so 'saptune note verify' will report the parameter as deviating.\n")
+	return nil
+}
+
+// NoteActionRevertToDefault reverts a Note and sets the sysctl parameters
+// tuned by the Note to their default values instead of the values saved
+// before the Note was applied
+func NoteActionRevertToDefault(writer io.Writer, noteID string, tuneApp *app.App) error {
+	if noteID == "" {
+		return usageError()
+	}
+	defaults, saved, err := tuneApp.RevertNoteToDefault(noteID)
+	recordHistory(tuneApp, "revert --to-default", "note", noteID, err)
+	if err != nil {
+		return newExitError("Failed to revert note %s to the default values: %v", noteID, err)
+	}
+	fmt.Fprintf(writer, "Parameters tuned by the note have been successfully reverted.\n")
+	if len(defaults) != 0 {
+		fmt.Fprintf(writer, "Set to their default values: %s\n", strings.Join(defaults, " "))
+	}
+	if len(saved) != 0 {
+		fmt.Fprintf(writer, "Reverted to the values saved before the note was applied, because the default value is unknown or another applied note tunes them: %s\n", strings.Join(saved, " "))
+	}
+	fmt.Fprintf(writer, "Please note: parameters of other sections than [sysctl] are always reverted to the values saved before the note was applied.\n")
+	return nil
+}
+
+// SolutionAction  Solution actions like apply, revert, verify asm.
+func SolutionAction(actionName, solName string) {
 	switch actionName {
 	case "apply":
-		NoteActionApply(os.Stdout, noteID, tuneApp)
+		exitOnError(SolutionActionApply(solName, cliNoteIDs("only"), cliNoteIDs("except")))
 	case "list":
-		NoteActionList(os.Stdout, tuneApp, tuningOptions)
+		SolutionActionList(os.Stdout, cliFlag("notes"), tuneApp, tuningOptions)
 	case "verify":
-		NoteActionVerify(os.Stdout, noteID, tuneApp)
+		exitOnError(reportAction(func(writer io.Writer) error {
+			return SolutionActionVerify(writer, solName)
+		}))
 	case "simulate":
-		NoteActionSimulate(os.Stdout, noteID, tuneApp)
-	case "customise":
-		NoteActionCustomise(noteID)
+		exitOnError(reportAction(func(writer io.Writer) error {
+			return SolutionActionSimulate(writer, solName)
+		}))
+	case "revert":
+		if cliFlag("all") {
+			if solName != "" {
+				PrintHelpAndExit(1)
+			}
+			exitOnError(SolutionActionRevertAll(os.Stdout, tuneApp))
+			return
+		}
+		exitOnError(SolutionActionRevert(solName))
 	case "create":
-		NoteActionCreate(noteID)
+		exitOnError(SolutionActionCreate(os.Stdout, solName, cliArgsFrom(4), tuneApp))
 	case "show":
-		NoteActionShow(noteID)
-	case "revert":
-		NoteActionRevert(os.Stdout, noteID, tuneApp)
+		exitOnError(SolutionActionShow(os.Stdout, solName, tuneApp, tuningOptions))
+	case "diff":
+		exitOnError(SolutionActionDiff(os.Stdout, solName, cliArg(4), tuneApp))
 	default:
 		PrintHelpAndExit(1)
 	}
 }
 
-// NoteActionApply applies Note parameter settings to the system
-func NoteActionApply(writer io.Writer, noteID string, tuneApp *app.App) {
-	if noteID == "" {
-		PrintHelpAndExit(1)
+// SolutionActionCreate creates a user-defined solution containing the given
+// notes
+func SolutionActionCreate(writer io.Writer, solName string, noteIDs []string, tuneApp *app.App) error {
+	if solName == "" || len(noteIDs) == 0 {
+		return usageError()
 	}
-	// Do not apply the note, if it was applied before
-	// Otherwise, the state file (serialised parameters) will be
-	// overwritten, and it will no longer be possible to revert the
-	// note to the state before it was tuned.
-	_, err := os.Stat(tuneApp.State.GetPathToNote(noteID))
-	if err == nil {
-		// state file for note already exists
-		// do not apply the note again
-		system.InfoLog("note '%s' already applied. Nothing to do", noteID)
-		os.Exit(0)
+	if _, err := tuneApp.GetSolutionByName(solName); err == nil {
+		return newExitError("Solution '%s' already exists. Please choose another solution name.", solName)
 	}
-	if err := tuneApp.TuneNote(noteID); err != nil {
-		errorExit("Failed to tune for note %s: %v", noteID, err)
+	for _, noteID := range noteIDs {
+		if _, err := tuneApp.GetNoteByID(noteID); err != nil {
+			return newExitError("%v", err)
+		}
 	}
-	fmt.Fprintf(writer, "The note has been applied successfully.\n")
-	if !system.SystemctlIsRunning(TunedService) || system.GetTunedProfile() != TunedProfileName {
-		fmt.Fprintf(writer, "\nRemember: if you wish to automatically activate the solution's tuning options after a reboot,"+
-			"you must instruct saptune to configure \"tuned\" daemon by running:"+
-			"\n    saptune daemon start\n")
+	fileName, err := solution.CreateCustomSolution(solution.ExtraSolutionSheets, solName, noteIDs)
+	if err != nil {
+		return newExitError("Failed to create solution '%s': %v", solName, err)
 	}
+	fmt.Fprintf(writer, "Solution '%s' with the notes '%s' has been created in file '%s'.\n", solName, strings.Join(noteIDs, " "), fileName)
+	fmt.Fprintf(writer, "Use 'saptune solution apply %s' to tune the system for the new solution.\n", solName)
+	return nil
 }
 
-// NoteActionList lists all available Note definitions
-func NoteActionList(writer io.Writer, tuneApp *app.App, tOptions note.TuningOptions) {
-	fmt.Fprintf(writer, "\nAll notes (+ denotes manually enabled notes, * denotes notes enabled by solutions, - denotes notes enabled by solutions but reverted manually later, O denotes override file exists for note):\n")
-	solutionNoteIDs := tuneApp.GetSortedSolutionEnabledNotes()
-	for _, noteID := range tOptions.GetSortedIDs() {
-		noteObj := tOptions[noteID]
-		format := "\t%s\t\t%s\n"
-		if len(noteID) >= 8 {
-			format = "\t%s\t%s\n"
-		}
-		if _, err := os.Stat(fmt.Sprintf("%s%s", OverrideTuningSheets, noteID)); err == nil {
-			format = " O" + format
-		}
-		if i := sort.SearchStrings(solutionNoteIDs, noteID); i < len(solutionNoteIDs) && solutionNoteIDs[i] == noteID {
-			j := tuneApp.PositionInNoteApplyOrder(noteID)
-			if j < 0 { // noteID was reverted manually
-				format = " " + setGreenText + "-" + format + resetTextColor
-			} else {
-				format = " " + setGreenText + "*" + format + resetTextColor
-			}
-		} else if i := sort.SearchStrings(tuneApp.TuneForNotes, noteID); i < len(tuneApp.TuneForNotes) && tuneApp.TuneForNotes[i] == noteID {
-			format = " " + setGreenText + "+" + format + resetTextColor
-		}
-		fmt.Fprintf(writer, format, noteID, noteObj.Name())
-	}
-	tuneApp.PrintNoteApplyOrder(writer)
-	if !system.SystemctlIsRunning(TunedService) || system.GetTunedProfile() != TunedProfileName {
-		fmt.Fprintf(writer, "Remember: if you wish to automatically activate the solution's tuning options after a reboot,"+
-			"you must instruct saptune to configure \"tuned\" daemon by running:"+
-			"\n    saptune daemon start\n")
-	}
+// solutionParam is the value of a parameter after all notes of a solution
+// are applied together with the note, which sets the value
+type solutionParam struct {
+	value  string
+	noteID string
 }
 
-// NoteActionVerify compares all parameter settings from a Note definition
-// against the system settings
-func NoteActionVerify(writer io.Writer, noteID string, tuneApp *app.App) {
-	if noteID == "" {
-		VerifyAllParameters()
-	} else {
-		// Check system parameters against the specified note, no matter the note has been tuned for or not.
-		conforming, comparisons, _, err := tuneApp.VerifyNote(noteID)
+// solutionEffectiveParams returns the values of the parameters after all
+// notes of the solution are applied in the order of the solution. If more
+// than one note defines a parameter, the value of the last note wins.
+// Notes without Note definition file are skipped
+func solutionEffectiveParams(noteIDs []string, tuneApp *app.App) (map[string]solutionParam, error) {
+	params := make(map[string]solutionParam)
+	for _, noteID := range noteIDs {
+		aNote, err := tuneApp.GetNoteByID(noteID)
 		if err != nil {
-			errorExit("Failed to test the current system against the specified note: %v", err)
+			return nil, newExitError("%v", err)
 		}
-		noteComp := make(map[string]map[string]note.FieldComparison)
-		noteComp[noteID] = comparisons
-		PrintNoteFields(writer, "HEAD", noteComp, true)
-		tuneApp.PrintNoteApplyOrder(writer)
-		if !conforming {
-			errorExit("The parameters listed above have deviated from the specified note.\n")
-		} else {
-			fmt.Fprintf(writer, "The system fully conforms to the specified note.\n")
+		iniNote, ok := aNote.(note.INISettings)
+		if !ok {
+			continue
+		}
+		noteParams, err := iniNote.DefinedParams()
+		if err != nil {
+			return nil, newExitError("Failed to read the definition of Note %s - %v", noteID, err)
+		}
+		for key, value := range noteParams {
+			params[key] = solutionParam{value: value, noteID: noteID}
 		}
 	}
+	return params, nil
 }
 
-// NoteActionSimulate shows all changes that will be applied to the system if
-// the Note will be applied.
-func NoteActionSimulate(writer io.Writer, noteID string, tuneApp *app.App) {
-	if noteID == "" {
-		PrintHelpAndExit(1)
+// SolutionActionDiff prints the notes, which are part of only one of the
+// two solutions, and the parameters of the notes shared by both solutions,
+// which end up with different values, because another note of one of the
+// solutions sets them to a different value
+func SolutionActionDiff(writer io.Writer, solName1, solName2 string, tuneApp *app.App) error {
+	if solName1 == "" || solName2 == "" {
+		return usageError()
 	}
-	// Run verify and print out all fields of the note
-	if _, comparisons, _, err := tuneApp.VerifyNote(noteID); err != nil {
-		errorExit("Failed to test the current system against the specified note: %v", err)
-	} else {
-		fmt.Fprintf(writer, "If you run `saptune note apply %s`, the following changes will be applied to your system:\n", noteID)
-		noteComp := make(map[string]map[string]note.FieldComparison)
-		noteComp[noteID] = comparisons
-		PrintNoteFields(writer, "HEAD", noteComp, false)
+	notes1, err := tuneApp.GetSolutionByName(solName1)
+	if err != nil {
+		return newExitError("%v", err)
 	}
-}
-
-// NoteActionCustomise creates an override file and allows to editing the Note
-// definition file
-func NoteActionCustomise(noteID string) {
-	if noteID == "" {
-		PrintHelpAndExit(1)
+	notes2, err := tuneApp.GetSolutionByName(solName2)
+	if err != nil {
+		return newExitError("%v", err)
 	}
-	if _, err := tuneApp.GetNoteByID(noteID); err != nil {
-		errorExit("%v", err)
+	inSol1, inSol2 := make(map[string]bool), make(map[string]bool)
+	for _, noteID := range notes1 {
+		inSol1[noteID] = true
 	}
-	editFileName := ""
-	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		_, files := system.ListDir(ExtraTuningSheets, "")
-		for _, f := range files {
-			if strings.HasPrefix(f, noteID) {
-				fileName = fmt.Sprintf("%s%s", ExtraTuningSheets, f)
-			}
-		}
-		if _, err := os.Stat(fileName); os.IsNotExist(err) {
-			errorExit("Note %s not found in %s or %s.", noteID, NoteTuningSheets, ExtraTuningSheets)
-		} else if err != nil {
-			errorExit("Failed to read file '%s' - %v", fileName, err)
-		}
-	} else if err != nil {
-		errorExit("Failed to read file '%s' - %v", fileName, err)
+	for _, noteID := range notes2 {
+		inSol2[noteID] = true
 	}
-	ovFileName := fmt.Sprintf("%s%s", OverrideTuningSheets, noteID)
-	if _, err := os.Stat(ovFileName); os.IsNotExist(err) {
-		//copy file
-		err := system.CopyFile(fileName, ovFileName)
-		if err != nil {
-			errorExit("Problems while copying '%s' to '%s' - %v", fileName, ovFileName, err)
+	only1, only2, shared := make([]string, 0), make([]string, 0), make([]string, 0)
+	for _, noteID := range notes1 {
+		if inSol2[noteID] {
+			shared = append(shared, noteID)
+		} else {
+			only1 = append(only1, noteID)
 		}
-		editFileName = ovFileName
-	} else if err == nil {
-		system.InfoLog("Note override file already exists, using file '%s' as base for editing", ovFileName)
-		editFileName = ovFileName
-	} else {
-		errorExit("Failed to read file '%s' - %v", ovFileName, err)
-	}
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "/usr/bin/vim" // launch vim by default
 	}
-	i := tuneApp.PositionInNoteApplyOrder(noteID)
-	if i < 0 { // noteID not yet available
-		system.InfoLog("Do not forget to apply the just edited Note to get your changes to take effect\n")
-	} else { // noteID already applied
-		system.InfoLog("Your just edited Note is already applied. To get your changes to take effect, please 'revert' the Note and apply again.\n")
+	for _, noteID := range notes2 {
+		if !inSol1[noteID] {
+			only2 = append(only2, noteID)
+		}
 	}
-	if err := syscall.Exec(editor, []string{editor, editFileName}, os.Environ()); err != nil {
-		errorExit("Failed to start launch editor %s: %v", editor, err)
+	noteList := func(noteIDs []string) string {
+		if len(noteIDs) == 0 {
+			return "(none)"
+		}
+		return strings.Join(noteIDs, " ")
 	}
-	// if syscall.Exec returns 'nil' the execution of the program ends immediately
-}
+	fmt.Fprintf(writer, "\nDifferences between solution %s and solution %s:\n\n", solName1, solName2)
+	fmt.Fprintf(writer, "   Notes only in solution %s: %s\n", solName1, noteList(only1))
+	fmt.Fprintf(writer, "   Notes only in solution %s: %s\n", solName2, noteList(only2))
+	fmt.Fprintf(writer, "   Notes in both solutions: %s\n\n", noteList(shared))
 
-// NoteActionCreate helps the customer to create an own Note definition
-func NoteActionCreate(noteID string) {
-	if noteID == "" {
-		PrintHelpAndExit(1)
-	}
-	if _, err := tuneApp.GetNoteByID(noteID); err == nil {
-		errorExit("Note '%s' already exists. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, noteID)
-	}
-	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
-	if _, err := os.Stat(fileName); err == nil {
-		errorExit("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, NoteTuningSheets, noteID)
-	}
-	extraFileName := fmt.Sprintf("%s%s.conf", ExtraTuningSheets, noteID)
-	if _, err := os.Stat(extraFileName); err == nil {
-		errorExit("Note '%s' already exists in %s. Please use 'saptune note customise %s' instead to create an override file or choose another NoteID.", noteID, ExtraTuningSheets, noteID)
+	// the parameters of the shared notes with different values after
+	// all notes of the solutions are applied
+	params1, err := solutionEffectiveParams(notes1, tuneApp)
+	if err != nil {
+		return err
 	}
-	templateFile := "/usr/share/saptune/NoteTemplate.conf"
-	//if _, err := os.Stat(extraFileName); os.IsNotExist(err) {
-	//copy template file
-	err := system.CopyFile(templateFile, extraFileName)
+	params2, err := solutionEffectiveParams(notes2, tuneApp)
 	if err != nil {
-		errorExit("Problems while copying '%s' to '%s' - %v", templateFile, extraFileName, err)
+		return err
 	}
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "/usr/bin/vim" // launch vim by default
+	sharedParams, err := solutionEffectiveParams(shared, tuneApp)
+	if err != nil {
+		return err
 	}
-	if err := syscall.Exec(editor, []string{editor, extraFileName}, os.Environ()); err != nil {
-		errorExit("Failed to start launch editor %s: %v", editor, err)
+	diffs := make([]paramDiff, 0)
+	for key := range sharedParams {
+		val1, val2 := params1[key], params2[key]
+		if val1.value == val2.value {
+			continue
+		}
+		diffs = append(diffs, paramDiff{name: key, value1: fmt.Sprintf("%s (%s)", strings.Replace(val1.value, "\t", " ", -1), val1.noteID), value2: fmt.Sprintf("%s (%s)", strings.Replace(val2.value, "\t", " ", -1), val2.noteID)})
 	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].name < diffs[j].name })
+	fmt.Fprintf(writer, "Parameters of the shared notes tuned to different values (value and the note setting it):\n\n")
+	printParamDiffTable(writer, diffs, solName1, solName2)
+	return nil
 }
 
-// NoteActionShow shows the content of the Note definition file
-func NoteActionShow(noteID string) {
-	if noteID == "" {
-		PrintHelpAndExit(1)
+// SolutionActionShow prints the notes of a solution together with their
+// names and the state of the solution
+func SolutionActionShow(writer io.Writer, solName string, tuneApp *app.App, tOptions note.TuningOptions) error {
+	if solName == "" {
+		return usageError()
 	}
-	if _, err := tuneApp.GetNoteByID(noteID); err != nil {
-		errorExit("%v", err)
+	sol, err := tuneApp.GetSolutionByName(solName)
+	if err != nil {
+		return newExitError("%v", err)
 	}
-	fileName := fmt.Sprintf("%s%s", NoteTuningSheets, noteID)
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		_, files := system.ListDir(ExtraTuningSheets, "")
-		for _, f := range files {
-			if strings.HasPrefix(f, noteID) {
-				fileName = fmt.Sprintf("%s%s", ExtraTuningSheets, f)
-			}
+	yesNo := func(flag bool) string {
+		if flag {
+			return "yes"
 		}
-		if _, err := os.Stat(fileName); os.IsNotExist(err) {
-			errorExit("Note %s not found in %s or %s.", noteID, NoteTuningSheets, ExtraTuningSheets)
-		} else if err != nil {
-			errorExit("Failed to read file '%s' - %v", fileName, err)
-		}
-	} else if err != nil {
-		errorExit("Failed to read file '%s' - %v", fileName, err)
+		return "no"
 	}
-	cont, err := ioutil.ReadFile(fileName)
-	if err != nil {
-		errorExit("Failed to read file '%s' - %v", fileName, err)
+	i := sort.SearchStrings(tuneApp.TuneForSolutions, solName)
+	enabled := i < len(tuneApp.TuneForSolutions) && tuneApp.TuneForSolutions[i] == solName
+	deprecated := false
+	if _, ok := solution.DeprecSolutions[solutionSelector][solName]; ok {
+		deprecated = true
 	}
-	fmt.Printf("\nContent of Note %s:\n%s\n", noteID, string(cont))
-}
+	override := len(solution.OverrideSolutions[solutionSelector][solName]) != 0
 
-// NoteActionRevert reverts all parameter settings of a Note back to the
-// state before 'apply'
-func NoteActionRevert(writer io.Writer, noteID string, tuneApp *app.App) {
-	if noteID == "" {
-		PrintHelpAndExit(1)
+	fmt.Fprintf(writer, "\nSolution %s:\n", solName)
+	fmt.Fprintf(writer, "\tenabled:       %s\n", yesNo(enabled))
+	fmt.Fprintf(writer, "\tdeprecated:    %s\n", yesNo(deprecated))
+	fmt.Fprintf(writer, "\tuser-defined:  %s\n", yesNo(solution.IsCustomSolution(solutionSelector, solName)))
+	if override {
+		fmt.Fprintf(writer, "\toverride:      yes, the notes are taken from '%s'\n", solution.OverrideSolutionSheet)
+	} else {
+		fmt.Fprintf(writer, "\toverride:      no\n")
 	}
-	if err := tuneApp.RevertNote(noteID, true); err != nil {
-		errorExit("Failed to revert note %s: %v", noteID, err)
+	fmt.Fprintf(writer, "\nNotes of the solution in the order they are applied:\n")
+	for _, noteID := range sol {
+		format := "\t%s\t\t%s\n"
+		if len(noteID) >= 8 {
+			format = "\t%s\t%s\n"
+		}
+		name := "unknown Note, not recognised by saptune"
+		if noteObj, ok := tOptions[noteID]; ok {
+			name = noteObj.Name()
+		}
+		if enabled && tuneApp.IsNoteExcluded(solName, noteID) {
+			name = name + " (excluded, not applied)"
+		}
+		fmt.Fprintf(writer, format, noteID, name)
 	}
-	fmt.Fprintf(writer, "Parameters tuned by the note have been successfully reverted.\n")
-	fmt.Fprintf(writer, "Please note: the reverted note may still show up in list of enabled notes, if an enabled solution refers to it.\n")
+	fmt.Fprintf(writer, "\n")
+	return nil
 }
 
-// SolutionAction  Solution actions like apply, revert, verify asm.
-func SolutionAction(actionName, solName string) {
-	switch actionName {
-	case "apply":
-		SolutionActionApply(solName)
-	case "list":
-		SolutionActionList()
-	case "verify":
-		SolutionActionVerify(solName)
-	case "simulate":
-		SolutionActionSimulate(solName)
-	case "revert":
-		SolutionActionRevert(solName)
-	default:
-		PrintHelpAndExit(1)
+// cliNoteIDs returns the Note IDs given by all occurrences of the command
+// line option '--name=NoteID,NoteID'. It is used for the comma separated
+// parameter names of 'saptune note apply --only' as well
+func cliNoteIDs(name string) []string {
+	noteIDs := make([]string, 0)
+	for _, value := range cliFlagValues(name) {
+		for _, noteID := range strings.Split(value, ",") {
+			if noteID = strings.TrimSpace(noteID); noteID != "" {
+				noteIDs = append(noteIDs, noteID)
+			}
+		}
 	}
+	return noteIDs
 }
 
 // SolutionActionApply applies parameter settings defined by the solution
-// to the system
-func SolutionActionApply(solName string) {
+// to the system. If only is not empty, only the listed notes of the
+// solution are applied. The notes listed in except are not applied.
+func SolutionActionApply(solName string, only, except []string) error {
 	if solName == "" {
-		PrintHelpAndExit(1)
+		return usageError()
 	}
 	if len(tuneApp.TuneForSolutions) > 0 {
 		// already one solution applied.
 		// do not apply another solution. Does not make sense
 		system.InfoLog("There is already one solution applied. Applying another solution is NOT supported.")
-		os.Exit(0)
+		return nil
+	}
+	excluded, err := tuneApp.SolutionNotesToExclude(solName, only, except)
+	if err != nil {
+		return newExitError("Failed to tune for solution %s: %v", solName, err)
 	}
-	removedAdditionalNotes, err := tuneApp.TuneSolution(solName)
+	removedAdditionalNotes, err := tuneApp.TuneSolutionExcept(solName, excluded)
+	recordHistory(tuneApp, "apply", "solution", solName, err)
 	if err != nil {
-		errorExit("Failed to tune for solution %s: %v", solName, err)
+		return newExitError("Failed to tune for solution %s: %v", solName, err)
+	}
+	if len(excluded) == 0 {
+		fmt.Println("All tuning options for the SAP solution have been applied successfully.")
+	} else {
+		fmt.Println("The tuning options for the SAP solution have been applied successfully, except for the following excluded notes:")
+		for _, noteNumber := range excluded {
+			name := "unknown Note, not recognised by saptune"
+			if noteObj, ok := tuningOptions[noteNumber]; ok {
+				name = noteObj.Name()
+			}
+			fmt.Printf("\t%s\t%s\n", noteNumber, name)
+		}
 	}
-	fmt.Println("All tuning options for the SAP solution have been applied successfully.")
 	if len(removedAdditionalNotes) > 0 {
 		fmt.Println("The following previously-enabled notes are now tuned by the SAP solution:")
 		for _, noteNumber := range removedAdditionalNotes {
 			fmt.Printf("\t%s\t%s\n", noteNumber, tuningOptions[noteNumber].Name())
 		}
 	}
-	if !system.SystemctlIsRunning(TunedService) || system.GetTunedProfile() != TunedProfileName {
+	if daemonReminderNeeded() {
 		fmt.Println("\nRemember: if you wish to automatically activate the solution's tuning options after a reboot," +
 			"you must instruct saptune to configure \"tuned\" daemon by running:" +
 			"\n    saptune daemon start")
 	}
+	return nil
 }
 
 // SolutionActionList lists all available solution definitions
-func SolutionActionList() {
-	fmt.Println("\nAll solutions (* denotes enabled solution, O denotes override file exists for solution, D denotes deprecated solutions):")
+// With 'showNotes' each note of a solution is printed on its own line
+// together with the note name
+func SolutionActionList(writer io.Writer, showNotes bool, tuneApp *app.App, tOptions note.TuningOptions) {
+	fmt.Fprintln(writer, "\nAll solutions (* denotes enabled solution, O denotes override file exists for solution, D denotes deprecated solutions, U denotes user-defined solutions):")
 	for _, solName := range solution.GetSortedSolutionNames(solutionSelector) {
 		format := "\t%-18s -"
-		if i := sort.SearchStrings(tuneApp.TuneForSolutions, solName); i < len(tuneApp.TuneForSolutions) && tuneApp.TuneForSolutions[i] == solName {
-			format = " " + setGreenText + "*" + format
-		}
-		if len(solution.OverrideSolutions[solutionSelector][solName]) != 0 {
-			//override solution
-			format = " O" + format
-		}
-
 		solNotes := ""
 		for _, noteString := range solution.AllSolutions[solutionSelector][solName] {
 			solNotes = solNotes + " " + noteString
 		}
-		if _, ok := solution.DeprecSolutions[solutionSelector][solName]; ok {
+		if !showNotes {
+			format = format + solNotes
+		}
+		if i := sort.SearchStrings(tuneApp.TuneForSolutions, solName); i < len(tuneApp.TuneForSolutions) && tuneApp.TuneForSolutions[i] == solName {
+			format = " " + colorize("*"+format, setGreenText)
+		}
+		solOverrides := note.SolutionOverrideNotes(solName)
+		if len(solution.OverrideSolutions[solutionSelector][solName]) != 0 || len(solOverrides) != 0 {
+			//override solution or solution specific override files
+			// of its notes
+			format = " O" + format
+		}
+		reason, deprecated := solution.DeprecSolutions[solutionSelector][solName]
+		if deprecated {
 			format = " D" + format
 		}
-		format = format + solNotes + resetTextColor + "\n"
-		fmt.Printf(format, solName)
+		if solution.IsCustomSolution(solutionSelector, solName) {
+			format = " U" + format
+		}
+		format = format + "\n"
+		fmt.Fprintf(writer, format, solName)
+		if !showNotes {
+			continue
+		}
+		if deprecated && reason != "" && !strings.EqualFold(reason, "deprecated") {
+			// the definition carries the reason of the deprecation
+			fmt.Fprintf(writer, "\t\t\tdeprecated: %s\n", reason)
+		}
+		for _, noteID := range solution.AllSolutions[solutionSelector][solName] {
+			name := "unknown Note, not recognised by saptune"
+			if noteObj, ok := tOptions[noteID]; ok {
+				// only the first line, the version follows on
+				// the next line
+				name = strings.Split(noteObj.Name(), "\n")[0]
+			}
+			if i := sort.SearchStrings(solOverrides, noteID); i < len(solOverrides) && solOverrides[i] == noteID {
+				name = name + " (solution override)"
+			}
+			fmt.Fprintf(writer, "\t\t\t%-12s %s\n", noteID, name)
+		}
 	}
-	if !system.SystemctlIsRunning(TunedService) || system.GetTunedProfile() != TunedProfileName {
-		fmt.Println("\nRemember: if you wish to automatically activate the solution's tuning options after a reboot," +
-			"you must instruct saptune to configure \"tuned\" daemon by running:" +
+	if daemonReminderNeeded() {
+		fmt.Fprintln(writer, "\nRemember: if you wish to automatically activate the solution's tuning options after a reboot,"+
+			"you must instruct saptune to configure \"tuned\" daemon by running:"+
 			"\n    saptune daemon start")
 	}
 }
 
 // SolutionActionVerify compares all parameter settings from a solution
 // definition against the system settings
-func SolutionActionVerify(solName string) {
+func SolutionActionVerify(writer io.Writer, solName string) error {
 	if solName == "" {
-		VerifyAllParameters()
-	} else {
-		// Check system parameters against the specified solution, no matter the solution has been tuned for or not.
-		unsatisfiedNotes, comparisons, err := tuneApp.VerifySolution(solName)
-		if err != nil {
-			errorExit("Failed to test the current system against the specified SAP solution: %v", err)
-		}
-		PrintNoteFields(os.Stdout, "NONE", comparisons, true)
-		if len(unsatisfiedNotes) == 0 {
-			fmt.Println("The system fully conforms to the tuning guidelines of the specified SAP solution.")
-		} else {
-			errorExit("The parameters listed above have deviated from the specified SAP solution recommendations.\n")
-		}
+		return VerifyAllParameters(writer, tuneApp)
+	}
+	// Check system parameters against the specified solution, no matter the solution has been tuned for or not.
+	unsatisfiedNotes, comparisons, err := tuneApp.VerifySolution(solName)
+	if err != nil {
+		return newExitError("Failed to test the current system against the specified SAP solution: %v", err)
+	}
+	ignore, err := verifyIgnoreList()
+	if err != nil {
+		return err
+	}
+	unsatisfiedNotes = ignoreDeviations(comparisons, unsatisfiedNotes, ignore)
+	if done, err := printVerifyFormat(writer, comparisons, unsatisfiedNotes); done || err != nil {
+		return err
 	}
+	PrintNoteFields(writer, "NONE", comparisons, true)
+	insecure := verifyParanoidFiles(writer, comparisons, tuneApp)
+	if len(unsatisfiedNotes) == 0 {
+		fmt.Fprintln(writer, "The system fully conforms to the tuning guidelines of the specified SAP solution.")
+	} else if err := deviationError(comparisons, "The parameters listed above have deviated from the specified SAP solution recommendations.\n"); err != nil {
+		return err
+	}
+	return insecureFilesError(insecure)
 }
 
 // SolutionActionSimulate shows all changes that will be applied to the system if
 // the solution will be applied.
-func SolutionActionSimulate(solName string) {
+func SolutionActionSimulate(writer io.Writer, solName string) error {
 	if solName == "" {
-		PrintHelpAndExit(1)
+		return usageError()
 	}
 	// Run verify and print out all fields of the note
-	if _, comparisons, err := tuneApp.VerifySolution(solName); err != nil {
-		errorExit("Failed to test the current system against the specified note: %v", err)
-	} else {
-		fmt.Printf("If you run `saptune solution apply %s`, the following changes will be applied to your system:\n", solName)
-		PrintNoteFields(os.Stdout, "NONE", comparisons, false)
+	_, comparisons, err := tuneApp.VerifySolution(solName)
+	if err != nil {
+		return newExitError("Failed to test the current system against the specified note: %v", err)
 	}
+	if done, err := printFootnotesFormat(writer, comparisons); done || err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "If you run `saptune solution apply %s`, the following changes will be applied to your system:\n", solName)
+	PrintNoteFields(writer, "NONE", comparisons, false)
+	return nil
 }
 
 // SolutionActionRevert reverts all parameter settings of a solution back to
 // the state before 'apply'
-func SolutionActionRevert(solName string) {
+func SolutionActionRevert(solName string) error {
 	if solName == "" {
-		PrintHelpAndExit(1)
+		return usageError()
 	}
-	if err := tuneApp.RevertSolution(solName); err != nil {
-		errorExit("Failed to revert tuning for solution %s: %v", solName, err)
+	err := tuneApp.RevertSolution(solName)
+	recordHistory(tuneApp, "revert", "solution", solName, err)
+	if err != nil {
+		return newExitError("Failed to revert tuning for solution %s: %v", solName, err)
 	}
 	fmt.Println("Parameters tuned by the notes referred by the SAP solution have been successfully reverted.")
+	return nil
+}
+
+// SolutionActionRevertAll reverts all enabled solutions. The manually
+// enabled notes stay applied
+func SolutionActionRevertAll(writer io.Writer, tuneApp *app.App) error {
+	if len(tuneApp.TuneForSolutions) == 0 {
+		fmt.Fprintf(writer, "No enabled solutions found, nothing reverted.\n")
+		return nil
+	}
+	// RevertSolution removes the solution from TuneForSolutions
+	solNames := append([]string{}, tuneApp.TuneForSolutions...)
+	for _, solName := range solNames {
+		err := tuneApp.RevertSolution(solName)
+		recordHistory(tuneApp, "revert", "solution", solName, err)
+		if err != nil {
+			return newExitError("Failed to revert tuning for solution %s: %v", solName, err)
+		}
+	}
+	fmt.Fprintf(writer, "Parameters tuned by the notes referred by the SAP solutions %s have been successfully reverted.\n", strings.Join(solNames, " "))
+	if len(tuneApp.TuneForNotes) != 0 {
+		fmt.Fprintf(writer, "Please note: the manually enabled notes %s are still applied. Use 'saptune note revert' to revert them.\n", strings.Join(tuneApp.TuneForNotes, " "))
+	}
+	return nil
 }
@@ -1,16 +1,30 @@
 package main
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/SUSE/saptune/app"
 	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/sap/solution"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path"
+	"reflect"
+	"runtime"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 )
 
 var OSNotesInGOPATH = path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/ospackage/usr/share/saptune/notes")
@@ -86,14 +100,40 @@ func TestRevertAction(t *testing.T) {
 Parameters tuned by the notes and solutions have been successfully reverted.
 `
 	buffer := bytes.Buffer{}
-	RevertAction(&buffer, "all", tApp)
+	RevertAction(&buffer, "all", "", false, false, tApp)
 	txt := buffer.String()
 	checkOut(t, txt, revertMatchText)
+
+	// progress messages of the applied notes
+	// use a separate state directory to not interfere with other tests
+	confDir := "/tmp/saptune_revert_test"
+	defer os.RemoveAll(confDir)
+	revertApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	if err := revertApp.TuneNote("simpleNote"); err != nil {
+		t.Fatal(err)
+	}
+	revertMatchText = `Reverting all notes and solutions, this may take some time...
+reverting simpleNote (1/1)...
+Parameters tuned by the notes and solutions have been successfully reverted.
+`
+	buffer.Reset()
+	RevertAction(&buffer, "all", "", false, false, revertApp)
+	txt = buffer.String()
+	checkOut(t, txt, revertMatchText)
+
+	// no progress messages with quiet
+	if err := revertApp.TuneNote("simpleNote"); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	RevertAction(&buffer, "all", "", true, false, revertApp)
+	txt = buffer.String()
+	checkOut(t, txt, "Parameters tuned by the notes and solutions have been successfully reverted.\n")
 }
 
 func TestNoteActionList(t *testing.T) {
 	var listMatchText = `
-All notes (+ denotes manually enabled notes, * denotes notes enabled by solutions, - denotes notes enabled by solutions but reverted manually later, O denotes override file exists for note):
+All notes (+ denotes manually enabled notes, * denotes notes enabled by solutions, - denotes notes enabled by solutions but reverted manually later, O denotes override file exists for note, C denotes notes, which are only checked, but NOT set, S denotes notes, which are enabled, but not yet applied, X denotes notes, whose ID is used by more than one Note definition):
 	extraNote	Configuration drop in for extra tests
 			Version 0 from 04.06.2019 
 	oldFile		Name_syntax
@@ -104,184 +144,3233 @@ Remember: if you wish to automatically activate the solution's tuning options af
 `
 
 	buffer := bytes.Buffer{}
-	NoteActionList(&buffer, tApp, tuningOpts)
+	NoteActionList(&buffer, tApp, tuningOpts, false, "")
 	txt := buffer.String()
 	checkOut(t, txt, listMatchText)
 }
 
-func TestNoteActionApply(t *testing.T) {
-	var applyMatchText = `The note has been applied successfully.
+func TestNoteActionListAnnotation(t *testing.T) {
+	confDir := "/tmp/saptune_listannotation_test"
+	defer os.RemoveAll(confDir)
+	listApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	if err := listApp.EnableNote("simpleNote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := listApp.AnnotateNote("simpleNote", "RITM12345"); err != nil {
+		t.Fatal(err)
+	}
 
-Remember: if you wish to automatically activate the solution's tuning options after a reboot,you must instruct saptune to configure "tuned" daemon by running:
-    saptune daemon start
-`
 	buffer := bytes.Buffer{}
-	nID := "simpleNote"
-	NoteActionApply(&buffer, nID, tApp)
-	txt := buffer.String()
-	checkOut(t, txt, applyMatchText)
+	NoteActionList(&buffer, listApp, tuningOpts, true, "")
+	if !strings.Contains(buffer.String(), "\t\t\tAnnotation: RITM12345\n") {
+		t.Errorf("missing annotation: '%s'", buffer.String())
+	}
+	buffer.Reset()
+	NoteActionList(&buffer, listApp, tuningOpts, false, "")
+	if strings.Contains(buffer.String(), "RITM12345") {
+		t.Errorf("annotation printed without '--verbose': '%s'", buffer.String())
+	}
+	buffer.Reset()
+	printNoteAnnotations(&buffer, []string{"extraNote", "simpleNote"}, listApp)
+	checkOut(t, buffer.String(), "\nAnnotations of the applied notes:\n   simpleNote: RITM12345\n")
+
+	found := false
+	for _, file := range collectSupportFiles(listApp, path.Join(confDir, "saptune")) {
+		if file.name == "note-annotations" {
+			found = true
+			checkOut(t, string(file.content), "simpleNote: RITM12345\n")
+		}
+	}
+	if !found {
+		t.Error("annotations missing in the support files")
+	}
 }
 
-func TestNoteActionVerify(t *testing.T) {
-	var verifyMatchText = `
-simpleNote -  
+func TestNoteActionListVersionPin(t *testing.T) {
+	confDir := "/tmp/saptune_listpin_test"
+	defer os.RemoveAll(confDir)
+	oldNoColor := noColor
+	noColor = true
+	defer func() { noColor = oldNoColor }()
+	listApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	actual := listApp.NoteVersion("simpleNote")
+	if actual == "" {
+		t.Fatal("missing version of simpleNote")
+	}
+	listApp.NoteVersionPins["simpleNote"] = actual
+	listApp.NoteVersionPins["extraNote"] = "999"
 
-   SAPNote, Version | Parameter                    | Expected    | Override  | Actual      | Compliant
---------------------+------------------------------+-------------+-----------+-------------+-----------
-   simpleNote, 1    | net.ipv4.ip_local_port_range | 31768 61999 |           | 31768 61999 | yes
+	buffer := bytes.Buffer{}
+	NoteActionList(&buffer, listApp, tuningOpts, false, "")
+	if !strings.Contains(buffer.String(), "\t\t\tpinned to version "+actual+"\n") {
+		t.Errorf("missing pinned version: '%s'", buffer.String())
+	}
+	if !strings.Contains(buffer.String(), "\t\t\tpinned to version 999, but the Note definition has version "+listApp.NoteVersion("extraNote")+"\n") {
+		t.Errorf("missing differing version: '%s'", buffer.String())
+	}
+	checkOut(t, noteVersionPinInfo("3", ""), "pinned to version 3, but the Note definition has version unknown")
+}
 
-   (no change)
+func TestNoteActionListOverrideLayers(t *testing.T) {
+	confDir := "/tmp/saptune_listlayers_test"
+	defer os.RemoveAll(confDir)
+	oldOverride, oldLayers := note.OverrideTuningSheets, note.OverrideLayers
+	oldMainLayers := OverrideLayers
+	defer func() {
+		note.OverrideTuningSheets, note.OverrideLayers = oldOverride, oldLayers
+		OverrideLayers = oldMainLayers
+	}()
+	siteDir := path.Join(confDir, "site") + "/"
+	hostDir := path.Join(confDir, "host") + "/"
+	for _, dir := range []string{siteDir, hostDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(dir, "simpleNote"), []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 32768 60999\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	OverrideLayers = overrideLayers(strings.TrimSuffix(siteDir, "/") + " ")
+	if len(OverrideLayers) != 1 || OverrideLayers[0] != siteDir {
+		t.Fatal(OverrideLayers)
+	}
+	note.OverrideTuningSheets, note.OverrideLayers = hostDir, OverrideLayers
+	listApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
 
+	buffer := bytes.Buffer{}
+	NoteActionList(&buffer, listApp, tuningOpts, false, "")
+	overrideFiles := path.Join(siteDir, "simpleNote") + " " + path.Join(hostDir, "simpleNote")
+	if !strings.Contains(buffer.String(), "\t\t\toverride files: "+overrideFiles+"\n") {
+		t.Errorf("missing override files: '%s'", buffer.String())
+	}
 
-[31mAttention for SAP Note simpleNote:
-Hints or values not yet handled by saptune. So please read carefully, check and set manually, if needed:
-# Text to ignore for apply but to display.
-# Everything the customer should know about this note, especially
-# which parameters are NOT handled and the reason.
-[0m
+	buffer.Reset()
+	NoteActionListJSON(&buffer, listApp, tuningOpts, "")
+	notes := []noteListEntry{}
+	if err := json.Unmarshal(buffer.Bytes(), &notes); err != nil {
+		t.Fatalf("no valid json '%s': %v", buffer.String(), err)
+	}
+	for _, entry := range notes {
+		if entry.ID == "simpleNote" && (!entry.OverrideExists || len(entry.OverrideFiles) != 2 || entry.OverrideFiles[1] != path.Join(hostDir, "simpleNote")) {
+			t.Errorf("wrong entry '%+v'", entry)
+		}
+	}
+}
 
-current order of applied notes is: simpleNote
+func TestNoteActionListJSON(t *testing.T) {
+	confDir := "/tmp/saptune_listjson_test"
+	defer os.RemoveAll(confDir)
+	oldExtra := ExtraTuningSheets
+	defer func() { ExtraTuningSheets = oldExtra }()
+	ExtraTuningSheets = TstFilesInGOPATH + "/"
+	listApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	if err := listApp.EnableNote("simpleNote"); err != nil {
+		t.Fatal(err)
+	}
 
-The system fully conforms to the specified note.
-`
 	buffer := bytes.Buffer{}
-	nID := "simpleNote"
-	NoteActionVerify(&buffer, nID, tApp)
-	txt := buffer.String()
-	checkOut(t, txt, verifyMatchText)
+	NoteActionListJSON(&buffer, listApp, tuningOpts, "")
+	notes := []noteListEntry{}
+	if err := json.Unmarshal(buffer.Bytes(), &notes); err != nil {
+		t.Fatalf("no valid json '%s': %v", buffer.String(), err)
+	}
+	if len(notes) != 3 {
+		t.Fatalf("expected 3 notes, got '%+v'", notes)
+	}
+	simple := noteListEntry{ID: "simpleNote", Name: tuningOpts["simpleNote"].Name(), Source: "extra", Enabled: true, EnabledBy: "manual", AppliedPosition: 0}
+	if !reflect.DeepEqual(notes[2], simple) {
+		t.Errorf("expected '%+v', got '%+v'", simple, notes[2])
+	}
+	if notes[0].ID != "extraNote" || notes[0].Enabled || notes[0].EnabledBy != "" || notes[0].AppliedPosition != -1 {
+		t.Errorf("wrong entry '%+v'", notes[0])
+	}
+
+	buffer.Reset()
+	NoteActionListJSON(&buffer, listApp, tuningOpts, "enabled")
+	notes = []noteListEntry{}
+	if err := json.Unmarshal(buffer.Bytes(), &notes); err != nil || len(notes) != 1 || notes[0].ID != "simpleNote" {
+		t.Errorf("wrong filtered output '%s': %v", buffer.String(), err)
+	}
 }
 
-func TestNoteActionRevert(t *testing.T) {
-	var revertMatchText = `Parameters tuned by the note have been successfully reverted.
-Please note: the reverted note may still show up in list of enabled notes, if an enabled solution refers to it.
+func TestNoteActionEnableDisable(t *testing.T) {
+	confDir := "/tmp/saptune_enable_test"
+	defer os.RemoveAll(confDir)
+	enableApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	enableMatchText := `Note simpleNote has been enabled, but not yet applied.
+It will be applied together with all other enabled notes by 'saptune daemon start'.
+
+current order of applied notes is: simpleNote
+
 `
 	buffer := bytes.Buffer{}
-	nID := "simpleNote"
-	NoteActionRevert(&buffer, nID, tApp)
-	txt := buffer.String()
-	checkOut(t, txt, revertMatchText)
+	NoteActionEnable(&buffer, "simpleNote", enableApp)
+	checkOut(t, buffer.String(), enableMatchText)
+
+	buffer.Reset()
+	NoteActionEnable(&buffer, "simpleNote", enableApp)
+	checkOut(t, buffer.String(), "Note simpleNote is already enabled.\n")
+
+	buffer.Reset()
+	NoteActionList(&buffer, enableApp, tuningOpts, false, "")
+	if !strings.Contains(buffer.String(), "+ S\tsimpleNote") {
+		t.Errorf("missing marker of the enabled, but not applied note in '%s'", buffer.String())
+	}
+
+	buffer.Reset()
+	NoteActionList(&buffer, enableApp, tuningOpts, false, "enabled")
+	if !strings.Contains(buffer.String(), "\tsimpleNote") || strings.Contains(buffer.String(), "\textraNote") {
+		t.Errorf("wrong list of manually enabled notes '%s'", buffer.String())
+	}
+	if !strings.HasPrefix(buffer.String(), "\nManually enabled notes (") {
+		t.Errorf("wrong heading of the list of manually enabled notes '%s'", buffer.String())
+	}
+	buffer.Reset()
+	NoteActionList(&buffer, enableApp, tuningOpts, false, "applied")
+	if strings.Contains(buffer.String(), "\tsimpleNote") {
+		t.Errorf("unexpected not applied note in '%s'", buffer.String())
+	}
+
+	buffer.Reset()
+	NoteActionDisable(&buffer, "simpleNote", enableApp)
+	checkOut(t, buffer.String(), "Note simpleNote has been disabled.\n")
 }
 
-func TestPrintNoteFields(t *testing.T) {
-	//tuningOptions := note.GetTuningOptions(path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/ospackage/usr/share/saptune/notes"), "")
-	var printMatchText1 = `
-941735 -  
+func TestSnapshotActions(t *testing.T) {
+	confDir := "/tmp/saptune_snapshot_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	snapFile := path.Join(confDir, "snapNote")
+	if err := ioutil.WriteFile(snapFile, []byte("[version]\n# SAP-NOTE=snapNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"snapshot test\"\n[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapOpts := note.TuningOptions{"snapNote": note.INISettings{ConfFilePath: snapFile, ID: "snapNote", DescriptiveName: "snapshot test"}}
+	snapApp := app.InitialiseApp(confDir, confDir, snapOpts, AllTestSolutions)
+	if err := snapApp.EnableNote("snapNote"); err != nil {
+		t.Fatal(err)
+	}
 
-   SAPNote, Version | Parameter           | Expected             | Override  | Actual               | Compliant
---------------------+---------------------+----------------------+-----------+----------------------+-----------
-   941735,          | ShmFileSystemSizeMB | 1714                 |           | 488                  | no 
-   941735,          | kernel.shmmax       | 18446744073709551615 |           | 18446744073709551615 | yes
+	buffer := bytes.Buffer{}
+	SnapshotAction(&buffer, "save", "base", snapApp)
+	checkOut(t, buffer.String(), "Snapshot 'base' with the values of 1 parameters saved to '/tmp/saptune_snapshot_test/var/lib/saptune/snapshots/base'.\n")
+
+	snapshot, err := snapApp.ReadSnapshot("base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	SnapshotAction(&buffer, "diff", "base", snapApp)
+	checkOut(t, buffer.String(), fmt.Sprintf("\nNo parameters changed since snapshot 'base' was saved at %s.\n\n", snapshot.Created))
 
+	// simulate a changed system value
+	key := "SysctlParams[grub:numa_balancing]"
+	saved := snapshot.Values["snapNote"][key]
+	actual := saved.ActualValueJS
+	saved.ActualValueJS = "enable"
+	snapshot.Values["snapNote"][key] = saved
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(snapApp.GetPathToSnapshot("base"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	diffMatchText := fmt.Sprintf(`
+Parameters changed since snapshot 'base' was saved at %s:
 
-`
-	var printMatchText2 = `
-941735 -  
+   SAPNote  | Parameter           | Snapshot | Actual
+------------+---------------------+----------+-------
+   snapNote | grub:numa_balancing | enable   | %s
 
-   Parameter           | Value set            | Value expected       | Override  | Comment
------------------------+----------------------+----------------------+-----------+--------------
-   ShmFileSystemSizeMB | 488                  | 1714                 |           |   
-   kernel.shmmax       | 18446744073709551615 | 18446744073709551615 |           |   
+`, snapshot.Created, actual)
+	buffer.Reset()
+	SnapshotAction(&buffer, "diff", "base", snapApp)
+	checkOut(t, buffer.String(), diffMatchText)
+}
 
+func TestSupportAction(t *testing.T) {
+	confDir := "/tmp/saptune_support_test"
+	defer os.RemoveAll(confDir)
+	ovDir := path.Join(confDir, "override")
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldOverride, oldExtra, oldNoteOverride := OverrideTuningSheets, ExtraTuningSheets, note.OverrideTuningSheets
+	defer func() {
+		OverrideTuningSheets, ExtraTuningSheets, note.OverrideTuningSheets = oldOverride, oldExtra, oldNoteOverride
+	}()
+	OverrideTuningSheets = ovDir + "/"
+	note.OverrideTuningSheets = OverrideTuningSheets
+	ExtraTuningSheets = path.Join(confDir, "extra") + "/"
+	ioutil.WriteFile(path.Join(ovDir, "simpleNote"), []byte("# tuned on host myhost.example.com\n[sysctl]\nvm.swappiness = 10\n"), 0644)
+	sysconfigFile := path.Join(confDir, "saptune")
+	ioutil.WriteFile(sysconfigFile, []byte("SAPTUNE_VERSION=\"2\"\n"), 0644)
+	supportApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
 
-`
-	var printMatchText3 = `   SAPNote, Version | Parameter           | Expected             | Override  | Actual               | Compliant
---------------------+---------------------+----------------------+-----------+----------------------+-----------
-   941735,          | ShmFileSystemSizeMB | 1714                 |           | 488                  | no 
-   941735,          | kernel.shmmax       | 18446744073709551615 |           | 18446744073709551615 | yes
+	files := collectSupportFiles(supportApp, sysconfigFile)
+	names := []string{}
+	for _, file := range files {
+		names = append(names, file.name)
+	}
+	checkOut(t, strings.Join(names, " "), sysconfigFile+" "+path.Join(ovDir, "simpleNote")+" saptune-verify tuned-status kernel-values")
 
+	files = redactSupportFiles(files, []string{"myhost.example.com"})
+	buffer := bytes.Buffer{}
+	writeSupportText(&buffer, files[:3])
+	supportMatchText := "===== " + sysconfigFile + ` =====
+SAPTUNE_VERSION="2"
 
-`
-	var printMatchText4 = `   Parameter           | Value set            | Value expected       | Override  | Comment
------------------------+----------------------+----------------------+-----------+--------------
-   ShmFileSystemSizeMB | 488                  | 1714                 |           |   
-   kernel.shmmax       | 18446744073709551615 | 18446744073709551615 |           |   
+===== ` + path.Join(ovDir, "simpleNote") + ` =====
+# tuned on host <hostname>
+[sysctl]
+vm.swappiness = 10
 
+===== saptune-verify =====
+No notes or solutions enabled, nothing to verify.
 
 `
-	checkCorrectMessage := func(t *testing.T, got, want string) {
-		t.Helper()
-		if got != want {
-			fmt.Println("==============")
-			fmt.Println(got)
-			fmt.Println("==============")
-			fmt.Println(want)
-			fmt.Println("==============")
-			t.Errorf("Output differs from expected one")
+	checkOut(t, buffer.String(), supportMatchText)
+
+	tarball := path.Join(confDir, "support.tgz")
+	if err := writeSupportTarball(tarball, files); err != nil {
+		t.Fatal(err)
+	}
+	tgz, err := os.Open(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tgz.Close()
+	gzReader, err := gzip.NewReader(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzReader)
+	cnt := 0
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
 		}
+		if hdr.Name != path.Join("saptune-support", files[cnt].name) {
+			t.Errorf("expected '%s', got '%s'", files[cnt].name, hdr.Name)
+		}
+		cnt++
+	}
+	if cnt != len(files) {
+		t.Errorf("expected %d files in the archive, got %d", len(files), cnt)
 	}
+}
 
-	fcomp1 := note.FieldComparison{ReflectFieldName: "ConfFilePath", ReflectMapKey: "", ActualValue: "/usr/share/saptune/notes/941735", ExpectedValue: "/usr/share/saptune/notes/941735", ActualValueJS: "/usr/share/saptune/notes/941735", ExpectedValueJS: "/usr/share/saptune/notes/941735", MatchExpectation: true}
-	fcomp2 := note.FieldComparison{ReflectFieldName: "ID", ReflectMapKey: "", ActualValue: "941735", ExpectedValue: "941735", ActualValueJS: "941735", ExpectedValueJS: "941735", MatchExpectation: true}
-	fcomp3 := note.FieldComparison{ReflectFieldName: "DescriptiveName", ReflectMapKey: "", ActualValue: "", ExpectedValue: "", ActualValueJS: "", ExpectedValueJS: "", MatchExpectation: true}
-	fcomp4 := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "ShmFileSystemSizeMB", ActualValue: "488", ExpectedValue: "1714", ActualValueJS: "488", ExpectedValueJS: "1714", MatchExpectation: false}
-	fcomp5 := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValue: "18446744073709551615", ExpectedValue: "18446744073709551615", ActualValueJS: "18446744073709551615", ExpectedValueJS: "18446744073709551615", MatchExpectation: true}
-	map941735 := map[string]note.FieldComparison{"ConfFilePath": fcomp1, "ID": fcomp2, "DescriptiveName": fcomp3, "SysctlParams[ShmFileSystemSizeMB]": fcomp4, "SysctlParams[kernel.shmmax]": fcomp5}
-	noteComp := map[string]map[string]note.FieldComparison{"941735": map941735}
+func TestTagActions(t *testing.T) {
+	confDir := "/tmp/saptune_tag_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tagFile := path.Join(confDir, "tagNote")
+	if err := ioutil.WriteFile(tagFile, []byte("[version]\n# SAP-NOTE=tagNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"tag test\"\n[tags]\nHANA production\n[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tagOpts := note.TuningOptions{"tagNote": note.INISettings{ConfFilePath: tagFile, ID: "tagNote", DescriptiveName: "tag test"}}
+	tagApp := app.InitialiseApp(confDir, confDir, tagOpts, AllTestSolutions)
+	if err := tagApp.EnableNote("tagNote"); err != nil {
+		t.Fatal(err)
+	}
 
-	t.Run("verify with header", func(t *testing.T) {
-		buffer := bytes.Buffer{}
-		PrintNoteFields(&buffer, "HEAD", noteComp, true)
-		txt := buffer.String()
-		//txt := PrintNoteFields("HEAD", noteComp, true)
-		checkCorrectMessage(t, txt, printMatchText1)
-	})
-	t.Run("simulate with header", func(t *testing.T) {
-		buffer := bytes.Buffer{}
-		PrintNoteFields(&buffer, "HEAD", noteComp, false)
-		txt := buffer.String()
-		//txt := PrintNoteFields("HEAD", noteComp, false)
-		checkCorrectMessage(t, txt, printMatchText2)
-	})
-	t.Run("verify without header", func(t *testing.T) {
-		buffer := bytes.Buffer{}
-		PrintNoteFields(&buffer, "NONE", noteComp, true)
-		txt := buffer.String()
-		//txt := PrintNoteFields("NONE", noteComp, true)
-		checkCorrectMessage(t, txt, printMatchText3)
-	})
-	t.Run("simulate without header", func(t *testing.T) {
-		buffer := bytes.Buffer{}
-		PrintNoteFields(&buffer, "NONE", noteComp, false)
-		txt := buffer.String()
-		//txt := PrintNoteFields("NONE", noteComp, false)
-		checkCorrectMessage(t, txt, printMatchText4)
-	})
-}
+	buffer := bytes.Buffer{}
+	NoteActionList(&buffer, tagApp, tagOpts, true, "")
+	if !strings.Contains(buffer.String(), "\t\t\tTags: HANA production\n") {
+		t.Errorf("missing tags in '%s'", buffer.String())
+	}
+	buffer.Reset()
+	NoteActionList(&buffer, tagApp, tagOpts, false, "")
+	if strings.Contains(buffer.String(), "Tags:") {
+		t.Errorf("unexpected tags in '%s'", buffer.String())
+	}
 
-func TestCheckUpdateLeftOvers(t *testing.T) {
-	checkUpdateLeftOvers()
+	buffer.Reset()
+	RevertAction(&buffer, "tag", "SAP", false, false, tagApp)
+	checkOut(t, buffer.String(), "No enabled notes with tag 'SAP' found.\n")
+	buffer.Reset()
+	RevertAction(&buffer, "tag", "HANA", false, false, tagApp)
+	checkOut(t, buffer.String(), "Parameters tuned by the notes with tag 'HANA' have been successfully reverted: tagNote\n")
 }
 
-/*
-func TestRevertAction(t *testing.T) {
-	RevertAction("all")
-	RevertAction("")
-}
+func TestRevertActionKeepSolutions(t *testing.T) {
+	confDir := "/tmp/saptune_keepsol_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	keepFile := path.Join(confDir, "keepNote")
+	if err := ioutil.WriteFile(keepFile, []byte("[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keepOpts := note.TuningOptions{"keepNote": note.INISettings{ConfFilePath: keepFile, ID: "keepNote", DescriptiveName: "keep test"}}
+	keepApp := app.InitialiseApp(confDir, confDir, keepOpts, AllTestSolutions)
 
-func TestDaemonAction(t *testing.T) {
-	DaemonAction("start")
+	buffer := bytes.Buffer{}
+	RevertAction(&buffer, "all", "", false, true, keepApp)
+	checkOut(t, buffer.String(), "No manually enabled notes outside of the enabled solutions found, nothing reverted.\n")
+
+	if err := keepApp.EnableNote("keepNote"); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	RevertActionKeepSolutions(&buffer, keepApp)
+	checkOut(t, buffer.String(), "Parameters tuned by the notes keepNote have been successfully reverted.\n")
 }
-*/
 
-func TestPrintHelpAndExit(t *testing.T) {
-	exitCode := 0
-	if os.Getenv("DO_EXIT") == "1" {
-		PrintHelpAndExit(9)
-		return
+func TestSolutionActionRevertAll(t *testing.T) {
+	confDir := "/tmp/saptune_solrevert_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestPrintHelpAndExit")
-	cmd.Env = append(os.Environ(), "DO_EXIT=1")
-	err := cmd.Run()
-	e, ok := err.(*exec.ExitError)
-	if ok {
-		ws := e.Sys().(syscall.WaitStatus)
-		exitCode = ws.ExitStatus()
-		if exitCode != 9 {
-			t.Fatalf("process ran with err %v, want exit status 9", err)
+	// the notes change the sysctl parameters to values differing from the
+	// current ones, so that the revert is visible
+	params := map[string]string{"solNote": "vm.swappiness", "manualNote": "vm.dirty_ratio"}
+	startValues := make(map[string]string)
+	solOpts := note.TuningOptions{}
+	for noteID, param := range params {
+		startValue, err := system.GetSysctlString(param)
+		if err != nil {
+			t.Skipf("sysctl parameter '%s' not available: %v", param, err)
 		}
-		if !e.Success() {
-			return
+		startValues[param] = startValue
+		newValue := "31"
+		if startValue == newValue {
+			newValue = "32"
+		}
+		defer note.CleanUpParamFile(param)
+		noteFile := path.Join(confDir, noteID)
+		if err := ioutil.WriteFile(noteFile, []byte("[sysctl]\n"+param+" = "+newValue+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		solOpts[noteID] = note.INISettings{ConfFilePath: noteFile, ID: noteID, DescriptiveName: "revert test"}
+	}
+	solutions := map[string]solution.Solution{"solA": {"solNote"}, "solB": {"solNote"}}
+	solApp := app.InitialiseApp(confDir, confDir, solOpts, solutions)
+
+	buffer := bytes.Buffer{}
+	SolutionActionRevertAll(&buffer, solApp)
+	checkOut(t, buffer.String(), "No enabled solutions found, nothing reverted.\n")
+
+	for _, solName := range []string{"solA", "solB"} {
+		if _, err := solApp.TuneSolution(solName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := solApp.TuneNote("manualNote"); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	SolutionActionRevertAll(&buffer, solApp)
+	checkOut(t, buffer.String(), "Parameters tuned by the notes referred by the SAP solutions solA solB have been successfully reverted.\nPlease note: the manually enabled notes manualNote are still applied. Use 'saptune note revert' to revert them.\n")
+	if len(solApp.TuneForSolutions) != 0 || len(solApp.TuneForNotes) != 1 {
+		t.Fatal(solApp.TuneForSolutions, solApp.TuneForNotes)
+	}
+	if solApp.IsNoteApplied("solNote") || !solApp.IsNoteApplied("manualNote") {
+		t.Error("the manually enabled note was reverted")
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != startValues["vm.swappiness"] {
+		t.Errorf("parameter of the solution not reverted: '%s'", value)
+	}
+	if value, _ := system.GetSysctlString("vm.dirty_ratio"); value == startValues["vm.dirty_ratio"] {
+		t.Errorf("parameter of the manually enabled note reverted: '%s'", value)
+	}
+	if err := solApp.RevertNote("manualNote", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := system.GetSysctlString("vm.dirty_ratio"); value != startValues["vm.dirty_ratio"] {
+		t.Errorf("parameter not reverted: '%s'", value)
+	}
+}
+
+func TestNoteActionApplyRefresh(t *testing.T) {
+	confDir := "/tmp/saptune_refresh_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldSwappiness, _ := system.GetSysctlString("vm.swappiness")
+	defer system.SetSysctlString("vm.swappiness", oldSwappiness)
+	if err := system.SetSysctlString("vm.swappiness", "60"); err != nil {
+		t.Skipf("sysctl parameters can not be set: %v", err)
+	}
+	defer note.CleanUpParamFile("vm.swappiness")
+	refreshFile := path.Join(confDir, "refreshNote")
+	if err := ioutil.WriteFile(refreshFile, []byte("[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refreshOpts := note.TuningOptions{"refreshNote": note.INISettings{ConfFilePath: refreshFile, ID: "refreshNote", DescriptiveName: "refresh test"}}
+	refreshApp := app.InitialiseApp(confDir, confDir, refreshOpts, AllTestSolutions)
+
+	buffer := bytes.Buffer{}
+	err := NoteActionApplyRefresh(&buffer, "refreshNote", refreshApp)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || exitErr.Message != "Failed to refresh note refreshNote: note refreshNote is not applied, so there is nothing to refresh\n" {
+		t.Fatal(err)
+	}
+	if err := refreshApp.TuneNote("refreshNote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := NoteActionApplyRefresh(&buffer, "refreshNote", refreshApp); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), "All parameters already conform to note refreshNote, nothing to refresh.\n")
+
+	buffer.Reset()
+	_ = system.SetSysctlString("vm.swappiness", "30")
+	if err := NoteActionApplyRefresh(&buffer, "refreshNote", refreshApp); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), "\tparameter 'vm.swappiness' changed from '30' to '10'\n1 parameter(s) of note refreshNote set again. The values saved before the note was applied are kept for 'saptune note revert'.\n")
+	if err := refreshApp.RevertNote("refreshNote", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := system.GetSysctlString("vm.swappiness"); value != "60" {
+		t.Error(value)
+	}
+}
+
+func TestNoteActionApplyOnly(t *testing.T) {
+	confDir := "/tmp/saptune_applyonly_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	onlyFile := path.Join(confDir, "onlyNote")
+	if err := ioutil.WriteFile(onlyFile, []byte("[grub]\nnuma_balancing=disable\ntransparent_hugepage=never\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	onlyOpts := note.TuningOptions{"onlyNote": note.INISettings{ConfFilePath: onlyFile, ID: "onlyNote", DescriptiveName: "only test"}}
+	onlyApp := app.InitialiseApp(confDir, confDir, onlyOpts, AllTestSolutions)
+
+	buffer := bytes.Buffer{}
+	err := NoteActionApplyOnly(&buffer, "onlyNote", []string{}, "", onlyApp)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || exitErr.Message != "Missing parameter names for option '--only', expected e.g. '--only=kernel.shmmax,vm.swappiness'.\n" {
+		t.Fatal(err)
+	}
+	err = NoteActionApplyOnly(&buffer, "onlyNote", []string{"grub:quiet"}, "", onlyApp)
+	if !errors.As(err, &exitErr) || exitErr.Message != "Failed to tune for note onlyNote: the parameter(s) 'grub:quiet' are not defined by note onlyNote\n" {
+		t.Fatal(err)
+	}
+	if err := NoteActionApplyOnly(&buffer, "onlyNote", []string{"grub:numa_balancing"}, "", onlyApp); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buffer.String(), "The parameters grub:numa_balancing of note onlyNote have been applied successfully.\nAll other parameters of the note are not managed by saptune.\n") {
+		t.Errorf("unexpected output '%s'", buffer.String())
+	}
+	buffer.Reset()
+	NoteActionList(&buffer, onlyApp, onlyOpts, false, "")
+	if !strings.Contains(buffer.String(), "\t\t\tonly parameters applied: grub:numa_balancing\n") {
+		t.Errorf("missing applied parameters: '%s'", buffer.String())
+	}
+	if err := onlyApp.RevertNote("onlyNote", true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoteActionApply(t *testing.T) {
+	var applyMatchText = `The note has been applied successfully.
+
+Remember: if you wish to automatically activate the solution's tuning options after a reboot,you must instruct saptune to configure "tuned" daemon by running:
+    saptune daemon start
+`
+	buffer := bytes.Buffer{}
+	nID := "simpleNote"
+	NoteActionApply(&buffer, nID, false, 0, "", tApp)
+	txt := buffer.String()
+	checkOut(t, txt, applyMatchText)
+
+	// the note is not applied again, only the annotation is recorded
+	defer os.Remove(tApp.GetPathToAnnotation(nID))
+	buffer.Reset()
+	if err := NoteActionApply(&buffer, nID, false, 0, "RITM12345", tApp); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), "The note simpleNote is already applied, its annotation has been updated.\n")
+	if annotation, ok := tApp.NoteAnnotation(nID); !ok || annotation != "RITM12345" {
+		t.Errorf("annotation not recorded: '%s'", annotation)
+	}
+}
+
+func TestNoteActionApplyRequirements(t *testing.T) {
+	confDir := "/tmp/saptune_requires_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	reqFile := path.Join(confDir, "reqNote")
+	if err := ioutil.WriteFile(reqFile, []byte("[version]\n# SAP-NOTE=reqNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"requires test\"\n[requires]\nbaseNote\n[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseFile := path.Join(confDir, "baseNote")
+	if err := ioutil.WriteFile(baseFile, []byte("[version]\n# SAP-NOTE=baseNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"base test\"\n[grub]\ntransparent_hugepage=never\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reqOpts := note.TuningOptions{
+		"reqNote":  note.INISettings{ConfFilePath: reqFile, ID: "reqNote", DescriptiveName: "requires test"},
+		"baseNote": note.INISettings{ConfFilePath: baseFile, ID: "baseNote", DescriptiveName: "base test"},
+	}
+
+	reqApp := app.InitialiseApp(confDir, confDir, reqOpts, AllTestSolutions)
+	buffer := bytes.Buffer{}
+	NoteActionApply(&buffer, "reqNote", false, 0, "", reqApp)
+	if !strings.HasPrefix(buffer.String(), "Note reqNote requires the notes baseNote, which are not enabled.\nUse 'saptune note apply --with-requirements reqNote' to apply them together with the note.\nThe note has been applied successfully.\n") {
+		t.Errorf("wrong output '%s'", buffer.String())
+	}
+	if err := reqApp.RevertNote("reqNote", true); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer.Reset()
+	NoteActionApply(&buffer, "reqNote", true, 0, "", reqApp)
+	if !strings.HasPrefix(buffer.String(), "The required note baseNote has been applied successfully.\nThe note has been applied successfully.\n") {
+		t.Errorf("wrong output '%s'", buffer.String())
+	}
+	if strings.Join(reqApp.NoteApplyOrder, " ") != "baseNote reqNote" {
+		t.Error(reqApp.NoteApplyOrder)
+	}
+	if err := reqApp.RevertAll(true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNoteActionApplyStdin(t *testing.T) {
+	confDir := "/tmp/saptune_stdin_test"
+	defer os.RemoveAll(confDir)
+	stdinApp := app.InitialiseApp(confDir, confDir, note.TuningOptions{}, AllTestSolutions)
+	definition := "[version]\n# SAP-NOTE=stdinNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"stdin test\"\n[grub]\nnuma_balancing=disable\n"
+	noteID := app.TransientNoteID([]byte(definition))
+	buffer := bytes.Buffer{}
+	NoteActionApplyStdin(strings.NewReader(definition), &buffer, false, stdinApp)
+	if !strings.HasPrefix(buffer.String(), fmt.Sprintf("Note definition read from stdin registered as transient note %s.\nThe note has been applied successfully.\n", noteID)) {
+		t.Errorf("wrong output '%s'", buffer.String())
+	}
+	if !stdinApp.IsNoteApplied(noteID) || !stdinApp.IsTransientNote(noteID) {
+		t.Errorf("transient note %s not applied", noteID)
+	}
+	if err := stdinApp.RevertNote(noteID, true); err != nil {
+		t.Fatal(err)
+	}
+	if stdinApp.IsTransientNote(noteID) {
+		t.Errorf("transient note %s not removed by the revert", noteID)
+	}
+}
+
+func TestSolutionActionDiff(t *testing.T) {
+	confDir := "/tmp/saptune_soldiff_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defs := map[string]string{
+		"sharedNote": "[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 4096\n",
+		"overNote":   "[sysctl]\nvm.swappiness = 30\n",
+		"onlyBNote":  "[sysctl]\nkernel.sem = 1250 256000 100 8192\n",
+	}
+	diffOpts := note.TuningOptions{}
+	for noteID, def := range defs {
+		fileName := path.Join(confDir, noteID)
+		if err := ioutil.WriteFile(fileName, []byte("[version]\n# SAP-NOTE="+noteID+" CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"diff test\"\n"+def), 0644); err != nil {
+			t.Fatal(err)
+		}
+		diffOpts[noteID] = note.INISettings{ConfFilePath: fileName, ID: noteID, DescriptiveName: "diff test"}
+	}
+	diffSols := map[string]solution.Solution{"solA": {"sharedNote", "overNote"}, "solB": {"sharedNote", "onlyBNote"}}
+	diffApp := app.InitialiseApp(confDir, confDir, diffOpts, diffSols)
+
+	diffMatchText := `
+Differences between solution solA and solution solB:
+
+   Notes only in solution solA: overNote
+   Notes only in solution solB: onlyBNote
+   Notes in both solutions: sharedNote
+
+Parameters of the shared notes tuned to different values (value and the note setting it):
+
+   Parameter     | solA          | solB           
+-----------------+---------------+----------------
+   vm.swappiness | 30 (overNote) | 10 (sharedNote)
+
+`
+	buffer := bytes.Buffer{}
+	SolutionActionDiff(&buffer, "solA", "solB", diffApp)
+	checkOut(t, buffer.String(), diffMatchText)
+
+	diffMatchText = `
+Differences between solution solA and solution solA:
+
+   Notes only in solution solA: (none)
+   Notes only in solution solA: (none)
+   Notes in both solutions: sharedNote overNote
+
+Parameters of the shared notes tuned to different values (value and the note setting it):
+
+   (no difference)
+
+`
+	buffer.Reset()
+	SolutionActionDiff(&buffer, "solA", "solA", diffApp)
+	checkOut(t, buffer.String(), diffMatchText)
+}
+
+func TestHistoryAction(t *testing.T) {
+	histDir := "/tmp/saptune_history_test"
+	defer os.RemoveAll(histDir)
+	os.Setenv("SUDO_USER", "histuser")
+	defer os.Unsetenv("SUDO_USER")
+	histApp := app.InitialiseApp(histDir, histDir, note.TuningOptions{}, AllTestSolutions)
+
+	buffer := bytes.Buffer{}
+	HistoryAction(&buffer, "", false, histApp)
+	checkOut(t, buffer.String(), "\nNo actions recorded in the history.\n\n")
+
+	recordHistory(histApp, "apply", "note", "simpleNote", nil)
+	recordHistory(histApp, "revert", "solution", "sol1", fmt.Errorf("not applied"))
+	buffer.Reset()
+	HistoryAction(&buffer, "2000-01-01", false, histApp)
+	lines := strings.Split(buffer.String(), "\n")
+	if len(lines) != 7 || !strings.HasPrefix(lines[1], "   Time ") || !strings.Contains(lines[3], " | histuser | apply  | note simpleNote | success") || !strings.Contains(lines[4], " | histuser | revert | solution sol1   | failed: not applied") {
+		t.Errorf("wrong output '%s'", buffer.String())
+	}
+
+	buffer.Reset()
+	HistoryAction(&buffer, "", true, histApp)
+	if !strings.Contains(buffer.String(), `"id": "simpleNote",`) || !strings.Contains(buffer.String(), `"result": "failed: not applied"`) {
+		t.Errorf("wrong output '%s'", buffer.String())
+	}
+
+	buffer.Reset()
+	HistoryAction(&buffer, time.Now().Add(time.Hour).Format("2006-01-02 15:04:05"), true, histApp)
+	checkOut(t, buffer.String(), "[]\n")
+}
+
+func TestParseHistorySince(t *testing.T) {
+	for _, since := range []string{"2020-01-02", "2020-01-02 10:11:12", "2020-01-02T10:11:12", "2020-01-02T10:11:12+02:00"} {
+		if sinceTime, err := parseHistorySince(since); err != nil || sinceTime.Year() != 2020 || sinceTime.Day() != 2 {
+			t.Error(since, sinceTime, err)
+		}
+	}
+	if sinceTime, err := parseHistorySince(""); err != nil || !sinceTime.IsZero() {
+		t.Error(sinceTime, err)
+	}
+	if _, err := parseHistorySince("yesterday"); err == nil {
+		t.Error("expected an error for an unsupported date")
+	}
+}
+
+func TestNoteActionApplyStdinExitCode(t *testing.T) {
+	if os.Getenv("DO_EXIT") == "1" {
+		exitOnError(NoteActionApplyStdin(strings.NewReader("[grub]\n[unknown]\n"), os.Stdout, false, tApp))
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=TestNoteActionApplyStdinExitCode")
+	cmd.Env = append(os.Environ(), "DO_EXIT=1")
+	out, err := cmd.Output()
+	if e, ok := err.(*exec.ExitError); ok {
+		if exitCode := e.Sys().(syscall.WaitStatus).ExitStatus(); exitCode != 1 {
+			t.Fatalf("process ran with err %v, want exit status 1", err)
+		}
+		if !strings.Contains(string(out), "<stdin>:2: unknown section '[unknown]'") {
+			t.Errorf("missing problem report in output '%s'", string(out))
+		}
+		return
+	}
+	t.Fatalf("process ran with err %v, want exit status 1", err)
+}
+
+func TestNoteActionSimulateAll(t *testing.T) {
+	confDir := "/tmp/saptune_simulate_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	simOpts := note.TuningOptions{}
+	for _, noteID := range []string{"simNote1", "simNote2"} {
+		noteFile := path.Join(confDir, noteID)
+		if err := ioutil.WriteFile(noteFile, []byte("[version]\n# SAP-NOTE="+noteID+" CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"simulate test\"\n[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		simOpts[noteID] = note.INISettings{ConfFilePath: noteFile, ID: noteID, DescriptiveName: "simulate test"}
+	}
+	simApp := app.InitialiseApp(confDir, confDir, simOpts, AllTestSolutions)
+
+	buffer := bytes.Buffer{}
+	NoteActionSimulateAll(&buffer, simApp)
+	checkOut(t, buffer.String(), "No notes or solutions enabled, nothing to simulate.\n")
+
+	for _, noteID := range []string{"simNote2", "simNote1"} {
+		if err := simApp.EnableNote(noteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buffer.Reset()
+	NoteActionSimulateAll(&buffer, simApp)
+	txt := buffer.String()
+	if !strings.HasPrefix(txt, "If you run `saptune daemon start`, the following changes will be applied to your system:\n") {
+		t.Errorf("wrong output: '%s'", txt)
+	}
+	// grouped by note in the order the notes are applied
+	pos2 := strings.Index(txt, "\nsimNote2 - ")
+	pos1 := strings.Index(txt, "\nsimNote1 - ")
+	if pos2 < 0 || pos1 < 0 || pos2 > pos1 || strings.Count(txt, "grub:numa_balancing") != 2 {
+		t.Errorf("wrong output: '%s'", txt)
+	}
+}
+
+func TestNoteActionSimulateFirst(t *testing.T) {
+	simulateHead := "If you run `saptune note apply simpleNote`, the following changes will be applied to your system:\n"
+	buffer := bytes.Buffer{}
+	if apply, err := NoteActionSimulateFirst(strings.NewReader(""), &buffer, "simpleNote", true, false, tApp); !apply || err != nil {
+		t.Error("expected confirmation by '--yes'", err)
+	}
+	if txt := buffer.String(); !strings.HasPrefix(txt, simulateHead) || !strings.Contains(txt, "net.ipv4.ip_local_port_range") || strings.Contains(txt, "[y/n]") {
+		t.Errorf("wrong output: '%s'", txt)
+	}
+
+	buffer.Reset()
+	if apply, err := NoteActionSimulateFirst(strings.NewReader("Y\n"), &buffer, "simpleNote", false, true, tApp); !apply || err != nil {
+		t.Error("expected confirmation by the user", err)
+	}
+	if txt := buffer.String(); !strings.HasPrefix(txt, simulateHead) || !strings.HasSuffix(txt, "Apply the note simpleNote? [y/n]: ") {
+		t.Errorf("wrong output: '%s'", txt)
+	}
+
+	buffer.Reset()
+	if apply, err := NoteActionSimulateFirst(strings.NewReader("n\n"), &buffer, "simpleNote", false, true, tApp); apply || err != nil {
+		t.Error("expected no confirmation", err)
+	}
+	if txt := buffer.String(); !strings.HasSuffix(txt, "Apply the note simpleNote? [y/n]: \nThe note simpleNote has not been applied.\n") {
+		t.Errorf("wrong output: '%s'", txt)
+	}
+}
+
+func TestSolutionActionShow(t *testing.T) {
+	confDir := "/tmp/saptune_solshow_test"
+	defer os.RemoveAll(confDir)
+	showApp := app.InitialiseApp(confDir, confDir, tuningOpts, map[string]solution.Solution{"solShow": solution.Solution{"simpleNote", "extraNote", "unknownNote"}})
+	showMatchText := `
+Solution solShow:
+	enabled:       no
+	deprecated:    no
+	user-defined:  no
+	override:      no
+
+Notes of the solution in the order they are applied:
+	simpleNote	Configuration drop in for simple tests
+			Version 1 from 09.07.2019 
+	extraNote	Configuration drop in for extra tests
+			Version 0 from 04.06.2019 
+	unknownNote	unknown Note, not recognised by saptune
+
+`
+	buffer := bytes.Buffer{}
+	SolutionActionShow(&buffer, "solShow", showApp, tuningOpts)
+	checkOut(t, buffer.String(), showMatchText)
+}
+
+func TestSolutionActionList(t *testing.T) {
+	confDir := "/tmp/saptune_sollist_test"
+	defer os.RemoveAll(confDir)
+	oldSols, oldDeprec := solution.AllSolutions, solution.DeprecSolutions
+	defer func() { solution.AllSolutions, solution.DeprecSolutions = oldSols, oldDeprec }()
+	solution.AllSolutions = map[string]map[string]solution.Solution{solutionSelector: {"solList": {"simpleNote", "unknownNote"}, "solOld": {"extraNote"}}}
+	solution.DeprecSolutions = map[string]map[string]string{solutionSelector: {"solOld": "replaced by solution solList"}}
+	listApp := app.InitialiseApp(confDir, confDir, tuningOpts, solution.AllSolutions[solutionSelector])
+
+	buffer := bytes.Buffer{}
+	SolutionActionList(&buffer, false, listApp, tuningOpts)
+	txt := buffer.String()
+	if !strings.Contains(txt, "\tsolList            - simpleNote unknownNote\n") || !strings.Contains(txt, " D\tsolOld             - extraNote\n") || strings.Contains(txt, "replaced by") {
+		t.Errorf("wrong compact output: '%s'", txt)
+	}
+
+	buffer.Reset()
+	SolutionActionList(&buffer, true, listApp, tuningOpts)
+	txt = buffer.String()
+	listMatchText := `	solList            -
+			simpleNote   Configuration drop in for simple tests
+			unknownNote  unknown Note, not recognised by saptune
+ D	solOld             -
+			deprecated: replaced by solution solList
+			extraNote    Configuration drop in for extra tests
+`
+	if !strings.Contains(txt, listMatchText) {
+		t.Errorf("wrong output with notes: '%s'", txt)
+	}
+
+	// the reminder to start the daemon is switched off
+	skipDaemonReminder = true
+	defer func() { skipDaemonReminder = false }()
+	buffer.Reset()
+	SolutionActionList(&buffer, false, listApp, tuningOpts)
+	if strings.Contains(buffer.String(), "Remember:") {
+		t.Errorf("unexpected reminder: '%s'", buffer.String())
+	}
+
+	// solution specific override file of a note
+	oldOverride := note.OverrideTuningSheets
+	defer func() { note.OverrideTuningSheets = oldOverride }()
+	note.OverrideTuningSheets = confDir
+	solOverride := note.GetSolutionOverrideFile("solList", "simpleNote")
+	if err := os.MkdirAll(path.Dir(solOverride), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(solOverride, []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 32768 60999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	SolutionActionList(&buffer, true, listApp, tuningOpts)
+	txt = buffer.String()
+	if !strings.Contains(txt, " O\tsolList            -\n\t\t\tsimpleNote   Configuration drop in for simple tests (solution override)\n") {
+		t.Errorf("missing solution override: '%s'", txt)
+	}
+}
+
+func TestNoteActionVerify(t *testing.T) {
+	var verifyMatchText = `
+simpleNote -  
+
+   SAPNote, Version | Parameter                    | Expected    | Override  | Actual      | Compliant
+--------------------+------------------------------+-------------+-----------+-------------+-----------
+   simpleNote, 1    | net.ipv4.ip_local_port_range | 31768 61999 |           | 31768 61999 | yes
+
+   (no change)
+
+
+[31mAttention for SAP Note simpleNote:
+Hints or values not yet handled by saptune. So please read carefully, check and set manually, if needed:
+# Text to ignore for apply but to display.
+# Everything the customer should know about this note, especially
+# which parameters are NOT handled and the reason.
+[0m
+
+current order of applied notes is: simpleNote
+
+Compliance: 1/1 parameters (100.0%), 0 deviating across 0 notes
+The system fully conforms to the specified note.
+`
+	buffer := bytes.Buffer{}
+	nID := "simpleNote"
+	NoteActionVerify(&buffer, nID, tApp)
+	txt := buffer.String()
+	checkOut(t, txt, verifyMatchText)
+}
+
+func TestComputeComplianceScore(t *testing.T) {
+	param := func(key string, match bool) note.FieldComparison {
+		return note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: key, MatchExpectation: match}
+	}
+	comparisons := map[string]map[string]note.FieldComparison{
+		"noteA": {
+			"SysctlParams[p1]":       param("p1", true),
+			"SysctlParams[p2]":       param("p2", false),
+			"SysctlParams[p3]":       param("p3", true),
+			"SysctlParams[reminder]": param("reminder", true),
+		},
+		"noteB": {
+			"SysctlParams[p4]":   param("p4", false),
+			"SysctlParams[p5]":   param("p5", true),
+			"SysctlParams[p6]":   param("p6", true),
+			"OverrideParams[p6]": {ReflectFieldName: "OverrideParams", ReflectMapKey: "p6", ExpectedValueJS: note.NotManaged},
+		},
+	}
+	score := computeComplianceScore(comparisons)
+	if score != (complianceScore{Parameters: 5, Compliant: 3, Deviating: 2, DeviatingNotes: 2, Percent: 60}) {
+		t.Errorf("unexpected score '%+v'", score)
+	}
+	if score.String() != "Compliance: 3/5 parameters (60.0%), 2 deviating across 2 notes" {
+		t.Errorf("unexpected summary '%s'", score.String())
+	}
+	delete(comparisons, "noteB")
+	if txt := computeComplianceScore(comparisons).String(); txt != "Compliance: 2/3 parameters (66.7%), 1 deviating across 1 note" {
+		t.Errorf("unexpected summary '%s'", txt)
+	}
+	if score := computeComplianceScore(nil); score.Parameters != 0 || score.Percent != 100 {
+		t.Errorf("unexpected score '%+v'", score)
+	}
+}
+
+func TestCompletionAction(t *testing.T) {
+	buffer := bytes.Buffer{}
+	CompletionAction(&buffer, "", "note", tuningOpts, nil)
+	if txt := buffer.String(); !strings.Contains(txt, "\nsimpleNote\n") {
+		t.Errorf("missing Note ID in '%s'", txt)
+	}
+	buffer.Reset()
+	CompletionAction(&buffer, "", "solution", tuningOpts, []string{"BOBJ", "HANA"})
+	checkOut(t, buffer.String(), "BOBJ\nHANA\n")
+
+	buffer.Reset()
+	CompletionAction(&buffer, "bash", "", tuningOpts, nil)
+	bashScript := buffer.String()
+	// the shipped completion script is generated by 'saptune completion bash'
+	shipped, err := ioutil.ReadFile("ospackage/usr/share/bash-completion/completions/saptune.completion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bashScript != string(shipped) {
+		t.Error("the shipped completion script differs from the output of 'saptune completion bash', regenerate it")
+	}
+	if out, err := exec.Command("bash", "-n", "-c", bashScript).CombinedOutput(); err != nil {
+		t.Errorf("syntax error in bash completion: %v - %s", err, string(out))
+	}
+	buffer.Reset()
+	CompletionAction(&buffer, "zsh", "", tuningOpts, nil)
+	if txt := buffer.String(); !strings.HasPrefix(txt, "#compdef saptune\n") || !strings.HasSuffix(txt, bashScript) {
+		t.Errorf("wrong zsh completion '%s'", txt)
+	}
+}
+
+func TestVerifySinceLast(t *testing.T) {
+	confDir := "/tmp/saptune_sincelast_test"
+	os.RemoveAll(confDir)
+	defer os.RemoveAll(confDir)
+	defer func() { verifySince = "" }()
+	sinceApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	_, comparisons, _, err := sinceApp.VerifyNote("simpleNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// independent of the actual value of the system the parameter
+	// complies with the note
+	key := "SysctlParams[net.ipv4.ip_local_port_range]"
+	compliant := comparisons[key]
+	compliant.MatchExpectation = true
+	comparisons[key] = compliant
+	noteComp := map[string]map[string]note.FieldComparison{"simpleNote": comparisons}
+
+	buffer := bytes.Buffer{}
+	verifySince = "last"
+	if done, err := verifySinceLast(&buffer, noteComp, sinceApp); !done || err != nil {
+		t.Fatal("expected output of the changes", err)
+	}
+	checkOut(t, buffer.String(), "\nNo parameter changed its compliance since the last verify, no previous result found.\n")
+
+	// the parameter deviated during the last verify
+	deviating := comparisons[key]
+	deviating.MatchExpectation = false
+	noteComp["simpleNote"] = map[string]note.FieldComparison{key: deviating}
+	verifySince = ""
+	buffer.Reset()
+	if done, err := verifySinceLast(&buffer, noteComp, sinceApp); done || err != nil || buffer.Len() != 0 {
+		t.Fatalf("unexpected output '%s' %v", buffer.String(), err)
+	}
+	last, err := sinceApp.ReadLastVerify()
+	if err != nil || last.Compliance["simpleNote"]["net.ipv4.ip_local_port_range"] {
+		t.Fatal(last, err)
+	}
+	// a verify with a machine readable format does not save the result
+	oldFormat := outputFormat
+	defer func() { outputFormat = oldFormat }()
+	for _, format := range []string{"prometheus", "nagios"} {
+		outputFormat = format
+		if _, err := verifySinceLast(&buffer, map[string]map[string]note.FieldComparison{"simpleNote": comparisons}, sinceApp); err != nil {
+			t.Fatal(err)
+		}
+		if unchanged, err := sinceApp.ReadLastVerify(); err != nil || unchanged.Compliance["simpleNote"]["net.ipv4.ip_local_port_range"] || unchanged.Created != last.Created {
+			t.Fatal(format, unchanged, err)
+		}
+	}
+	outputFormat = ""
+
+	noteComp["simpleNote"] = comparisons
+	verifySince = "last"
+	buffer.Reset()
+	if _, err := verifySinceLast(&buffer, noteComp, sinceApp); err != nil {
+		t.Error(err)
+	}
+	txt := buffer.String()
+	if !strings.Contains(txt, "Parameters, whose compliance changed since the last verify at "+last.Created) || !strings.Contains(txt, "net.ipv4.ip_local_port_range") || !strings.Contains(txt, "All parameters listed above comply again.") {
+		t.Errorf("wrong output '%s'", txt)
+	}
+}
+
+func TestNoteActionVerifyPrometheus(t *testing.T) {
+	var verifyMatchText = `# HELP saptune_note_compliant Compliance of a parameter with the SAP Note (1 = compliant, 0 = deviating).
+# TYPE saptune_note_compliant gauge
+saptune_note_compliant{note="simpleNote",parameter="net.ipv4.ip_local_port_range"} 1
+# HELP saptune_notes_deviating Number of SAP Notes the system deviates from.
+# TYPE saptune_notes_deviating gauge
+saptune_notes_deviating 0
+`
+	outputFormat = "prometheus"
+	defer func() { outputFormat = "" }()
+	buffer := bytes.Buffer{}
+	NoteActionVerify(&buffer, "simpleNote", tApp)
+	txt := buffer.String()
+	checkOut(t, txt, verifyMatchText)
+}
+
+func TestPrintPrometheusMetrics(t *testing.T) {
+	var metricsMatchText = `# HELP saptune_note_compliant Compliance of a parameter with the SAP Note (1 = compliant, 0 = deviating).
+# TYPE saptune_note_compliant gauge
+saptune_note_compliant{note="4711",parameter="kernel.shmmax"} 0
+saptune_note_compliant{note="4711",parameter="odd\\\"param\n"} 1
+# HELP saptune_notes_deviating Number of SAP Notes the system deviates from.
+# TYPE saptune_notes_deviating gauge
+saptune_notes_deviating 1
+`
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"SysctlParams[kernel.shmmax]":  {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", MatchExpectation: false},
+			"SysctlParams[odd\\\"param\n]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "odd\\\"param\n", MatchExpectation: true},
+			"SysctlParams[reminder]":       {ReflectFieldName: "SysctlParams", ReflectMapKey: "reminder", MatchExpectation: true},
+		},
+	}
+	buffer := bytes.Buffer{}
+	PrintPrometheusMetrics(&buffer, comparisons, []string{"4711"})
+	txt := buffer.String()
+	checkOut(t, txt, metricsMatchText)
+}
+
+func TestPrintCSVVerifyResult(t *testing.T) {
+	var csvMatchText = `NoteID,Version,Parameter,Expected,Override,Actual,Compliant
+4711,1,IO_SCHEDULER_sda,"noop, none",,cfq,no
+4711,1,force_latency,70,,70,no
+4711,1,kernel.shmmni,8192 (@MUL 2),,4096,no
+4711,1,net.ipv4.ip_local_port_range,31768 61999,32768 60999,32768 60999,yes
+4711,1,"odd""param",1,,1,yes
+`
+	confFile := path.Join(TstFilesInGOPATH, "simpleNote.conf")
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                                 {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[IO_SCHEDULER_sda]":               {ReflectFieldName: "SysctlParams", ReflectMapKey: "IO_SCHEDULER_sda", ActualValueJS: "cfq", ExpectedValueJS: "noop, none", MatchExpectation: false},
+			"SysctlParams[force_latency]":                  {ReflectFieldName: "SysctlParams", ReflectMapKey: "force_latency", ActualValueJS: "70", ExpectedValueJS: "70", MatchExpectation: true},
+			"Inform[force_latency]":                        {ReflectFieldName: "Inform", ReflectMapKey: "force_latency", ActualValue: "hasDiffs"},
+			"SysctlParams[kernel.shmmni]":                  {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", ActualValueJS: "4096", ExpectedValueJS: "8192", MatchExpectation: false},
+			"Inform[kernel.shmmni]":                        {ReflectFieldName: "Inform", ReflectMapKey: "kernel.shmmni", ActualValue: "@MUL 2", ExpectedValueJS: "@MUL 2"},
+			"SysctlParams[net.ipv4.ip_local_port_range]":   {ReflectFieldName: "SysctlParams", ReflectMapKey: "net.ipv4.ip_local_port_range", ActualValueJS: "32768\t60999", ExpectedValueJS: "31768\t61999", MatchExpectation: true},
+			"OverrideParams[net.ipv4.ip_local_port_range]": {ReflectFieldName: "OverrideParams", ReflectMapKey: "net.ipv4.ip_local_port_range", ExpectedValueJS: "32768\t60999"},
+			"SysctlParams[odd\"param]":                     {ReflectFieldName: "SysctlParams", ReflectMapKey: "odd\"param", ActualValueJS: "1", ExpectedValueJS: "1", MatchExpectation: true},
+			"SysctlParams[reminder]":                       {ReflectFieldName: "SysctlParams", ReflectMapKey: "reminder", ExpectedValueJS: "# remember me", MatchExpectation: true},
+		},
+	}
+	buffer := bytes.Buffer{}
+	PrintCSVVerifyResult(&buffer, comparisons)
+	txt := buffer.String()
+	checkOut(t, txt, csvMatchText)
+}
+
+func TestPrintFootnotesJSON(t *testing.T) {
+	var jsonMatchText = `[
+  {
+    "footnote": 2,
+    "meaning": "setting is not available on the system",
+    "parameters": [
+      {
+        "note": "4711",
+        "parameter": "vm.pagecache_limit_mb"
+      }
+    ]
+  },
+  {
+    "footnote": 3,
+    "meaning": "value is only checked, but NOT set",
+    "parameters": [
+      {
+        "note": "0815",
+        "parameter": "grub:numa_balancing"
+      },
+      {
+        "note": "4711",
+        "parameter": "rpm:glibc"
+      }
+    ]
+  },
+  {
+    "footnote": 4,
+    "meaning": "cpu idle state settings differ",
+    "parameters": [
+      {
+        "note": "4711",
+        "parameter": "force_latency"
+      }
+    ]
+  },
+  {
+    "footnote": 6,
+    "meaning": "deviation is informational only, setting can not be changed in this environment",
+    "parameters": [
+      {
+        "note": "4711",
+        "parameter": "vm.nr_hugepages",
+        "detail": "container",
+        "severity": "critical"
+      }
+    ]
+  }
+]
+`
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"SysctlParams[force_latency]":         {ReflectFieldName: "SysctlParams", ReflectMapKey: "force_latency", MatchExpectation: true},
+			"Inform[force_latency]":               {ReflectFieldName: "Inform", ReflectMapKey: "force_latency", ActualValue: "hasDiffs"},
+			"SysctlParams[rpm:glibc]":             {ReflectFieldName: "SysctlParams", ReflectMapKey: "rpm:glibc", MatchExpectation: true},
+			"SysctlParams[vm.pagecache_limit_mb]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.pagecache_limit_mb", ActualValue: "NA", MatchExpectation: true},
+			"SysctlParams[vm.nr_hugepages]":       {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.nr_hugepages", NotApplicable: "container"},
+			"SysctlParams[vm.swappiness]":         {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", MatchExpectation: true},
+			"SysctlParams[reminder]":              {ReflectFieldName: "SysctlParams", ReflectMapKey: "reminder", ExpectedValueJS: "# remember me"},
+		},
+		"0815": {
+			"SysctlParams[grub:numa_balancing]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "grub:numa_balancing", MatchExpectation: true},
+		},
+	}
+	buffer := bytes.Buffer{}
+	PrintFootnotesJSON(&buffer, comparisons)
+	checkOut(t, buffer.String(), jsonMatchText)
+
+	buffer.Reset()
+	PrintFootnotesJSON(&buffer, map[string]map[string]note.FieldComparison{})
+	checkOut(t, buffer.String(), "[]\n")
+
+	// the table shows the same footnotes
+	compliant, comment, footnote := prepareFootnote(comparisons["4711"]["SysctlParams[vm.nr_hugepages]"], "no ", "", "", make([]string, 6, 6))
+	if compliant != "no [6]" || comment != " [6]" || footnote[5] != "[6] deviation is informational only, setting can not be changed in this environment (container)" {
+		t.Error(compliant, comment, footnote)
+	}
+}
+
+func TestNagiosVerifyResult(t *testing.T) {
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", MatchExpectation: true},
+			"SysctlParams[force_latency]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "force_latency", MatchExpectation: true},
+			"SysctlParams[reminder]":      {ReflectFieldName: "SysctlParams", ReflectMapKey: "reminder", MatchExpectation: false},
+			"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", MatchExpectation: true},
+		},
+		"0815": {
+			"SysctlParams[kernel.shmmax]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", MatchExpectation: true},
+		},
+	}
+	result, state := nagiosVerifyResult(comparisons, []string{})
+	checkOut(t, result, "SAPTUNE OK - the system conforms to 2 notes | deviating_parameters=0 deviating_notes=0")
+	if state != nagiosOK {
+		t.Errorf("got state %d, expected %d", state, nagiosOK)
+	}
+
+	comparisons["4711"]["SysctlParams[kernel.shmmni]"] = note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", MatchExpectation: false}
+	comparisons["4711"]["Inform[force_latency]"] = note.FieldComparison{ReflectFieldName: "Inform", ReflectMapKey: "force_latency", ActualValue: "hasDiffs"}
+	comparisons["0815"]["SysctlParams[kernel.shmmax]"] = note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", MatchExpectation: false}
+	result, state = nagiosVerifyResult(comparisons, []string{"4711", "0815"})
+	checkOut(t, result, "SAPTUNE CRITICAL - 3 parameters deviate from the notes 0815 4711 | deviating_parameters=3 deviating_notes=2")
+	if state != nagiosCritical {
+		t.Errorf("got state %d, expected %d", state, nagiosCritical)
+	}
+
+	result, state = nagiosVerifyResult(map[string]map[string]note.FieldComparison{}, []string{})
+	checkOut(t, result, "SAPTUNE WARNING - no notes or solutions enabled, nothing to verify | deviating_parameters=0 deviating_notes=0")
+	if state != nagiosWarning {
+		t.Errorf("got state %d, expected %d", state, nagiosWarning)
+	}
+}
+
+func TestVerifyIgnore(t *testing.T) {
+	if _, err := parseVerifyIgnore("4711:vm.swappiness 4712"); err == nil {
+		t.Error("expected an error for an entry without parameter")
+	}
+	ignore, err := parseVerifyIgnore(" 4711:vm.swappiness,4711:kernel.shmmax  4712:net.core.somaxconn ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ignore, map[string]map[string]bool{"4711": {"vm.swappiness": true, "kernel.shmmax": true}, "4712": {"net.core.somaxconn": true}}) {
+		t.Errorf("unexpected ignore list '%+v'", ignore)
+	}
+
+	confFile := "/tmp/saptune_ignore_note"
+	defer os.Remove(confFile)
+	if err := ioutil.WriteFile(confFile, []byte("[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 32768\nkernel.shmmax = 1024\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValueJS: "60", ExpectedValueJS: "10", MatchExpectation: false},
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", ActualValueJS: "4096", ExpectedValueJS: "32768", MatchExpectation: false},
+			"SysctlParams[kernel.shmmax]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValueJS: "1024", ExpectedValueJS: "1024", MatchExpectation: true},
+		},
+	}
+	if unsatisfied := ignoreDeviations(comparisons, []string{"4711"}, ignore); !reflect.DeepEqual(unsatisfied, []string{"4711"}) {
+		t.Errorf("unexpected unsatisfied notes '%v'", unsatisfied)
+	}
+	if comparison := comparisons["4711"]["SysctlParams[vm.swappiness]"]; !comparison.MatchExpectation || !comparison.Ignored {
+		t.Errorf("deviation not ignored '%+v'", comparison)
+	}
+	if comparison := comparisons["4711"]["SysctlParams[kernel.shmmax]"]; comparison.Ignored {
+		t.Errorf("compliant parameter marked as ignored '%+v'", comparison)
+	}
+	checkOut(t, computeComplianceScore(comparisons).String(), "Compliance: 1/2 parameters (50.0%), 1 deviating across 1 note, 1 ignored")
+
+	// the ignored deviation is still shown in the table
+	buffer := bytes.Buffer{}
+	oldNoColor := noColor
+	defer func() { noColor = oldNoColor }()
+	noColor = true
+	oldDiffOnly := verifyDiffOnly
+	defer func() { verifyDiffOnly = oldDiffOnly }()
+	verifyDiffOnly = true
+	PrintNoteFields(&buffer, "NONE", comparisons, true)
+	txt := buffer.String()
+	if !strings.Contains(txt, "| 60      | ignored\n") || !strings.Contains(txt, "| 4096    | no \n") || strings.Contains(txt, "kernel.shmmax") {
+		t.Errorf("unexpected table '%s'", txt)
+	}
+
+	// all deviations ignored
+	ignore["4711"]["kernel.shmmni"] = true
+	if unsatisfied := ignoreDeviations(comparisons, []string{"4711"}, ignore); len(unsatisfied) != 0 {
+		t.Errorf("unexpected unsatisfied notes '%v'", unsatisfied)
+	}
+	if severity := deviationSeverity(comparisons); severity != "" {
+		t.Errorf("got severity '%s'", severity)
+	}
+
+	// the parameters of the [block] section per block device or for all
+	// block devices
+	blockIgnore := map[string]bool{"IO_SCHEDULER": true, "NRREQ_sdb": true}
+	for key, expected := range map[string]bool{"IO_SCHEDULER_sda": true, "IO_SCHEDULER_vdb": true, "NRREQ_sda": false, "NRREQ_sdb": true, "vm.swappiness": false} {
+		if ignored := ignoredParam(blockIgnore, key); ignored != expected {
+			t.Errorf("parameter '%s': got %v, expected %v", key, ignored, expected)
+		}
+	}
+
+	// a wrong entry of VERIFY_IGNORE fails verify
+	oldVerifyIgnoreConf := verifyIgnoreConf
+	defer func() { verifyIgnoreConf = oldVerifyIgnoreConf }()
+	verifyIgnoreConf = "4711:vm.swappiness 4712"
+	_, err = verifyIgnoreList()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || exitErr.Message != "Wrong value for VERIFY_IGNORE in file '/etc/sysconfig/saptune' or for option '--ignore': wrong entry '4712' in the ignore list of verify, expected 'NoteID:ParameterName'\n" {
+		t.Fatal(err)
+	}
+	verifyIgnoreConf = "4711:vm.swappiness"
+	if ignore, err := verifyIgnoreList(); err != nil || !ignore["4711"]["vm.swappiness"] {
+		t.Fatal(ignore, err)
+	}
+}
+
+func TestDeviationSeverity(t *testing.T) {
+	confFile := "/tmp/saptune_severity_note"
+	defer os.Remove(confFile)
+	if err := ioutil.WriteFile(confFile, []byte("[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 32768\nkernel.shmmax = 1024\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValueJS: "60", ExpectedValueJS: "10", MatchExpectation: false, Severity: "info"},
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", ActualValueJS: "32768", ExpectedValueJS: "32768", MatchExpectation: true, Severity: "warning"},
+			"SysctlParams[kernel.shmmax]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValueJS: "1024", ExpectedValueJS: "1024", MatchExpectation: true},
+		},
+	}
+	if severity := deviationSeverity(comparisons); severity != "info" {
+		t.Errorf("got severity '%s'", severity)
+	}
+	result, state := nagiosVerifyResult(comparisons, []string{"4711"})
+	checkOut(t, result, "SAPTUNE OK - 1 parameters of severity info deviate from the notes 4711 | deviating_parameters=1 deviating_notes=1")
+	if state != nagiosOK {
+		t.Errorf("got state %d, expected %d", state, nagiosOK)
+	}
+
+	if err := deviationError(comparisons, "deviation"); err != nil {
+		t.Errorf("unexpected error '%v'", err)
+	}
+
+	comparisons["4711"]["SysctlParams[kernel.shmmni]"] = note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", ActualValueJS: "4096", ExpectedValueJS: "32768", MatchExpectation: false, Severity: "warning"}
+	if severity := deviationSeverity(comparisons); severity != "warning" {
+		t.Errorf("got severity '%s'", severity)
+	}
+	if _, state := nagiosVerifyResult(comparisons, []string{"4711"}); state != nagiosWarning {
+		t.Errorf("got state %d, expected %d", state, nagiosWarning)
+	}
+	if err, ok := deviationError(comparisons, "deviation").(*ExitError); !ok || err.Code != exitNotCompliant {
+		t.Errorf("unexpected error '%v'", err)
+	}
+	if err, ok := formatDeviationError(comparisons, []string{"4711"}).(*ExitError); !ok || err.Code != exitNotCompliant {
+		t.Errorf("unexpected error '%v'", err)
+	}
+
+	// the table shows the severity of the deviating parameters
+	buffer := bytes.Buffer{}
+	oldNoColor := noColor
+	defer func() { noColor = oldNoColor }()
+	noColor = true
+	PrintNoteFields(&buffer, "NONE", comparisons, true)
+	txt := buffer.String()
+	if !strings.Contains(txt, "| 60      | no (info)\n") || !strings.Contains(txt, "| 4096    | no (warning)\n") || !strings.Contains(txt, "| 1024    | yes\n") {
+		t.Errorf("unexpected table '%s'", txt)
+	}
+	if colorizeSeverity("row\n", "warning") != "row\n" {
+		t.Error("no colour expected")
+	}
+	noColor = false
+	if colorizeSeverity("row\n", "warning") != setYellowText+"row"+resetTextColor+"\n" || colorizeSeverity("row\n", "critical") != setRedText+"row"+resetTextColor+"\n" || colorizeSeverity("row\n", "info") != "row\n" {
+		t.Error("wrong colour")
+	}
+
+	// parameters without severity are critical
+	comparisons["4711"]["SysctlParams[kernel.shmmax]"] = note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValueJS: "512", ExpectedValueJS: "1024", MatchExpectation: false}
+	if severity := deviationSeverity(comparisons); severity != "critical" {
+		t.Errorf("got severity '%s'", severity)
+	}
+	deviations := paramDeviations(comparisons)
+	if !reflect.DeepEqual(deviations, []paramDeviation{{"4711", "kernel.shmmax", "critical"}, {"4711", "kernel.shmmni", "warning"}, {"4711", "vm.swappiness", "info"}}) {
+		t.Errorf("unexpected deviations '%+v'", deviations)
+	}
+	if severity := deviationSeverity(map[string]map[string]note.FieldComparison{}); severity != "" {
+		t.Errorf("got severity '%s'", severity)
+	}
+}
+
+func TestPrepareFootnoteNotApplicable(t *testing.T) {
+	footnote := make([]string, 6, 6)
+	comparison := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "governor", ActualValue: "all:powersave", MatchExpectation: false, NotApplicable: "kvm"}
+	compliant, comment, footnote := prepareFootnote(comparison, "no ", "", "", footnote)
+	checkOut(t, compliant, "no [6]")
+	checkOut(t, comment, " [6]")
+	checkOut(t, footnote[5], "[6] deviation is informational only, setting can not be changed in this environment (kvm)")
+
+	footnote = make([]string, 6, 6)
+	comparison.NotApplicable = ""
+	compliant, _, footnote = prepareFootnote(comparison, "no ", "", "", footnote)
+	checkOut(t, compliant, "no ")
+	checkOut(t, footnote[5], "")
+}
+
+func TestCPUIdleStateDetail(t *testing.T) {
+	oldStates := cpuIdleStates
+	defer func() { cpuIdleStates = oldStates }()
+	cpuIdleStates = func() []system.CPUIdleState {
+		return []system.CPUIdleState{
+			{CPU: "cpu0", State: "state1", Name: "C1", Latency: 2, Disabled: false},
+			{CPU: "cpu0", State: "state2", Name: "C6", Latency: 133, Disabled: true},
+			{CPU: "cpu3", State: "state1", Name: "C1", Latency: 2, Disabled: true},
+			{CPU: "cpu3", State: "state2", Latency: 133, Disabled: false},
+		}
+	}
+	comparison := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "force_latency", ActualValueJS: "70", ExpectedValueJS: "70", MatchExpectation: true}
+	compliant, _, footnote := prepareFootnote(comparison, "yes", "", "hasDiffs", make([]string, 6, 6))
+	checkOut(t, compliant, "no [4]")
+	checkOut(t, footnote[3], "[4] cpu idle state settings differ:\n      cpu3: state1 (C1, latency 2) is disabled, expected enabled\n      cpu3: state2 (latency 133) is enabled, expected disabled")
+
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"SysctlParams[force_latency]": comparison,
+			"Inform[force_latency]":       {ReflectFieldName: "Inform", ReflectMapKey: "force_latency", ActualValue: "hasDiffs"},
+		},
+	}
+	buffer := bytes.Buffer{}
+	PrintFootnotesJSON(&buffer, comparisons)
+	if !strings.Contains(buffer.String(), `"detail": "cpu3: state1 (C1, latency 2) is disabled, expected enabled; cpu3: state2 (latency 133) is enabled, expected disabled"`) {
+		t.Error(buffer.String())
+	}
+
+	// no details for unsupported latency settings
+	if detail := cpuIdleStateDetail("all:none"); len(detail) != 0 {
+		t.Error(detail)
+	}
+}
+
+func TestSchedulerDetail(t *testing.T) {
+	oldSchedulers := blockDeviceSchedulers
+	defer func() { blockDeviceSchedulers = oldSchedulers }()
+	blockDeviceSchedulers = func(blockdev string) ([]string, string, error) {
+		switch blockdev {
+		case "sda":
+			return []string{"mq-deadline", "kyber", "bfq", "none"}, "bfq", nil
+		case "vda":
+			return []string{"none"}, "none", nil
+		}
+		return nil, "", fmt.Errorf("no block device %s", blockdev)
+	}
+	sda := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "IO_SCHEDULER_sda", ActualValueJS: "bfq", ExpectedValueJS: "noop, deadline", MatchExpectation: false}
+	vda := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "IO_SCHEDULER_vda", ActualValueJS: "none", ExpectedValueJS: "noop", MatchExpectation: false}
+	compliant, _, footnote := prepareFootnote(sda, "no ", "", "NA", make([]string, 6, 6))
+	checkOut(t, compliant, "no  [5]")
+	_, _, footnote = prepareFootnote(vda, "no ", "", "NA", footnote)
+	checkOut(t, footnote[4], "[5] expected value does not contain a supported scheduler:\n      sda: expected 'noop, deadline', available 'mq-deadline kyber bfq none', current 'bfq'\n      vda: expected 'noop', available 'none', current 'none'")
+
+	// no details for an unknown device
+	unknown := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "IO_SCHEDULER_sdx", ExpectedValueJS: "noop"}
+	_, _, footnote = prepareFootnote(unknown, "no ", "", "NA", make([]string, 6, 6))
+	checkOut(t, footnote[4], footnote5)
+
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"SysctlParams[IO_SCHEDULER_sda]": sda,
+			"Inform[IO_SCHEDULER_sda]":       {ReflectFieldName: "Inform", ReflectMapKey: "IO_SCHEDULER_sda", ActualValue: "NA"},
+		},
+	}
+	buffer := bytes.Buffer{}
+	PrintFootnotesJSON(&buffer, comparisons)
+	if !strings.Contains(buffer.String(), `"detail": "sda: expected 'noop, deadline', available 'mq-deadline kyber bfq none', current 'bfq'"`) {
+		t.Error(buffer.String())
+	}
+}
+
+func TestNoteActionVerifyBaseline(t *testing.T) {
+	confDir := "/tmp/saptune_baseline_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	baselineApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	ostype, err := system.GetSysctlString("kernel.ostype")
+	if err != nil {
+		t.Skip("kernel.ostype is not available")
+	}
+	baseline := path.Join(confDir, "baseline")
+	if err := ioutil.WriteFile(baseline, []byte("kernel.ostype = "+ostype+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer := bytes.Buffer{}
+	NoteActionVerifyBaseline(&buffer, baseline, baselineApp)
+	txt := buffer.String()
+	if !strings.Contains(txt, "baseline, ") || !strings.Contains(txt, "kernel.ostype") || !strings.HasSuffix(txt, "The system fully conforms to the baseline file '"+baseline+"'.\n") {
+		t.Errorf("unexpected output '%s'", txt)
+	}
+}
+
+func TestVerifyParameter(t *testing.T) {
+	confFile := path.Join(TstFilesInGOPATH, "simpleNote.conf")
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[kernel.shmmax]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValueJS: "1024", ExpectedValueJS: "1024", MatchExpectation: true},
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", ActualValueJS: "4096", ExpectedValueJS: "4096", MatchExpectation: true},
+		},
+		"0815": {
+			"ConfFilePath":                  {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[kernel.shmmax]":   {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValueJS: "1024", ExpectedValueJS: "2048", MatchExpectation: false},
+			"OverrideParams[kernel.shmmax]": {ReflectFieldName: "OverrideParams", ReflectMapKey: "kernel.shmmax", ExpectedValueJS: "2048"},
+		},
+		"1234": {
+			"ConfFilePath":                {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", ActualValueJS: "4096", ExpectedValueJS: "4096", MatchExpectation: true},
+		},
+	}
+	filtered := filterParamComparisons(comparisons, "kernel.shmmax")
+	if len(filtered) != 2 || len(filtered["4711"]) != 2 || len(filtered["0815"]) != 3 {
+		t.Fatalf("wrong filter result: %+v", filtered)
+	}
+	noteIDs := paramNoteOrder(filtered, []string{"1234", "4711"})
+	if strings.Join(noteIDs, " ") != "4711 0815" {
+		t.Errorf("wrong order: %v", noteIDs)
+	}
+
+	oldNoColor := noColor
+	defer func() { noColor = oldNoColor }()
+	noColor = true
+	buffer := bytes.Buffer{}
+	printParamConflict(&buffer, "kernel.shmmax", filtered, noteIDs, []string{"1234", "4711", "0815"})
+	checkOut(t, buffer.String(), "The enabled notes expect different values for parameter 'kernel.shmmax':\n   4711: 1024\n   0815: 2048\nThe value of note 0815, which is applied last, is set.\n\n")
+	// a note, which is not part of the apply order, is never applied last
+	buffer.Reset()
+	printParamConflict(&buffer, "kernel.shmmax", filtered, noteIDs, []string{"1234", "4711"})
+	checkOut(t, buffer.String(), "The enabled notes expect different values for parameter 'kernel.shmmax':\n   4711: 1024\n   0815: 2048\nThe value of note 4711, which is applied last, is set.\n\n")
+	buffer.Reset()
+	printParamConflict(&buffer, "kernel.shmmax", filtered, noteIDs, []string{"1234"})
+	checkOut(t, buffer.String(), "The enabled notes expect different values for parameter 'kernel.shmmax':\n   4711: 1024\n   0815: 2048\nNone of these notes is applied, so none of the values is set.\n\n")
+	buffer.Reset()
+	printParamConflict(&buffer, "kernel.shmmni", filterParamComparisons(comparisons, "kernel.shmmni"), []string{"4711", "1234"}, []string{"4711", "1234"})
+	checkOut(t, buffer.String(), "")
+
+	confDir := "/tmp/saptune_verifyparam_test"
+	defer os.RemoveAll(confDir)
+	buffer.Reset()
+	VerifyParameter(&buffer, "kernel.shmmax", app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions))
+	checkOut(t, buffer.String(), "No notes or solutions enabled, nothing to verify.\n")
+}
+
+func TestParanoidFileProblems(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("the test needs to run as root")
+	}
+	confDir := "/tmp/saptune_paranoid_test"
+	defer os.RemoveAll(confDir)
+	ovDir := path.Join(confDir, "override")
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldOverride, oldNoteOverride := OverrideTuningSheets, note.OverrideTuningSheets
+	defer func() { OverrideTuningSheets, note.OverrideTuningSheets = oldOverride, oldNoteOverride }()
+	OverrideTuningSheets = ovDir + "/"
+	note.OverrideTuningSheets = OverrideTuningSheets
+	confFile := path.Join(confDir, "paranoidNote.conf")
+	ioutil.WriteFile(confFile, []byte("[sysctl]\nvm.swappiness = 10\n"), 0644)
+	ioutil.WriteFile(path.Join(ovDir, "paranoidNote"), []byte("[sysctl]\nvm.swappiness = 20\n"), 0644)
+	comparisons := map[string]map[string]note.FieldComparison{
+		"paranoidNote": {
+			"ConfFilePath": {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+		},
+	}
+	paranoidApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	if problems := paranoidFileProblems(comparisons, paranoidApp); len(problems) != 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	if err := os.Chmod(path.Join(ovDir, "paranoidNote"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(confFile, 4711, 0); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{path.Join(ovDir, "paranoidNote") + ": world writable", confFile + ": owned by uid 4711 instead of root"}
+	if problems := paranoidFileProblems(comparisons, paranoidApp); strings.Join(problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong problems: %v", problems)
+	}
+
+	oldParanoid := verifyParanoid
+	defer func() { verifyParanoid = oldParanoid }()
+	buffer := bytes.Buffer{}
+	verifyParanoid = false
+	if verifyParanoidFiles(&buffer, comparisons, paranoidApp) || buffer.Len() != 0 {
+		t.Errorf("files checked without '--paranoid': '%s'", buffer.String())
+	}
+	verifyParanoid = true
+	if !verifyParanoidFiles(&buffer, comparisons, paranoidApp) {
+		t.Error("insecure files not reported")
+	}
+	checkOut(t, buffer.String(), "\nFiles with insecure ownership or permissions:\n   "+expected[0]+"\n   "+expected[1]+"\n")
+}
+
+func TestFilterPrefixComparisons(t *testing.T) {
+	confFile := path.Join(TstFilesInGOPATH, "simpleNote.conf")
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                     {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[net.core.somaxconn]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "net.core.somaxconn", ActualValueJS: "4096", ExpectedValueJS: "4096", MatchExpectation: true},
+			"SysctlParams[vm.swappiness]":      {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", ActualValueJS: "60", ExpectedValueJS: "10", MatchExpectation: false},
+			"SysctlParams[reminder]":           {ReflectFieldName: "SysctlParams", ReflectMapKey: "reminder", ExpectedValueJS: "remember"},
+		},
+		"0815": {
+			"ConfFilePath":                    {ReflectFieldName: "ConfFilePath", ActualValue: confFile},
+			"SysctlParams[vm.dirty_ratio]":    {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.dirty_ratio", ActualValueJS: "20", ExpectedValueJS: "10", MatchExpectation: false},
+			"OverrideParams[vm.dirty_ratio]":  {ReflectFieldName: "OverrideParams", ReflectMapKey: "vm.dirty_ratio", ExpectedValueJS: "10"},
+			"SysctlParams[net.ipv4.tcp_slow]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "net.ipv4.tcp_slow", ActualValueJS: "1", ExpectedValueJS: "1", MatchExpectation: true},
+		},
+	}
+	filtered, unsatisfied, err := filterPrefixComparisons(comparisons, []string{"4711", "0815"}, "net.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 2 || len(filtered["4711"]) != 2 || len(filtered["0815"]) != 2 || len(unsatisfied) != 0 {
+		t.Fatalf("wrong filter result: %+v, %v", filtered, unsatisfied)
+	}
+	filtered, unsatisfied, _ = filterPrefixComparisons(comparisons, []string{"4711", "0815"}, "vm.dirty_*")
+	if len(filtered) != 1 || len(filtered["0815"]) != 3 || strings.Join(unsatisfied, " ") != "0815" {
+		t.Fatalf("wrong filter result: %+v, %v", filtered, unsatisfied)
+	}
+	filtered, unsatisfied, _ = filterPrefixComparisons(comparisons, []string{"4711", "0815"}, "vm.")
+	if len(filtered) != 2 || strings.Join(unsatisfied, " ") != "4711 0815" {
+		t.Fatalf("wrong filter result: %+v, %v", filtered, unsatisfied)
+	}
+	if _, _, err := filterPrefixComparisons(comparisons, []string{"4711", "0815"}, "fs."); err == nil {
+		t.Error("missing error for a prefix without matching parameter")
+	}
+	if _, _, err := filterPrefixComparisons(comparisons, []string{"4711", "0815"}, "vm.[dirty"); err == nil {
+		t.Error("missing error for a wrong glob pattern")
+	}
+
+	if !matchParamPrefix("net.ipv4.tcp_slow", "net.") || matchParamPrefix("kernel.net", "net.") {
+		t.Error("wrong prefix match")
+	}
+	if !matchParamPrefix("net.ipv4.tcp_slow", "net.*.tcp_*") || matchParamPrefix("net.ipv4.tcp_slow", "net.*.udp_*") {
+		t.Error("wrong glob match")
+	}
+
+	oldNoColor := noColor
+	defer func() { noColor = oldNoColor }()
+	noColor = true
+	buffer := bytes.Buffer{}
+	filtered, _, _ = filterPrefixComparisons(comparisons, []string{"4711", "0815"}, "net.")
+	PrintNoteFields(&buffer, "NONE", filtered, true)
+	if strings.Contains(buffer.String(), "vm.") || strings.Contains(buffer.String(), "remember") || !strings.Contains(buffer.String(), "net.core.somaxconn") || !strings.Contains(buffer.String(), "net.ipv4.tcp_slow") {
+		t.Errorf("wrong output: '%s'", buffer.String())
+	}
+}
+
+func TestPrintPreflightChecks(t *testing.T) {
+	var checkMatchText = `
+saptune preflight checks:
+
+   [PASS] passing: everything fine
+   [FAIL] failing: something is broken
+          hint: repair it
+   [WARN] optional: something is missing
+          hint: add it
+
+`
+	checks := []preflightCheck{
+		{"passing", true, func() (bool, string, string) { return true, "everything fine", "" }},
+		{"failing", true, func() (bool, string, string) { return false, "something is broken", "repair it" }},
+		{"optional", false, func() (bool, string, string) { return false, "something is missing", "add it" }},
+	}
+	buffer := bytes.Buffer{}
+	if failed := printPreflightChecks(&buffer, checks); failed != 1 {
+		t.Errorf("expected 1 failed mandatory check, got %d", failed)
+	}
+	checkOut(t, buffer.String(), checkMatchText)
+
+	// the version check is independent of the system
+	for _, chk := range preflightChecks("1") {
+		if chk.name == "saptune version" {
+			if passed, _, hint := chk.check(); passed || hint == "" {
+				t.Error("expected a failed version check with a hint")
+			}
+		}
+	}
+}
+
+func TestSetupAction(t *testing.T) {
+	confDir := "/tmp/saptune_setup_test"
+	defer os.RemoveAll(confDir)
+	os.RemoveAll(confDir)
+	fileName := path.Join(confDir, "etc/sysconfig/saptune")
+
+	buffer := bytes.Buffer{}
+	SetupAction(&buffer, fileName, &app.State{StateDirPrefix: confDir})
+	checkOut(t, buffer.String(), "The configuration file '"+fileName+"' has been created with the default values.\nPlease enable the solutions and notes again with 'saptune solution apply' and 'saptune note apply'.\n")
+	sconf, err := txtparser.ParseSysconfigFile(fileName, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sconf.GetString("SAPTUNE_VERSION", "") != "2" || sconf.GetString("LOG_FILE", "") != logFile || sconf.GetInt("COMMAND_TIMEOUT", 0) != 90 {
+		t.Errorf("unexpected content '%s'", sconf.ToText())
+	}
+	if _, ok := sconf.KeyValue["TUNE_FOR_NOTES"]; !ok {
+		t.Errorf("missing TUNE_FOR_NOTES in '%s'", sconf.ToText())
+	}
+
+	// an existing file is never overwritten
+	if err := ioutil.WriteFile(fileName, []byte("SAPTUNE_VERSION=\"2\"\nTUNE_FOR_NOTES=\"1410736\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	SetupAction(&buffer, fileName, &app.State{StateDirPrefix: confDir})
+	checkOut(t, buffer.String(), "The configuration file '"+fileName+"' already exists, nothing to do.\n")
+	if content, _ := ioutil.ReadFile(fileName); !strings.Contains(string(content), "1410736") {
+		t.Errorf("the file was overwritten: '%s'", string(content))
+	}
+
+	// the notes still applied according to their saved state are enabled
+	state := &app.State{StateDirPrefix: confDir}
+	if err := state.Store("noteB", note.INISettings{ID: "noteB"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Store("noteA", note.INISettings{ID: "noteA"}, true); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(state.GetPathToNote("noteB"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(fileName)
+	buffer.Reset()
+	if err := SetupAction(&buffer, fileName, state); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "The notes noteB noteA are still applied") {
+		t.Errorf("unexpected output '%s'", buffer.String())
+	}
+	sconf, err = txtparser.ParseSysconfigFile(fileName, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sconf.GetString("TUNE_FOR_NOTES", "") != "noteA noteB" || sconf.GetString("NOTE_APPLY_ORDER", "") != "noteB noteA" {
+		t.Errorf("unexpected content '%s'", sconf.ToText())
+	}
+
+	hint := missingSysconfigHint(fileName)
+	if !strings.Contains(hint, "'"+fileName+"' is missing") || !strings.Contains(hint, "saptune setup") || !strings.Contains(hint, "SAPTUNE_VERSION=\"2\"") {
+		t.Errorf("unexpected hint '%s'", hint)
+	}
+}
+
+func TestMigrateAction(t *testing.T) {
+	confDir := "/tmp/saptune_migrate_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fileName := path.Join(confDir, "saptune")
+	migSolutions := map[string]solution.Solution{"HANA": {"simpleNote", "extraNote"}, "NETWEAVER": {"simpleNote"}}
+	migState := &app.State{StateDirPrefix: confDir}
+	v1Config := "# comment kept\nSAPTUNE_VERSION=\"1\"\nTUNE_FOR_SOLUTIONS=\"HANA OLDSOL\"\nTUNE_FOR_NOTES=\"1275776 oldFile simpleNote\"\n"
+	if err := ioutil.WriteFile(fileName, []byte(v1Config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	migrateMatchText := `Migration of the configuration file '` + fileName + `' from saptune version 1 to saptune version 2:
+
+	solution OLDSOL is not available in saptune version 2 and is dropped
+	note 1275776 is not available in saptune version 2 and is dropped
+	TUNE_FOR_SOLUTIONS: 'HANA OLDSOL' -> 'HANA'
+	TUNE_FOR_NOTES: '1275776 oldFile simpleNote' -> 'oldFile'
+	NOTE_APPLY_ORDER: 'simpleNote extraNote oldFile'
+	SAPTUNE_VERSION: '1' -> '2'
+
+`
+	// dry run
+	buffer := bytes.Buffer{}
+	if err := MigrateAction(&buffer, fileName, tuningOpts, migSolutions, migState, false, false); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), migrateMatchText+"This was a dry run, nothing has been changed. Use 'saptune migrate --commit' to write the changes.\n")
+	if content, _ := ioutil.ReadFile(fileName); string(content) != v1Config {
+		t.Fatalf("the file was changed by the dry run: '%s'", string(content))
+	}
+
+	// saptune version 1 still tunes the system
+	stateFile := path.Join(confDir, app.SaptuneStateDir, "simpleNote")
+	if err := os.MkdirAll(path.Dir(stateFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(stateFile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	if err := MigrateAction(&buffer, fileName, tuningOpts, migSolutions, migState, true, false); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), migrateMatchText+"Attention: saptune version 1 still tunes the system (the tuned profile 'saptune' is active, the notes simpleNote are still applied). Please revert all solutions and notes and stop the daemon with saptune version 1 before the migration, see saptune-migrate(7).\nThis was a dry run, nothing has been changed. Use 'saptune migrate --commit' to write the changes.\n")
+	buffer.Reset()
+	err := MigrateAction(&buffer, fileName, tuningOpts, migSolutions, migState, false, true)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || !strings.Contains(exitErr.Message, "saptune version 1 still tunes the system (the notes simpleNote are still applied)") {
+		t.Fatal(err)
+	}
+	if content, _ := ioutil.ReadFile(fileName); string(content) != v1Config {
+		t.Fatalf("the file was changed: '%s'", string(content))
+	}
+	os.Remove(stateFile)
+
+	buffer.Reset()
+	if err := MigrateAction(&buffer, fileName, tuningOpts, migSolutions, migState, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buffer.String(), migrateMatchText+"The configuration file '"+fileName+"' has been migrated to saptune version 2.\n") {
+		t.Errorf("unexpected output '%s'", buffer.String())
+	}
+	sconf, err := txtparser.ParseSysconfigFile(fileName, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sconf.GetString("SAPTUNE_VERSION", "") != "2" || sconf.GetString("TUNE_FOR_SOLUTIONS", "") != "HANA" || sconf.GetString("TUNE_FOR_NOTES", "") != "oldFile" || sconf.GetString("NOTE_APPLY_ORDER", "") != "simpleNote extraNote oldFile" {
+		t.Errorf("unexpected content '%s'", sconf.ToText())
+	}
+	if !strings.Contains(sconf.ToText(), "# comment kept") {
+		t.Errorf("comment lost '%s'", sconf.ToText())
+	}
+
+	// nothing to do for version 2
+	buffer.Reset()
+	if err := MigrateAction(&buffer, fileName, tuningOpts, migSolutions, migState, false, true); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), "The configuration file '"+fileName+"' is already used by saptune version 2, nothing to migrate.\n")
+
+	// only one solution is supported
+	if err := ioutil.WriteFile(fileName, []byte("SAPTUNE_VERSION=\"1\"\nTUNE_FOR_SOLUTIONS=\"HANA NETWEAVER\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	err = MigrateAction(&buffer, fileName, tuningOpts, migSolutions, migState, false, true)
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || !strings.Contains(exitErr.Message, "supports only one solution, but the solutions HANA, NETWEAVER are enabled") {
+		t.Fatal(err)
+	}
+	if content, _ := ioutil.ReadFile(fileName); !strings.Contains(string(content), "SAPTUNE_VERSION=\"1\"") {
+		t.Errorf("the file was changed: '%s'", string(content))
+	}
+}
+
+func TestNoteActionRevert(t *testing.T) {
+	var revertMatchText = `Parameters tuned by the note have been successfully reverted.
+Please note: the reverted note may still show up in list of enabled notes, if an enabled solution refers to it.
+`
+	buffer := bytes.Buffer{}
+	nID := "simpleNote"
+	NoteActionRevert(&buffer, nID, tApp)
+	txt := buffer.String()
+	checkOut(t, txt, revertMatchText)
+}
+
+func TestPrintNoteFields(t *testing.T) {
+	//tuningOptions := note.GetTuningOptions(path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/ospackage/usr/share/saptune/notes"), "")
+	var printMatchText1 = `
+941735 -  
+
+   SAPNote, Version | Parameter           | Expected             | Override  | Actual               | Compliant
+--------------------+---------------------+----------------------+-----------+----------------------+-----------
+   941735,          | ShmFileSystemSizeMB | 1714                 |           | 488                  | no 
+   941735,          | kernel.shmmax       | 18446744073709551615 |           | 18446744073709551615 | yes
+
+
+`
+	var printMatchText2 = `
+941735 -  
+
+   Parameter           | Value set            | Value expected       | Override  | Comment
+-----------------------+----------------------+----------------------+-----------+--------------
+   ShmFileSystemSizeMB | 488                  | 1714                 |           |   
+   kernel.shmmax       | 18446744073709551615 | 18446744073709551615 |           |   
+
+
+`
+	var printMatchText3 = `   SAPNote, Version | Parameter           | Expected             | Override  | Actual               | Compliant
+--------------------+---------------------+----------------------+-----------+----------------------+-----------
+   941735,          | ShmFileSystemSizeMB | 1714                 |           | 488                  | no 
+   941735,          | kernel.shmmax       | 18446744073709551615 |           | 18446744073709551615 | yes
+
+
+`
+	var printMatchText4 = `   Parameter           | Value set            | Value expected       | Override  | Comment
+-----------------------+----------------------+----------------------+-----------+--------------
+   ShmFileSystemSizeMB | 488                  | 1714                 |           |   
+   kernel.shmmax       | 18446744073709551615 | 18446744073709551615 |           |   
+
+
+`
+	checkCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			fmt.Println("==============")
+			fmt.Println(got)
+			fmt.Println("==============")
+			fmt.Println(want)
+			fmt.Println("==============")
+			t.Errorf("Output differs from expected one")
+		}
+	}
+
+	fcomp1 := note.FieldComparison{ReflectFieldName: "ConfFilePath", ReflectMapKey: "", ActualValue: "/usr/share/saptune/notes/941735", ExpectedValue: "/usr/share/saptune/notes/941735", ActualValueJS: "/usr/share/saptune/notes/941735", ExpectedValueJS: "/usr/share/saptune/notes/941735", MatchExpectation: true}
+	fcomp2 := note.FieldComparison{ReflectFieldName: "ID", ReflectMapKey: "", ActualValue: "941735", ExpectedValue: "941735", ActualValueJS: "941735", ExpectedValueJS: "941735", MatchExpectation: true}
+	fcomp3 := note.FieldComparison{ReflectFieldName: "DescriptiveName", ReflectMapKey: "", ActualValue: "", ExpectedValue: "", ActualValueJS: "", ExpectedValueJS: "", MatchExpectation: true}
+	fcomp4 := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "ShmFileSystemSizeMB", ActualValue: "488", ExpectedValue: "1714", ActualValueJS: "488", ExpectedValueJS: "1714", MatchExpectation: false}
+	fcomp5 := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", ActualValue: "18446744073709551615", ExpectedValue: "18446744073709551615", ActualValueJS: "18446744073709551615", ExpectedValueJS: "18446744073709551615", MatchExpectation: true}
+	map941735 := map[string]note.FieldComparison{"ConfFilePath": fcomp1, "ID": fcomp2, "DescriptiveName": fcomp3, "SysctlParams[ShmFileSystemSizeMB]": fcomp4, "SysctlParams[kernel.shmmax]": fcomp5}
+	noteComp := map[string]map[string]note.FieldComparison{"941735": map941735}
+
+	t.Run("verify with header", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "HEAD", noteComp, true)
+		txt := buffer.String()
+		//txt := PrintNoteFields("HEAD", noteComp, true)
+		checkCorrectMessage(t, txt, printMatchText1)
+	})
+	t.Run("simulate with header", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "HEAD", noteComp, false)
+		txt := buffer.String()
+		//txt := PrintNoteFields("HEAD", noteComp, false)
+		checkCorrectMessage(t, txt, printMatchText2)
+	})
+	t.Run("verify without header", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "NONE", noteComp, true)
+		txt := buffer.String()
+		//txt := PrintNoteFields("NONE", noteComp, true)
+		checkCorrectMessage(t, txt, printMatchText3)
+	})
+	t.Run("simulate without header", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "NONE", noteComp, false)
+		txt := buffer.String()
+		//txt := PrintNoteFields("NONE", noteComp, false)
+		checkCorrectMessage(t, txt, printMatchText4)
+	})
+	t.Run("verify with explanation", func(t *testing.T) {
+		explainFile := "/tmp/saptune_explain_note"
+		defer os.Remove(explainFile)
+		if err := ioutil.WriteFile(explainFile, []byte("[mem]\n# size of /dev/shm\n# as recommended by SAP\nShmFileSystemSizeMB=1714\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		explainComp := map[string]note.FieldComparison{"ConfFilePath": note.FieldComparison{ReflectFieldName: "ConfFilePath", ActualValue: explainFile, ActualValueJS: explainFile}, "SysctlParams[ShmFileSystemSizeMB]": fcomp4, "SysctlParams[kernel.shmmax]": fcomp5}
+		explainMatchText := `   SAPNote, Version | Parameter           | Expected             | Override  | Actual               | Compliant
+--------------------+---------------------+----------------------+-----------+----------------------+-----------
+   941735,          | ShmFileSystemSizeMB | 1714                 |           | 488                  | no 
+                    | -> size of /dev/shm
+                    | -> as recommended by SAP
+   941735,          | kernel.shmmax       | 18446744073709551615 |           | 18446744073709551615 | yes
+
+
+`
+		explainVerify = true
+		defer func() { explainVerify = false }()
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "NONE", map[string]map[string]note.FieldComparison{"941735": explainComp}, true)
+		checkCorrectMessage(t, buffer.String(), explainMatchText)
+	})
+	t.Run("verify only deviating parameters", func(t *testing.T) {
+		diffMatchText := `   SAPNote, Version | Parameter           | Expected             | Override  | Actual               | Compliant
+--------------------+---------------------+----------------------+-----------+----------------------+-----------
+   941735,          | ShmFileSystemSizeMB | 1714                 |           | 488                  | no 
+
+
+`
+		verifyDiffOnly = true
+		defer func() { verifyDiffOnly = false }()
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "NONE", noteComp, true)
+		checkCorrectMessage(t, buffer.String(), diffMatchText)
+		// simulate is not filtered
+		buffer.Reset()
+		PrintNoteFields(&buffer, "NONE", noteComp, false)
+		checkCorrectMessage(t, buffer.String(), printMatchText4)
+	})
+	t.Run("verify only deviating parameters, all compliant", func(t *testing.T) {
+		compliantComp := map[string]note.FieldComparison{"ConfFilePath": fcomp1, "ID": fcomp2, "DescriptiveName": fcomp3, "SysctlParams[kernel.shmmax]": fcomp5}
+		compliantMatchText := `No deviating parameters found.
+
+
+`
+		verifyDiffOnly = true
+		defer func() { verifyDiffOnly = false }()
+		buffer := bytes.Buffer{}
+		PrintNoteFields(&buffer, "NONE", map[string]map[string]note.FieldComparison{"941735": compliantComp}, true)
+		checkCorrectMessage(t, buffer.String(), compliantMatchText)
+	})
+}
+
+func TestCheckUpdateLeftOvers(t *testing.T) {
+	checkUpdateLeftOvers()
+}
+
+/*
+func TestRevertAction(t *testing.T) {
+	RevertAction("all")
+	RevertAction("")
+}
+
+func TestDaemonAction(t *testing.T) {
+	DaemonAction("start")
+}
+*/
+
+func TestWaitForTuning(t *testing.T) {
+	polls := 0
+	if !waitForTuning(time.Second, time.Millisecond, func() bool { polls++; return polls == 3 }) || polls != 3 {
+		t.Errorf("tuning not detected after %d polls", polls)
+	}
+	if waitForTuning(10*time.Millisecond, time.Millisecond, func() bool { return false }) {
+		t.Error("timeout not detected")
+	}
+
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"saptune", "daemon", "start"}
+	if _, wait, _ := daemonWaitTimeout(); wait {
+		t.Error("wait without option")
+	}
+	os.Args = []string{"saptune", "daemon", "start", "--wait"}
+	if timeout, wait, _ := daemonWaitTimeout(); !wait || timeout != defaultWaitTimeout {
+		t.Errorf("wrong timeout %v", timeout)
+	}
+	os.Args = []string{"saptune", "daemon", "start", "--wait=30"}
+	if timeout, wait, _ := daemonWaitTimeout(); !wait || timeout != 30*time.Second {
+		t.Errorf("wrong timeout %v", timeout)
+	}
+	os.Args = []string{"saptune", "daemon", "start", "--wait=soon"}
+	if _, _, err := daemonWaitTimeout(); err == nil {
+		t.Error("missing error for a wrong timeout")
+	}
+}
+
+func TestDaemonActionStartDryRun(t *testing.T) {
+	confDir := "/tmp/saptune_daemondryrun_test"
+	defer os.RemoveAll(confDir)
+	dryApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	buffer := bytes.Buffer{}
+	exe := dryRunExecutor{
+		writer:        &buffer,
+		isRunning:     func(service string) bool { return service == SapconfService },
+		activeProfile: func() string { return "" },
+	}
+	DaemonActionStartDryRun(&buffer, exe, dryApp)
+	expected := `Dry run of 'saptune daemon start', the system is not changed.
+Would disable and stop sapconf.service (currently running).
+Would set the tuned profile to 'saptune' (currently 'none').
+Would enable and start tuned.service (currently not running).
+No notes or solutions enabled, tuned.service would not apply any note.
+`
+	checkOut(t, buffer.String(), expected)
+
+	buffer.Reset()
+	dryApp.TuneForSolutions = []string{"sol1"}
+	dryApp.NoteApplyOrder = []string{"4711", "0815"}
+	exe.activeProfile = func() string { return "throughput-performance" }
+	DaemonActionStartDryRun(&buffer, exe, dryApp)
+	expected = `Dry run of 'saptune daemon start', the system is not changed.
+Would disable and stop sapconf.service (currently running).
+Would set the tuned profile to 'saptune' (currently 'throughput-performance').
+Would enable and start tuned.service (currently not running).
+Enabled solutions: sol1
+tuned.service would apply the following notes in this order:
+	4711
+	0815
+`
+	checkOut(t, buffer.String(), expected)
+}
+
+func TestApplyStartDaemon(t *testing.T) {
+	buffer := bytes.Buffer{}
+	exe := dryRunExecutor{
+		writer:        &buffer,
+		isRunning:     func(service string) bool { return false },
+		activeProfile: func() string { return "" },
+	}
+	applyStartDaemon(&buffer, exe)
+	expected := `
+Enabling and starting the daemon (tuned.service) with the tuned profile 'saptune', so that the tuning is restored after a reboot.
+This stops sapconf.service and applies all enabled notes and solutions at every system start like 'saptune daemon start'.
+Would disable and stop sapconf.service (currently not running).
+Would set the tuned profile to 'saptune' (currently 'none').
+Would enable and start tuned.service (currently not running).
+Daemon (tuned.service) has been enabled and started.
+`
+	checkOut(t, buffer.String(), expected)
+}
+
+func TestTunedProfileConflict(t *testing.T) {
+	checkOut(t, tunedProfileConflict("throughput-performance", true), "tuned.service profile is incorrect. The active tuned profile is 'throughput-performance' instead of 'saptune', so tuned applies the settings of profile 'throughput-performance'.\nsapconf.service is running and tunes the system, too. saptune and sapconf must not be used at the same time.\nIf you wish to correct it, run `saptune daemon start`.\n")
+	checkOut(t, tunedProfileConflict("", false), "tuned.service profile is incorrect. No tuned profile is active instead of 'saptune'.\nsapconf.service is not running.\nIf you wish to correct it, run `saptune daemon start`.\n")
+}
+
+func TestVersionActionDetailed(t *testing.T) {
+	oldNoteTuningSheets := NoteTuningSheets
+	oldExtraTuningSheets := ExtraTuningSheets
+	defer func() {
+		NoteTuningSheets = oldNoteTuningSheets
+		ExtraTuningSheets = oldExtraTuningSheets
+	}()
+	NoteTuningSheets = OSNotesInGOPATH + "/"
+	ExtraTuningSheets = TstFilesInGOPATH + "/"
+	_, noteFiles := system.ListDir(NoteTuningSheets, "")
+
+	buffer := bytes.Buffer{}
+	VersionActionDetailed(&buffer, "2")
+	txt := buffer.String()
+	for _, expected := range []string{
+		"current active saptune version is '2'\n",
+		"   binary version:           unknown\n",
+		"   go version:               " + runtime.Version() + "\n",
+		"   architecture:             " + runtime.GOARCH + "\n",
+		fmt.Sprintf("   Note definitions:         %d from %s\n", len(noteFiles), NoteTuningSheets),
+	} {
+		if !strings.Contains(txt, expected) {
+			t.Errorf("missing '%s' in output '%s'", expected, txt)
+		}
+	}
+	if !strings.Contains(txt, "   vendor Note definitions:  ") || strings.Contains(txt, "vendor Note definitions:  0 ") {
+		t.Errorf("wrong number of vendor notes in output '%s'", txt)
+	}
+}
+
+func TestPrintHelpAndExit(t *testing.T) {
+	exitCode := 0
+	if os.Getenv("DO_EXIT") == "1" {
+		PrintHelpAndExit(9)
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=TestPrintHelpAndExit")
+	cmd.Env = append(os.Environ(), "DO_EXIT=1")
+	err := cmd.Run()
+	e, ok := err.(*exec.ExitError)
+	if ok {
+		ws := e.Sys().(syscall.WaitStatus)
+		exitCode = ws.ExitStatus()
+		if exitCode != 9 {
+			t.Fatalf("process ran with err %v, want exit status 9", err)
+		}
+		if !e.Success() {
+			return
+		}
+	}
+	t.Fatalf("process ran with err %v, want exit status 9", err)
+}
+
+func TestNoteActionVerifyWatch(t *testing.T) {
+	stop := make(chan os.Signal, 1)
+	stop <- os.Interrupt
+	buffer := bytes.Buffer{}
+	if err := NoteActionVerifyWatch(&buffer, "simpleNote", time.Second, false, stop, tApp); err != nil {
+		t.Fatal(err)
+	}
+	txt := buffer.String()
+	if !strings.HasPrefix(txt, "Every 1s: saptune note verify simpleNote    ") || !strings.Contains(txt, "net.ipv4.ip_local_port_range") || strings.Contains(txt, "<-- changed") || strings.Contains(txt, clearScreen) {
+		t.Errorf("wrong output '%s'", txt)
+	}
+
+	verifySince = "last"
+	if err := NoteActionVerifyWatch(&buffer, "simpleNote", time.Second, false, stop, tApp); err == nil {
+		t.Error("expected an error for the option '--since'")
+	}
+	verifySince = ""
+}
+
+func TestComplianceChangedParams(t *testing.T) {
+	previous := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                {ReflectFieldName: "ConfFilePath", ActualValue: "/tmp/4711"},
+			"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", MatchExpectation: true},
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", MatchExpectation: false},
+		},
+	}
+	current := map[string]map[string]note.FieldComparison{
+		"4711": {
+			"ConfFilePath":                {ReflectFieldName: "ConfFilePath", ActualValue: "/tmp/4711"},
+			"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", MatchExpectation: false},
+			"SysctlParams[kernel.shmmni]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmni", MatchExpectation: false},
+			"SysctlParams[kernel.shmmax]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "kernel.shmmax", MatchExpectation: false},
+		},
+	}
+	if changed := complianceChangedParams(nil, current); len(changed) != 0 {
+		t.Errorf("unexpected changes %v", changed)
+	}
+	changed := complianceChangedParams(previous, current)
+	if len(changed) != 1 || len(changed["4711"]) != 1 || !changed["4711"]["vm.swappiness"] {
+		t.Errorf("unexpected changes %v", changed)
+	}
+
+	// the changed parameters are marked in the verify table
+	_, comparisons, _, err := tApp.VerifyNote("simpleNote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchChanged = map[string]map[string]bool{"simpleNote": {"net.ipv4.ip_local_port_range": true}}
+	defer func() { watchChanged = map[string]map[string]bool{} }()
+	oldNoColor := noColor
+	defer func() { noColor = oldNoColor }()
+	noColor = true
+	buffer := bytes.Buffer{}
+	PrintNoteFields(&buffer, "NONE", map[string]map[string]note.FieldComparison{"simpleNote": comparisons}, true)
+	if !strings.Contains(buffer.String(), " <-- changed\n") {
+		t.Errorf("missing mark in '%s'", buffer.String())
+	}
+}
+
+func TestNoteActionVerifyExitCode(t *testing.T) {
+	if os.Getenv("DO_EXIT") == "1" {
+		exitOnError(NoteActionVerify(os.Stdout, "extraNote", tApp))
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=TestNoteActionVerifyExitCode")
+	cmd.Env = append(os.Environ(), "DO_EXIT=1")
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); ok {
+		if exitCode := e.Sys().(syscall.WaitStatus).ExitStatus(); exitCode != exitNotCompliant {
+			t.Fatalf("process ran with err %v, want exit status %d", err, exitNotCompliant)
+		}
+		return
+	}
+	t.Fatalf("process ran with err %v, want exit status %d", err, exitNotCompliant)
+}
+
+func TestExitError(t *testing.T) {
+	exErr := newExitError("Failed to tune for note %s: %v", "4711", fmt.Errorf("no space left"))
+	if exErr.Code != 1 || exErr.Error() != "Failed to tune for note 4711: no space left\n" {
+		t.Errorf("unexpected error %+v", exErr)
+	}
+	// the exit code of a failed command is kept
+	cmdErr := exec.Command("/bin/sh", "-c", "exit 3").Run()
+	if exErr := newExitError("command failed: %v", cmdErr); exErr.Code != 3 {
+		t.Errorf("unexpected exit code %d", exErr.Code)
+	}
+
+	// a deviating note is reported by the error, not by terminating
+	err := NoteActionVerify(ioutil.Discard, "extraNote", tApp)
+	var verifyErr *ExitError
+	if !errors.As(err, &verifyErr) || verifyErr.Code != exitNotCompliant {
+		t.Errorf("unexpected error %v", err)
+	}
+	// a missing Note ID prints the help text
+	err = NoteActionShow(ioutil.Discard, "", false, tApp)
+	if !errors.As(err, &verifyErr) || verifyErr.Code != 1 || !verifyErr.Help {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := NoteActionShow(ioutil.Discard, "unknownNote", false, tApp); !errors.As(err, &verifyErr) || verifyErr.Code != 1 {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	comparisons := map[string]map[string]note.FieldComparison{
+		"4711": {"SysctlParams[vm.swappiness]": {ReflectFieldName: "SysctlParams", ReflectMapKey: "vm.swappiness", MatchExpectation: false}},
+	}
+	if err := deviationError(comparisons, "deviation"); !errors.As(err, &verifyErr) || verifyErr.Code != exitNotCompliant || verifyErr.Message != "deviation" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := insecureFilesError(false); err != nil {
+		t.Error(err)
+	}
+	if err := insecureFilesError(true); !errors.As(err, &verifyErr) || verifyErr.Code != exitNotCompliant {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// the nagios state line is printed, so the error has no message
+	outputFormat = "nagios"
+	defer func() { outputFormat = "" }()
+	buffer := bytes.Buffer{}
+	done, err := printVerifyFormat(&buffer, comparisons, []string{"4711"})
+	if !done || !errors.As(err, &verifyErr) || verifyErr.Code != nagiosCritical || verifyErr.Message != "" {
+		t.Errorf("unexpected result %v %v", done, err)
+	}
+	if !strings.HasPrefix(buffer.String(), "SAPTUNE CRITICAL") {
+		t.Errorf("unexpected output '%s'", buffer.String())
+	}
+
+	// the other formats return the deviation without message, too
+	outputFormat = "prometheus"
+	buffer.Reset()
+	done, err = printVerifyFormat(&buffer, comparisons, []string{"4711"})
+	if !done || !errors.As(err, &verifyErr) || verifyErr.Code != exitNotCompliant || verifyErr.Message != "" {
+		t.Errorf("unexpected result %v %v", done, err)
+	}
+	if done, err := printVerifyFormat(&buffer, comparisons, []string{}); !done || err != nil {
+		t.Errorf("unexpected result %v %v", done, err)
+	}
+	outputFormat = ""
+	footnotesFormat = "json"
+	defer func() { footnotesFormat = "" }()
+	done, err = printVerifyFormat(&buffer, comparisons, []string{"4711"})
+	if !done || !errors.As(err, &verifyErr) || verifyErr.Code != exitNotCompliant || verifyErr.Message != "" {
+		t.Errorf("unexpected result %v %v", done, err)
+	}
+}
+
+func TestNoteActionShow(t *testing.T) {
+	oldExtraTuningSheets := ExtraTuningSheets
+	oldOverrideTuningSheets := OverrideTuningSheets
+	oldNoteOverrideTuningSheets := note.OverrideTuningSheets
+	defer func() {
+		ExtraTuningSheets = oldExtraTuningSheets
+		OverrideTuningSheets = oldOverrideTuningSheets
+		note.OverrideTuningSheets = oldNoteOverrideTuningSheets
+	}()
+	ExtraTuningSheets = TstFilesInGOPATH + "/"
+	OverrideTuningSheets = "/tmp/saptune_override_test/"
+	note.OverrideTuningSheets = OverrideTuningSheets
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(OverrideTuningSheets)
+
+	cont, err := ioutil.ReadFile(path.Join(TstFilesInGOPATH, "simpleNote.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawMatchText := fmt.Sprintf("\nContent of Note simpleNote:\n%s\n", string(cont))
+	// no override file available
+	buffer := bytes.Buffer{}
+	NoteActionShow(&buffer, "simpleNote", false, tApp)
+	checkOut(t, buffer.String(), rawMatchText)
+
+	overrideFile := path.Join(OverrideTuningSheets, "simpleNote")
+	if err := ioutil.WriteFile(overrideFile, []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 32768 60999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	NoteActionShow(&buffer, "simpleNote", true, tApp)
+	checkOut(t, buffer.String(), rawMatchText)
+
+	showMatchText := `
+Content of Note simpleNote with the values of the override file '/tmp/saptune_override_test/simpleNote' (O denotes lines taken from the override file):
+  [version]
+  # SAP-NOTE=simpleNote CATEGORY=simple VERSION=1 DATE=09.07.2019 NAME="Configuration drop in for simple tests" 
+
+  [sysctl]
+O net.ipv4.ip_local_port_range = 32768 60999
+
+  [reminder]
+  # Text to ignore for apply but to display.
+  # Everything the customer should know about this note, especially
+  # which parameters are NOT handled and the reason.
+`
+	buffer.Reset()
+	NoteActionShow(&buffer, "simpleNote", false, tApp)
+	checkOut(t, buffer.String(), showMatchText)
+}
+
+func TestEditOverrideFile(t *testing.T) {
+	ovDir := "/tmp/saptune_edit_override"
+	defer os.RemoveAll(ovDir)
+	ovFileName := path.Join(ovDir, "simpleNote")
+	baseFileName := path.Join(TstFilesInGOPATH, "simpleNote.conf")
+	editWith := func(contents ...string) func(string) error {
+		return func(fileName string) error {
+			content := contents[0]
+			contents = contents[1:]
+			return ioutil.WriteFile(fileName, []byte(content), 0644)
+		}
+	}
+	leftovers := func() {
+		files, _ := ioutil.ReadDir(ovDir)
+		for _, file := range files {
+			if file.Name() != "simpleNote" {
+				t.Errorf("temporary file '%s' not removed", file.Name())
+			}
+		}
+	}
+
+	// invalid content, discard the changes
+	buffer := bytes.Buffer{}
+	changed, err := editOverrideFile(strings.NewReader("d\n"), &buffer, ovFileName, baseFileName, editWith("[cpu]\nno_such_option = 1\n"))
+	if err != nil || changed {
+		t.Errorf("expected discarded changes, got '%v', '%v'", changed, err)
+	}
+	if _, err := os.Stat(ovFileName); !os.IsNotExist(err) {
+		t.Errorf("override file was created: %v", err)
+	}
+	if !strings.Contains(buffer.String(), "The edited override file is invalid:") {
+		t.Errorf("wrong output: '%s'", buffer.String())
+	}
+	leftovers()
+
+	// invalid content, edit again
+	buffer.Reset()
+	changed, err = editOverrideFile(strings.NewReader("e\n"), &buffer, ovFileName, baseFileName, editWith("[cpu]\nno_such_option = 1\n", "[sysctl]\nvm.swappiness = 10\n"))
+	if err != nil || !changed {
+		t.Errorf("expected changed override file, got '%v', '%v'", changed, err)
+	}
+	content, _ := ioutil.ReadFile(ovFileName)
+	checkOut(t, string(content), "[sysctl]\nvm.swappiness = 10\n")
+	leftovers()
+
+	// the editor fails, the override file is left untouched
+	changed, err = editOverrideFile(strings.NewReader(""), &buffer, ovFileName, ovFileName, func(string) error { return fmt.Errorf("no editor") })
+	if err == nil || changed {
+		t.Errorf("expected an error, got '%v', '%v'", changed, err)
+	}
+	content, _ = ioutil.ReadFile(ovFileName)
+	checkOut(t, string(content), "[sysctl]\nvm.swappiness = 10\n")
+	leftovers()
+}
+
+func TestCustomiseOverride(t *testing.T) {
+	base := []string{"[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 32768\nkernel.shmmax >= 1024\n[grub]\nnuma_balancing=disable\n[service]\nuuidd.socket = start\n"}
+	override := "[sysctl]\n# keep the default\nvm.swappiness = 60\n\n[service]\nuuidd.socket = stop\n"
+	content, err := customiseOverride(override, base, []string{"kernel.shmmax=2048", "vm.swappiness=30", "numa_balancing=enable", "kernel.shmmni="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[sysctl]\n# keep the default\nvm.swappiness = 30\nkernel.shmmax >= 2048\nkernel.shmmni =\n\n[service]\nuuidd.socket = stop\n\n[grub]\nnuma_balancing = enable\n"
+	checkOut(t, content, expected)
+
+	content, err = customiseOverride("", base, []string{"vm.swappiness=30", "vm.swappiness=40"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, content, "[sysctl]\nvm.swappiness = 40\n")
+
+	for _, set := range []string{"vm.dirty_ratio=10", "noValue", "=10"} {
+		if _, err := customiseOverride(override, base, []string{set}); err == nil {
+			t.Errorf("expected an error for '%s'", set)
+		}
+	}
+
+	// the parameters are matched as written in the Note definition and
+	// its included files, not as expanded by the parser
+	base = []string{"[block]\nIO_SCHEDULER = bfq\nNRREQ[nvme*] = 1024\n[systemd]\nuuidd.service:LimitNOFILE = 4096\n", "[sysctl:kernel>=5.3]\nvm.swappiness = 10\n"}
+	content, err = customiseOverride("[block]\nIO_SCHEDULER = none\n", base, []string{"IO_SCHEDULER=mq-deadline", "NRREQ[nvme*]=512", "uuidd.service:LimitNOFILE=8192", "vm.swappiness=30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, content, "[block]\nIO_SCHEDULER = mq-deadline\nNRREQ[nvme*] = 512\n\n[systemd]\nuuidd.service:LimitNOFILE = 8192\n\n[sysctl]\nvm.swappiness = 30\n")
+	for _, set := range []string{"IO_SCHEDULER_sda=none", "NRREQ=512", "LimitNOFILE=8192"} {
+		if _, err := customiseOverride("", base, []string{set}); err == nil {
+			t.Errorf("expected an error for '%s'", set)
+		}
+	}
+}
+
+func TestNoteActionCustomiseSet(t *testing.T) {
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_customise_test/"
+	defer os.RemoveAll(OverrideTuningSheets)
+
+	buffer := bytes.Buffer{}
+	NoteActionCustomiseSet(&buffer, "simpleNote", []string{"net.ipv4.ip_local_port_range=32768 60999"}, tApp)
+	checkOut(t, buffer.String(), "The override file '/tmp/saptune_customise_test/simpleNote' of Note simpleNote has been updated.\n")
+	content, err := ioutil.ReadFile(path.Join(OverrideTuningSheets, "simpleNote"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, string(content), "[sysctl]\nnet.ipv4.ip_local_port_range = 32768 60999\n")
+}
+
+func TestNoteActionCustomiseWizard(t *testing.T) {
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_customise_test/"
+	defer os.RemoveAll(OverrideTuningSheets)
+	ovFileName := path.Join(OverrideTuningSheets, "simpleNote")
+
+	// keep the recommended value
+	paramFile := note.GetPathToParameter("net.ipv4.ip_local_port_range")
+	_, paramErr := os.Stat(paramFile)
+	buffer := bytes.Buffer{}
+	NoteActionCustomiseWizard(strings.NewReader("\n"), &buffer, "simpleNote", tApp)
+	if _, err := os.Stat(paramFile); os.IsNotExist(paramErr) && !os.IsNotExist(err) {
+		os.Remove(paramFile)
+		t.Error("saved state file of the parameter created")
+	}
+	if !strings.Contains(buffer.String(), "[sysctl] net.ipv4.ip_local_port_range\n") || !strings.Contains(buffer.String(), "    recommended value: 31768\t61999\n") {
+		t.Error(buffer.String())
+	}
+	if !strings.HasSuffix(buffer.String(), "No parameter changed, the override file of Note simpleNote is left untouched.\n") {
+		t.Error(buffer.String())
+	}
+	// discard the changes
+	buffer.Reset()
+	NoteActionCustomiseWizard(strings.NewReader("32768 60999\nn\n"), &buffer, "simpleNote", tApp)
+	if !strings.HasSuffix(buffer.String(), "Changes discarded, the override file of Note simpleNote is left untouched.\n") {
+		t.Error(buffer.String())
+	}
+	if _, err := os.Stat(ovFileName); !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	// write the changes
+	buffer.Reset()
+	NoteActionCustomiseWizard(strings.NewReader("32768 60999\ny\n"), &buffer, "simpleNote", tApp)
+	if !strings.Contains(buffer.String(), "    net.ipv4.ip_local_port_range = 32768 60999\n") {
+		t.Error(buffer.String())
+	}
+	content, err := ioutil.ReadFile(ovFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, string(content), "[sysctl]\nnet.ipv4.ip_local_port_range = 32768 60999\n")
+}
+
+func TestWizardValue(t *testing.T) {
+	values := map[string]string{"vm.swappiness": "10", "IO_SCHEDULER_sda": "none", "IO_SCHEDULER_sdb": "mq-deadline", "net.ipv4.conf.eth0.rp_filter": "1", "net.ipv4.conf.lo.rp_filter": "0"}
+	for _, tst := range []struct {
+		param    definitionParam
+		expected string
+	}{
+		{definitionParam{section: "sysctl", key: "vm.swappiness"}, "10"},
+		{definitionParam{section: "sysctl", key: "vm.dirty_ratio"}, ""},
+		{definitionParam{section: "block", key: "IO_SCHEDULER"}, "none (IO_SCHEDULER_sda), mq-deadline (IO_SCHEDULER_sdb)"},
+		{definitionParam{section: "block", key: "IO_SCHEDULER[sd*]"}, "none (IO_SCHEDULER_sda), mq-deadline (IO_SCHEDULER_sdb)"},
+		{definitionParam{section: "sysctl", key: "net.ipv4.conf.{iface}.rp_filter"}, "1 (net.ipv4.conf.eth0.rp_filter), 0 (net.ipv4.conf.lo.rp_filter)"},
+	} {
+		if value := wizardValue(values, tst.param); value != tst.expected {
+			t.Errorf("'%s': expected '%s', got '%s'", tst.param.key, tst.expected, value)
+		}
+	}
+}
+
+func TestResolveNoteOverride(t *testing.T) {
+	content := `[service]
+uuidd.socket = start
+sysstat.service = start
+[rpm]
+glibc all 2.22-51.6
+[grub]
+numa_balancing=disable
+transparent_hugepage=never
+[limits]
+LIMITS = @sapsys hard nofile 65536`
+	override := `[service]
+sysstat = stop
+[rpm]
+glibc all 2.22-100.1
+[grub]
+numa_balancing=
+[limits]
+LIMITS = @sapsys hard nofile 1048576
+[check_only]`
+	expected := []string{
+		"  [service]",
+		"  uuidd.socket = start",
+		"O sysstat = stop",
+		"  [rpm]",
+		"O glibc all 2.22-100.1",
+		"  [grub]",
+		"O numa_balancing=",
+		"  transparent_hugepage=never",
+		"  [limits]",
+		"O LIMITS = @sapsys hard nofile 1048576",
+		"",
+		"O [check_only]",
+	}
+	resolved := resolveNoteOverride(content, override)
+	if strings.Join(resolved, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got: '%+v', expected: '%+v'", resolved, expected)
+	}
+}
+
+func TestNoteActionConflicts(t *testing.T) {
+	oldApplyOrder := tApp.NoteApplyOrder
+	defer func() { tApp.NoteApplyOrder = oldApplyOrder }()
+	tApp.NoteApplyOrder = []string{"extraNote", "simpleNote"}
+	buffer := bytes.Buffer{}
+	NoteActionConflicts(&buffer, tApp)
+	checkOut(t, buffer.String(), "\nNo conflicting parameters found in the enabled notes.\n\n")
+
+	confDir := "/tmp/saptune_conflicts_test"
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(confDir)
+	conflictFile := path.Join(confDir, "conflictNote")
+	if err := ioutil.WriteFile(conflictFile, []byte("[vm]\nTHP=never\nKSM=1\n[sysctl]\nvm.dirty_ratio = 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes := map[string]note.Note{
+		"extraNote":    note.INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "extraNote.conf"), ID: "extraNote"},
+		"conflictNote": note.INISettings{ConfFilePath: conflictFile, ID: "conflictNote"},
+	}
+	conflictApp := app.InitialiseApp(confDir, "", notes, AllTestSolutions)
+	conflictApp.NoteApplyOrder = []string{"conflictNote", "extraNote"}
+	conflictsMatchText := `
+Parameters set to different values by the enabled notes:
+
+   Parameter      | Note         | Value  | Wins
+------------------+--------------+--------+-----
+   THP            | conflictNote | never  | 
+                  | extraNote    | always | yes
+   vm.dirty_ratio | conflictNote | 20     | 
+                  | extraNote    | 10     | yes
+
+The value of the note applied last wins.
+current order of applied notes is: conflictNote extraNote
+
+`
+	buffer.Reset()
+	NoteActionConflicts(&buffer, conflictApp)
+	checkOut(t, buffer.String(), conflictsMatchText)
+}
+
+func TestNoteActionMove(t *testing.T) {
+	confDir := "/tmp/saptune_move_test"
+	defer os.RemoveAll(confDir)
+	moveApp := app.InitialiseApp(confDir, "", tuningOpts, AllTestSolutions)
+	moveApp.TuneForNotes = []string{"extraNote", "simpleNote"}
+	moveApp.NoteApplyOrder = []string{"extraNote", "simpleNote"}
+	moveMatchText := `Note simpleNote moved before Note extraNote.
+
+current order of applied notes is: simpleNote extraNote
+
+The new order takes effect the next time the enabled notes are applied.
+`
+	buffer := bytes.Buffer{}
+	NoteActionMove(&buffer, "simpleNote", "before", "extraNote", moveApp)
+	checkOut(t, buffer.String(), moveMatchText)
+}
+
+func TestNoteActionValidate(t *testing.T) {
+	validateMatchText := fmt.Sprintf("The definition of Note simpleNote is valid (%s).\n", path.Join(TstFilesInGOPATH, "simpleNote.conf"))
+	buffer := bytes.Buffer{}
+	NoteActionValidate(&buffer, "simpleNote", tApp)
+	txt := buffer.String()
+	checkOut(t, txt, validateMatchText)
+}
+
+func TestNoteActionValidateExitCode(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := NoteActionValidate(&buffer, "extraNote", tApp)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("unexpected error %v, want exit status 1", err)
+	}
+	if !strings.Contains(buffer.String(), "extraNote.conf:30: unknown parameter 'PAGECACHE_LIMIT_IGNORE_DIRTY' in section '[pagecache]'") {
+		t.Errorf("missing problem report in output '%s'", buffer.String())
+	}
+}
+
+func TestNoteActionDiff(t *testing.T) {
+	var diffMatchText = `
+Differences between Note simpleNote and Note oldFile:
+
+   Parameter                    | simpleNote  | oldFile
+--------------------------------+-------------+--------
+   net.ipv4.ip_local_port_range | 31768 61999 |        
+
+`
+	var noDiffMatchText = `
+Differences between Note simpleNote and Note simpleNote:
+
+   (no difference)
+
+`
+	buffer := bytes.Buffer{}
+	NoteActionDiff(&buffer, "simpleNote", "oldFile", tApp)
+	checkOut(t, buffer.String(), diffMatchText)
+
+	buffer = bytes.Buffer{}
+	NoteActionDiff(&buffer, "simpleNote", "simpleNote", tApp)
+	checkOut(t, buffer.String(), noDiffMatchText)
+
+	// the parameters are compared per section and a parameter without
+	// value is reported, if the other note does not define it
+	confDir := "/tmp/saptune_notediff_test"
+	defer os.RemoveAll(confDir)
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	diffFile1 := path.Join(confDir, "diffNote1.conf")
+	diffFile2 := path.Join(confDir, "diffNote2.conf")
+	if err := ioutil.WriteFile(diffFile1, []byte("[version]\n# SAP-NOTE=diffNote1 CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"diff test\"\n[sysctl]\nvm.swappiness =\nkernel.numa_balancing = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(diffFile2, []byte("[version]\n# SAP-NOTE=diffNote2 CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"diff test\"\n[sys]\nkernel.numa_balancing = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diffApp := app.InitialiseApp(confDir, confDir, note.TuningOptions{"diffNote1": note.INISettings{ConfFilePath: diffFile1, ID: "diffNote1"}, "diffNote2": note.INISettings{ConfFilePath: diffFile2, ID: "diffNote2"}}, AllTestSolutions)
+	var sectionMatchText = `
+Differences between Note diffNote1 and Note diffNote2:
+
+   Parameter                      | diffNote1 | diffNote2
+----------------------------------+-----------+----------
+   [sys] kernel.numa_balancing    |           | 0        
+   [sysctl] kernel.numa_balancing | 0         |          
+   vm.swappiness                  |           |          
+
+`
+	buffer = bytes.Buffer{}
+	if err := NoteActionDiff(&buffer, "diffNote1", "diffNote2", diffApp); err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, buffer.String(), sectionMatchText)
+}
+
+func TestColorize(t *testing.T) {
+	noColor = false
+	if txt := colorize("text", setRedText); txt != setRedText+"text"+resetTextColor {
+		t.Errorf("got: '%s'", txt)
+	}
+	noColor = true
+	if txt := colorize("text", setRedText); txt != "text" {
+		t.Errorf("got: '%s'", txt)
+	}
+	noColor = false
+}
+
+func TestCommandTimeout(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"saptune", "daemon", "start"}
+	if timeout, _ := commandTimeout(90); timeout != 90*time.Second {
+		t.Errorf("got: '%v'", timeout)
+	}
+	if timeout, _ := commandTimeout(0); timeout != 0 {
+		t.Errorf("got: '%v'", timeout)
+	}
+	os.Args = []string{"saptune", "--timeout=5m", "daemon", "start"}
+	if timeout, _ := commandTimeout(90); timeout != 5*time.Minute {
+		t.Errorf("got: '%v'", timeout)
+	}
+	os.Args = []string{"saptune", "--timeout", "0", "daemon", "start"}
+	if timeout, _ := commandTimeout(90); timeout != 0 {
+		t.Errorf("got: '%v'", timeout)
+	}
+	if arg := cliArg(1); arg != "daemon" {
+		t.Errorf("got: '%s'", arg)
+	}
+	if _, err := commandTimeout(-1); err != nil {
+		t.Errorf("the option needs to override the sysconfig value: %v", err)
+	}
+	os.Args = []string{"saptune", "--timeout=soon", "daemon", "start"}
+	if _, err := commandTimeout(90); err == nil {
+		t.Error("missing error for a wrong timeout")
+	}
+	os.Args = []string{"saptune", "daemon", "start"}
+	if _, err := commandTimeout(-1); err == nil {
+		t.Error("missing error for a negative COMMAND_TIMEOUT")
+	}
+}
+
+func TestCliArgs(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"saptune", "--no-color", "note", "verify", "--format=json", "1410736"}
+	if arg := cliArg(1); arg != "note" {
+		t.Errorf("got: '%s'", arg)
+	}
+	if arg := cliArg(3); arg != "1410736" {
+		t.Errorf("got: '%s'", arg)
+	}
+	if arg := cliArg(4); arg != "" {
+		t.Errorf("got: '%s'", arg)
+	}
+	if !cliFlag("no-color") || !cliFlag("format") || cliFlag("raw") {
+		t.Errorf("wrong flag detection")
+	}
+	if val := cliFlagValue("format"); val != "json" {
+		t.Errorf("got: '%s'", val)
+	}
+	if val := cliFlagValue("no-color"); val != "" {
+		t.Errorf("got: '%s'", val)
+	}
+	if args := cliArgsFrom(2); strings.Join(args, " ") != "verify 1410736" {
+		t.Errorf("got: '%v'", args)
+	}
+	if args := cliArgsFrom(4); len(args) != 0 {
+		t.Errorf("got: '%v'", args)
+	}
+
+	// options with the value in the following parameter
+	os.Args = []string{"saptune", "note", "customise", "--set", "vm.swappiness=10", "1410736", "--set=kernel.shmmni=4096", "--listen", ":8080"}
+	if args := cliArgsFrom(1); strings.Join(args, " ") != "note customise 1410736" {
+		t.Errorf("got: '%v'", args)
+	}
+	if vals := cliFlagValues("set"); strings.Join(vals, " ") != "vm.swappiness=10 kernel.shmmni=4096" {
+		t.Errorf("got: '%v'", vals)
+	}
+	if val := cliFlagValue("listen"); val != ":8080" {
+		t.Errorf("got: '%s'", val)
+	}
+
+	// list of Note IDs
+	os.Args = []string{"saptune", "solution", "apply", "HANA", "--except", "1410736,2382421", "--except=941735"}
+	if args := cliArgsFrom(1); strings.Join(args, " ") != "solution apply HANA" {
+		t.Errorf("got: '%v'", args)
+	}
+	if ids := cliNoteIDs("except"); strings.Join(ids, " ") != "1410736 2382421 941735" {
+		t.Errorf("got: '%v'", ids)
+	}
+	if ids := cliNoteIDs("only"); len(ids) != 0 {
+		t.Errorf("got: '%v'", ids)
+	}
+}
+
+func TestTuningDirectory(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"saptune", "note", "list"}
+	os.Unsetenv("SAPTUNE_NOTE_DIR")
+	if dir := tuningDirectory("SAPTUNE_NOTE_DIR", "note-dir", "/usr/share/saptune/notes/"); dir != "/usr/share/saptune/notes/" {
+		t.Errorf("got: '%s'", dir)
+	}
+	os.Setenv("SAPTUNE_NOTE_DIR", "/tmp/env_notes")
+	defer os.Unsetenv("SAPTUNE_NOTE_DIR")
+	if dir := tuningDirectory("SAPTUNE_NOTE_DIR", "note-dir", "/usr/share/saptune/notes/"); dir != "/tmp/env_notes/" {
+		t.Errorf("got: '%s'", dir)
+	}
+	// command line option takes precedence
+	os.Args = []string{"saptune", "--note-dir=/tmp/flag_notes/", "note", "list"}
+	if dir := tuningDirectory("SAPTUNE_NOTE_DIR", "note-dir", "/usr/share/saptune/notes/"); dir != "/tmp/flag_notes/" {
+		t.Errorf("got: '%s'", dir)
+	}
+}
+
+func TestStatusAction(t *testing.T) {
+	buffer := bytes.Buffer{}
+	StatusAction(&buffer, "json", tApp)
+	status := saptuneStatus{}
+	if err := json.Unmarshal(buffer.Bytes(), &status); err != nil {
+		t.Fatalf("invalid json output '%s': %v", buffer.String(), err)
+	}
+	if status.ProfileCorrect != (status.TunedProfile == TunedProfileName) {
+		t.Errorf("wrong profile state: %+v", status)
+	}
+	compliant := true
+	for _, noteCompliant := range status.NotesCompliance {
+		compliant = compliant && noteCompliant
+	}
+	if compliant != status.Compliant {
+		t.Errorf("wrong compliance state: %+v", status)
+	}
+
+	buffer = bytes.Buffer{}
+	StatusAction(&buffer, "", tApp)
+	if !strings.Contains(buffer.String(), "system compliant:") {
+		t.Errorf("wrong output: '%s'", buffer.String())
+	}
+}
+
+func TestDaemonActionStatusQuiet(t *testing.T) {
+	confDir := "/tmp/saptune_daemonstatus_test"
+	defer os.RemoveAll(confDir)
+	statusApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	expected := exitNotTuned
+	if !system.SystemctlIsRunning(TunedService) {
+		expected = exitTunedStopped
+	} else if activeTunedProfile() != TunedProfileName {
+		expected = exitTunedWrongProfile
+	}
+	buffer := bytes.Buffer{}
+	err := DaemonActionStatus(&buffer, false, true, statusApp)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != expected || exitErr.Message != "" {
+		t.Errorf("expected exit code %d without message, got '%v'", expected, err)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("nothing expected in quiet mode: '%s'", buffer.String())
+	}
+	err = DaemonActionStatus(&buffer, true, true, statusApp)
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || !strings.Contains(exitErr.Message, "can not be used together") {
+		t.Errorf("expected an error for '--json --quiet', got '%v'", err)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("nothing expected for '--json --quiet': '%s'", buffer.String())
+	}
+}
+
+func TestDaemonActionStatusJSON(t *testing.T) {
+	confDir := "/tmp/saptune_daemonstatus_test"
+	defer os.RemoveAll(confDir)
+	statusApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	buffer := bytes.Buffer{}
+	DaemonActionStatusJSON(&buffer, statusApp)
+	if !strings.Contains(buffer.String(), `"tunedForNotes": []`) {
+		t.Errorf("empty list expected: '%s'", buffer.String())
+	}
+
+	statusApp.TuneForSolutions = []string{"sol1"}
+	statusApp.TuneForNotes = []string{"simpleNote"}
+	buffer.Reset()
+	DaemonActionStatusJSON(&buffer, statusApp)
+	status := daemonStatus{}
+	if err := json.Unmarshal(buffer.Bytes(), &status); err != nil {
+		t.Fatalf("invalid json output '%s': %v", buffer.String(), err)
+	}
+	if status.ProfileCorrect != (status.ActiveProfile == TunedProfileName) || status.Running != system.SystemctlIsRunning(TunedService) {
+		t.Errorf("wrong daemon state: %+v", status)
+	}
+	if strings.Join(status.TunedForSolutions, " ") != "sol1" || strings.Join(status.TunedForNotes, " ") != "simpleNote" {
+		t.Errorf("wrong solutions or notes: %+v", status)
+	}
+}
+
+func TestDaemonActionReload(t *testing.T) {
+	confDir := "/tmp/saptune_daemonreload_test"
+	defer os.RemoveAll(confDir)
+	reloadApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	buffer := bytes.Buffer{}
+	DaemonActionReload(&buffer, reloadApp)
+	expected := "Reloading the Note definition and override files of the applied notes...\nAll parameters already conform to the Note definitions, nothing to update.\n"
+	if buffer.String() != expected {
+		t.Errorf("wrong output: '%s'", buffer.String())
+	}
+}
+
+func TestServeStatus(t *testing.T) {
+	confDir := "/tmp/saptune_serve_test"
+	defer os.RemoveAll(confDir)
+	serveApp := app.InitialiseApp(confDir, confDir, tuningOpts, AllTestSolutions)
+	server := httptest.NewServer(newStatusServeMux(func() *app.App { return serveApp }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok\n" {
+		t.Errorf("unexpected response %d '%s'", resp.StatusCode, string(body))
+	}
+
+	resp, err = http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := saptuneStatus{}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatal(resp.Header, err)
+	}
+	if !status.Compliant || len(status.NoteApplyOrder) != 0 {
+		t.Errorf("wrong status: %+v", status)
+	}
+
+	// the endpoints are read-only
+	resp, err = http.Post(server.URL+"/status", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	resp, err = http.Get(server.URL + "/unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
+
+func TestResolveNoteIncludes(t *testing.T) {
+	incDir := "/tmp/saptune_show_includes"
+	if err := os.MkdirAll(incDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(incDir)
+	baseFile := path.Join(incDir, "baseNote")
+	midFile := path.Join(incDir, "midNote")
+	if err := ioutil.WriteFile(baseFile, []byte("[version]\n# SAP-NOTE=baseNote\n[sysctl]\nvm.swappiness = 60\nkernel.shmmni = 4096\n[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(midFile, []byte("[include]\nbaseNote\n[sysctl]\nkernel.shmmni = 32768\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := "[include]\nmidNote\n[sysctl]\nvm.swappiness = 10\n"
+	resolved, err := resolveNoteIncludes(content, []string{baseFile, midFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[include]\nmidNote\n[sysctl]\nvm.swappiness = 10\n\n# included from '" + baseFile + "'\n[grub]\nnuma_balancing=disable\n\n# included from '" + midFile + "'\n[sysctl]\nkernel.shmmni = 32768\n"
+	checkOut(t, resolved, expected)
+	if _, err := resolveNoteIncludes(content, []string{"/file_does_not_exist"}); err == nil {
+		t.Error("expected an error for a missing included file")
+	}
+}
+
+func TestNoteActionExport(t *testing.T) {
+	exportDir := "/tmp/saptune_export_test"
+	ovDir := path.Join(exportDir, "override") + "/"
+	if err := os.MkdirAll(ovDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(exportDir)
+	oldOverride := note.OverrideTuningSheets
+	defer func() { note.OverrideTuningSheets = oldOverride }()
+	note.OverrideTuningSheets = ovDir
+	baseFile := path.Join(exportDir, "baseNote")
+	confFile := path.Join(exportDir, "exportNote.conf")
+	if err := ioutil.WriteFile(baseFile, []byte("[version]\n# SAP-NOTE=baseNote\n[sysctl]\nkernel.shmmni = 4096\n[grub]\nnuma_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(confFile, []byte("[version]\n# SAP-NOTE=exportNote VERSION=1\n[include]\nbaseNote\n[sysctl]\nvm.swappiness = 10\nvm.dirty_ratio = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(ovDir, "exportNote"), []byte("[sysctl]\nvm.swappiness = 20\nkernel.shmmni =\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exportNotes := note.TuningOptions{"exportNote": note.INISettings{ConfFilePath: confFile, ID: "exportNote", DescriptiveName: "", IncludeFiles: []string{baseFile}}}
+	exportApp := app.InitialiseApp(exportDir, exportDir, exportNotes, AllTestSolutions)
+
+	exported, err := exportNote("exportNote", exportApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(exported, "# Note exportNote exported by saptune ") {
+		t.Errorf("missing header: '%s'", exported)
+	}
+	for _, expected := range []string{"# included Note definition file: " + baseFile + "\n", "# override file: " + path.Join(ovDir, "exportNote") + "\n", "\nvm.swappiness = 20\n", "\nvm.dirty_ratio = 10\n", "\nnuma_balancing=disable\n", "\n# untouched by the override file: kernel.shmmni =\n"} {
+		if !strings.Contains(exported, expected) {
+			t.Errorf("missing '%s' in '%s'", expected, exported)
+		}
+	}
+	if strings.Contains(exported, "[include]") || strings.Contains(exported, "\nbaseNote\n") {
+		t.Errorf("include section not removed: '%s'", exported)
+	}
+
+	exportFile := path.Join(exportDir, "exported.conf")
+	buffer := bytes.Buffer{}
+	NoteActionExport(&buffer, "exportNote", exportFile, exportApp)
+	checkOut(t, buffer.String(), "Note exportNote has been exported to '"+exportFile+"'.\nCopy the file to '"+ExtraTuningSheets+"exportNote.conf' on the target host to use it there.\n")
+	if problems, err := note.ValidateNoteFile(exportFile); err != nil || len(problems) != 0 {
+		t.Error(problems, err)
+	}
+	// the exported file is a standalone Note definition
+	standalone := note.INISettings{ConfFilePath: exportFile, ID: "standaloneNote", DescriptiveName: ""}
+	params, err := standalone.DefinedParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["vm.swappiness"] != "20" || params["vm.dirty_ratio"] != "10" {
+		t.Error(params)
+	}
+	if _, ok := params["kernel.shmmni"]; ok {
+		t.Error(params)
+	}
+
+	if _, err := exportNote("unknownNote", exportApp); err == nil {
+		t.Error("expected an error for an unknown note")
+	}
+}
+
+func TestNoteActionSearch(t *testing.T) {
+	searchMatchText := `
+Notes matching 'SIMPLE':
+	simpleNote	Configuration drop in for simple tests
+			Version 1 from 09.07.2019 
+			found in: name, ` + path.Join(TstFilesInGOPATH, "simpleNote.conf") + `
+
+`
+	buffer := bytes.Buffer{}
+	NoteActionSearch(&buffer, "SIMPLE", tuningOpts)
+	checkOut(t, buffer.String(), searchMatchText)
+
+	searchMatchText = `
+Notes matching 'ip_local_port_range':
+	simpleNote	Configuration drop in for simple tests
+			Version 1 from 09.07.2019 
+			found in: ` + path.Join(TstFilesInGOPATH, "simpleNote.conf") + `
+
+`
+	buffer.Reset()
+	NoteActionSearch(&buffer, "ip_local_port_range", tuningOpts)
+	checkOut(t, buffer.String(), searchMatchText)
+
+	buffer.Reset()
+	NoteActionSearch(&buffer, "no note contains this text", tuningOpts)
+	checkOut(t, buffer.String(), "\nNo notes found matching 'no note contains this text'.\n\n")
+}
+
+func TestNoteTTLInfo(t *testing.T) {
+	now := time.Now()
+	checkOut(t, noteTTLInfo(now.Add(90*time.Minute), now), "Temporarily applied, reverted automatically in 1h30m0s")
+	checkOut(t, noteTTLInfo(now.Add(-time.Minute), now), "Temporarily applied, revert pending")
+}
+
+func TestOpenOutputFile(t *testing.T) {
+	outFileName := "/tmp/saptune_output_file_test"
+	defer os.Remove(outFileName)
+	if err := ioutil.WriteFile(outFileName, []byte("old content of the report\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outFile, err := openOutputFile(outFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	NoteActionSimulateAll(outFile, app.InitialiseApp("/tmp/saptune_output_file_conf", "/tmp/saptune_output_file_conf", tuningOpts, AllTestSolutions))
+	outFile.Close()
+	defer os.RemoveAll("/tmp/saptune_output_file_conf")
+	content, err := ioutil.ReadFile(outFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOut(t, string(content), "No notes or solutions enabled, nothing to simulate.\n")
+
+	if _, err := openOutputFile(""); err == nil {
+		t.Error("expected an error for a missing file name")
+	}
+	if _, err := openOutputFile("/dir_does_not_exist/report"); err == nil {
+		t.Error("expected an error for a file in a missing directory")
+	}
+	// symbolic links and files, which are no regular files, are refused
+	linkName := "/tmp/saptune_output_file_link"
+	defer os.Remove(linkName)
+	if err := os.Symlink(outFileName, linkName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openOutputFile(linkName); err == nil {
+		t.Error("expected an error for a symbolic link")
+	}
+	if content, _ := ioutil.ReadFile(outFileName); string(content) != "No notes or solutions enabled, nothing to simulate.\n" {
+		t.Errorf("target of the symbolic link changed: '%s'", string(content))
+	}
+	if _, err := openOutputFile("/tmp"); err == nil {
+		t.Error("expected an error for a directory")
+	}
+}
+
+func TestPrepareFootnoteGrub(t *testing.T) {
+	comparison := note.FieldComparison{ReflectFieldName: "SysctlParams", ReflectMapKey: "grub:intel_idle.max_cstate", ActualValue: "NA", ExpectedValue: "1"}
+	for state, exp := range map[string]string{"": " [2] [3]", note.GrubStateRunning: " [2] [3]", note.GrubStateReboot: " [2] [7]", note.GrubStateMissing: " [2] [8]"} {
+		comparison.GrubState = state
+		_, comment, footnote := prepareFootnote(comparison, "no ", "", "", make([]string, 8, 8))
+		checkOut(t, comment, exp)
+		if state == note.GrubStateReboot {
+			checkOut(t, footnote[6], "[7] value is set in the boot loader configuration, but NOT active - reboot needed")
+		}
+	}
+}
+
+func TestNoteActionDelete(t *testing.T) {
+	confDir := "/tmp/saptune_delete_test"
+	defer os.RemoveAll(confDir)
+	extraDir := path.Join(confDir, "extra")
+	if err := os.MkdirAll(extraDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldOverride, oldExtra := OverrideTuningSheets, ExtraTuningSheets
+	defer func() { OverrideTuningSheets, ExtraTuningSheets = oldOverride, oldExtra }()
+	OverrideTuningSheets = path.Join(confDir, "override") + "/"
+	ExtraTuningSheets = extraDir + "/"
+	extraFile := path.Join(extraDir, "delNote.conf")
+	if err := ioutil.WriteFile(extraFile, []byte("[version]\n# SAP-NOTE=delNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"delNote test\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	delApp := app.InitialiseApp(confDir, confDir, note.TuningOptions{"delNote": note.INISettings{ConfFilePath: extraFile, ID: "delNote"}}, AllTestSolutions)
+
+	buffer := bytes.Buffer{}
+	NoteActionDelete(strings.NewReader("n\n"), &buffer, "delNote", false, true, delApp)
+	checkOut(t, buffer.String(), "The following files will be deleted:\n    "+extraFile+"\nDelete the note delNote? [y/n]: \nThe note delNote has not been deleted.\n")
+	if _, err := os.Stat(extraFile); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer.Reset()
+	NoteActionDelete(strings.NewReader("y\n"), &buffer, "delNote", false, true, delApp)
+	if txt := buffer.String(); !strings.HasSuffix(txt, "[y/n]: Note delNote has been deleted.\n") {
+		t.Errorf("wrong output: '%s'", txt)
+	}
+	if _, err := os.Stat(extraFile); !os.IsNotExist(err) {
+		t.Error("Note definition file not deleted")
+	}
+}
+
+func TestFitTableWidth(t *testing.T) {
+	// verify table
+	col0, col1, col2, col3, col4, format := fitTableWidth(120, true, 16, 20, 40, 9, 30)
+	if col0 != 16 || col1 != 20 || col0+col1+col2+col3+col4+27 != 120 || col3 != 9 || col2 != col4 {
+		t.Errorf("wrong column widths: %d %d %d %d %d", col0, col1, col2, col3, col4)
+	}
+	checkOut(t, format, fmt.Sprintf("   %%-16s | %%-20s | %%-%ds | %%-9s | %%-%ds | %%2s\n", col2, col4))
+	// value columns are not reduced below the minimal width
+	_, _, col2, col3, col4, _ = fitTableWidth(40, false, 0, 20, 30, 30, 30)
+	if col2 != minTableValueWidth || col3 != minTableValueWidth || col4 != minTableValueWidth {
+		t.Errorf("wrong column widths: %d %d %d", col2, col3, col4)
+	}
+	// unlimited
+	_, _, col2, _, _, _ = fitTableWidth(0, false, 0, 20, 300, 30, 30)
+	if col2 != 300 {
+		t.Errorf("wrong column width: %d", col2)
+	}
+
+	checkOut(t, truncateValue("0123456789", 10), "0123456789")
+	checkOut(t, truncateValue("0123456789abc", 10), "012345678…")
+}
+
+func TestExtraNoteChecksumChecks(t *testing.T) {
+	extraDir := "/tmp/saptune_checksum_check"
+	os.RemoveAll(extraDir)
+	defer os.RemoveAll(extraDir)
+	if err := os.MkdirAll(extraDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, noteID := range []string{"validNote", "invalidNote", "missingNote"} {
+		if err := ioutil.WriteFile(path.Join(extraDir, noteID+".conf"), []byte("[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := note.WriteChecksumFile(path.Join(extraDir, "validNote.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(extraDir, "invalidNote.conf.sha256"), []byte("0123456789abcdef  invalidNote.conf\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buffer := bytes.Buffer{}
+	if failed := printPreflightChecks(&buffer, extraNoteChecksumChecks(extraDir)); failed != 0 {
+		t.Errorf("expected no failed mandatory check, got %d", failed)
+	}
+	txt := buffer.String()
+	for _, line := range []string{
+		"[WARN] checksum invalidNote.conf: checksum of '" + extraDir + "/invalidNote.conf' does not match",
+		"[PASS] checksum missingNote.conf: no checksum file '" + extraDir + "/missingNote.conf.sha256' available",
+		"[PASS] checksum validNote.conf: checksum of '" + extraDir + "/validNote.conf' is valid",
+	} {
+		if !strings.Contains(txt, line) {
+			t.Errorf("missing '%s' in '%s'", line, txt)
 		}
 	}
-	t.Fatalf("process ran with err %v, want exit status 9", err)
 }
@@ -0,0 +1,95 @@
+// Package sysfs is the reference implementation of a section handler. It
+// adds the section [sysfs] to the Note definition files, which tunes
+// arbitrary files below /sys.
+//
+// The parameter names are the paths below /sys with '.' instead of '/'
+// as separator, like the sysctl parameter names, e.g.
+//
+//	[sysfs]
+//	kernel.mm.ksm.run = 0
+//	kernel.mm.transparent_hugepage.defrag = never
+//
+// Files offering a choice of values like '[always] madvise never' are
+// handled, too. The current value is the selected choice.
+//
+// The handler is registered at startup by importing the package, e.g.
+//
+//	import _ "github.com/SUSE/saptune/plugins/sysfs"
+package sysfs
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// Section is the name of the section handled by this package
+const Section = "sysfs"
+
+// SysfsRoot is the mount point of the sysfs. Changed by the tests
+var SysfsRoot = "/sys"
+
+// Handler tunes the files below /sys
+type Handler struct{}
+
+func init() {
+	if err := note.RegisterSectionHandler(Section, Handler{}); err != nil {
+		system.ErrorLog("failed to register the handler for section '%s': %v", Section, err)
+	}
+}
+
+// sysfsPath returns the path of the file belonging to the parameter
+func sysfsPath(key string) (string, error) {
+	file := path.Join(SysfsRoot, strings.Replace(key, ".", "/", -1))
+	if !strings.HasPrefix(file, SysfsRoot+"/") {
+		return "", fmt.Errorf("parameter '%s' does not reference a file below '%s'", key, SysfsRoot)
+	}
+	return file, nil
+}
+
+// Get returns the current value of the file. For files offering a choice
+// of values the selected choice is returned
+func (hdl Handler) Get(key string) (string, error) {
+	file, err := sysfsPath(key)
+	if err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		system.WarningLog("failed to read sysfs parameter '%s': %v", key, err)
+		return "", err
+	}
+	val := strings.TrimSpace(string(content))
+	for _, choice := range strings.Fields(val) {
+		if len(choice) > 2 && strings.HasPrefix(choice, "[") && strings.HasSuffix(choice, "]") {
+			return choice[1 : len(choice)-1], nil
+		}
+	}
+	return val, nil
+}
+
+// Opt returns the value from the Note definition file. Several values are
+// separated by a blank
+func (hdl Handler) Opt(key, actval, cfgval string) string {
+	return strings.TrimSpace(strings.Replace(cfgval, "\t", " ", -1))
+}
+
+// Set writes the value to the file
+func (hdl Handler) Set(key, value string, revert bool) error {
+	if value == "" {
+		// parameter not available in the system, nothing to set
+		return nil
+	}
+	file, err := sysfsPath(key)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, []byte(value), 0644); err != nil {
+		system.WarningLog("failed to set sysfs parameter '%s' to '%s': %v", key, value, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package sysfs
+
+import (
+	"github.com/SUSE/saptune/sap/note"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestSysfsHandler(t *testing.T) {
+	SysfsRoot = "/tmp/saptune_sysfs"
+	defer func() { SysfsRoot = "/sys" }()
+	defer os.RemoveAll(SysfsRoot)
+	if err := os.MkdirAll(path.Join(SysfsRoot, "kernel/mm/ksm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(SysfsRoot, "kernel/mm/ksm/run"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(SysfsRoot, "kernel/mm/ksm/defrag"), []byte("always [madvise] never\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hdl := Handler{}
+
+	if val, err := hdl.Get("kernel.mm.ksm.run"); err != nil || val != "1" {
+		t.Errorf("got '%s', '%v'", val, err)
+	}
+	if val, err := hdl.Get("kernel.mm.ksm.defrag"); err != nil || val != "madvise" {
+		t.Errorf("got '%s', '%v'", val, err)
+	}
+	if val, err := hdl.Get("kernel.mm.ksm.missing"); err == nil || val != "" {
+		t.Errorf("expected an error, got '%s'", val)
+	}
+	if val := hdl.Opt("kernel.mm.ksm.run", "1", "0"); val != "0" {
+		t.Error(val)
+	}
+	if val := hdl.Opt("kernel.mm.ksm.run", "1", "a\tb"); val != "a b" {
+		t.Error(val)
+	}
+	if err := hdl.Set("kernel.mm.ksm.run", "0", false); err != nil {
+		t.Error(err)
+	}
+	if val, _ := hdl.Get("kernel.mm.ksm.run"); val != "0" {
+		t.Error(val)
+	}
+	// nothing to set for a parameter not available in the system
+	if err := hdl.Set("kernel.mm.ksm.missing", "", true); err != nil {
+		t.Error(err)
+	}
+	if _, err := hdl.Get(".."); err == nil {
+		t.Error("expected an error for a path outside of the sysfs")
+	}
+}
+
+func TestSysfsRegistered(t *testing.T) {
+	found := false
+	for _, section := range note.RegisteredSections() {
+		if section == Section {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("section '%s' not registered", Section)
+	}
+	if problems := note.ValidateNoteDefinition("sysfsNote", "[sysfs]\nkernel.mm.ksm.run = 0\n"); len(problems) != 0 {
+		t.Error(problems)
+	}
+}
@@ -0,0 +1,40 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/txtparser"
+	"sort"
+)
+
+// BoundsViolation describes a parameter value outside of the sane bounds
+// defined in the [bounds] section of the Note definition file
+type BoundsViolation struct {
+	Key    string
+	Value  string
+	Bounds txtparser.ParamBounds
+}
+
+// String returns the description of the violation
+func (violation BoundsViolation) String() string {
+	return fmt.Sprintf("parameter '%s' with value '%s' is outside of the bounds '%s'", violation.Key, violation.Value, violation.Bounds)
+}
+
+// OutOfBounds checks the parameter values against the bounds of the Note and
+// returns the violations sorted by parameter name.
+// The bounds are only taken from the Note definition file and the included
+// Note definition files, not from the override file, so that a wrong value
+// in the override file can not override the bounds
+func (vend INISettings) OutOfBounds(values map[string]string) ([]BoundsViolation, error) {
+	violations := make([]BoundsViolation, 0)
+	ini, err := vend.ParseDefinition()
+	if err != nil {
+		return violations, err
+	}
+	for key, value := range values {
+		if bounds, ok := ini.Bounds[key]; ok && !bounds.InBounds(value) {
+			violations = append(violations, BoundsViolation{Key: key, Value: value, Bounds: bounds})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Key < violations[j].Key })
+	return violations, nil
+}
@@ -0,0 +1,46 @@
+package note
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestOutOfBounds(t *testing.T) {
+	boundsFile := "/tmp/saptune_bounds_note"
+	defer os.Remove(boundsFile)
+	if err := ioutil.WriteFile(boundsFile, []byte("[bounds]\nvm.dirty_ratio = 5:40\nvm.swappiness = :100\n\n[sysctl]\nvm.dirty_ratio = 10\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_bounds_override"
+	defer os.RemoveAll(OverrideTuningSheets)
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// bounds of the override file are ignored
+	if err := ioutil.WriteFile(path.Join(OverrideTuningSheets, "boundsNote"), []byte("[bounds]\nvm.dirty_ratio = 0:100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	boundsNote := INISettings{ConfFilePath: boundsFile, ID: "boundsNote", DescriptiveName: ""}
+
+	violations, err := boundsNote.OutOfBounds(map[string]string{"vm.dirty_ratio": "95", "vm.swappiness": "200", "kernel.shmmni": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 2 || violations[0].Key != "vm.dirty_ratio" || violations[1].Key != "vm.swappiness" {
+		t.Fatalf("%+v", violations)
+	}
+	if txt := violations[0].String(); txt != "parameter 'vm.dirty_ratio' with value '95' is outside of the bounds '5:40'" {
+		t.Error(txt)
+	}
+	if violations, err := boundsNote.OutOfBounds(map[string]string{"vm.dirty_ratio": "40", "vm.swappiness": "0"}); err != nil || len(violations) != 0 {
+		t.Error(violations, err)
+	}
+	noNote := INISettings{ConfFilePath: "/not_avail", ID: "no", DescriptiveName: ""}
+	if _, err := noNote.OutOfBounds(map[string]string{"vm.dirty_ratio": "95"}); err == nil {
+		t.Error("expected an error for a missing Note definition file")
+	}
+}
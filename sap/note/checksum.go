@@ -0,0 +1,145 @@
+package note
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// ChecksumSuffix is the suffix of the checksum file, which is placed next to
+// a vendor or customer specific Note definition file
+const ChecksumSuffix = ".sha256"
+
+// result of the checksum verification of a Note definition file
+const (
+	ChecksumValid   = "valid"   // the checksum matches
+	ChecksumInvalid = "invalid" // the checksum does not match
+	ChecksumMissing = "missing" // no checksum file available
+)
+
+// refuseInvalidChecksum defines, if a vendor or customer specific Note
+// definition file with a wrong or without a checksum is skipped. By default
+// only a warning is logged for a wrong checksum
+var refuseInvalidChecksum = false
+
+// verifiedChecksums contains the checksums of the vendor or customer
+// specific Note definition files verified by GetTuningOptions. The content
+// of these files is verified again each time it is parsed, so that a file
+// changed after the verification is not used
+var verifiedChecksums = make(map[string]string)
+
+// SetExtraNotesChecksum sets the handling of vendor or customer specific
+// Note definition files, whose checksum does not match. Supported are
+// 'warn' (default) and 'refuse'
+func SetExtraNotesChecksum(handling string) error {
+	switch handling {
+	case "", "warn":
+		refuseInvalidChecksum = false
+	case "refuse":
+		refuseInvalidChecksum = true
+	default:
+		return fmt.Errorf("unsupported value '%s', use 'warn' or 'refuse'", handling)
+	}
+	return nil
+}
+
+// ChecksumRequired returns true, if vendor or customer specific Note
+// definition files without a valid checksum are skipped
+func ChecksumRequired() bool {
+	return refuseInvalidChecksum
+}
+
+// VerifyChecksum checks the Note definition file against the sha256 checksum
+// from its checksum file. The checksum is the first field of the checksum
+// file, so the output of sha256sum(1) can be used as checksum file
+func VerifyChecksum(fileName string) (string, error) {
+	state, _, err := verifyChecksum(fileName)
+	return state, err
+}
+
+// verifyChecksum works like VerifyChecksum, but additionally returns the
+// verified checksum
+func verifyChecksum(fileName string) (string, string, error) {
+	sumContent, err := ioutil.ReadFile(fileName + ChecksumSuffix)
+	if os.IsNotExist(err) {
+		return ChecksumMissing, "", nil
+	} else if err != nil {
+		return ChecksumInvalid, "", err
+	}
+	fields := strings.Fields(string(sumContent))
+	if len(fields) == 0 {
+		return ChecksumInvalid, "", fmt.Errorf("checksum file '%s%s' is empty", fileName, ChecksumSuffix)
+	}
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return ChecksumInvalid, "", err
+	}
+	sum := hex.EncodeToString(checksumOf(content))
+	if !strings.EqualFold(fields[0], sum) {
+		return ChecksumInvalid, "", fmt.Errorf("checksum of '%s' does not match the checksum from '%s%s'", fileName, fileName, ChecksumSuffix)
+	}
+	return ChecksumValid, sum, nil
+}
+
+// checksumOf returns the sha256 checksum of the content
+func checksumOf(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
+
+// extraNoteChecksumOK verifies the checksum of a vendor or customer specific
+// Note definition file. It returns false, if the file should be skipped
+func extraNoteChecksumOK(fileName string) bool {
+	state, sum, err := verifyChecksum(fileName)
+	delete(verifiedChecksums, fileName)
+	switch state {
+	case ChecksumValid:
+		verifiedChecksums[fileName] = sum
+		return true
+	case ChecksumMissing:
+		if refuseInvalidChecksum {
+			system.ErrorLog("GetTuningOptions: skip vendor file \"%s\": no checksum file '%s%s' available", fileName, fileName, ChecksumSuffix)
+			return false
+		}
+		return true
+	}
+	if refuseInvalidChecksum {
+		system.ErrorLog("GetTuningOptions: skip vendor file \"%s\": %v", fileName, err)
+		return false
+	}
+	system.WarningLog("GetTuningOptions: vendor file \"%s\" may be tampered: %v", fileName, err)
+	return true
+}
+
+// parseNoteFile parses a Note definition file. If the checksum of the file
+// was verified by GetTuningOptions, the parsed content needs to match this
+// checksum
+func parseNoteFile(fileName string) (*txtparser.INIFile, error) {
+	content, err := system.ReadConfigFile(fileName, false)
+	if err != nil {
+		return nil, err
+	}
+	if sum, ok := verifiedChecksums[fileName]; ok && !strings.EqualFold(sum, hex.EncodeToString(checksumOf(content))) {
+		if refuseInvalidChecksum {
+			return nil, fmt.Errorf("Note definition file '%s' was changed after its checksum was verified", fileName)
+		}
+		system.WarningLog("Note definition file '%s' was changed after its checksum was verified and may be tampered", fileName)
+	}
+	return txtparser.ParseINI(string(content)), nil
+}
+
+// WriteChecksumFile writes the checksum file of the Note definition file in
+// the format of sha256sum(1)
+func WriteChecksumFile(fileName string) error {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName+ChecksumSuffix, []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(checksumOf(content)), path.Base(fileName))), 0644)
+}
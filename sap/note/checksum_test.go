@@ -0,0 +1,86 @@
+package note
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	extraDir := "/tmp/saptune_checksum_test"
+	os.RemoveAll(extraDir)
+	defer os.RemoveAll(extraDir)
+	if err := os.MkdirAll(extraDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fileName := path.Join(extraDir, "sumNote.conf")
+	if err := ioutil.WriteFile(fileName, []byte("[version]\n# SAP-NOTE=sumNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"checksum test\"\n[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if state, err := VerifyChecksum(fileName); state != ChecksumMissing || err != nil {
+		t.Error(state, err)
+	}
+	if err := WriteChecksumFile(fileName); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := VerifyChecksum(fileName); state != ChecksumValid || err != nil {
+		t.Error(state, err)
+	}
+	sumNote, ok := GetTuningOptions("/tmp/saptune_no_notes", extraDir)["sumNote"].(INISettings)
+	if !ok {
+		t.Fatal("note with valid checksum not loaded")
+	}
+	if _, err := sumNote.ParseDefinition(); err != nil {
+		t.Error(err)
+	}
+
+	// tampered Note definition file
+	if err := ioutil.WriteFile(fileName, []byte("[version]\n# SAP-NOTE=sumNote CATEGORY=test VERSION=1 DATE=01.01.2020 NAME=\"checksum test\"\n[sysctl]\nvm.swappiness = 100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := VerifyChecksum(fileName); state != ChecksumInvalid || err == nil {
+		t.Error(state, err)
+	}
+	// the file was changed after the verification
+	if _, err := sumNote.ParseDefinition(); err != nil {
+		t.Errorf("changed file not parsed with 'warn': %v", err)
+	}
+	if err := SetExtraNotesChecksum("refuse"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetExtraNotesChecksum("warn")
+	if _, err := sumNote.ParseDefinition(); err == nil {
+		t.Error("expected an error for a file changed after the verification with 'refuse'")
+	}
+	SetExtraNotesChecksum("warn")
+	if _, ok := GetTuningOptions("/tmp/saptune_no_notes", extraDir)["sumNote"]; !ok {
+		t.Error("note with invalid checksum not loaded with 'warn'")
+	}
+	SetExtraNotesChecksum("refuse")
+	if _, ok := GetTuningOptions("/tmp/saptune_no_notes", extraDir)["sumNote"]; ok {
+		t.Error("note with invalid checksum loaded with 'refuse'")
+	}
+	// a missing checksum file is refused, too
+	if err := os.Remove(fileName + ChecksumSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := GetTuningOptions("/tmp/saptune_no_notes", extraDir)["sumNote"]; ok {
+		t.Error("note without checksum loaded with 'refuse'")
+	}
+	SetExtraNotesChecksum("warn")
+	if _, ok := GetTuningOptions("/tmp/saptune_no_notes", extraDir)["sumNote"]; !ok {
+		t.Error("note without checksum not loaded with 'warn'")
+	}
+	if err := SetExtraNotesChecksum("ignore"); err == nil {
+		t.Error("expected an error for an unsupported value")
+	}
+
+	if err := ioutil.WriteFile(fileName+ChecksumSuffix, []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := VerifyChecksum(fileName); state != ChecksumInvalid || err == nil {
+		t.Error(state, err)
+	}
+}
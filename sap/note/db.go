@@ -9,7 +9,8 @@ import (
 // LinuxPagingImprovements defines SAP Note 1557506
 // 1557506 - Linux paging improvements
 type LinuxPagingImprovements struct {
-	PagingConfig string // configuration file for page cache, used by test cases and during optimise
+	PagingConfig    string   // configuration file for page cache, used by test cases and during optimise
+	PagingOverrides []string // override files merged on top of the configuration file, the last one wins
 
 	VMPagecacheLimitMB          uint64
 	VMPagecacheLimitIgnoreDirty int
@@ -27,6 +28,7 @@ func (paging LinuxPagingImprovements) Initialise() (Note, error) {
 	vmIgnoreDirty, _ := system.GetSysctlInt(system.SysctlPagecacheLimitIgnoreDirty)
 	return LinuxPagingImprovements{
 		PagingConfig:                paging.PagingConfig,
+		PagingOverrides:             paging.PagingOverrides,
 		VMPagecacheLimitMB:          vmPagecach,
 		VMPagecacheLimitIgnoreDirty: vmIgnoreDirty,
 		UseAlgorithmForHANA:         true,
@@ -41,6 +43,18 @@ func (paging LinuxPagingImprovements) Optimise() (Note, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, owFile := range newPaging.PagingOverrides {
+		ow, owErr := txtparser.ParseSysconfigFile(owFile, false)
+		if owErr != nil {
+			// missing override files are skipped
+			continue
+		}
+		// keys not set in the override file keep the value of the
+		// previous layer, an empty value removes it
+		for key, entry := range ow.KeyValue {
+			conf.KeyValue[key] = entry
+		}
+	}
 	inputEnable := conf.GetBool("ENABLE_PAGECACHE_LIMIT", false)
 	inputOverride := conf.GetInt("OVERRIDE_PAGECACHE_LIMIT_MB", 0)
 
@@ -0,0 +1,77 @@
+package note
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SectionHandler implements the tuning of the parameters of a section type,
+// which is not built into saptune. A handler is registered for a section
+// name with RegisterSectionHandler, usually in the init function of the
+// package implementing the handler. The parameters of the section are
+// then handled like the parameters of the built-in sections: they are
+// shown in the verify and simulate table, their former values are saved
+// during apply and restored during revert.
+type SectionHandler interface {
+	// Get returns the current value of the parameter in the system.
+	// It is called during 'Initialise' of a Note
+	Get(key string) (string, error)
+	// Opt returns the expected value of the parameter. actval is the
+	// current value returned by Get, cfgval is the value from the Note
+	// definition or from the override file
+	Opt(key, actval, cfgval string) string
+	// Set sets the parameter to the value. revert is true, if the
+	// value is the former value of the parameter saved during apply
+	Set(key, value string, revert bool) error
+}
+
+var sectionHandlers = make(map[string]SectionHandler)
+var sectionHandlersLock sync.RWMutex
+
+// RegisterSectionHandler registers the handler for the section with the
+// given name. The names of the built-in sections can not be used and a
+// section can only have one handler
+func RegisterSectionHandler(section string, handler SectionHandler) error {
+	if isBuiltinSection(section) {
+		return fmt.Errorf("section '%s' is a built-in section", section)
+	}
+	if handler == nil {
+		return fmt.Errorf("missing handler for section '%s'", section)
+	}
+	sectionHandlersLock.Lock()
+	defer sectionHandlersLock.Unlock()
+	if _, exists := sectionHandlers[section]; exists {
+		return fmt.Errorf("a handler for section '%s' is already registered", section)
+	}
+	sectionHandlers[section] = handler
+	return nil
+}
+
+// UnregisterSectionHandler removes the handler of the section
+func UnregisterSectionHandler(section string) {
+	sectionHandlersLock.Lock()
+	defer sectionHandlersLock.Unlock()
+	delete(sectionHandlers, section)
+}
+
+// getSectionHandler returns the handler registered for the section
+func getSectionHandler(section string) (SectionHandler, bool) {
+	sectionHandlersLock.RLock()
+	defer sectionHandlersLock.RUnlock()
+	handler, ok := sectionHandlers[section]
+	return handler, ok
+}
+
+// RegisteredSections returns the sorted names of the sections handled by
+// a registered section handler
+func RegisteredSections() []string {
+	sectionHandlersLock.RLock()
+	defer sectionHandlersLock.RUnlock()
+	sections := make([]string, 0, len(sectionHandlers))
+	for section := range sectionHandlers {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return sections
+}
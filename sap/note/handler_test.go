@@ -0,0 +1,114 @@
+package note
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// mapHandler is a section handler keeping the parameter values in a map
+type mapHandler struct {
+	values map[string]string
+}
+
+func (hdl mapHandler) Get(key string) (string, error) {
+	return hdl.values[key], nil
+}
+
+func (hdl mapHandler) Opt(key, actval, cfgval string) string {
+	return cfgval
+}
+
+func (hdl mapHandler) Set(key, value string, revert bool) error {
+	hdl.values[key] = value
+	return nil
+}
+
+func TestRegisterSectionHandler(t *testing.T) {
+	hdl := mapHandler{values: map[string]string{}}
+	if err := RegisterSectionHandler(INISectionSysctl, hdl); err == nil {
+		t.Error("expected an error for a built-in section")
+	}
+	if err := RegisterSectionHandler("testsection", nil); err == nil {
+		t.Error("expected an error for a missing handler")
+	}
+	if err := RegisterSectionHandler("testsection", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterSectionHandler("testsection")
+	if err := RegisterSectionHandler("testsection", hdl); err == nil {
+		t.Error("expected an error for a second handler of the section")
+	}
+	if !reflect.DeepEqual(RegisteredSections(), []string{"testsection"}) {
+		t.Error(RegisteredSections())
+	}
+	if !isKnownSection("testsection") || isBuiltinSection("testsection") {
+		t.Error("section 'testsection' not handled as registered section")
+	}
+	if problems := ValidateNoteDefinition("handlerNote", "[testsection]\ntest.param = 5\n"); len(problems) != 0 {
+		t.Error(problems)
+	}
+	UnregisterSectionHandler("testsection")
+	if isKnownSection("testsection") || len(RegisteredSections()) != 0 {
+		t.Error("section 'testsection' still registered")
+	}
+}
+
+func TestSectionHandlerNote(t *testing.T) {
+	cleanUp()
+	defer cleanUp()
+	hdl := mapHandler{values: map[string]string{"test.param": "1"}}
+	if err := RegisterSectionHandler("testsection", hdl); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterSectionHandler("testsection")
+	handlerFile := "/tmp/saptune_handler_note"
+	defer os.Remove(handlerFile)
+	if err := ioutil.WriteFile(handlerFile, []byte("[testsection]\ntest.param = 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// apply
+	handlerNote := INISettings{ConfFilePath: handlerFile, ID: "handlerNote", DescriptiveName: ""}
+	initialised, err := handlerNote.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initialised.(INISettings).SysctlParams["test.param"] != "1" {
+		t.Error(initialised.(INISettings).SysctlParams)
+	}
+	optimised, err := initialised.(INISettings).Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if optimised.(INISettings).SysctlParams["test.param"] != "5" {
+		t.Error(optimised.(INISettings).SysctlParams)
+	}
+	if err := optimised.(INISettings).SetValuesToApply([]string{"test.param"}).Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["test.param"] != "5" {
+		t.Errorf("parameter not applied: %s", hdl.values["test.param"])
+	}
+
+	// revert
+	reverted := INISettings{ConfFilePath: handlerFile, ID: "handlerNote", DescriptiveName: "", SysctlParams: map[string]string{"test.param": "5"}}
+	if err := reverted.SetValuesToApply([]string{"revert"}).Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["test.param"] != "1" {
+		t.Errorf("parameter not reverted: %s", hdl.values["test.param"])
+	}
+
+	// a parameter missing in the saved state, e.g. reverted separately
+	// before, is not touched by the revert of the note
+	hdl.values["test.param"] = "7"
+	reverted = INISettings{ConfFilePath: handlerFile, ID: "handlerNote", DescriptiveName: "", SysctlParams: map[string]string{}}
+	if err := reverted.SetValuesToApply([]string{"revert"}).Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if hdl.values["test.param"] != "7" {
+		t.Errorf("parameter without saved value changed by the revert: '%s'", hdl.values["test.param"])
+	}
+}
@@ -1,24 +1,96 @@
 package note
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"github.com/SUSE/saptune/sap"
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // OverrideTuningSheets defines saptunes override directory
-const OverrideTuningSheets = "/etc/saptune/override/"
+var OverrideTuningSheets = "/etc/saptune/override/"
+
+// OverrideLayers are additional override directories, e.g. a site-wide
+// directory shipped by a configuration management. Their override files
+// are merged in the listed order below the override file in
+// OverrideTuningSheets, which is the host specific layer with the highest
+// precedence
+var OverrideLayers = []string{}
+
+// OverrideDirs returns the override directories in the order of increasing
+// precedence. The host specific OverrideTuningSheets is the last one
+func OverrideDirs() []string {
+	dirs := make([]string, 0, len(OverrideLayers)+1)
+	for _, dir := range OverrideLayers {
+		if path.Clean(dir) != path.Clean(OverrideTuningSheets) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, OverrideTuningSheets)
+}
+
+// OverrideFiles returns the existing override files of the Note in all
+// override directories in the order of increasing precedence
+func OverrideFiles(noteID string) []string {
+	fileNames := make([]string, 0)
+	for _, dir := range OverrideDirs() {
+		fileName := path.Join(dir, noteID)
+		if _, err := os.Stat(fileName); err == nil {
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	return fileNames
+}
+
+// SolutionOverrideDir is the directory below the override directory, which
+// contains the solution specific override files of the Notes. They are
+// stored in a sub directory named like the solution and are only used, if
+// the solution is enabled.
+const SolutionOverrideDir = "solutions.d"
 
-var pc = LinuxPagingImprovements{}
-var blck = param.BlockDeviceQueue{param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}}
 var isLimitSoft = regexp.MustCompile(`LIMIT_.*_soft_memlock`)
 var isLimitHard = regexp.MustCompile(`LIMIT_.*_hard_memlock`)
-var flstates = ""
+
+// iniState contains the page cache and block device settings and the
+// force latency cpu states collected during 'Initialise' of a Note. They are
+// needed by 'Optimise' and 'Apply' of the same Note.
+// The settings are kept per Note to allow the verification of several
+// Notes at the same time.
+type iniState struct {
+	pc       LinuxPagingImprovements
+	blck     param.BlockDeviceQueue
+	flstates string
+}
+
+var iniStates = make(map[string]*iniState)
+var iniStatesLock sync.Mutex
+
+// getINIState returns the settings of the Note with the given ID.
+// A new and empty entry is created, if reset is true or if there is no
+// entry available for the Note
+func getINIState(noteID string, reset bool) *iniState {
+	iniStatesLock.Lock()
+	defer iniStatesLock.Unlock()
+	state, ok := iniStates[noteID]
+	if !ok || reset {
+		state = &iniState{
+			pc:   LinuxPagingImprovements{},
+			blck: param.BlockDeviceQueue{param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}},
+		}
+		iniStates[noteID] = state
+	}
+	return state
+}
 
 // Tuning options composed by a third party vendor.
 
@@ -81,6 +153,31 @@ type INISettings struct {
 	ValuesToApply   map[string]string // values to apply
 	OverrideParams  map[string]string // parameter values from the override file
 	Inform          map[string]string // special information for parameter values
+	DefinitionHash  string            // hash of the Note definition file at the time the Note was applied
+	IncludeFiles    []string          // Note definition files included by the tuning configuration, in merge order
+	Solutions       []string          // enabled solutions containing the Note, their solution specific override files take precedence
+	ShadowFile      string            // the ignored built-in or vendor Note definition file using the same ID
+	OnlyParams      []string          // parameters selected by 'saptune note apply --only', all other parameters are not managed
+	ParamSections   []string          // 'section:parameter' of the parameters at the time the Note was applied, to revert parameters removed from the Note definition later
+}
+
+// NotManaged is shown as override value of the parameters of a Note, which
+// were not selected by 'saptune note apply --only'. These parameters are
+// neither set nor reverted by saptune
+const NotManaged = "not managed"
+
+// IsManaged returns true, if the parameter is tuned by the Note. Only the
+// parameters listed in OnlyParams are tuned, if the list is not empty
+func (vend INISettings) IsManaged(key string) bool {
+	if len(vend.OnlyParams) == 0 {
+		return true
+	}
+	for _, param := range vend.OnlyParams {
+		if param == key {
+			return true
+		}
+	}
+	return false
 }
 
 // Name returns the name of the related SAP Note or en empty string
@@ -91,17 +188,159 @@ func (vend INISettings) Name() string {
 	return vend.DescriptiveName
 }
 
+// ParseDefinition parses the Note definition file and merges the content
+// of the included Note definition files below the content of the Note
+// definition file, so that the values of the Note take precedence
+func (vend INISettings) ParseDefinition() (*txtparser.INIFile, error) {
+	ini, err := parseNoteFile(vend.ConfFilePath)
+	if err != nil {
+		return ini, err
+	}
+	if len(vend.IncludeFiles) == 0 {
+		return expandSysctlInterfaces(ini), nil
+	}
+	merged := txtparser.ParseINI("")
+	for _, fileName := range vend.IncludeFiles {
+		inc, err := parseNoteFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		merged = txtparser.MergeINI(merged, inc)
+	}
+	return expandSysctlInterfaces(txtparser.MergeINI(merged, ini)), nil
+}
+
+// GetSolutionOverrideFile returns the name of the solution specific override
+// file of the Note
+func GetSolutionOverrideFile(solName, noteID string) string {
+	return path.Join(OverrideTuningSheets, SolutionOverrideDir, solName, noteID)
+}
+
+// SolutionOverrideNotes returns the IDs of the Notes, which have a solution
+// specific override file for the solution
+func SolutionOverrideNotes(solName string) []string {
+	_, noteIDs := system.ListDir(path.Join(OverrideTuningSheets, SolutionOverrideDir, solName), "")
+	return noteIDs
+}
+
+// overrideFileNames returns the names of all possible override files of
+// the Note in the order they are merged, the last one wins
+func (vend INISettings) overrideFileNames() []string {
+	fileNames := make([]string, 0)
+	for _, dir := range OverrideDirs() {
+		fileNames = append(fileNames, path.Join(dir, vend.ID))
+	}
+	for _, solName := range vend.Solutions {
+		fileNames = append(fileNames, GetSolutionOverrideFile(solName, vend.ID))
+	}
+	return fileNames
+}
+
+// parseOverride returns the parsed content of the override files of the
+// Note. The override files of the override layers are merged in the order
+// of OverrideDirs, the solution specific override files of the enabled
+// solutions containing the Note are merged on top of them, so that their
+// values take precedence.
+// An error is returned, if none of the override files is readable
+func (vend INISettings) parseOverride() (*txtparser.INIFile, error) {
+	var ow *txtparser.INIFile
+	var err error
+	for _, fileName := range vend.overrideFileNames() {
+		layer, layerErr := txtparser.ParseINIFile(fileName, false)
+		if layerErr != nil {
+			if err == nil {
+				err = layerErr
+			}
+			continue
+		}
+		if ow == nil {
+			ow = layer
+		} else {
+			ow = txtparser.MergeINI(ow, layer)
+		}
+	}
+	if ow == nil {
+		return nil, err
+	}
+	return expandSysctlInterfaces(resolveConditions(ow)), nil
+}
+
+// parseDefinitionAndOverride returns the parsed content of the Note
+// definition file, including the included Note definition files, and of
+// the related override file, as far as the files are readable
+func (vend INISettings) parseDefinitionAndOverride() []*txtparser.INIFile {
+	inis := make([]*txtparser.INIFile, 0, 2)
+	if ini, err := vend.ParseDefinition(); err == nil {
+		inis = append(inis, ini)
+	}
+	if ow, err := vend.parseOverride(); err == nil {
+		inis = append(inis, ow)
+	}
+	return inis
+}
+
+// kernelRelease returns the release of the running kernel, which is
+// compared with the conditions of conditional sections. Replaced by the tests
+var kernelRelease = system.GetKernelRelease
+
+// conditionMet returns true, if the parameter is not part of a conditional
+// section like '[sysctl:kernel>=5.3]' or if the condition of the section is
+// true for the running kernel
+func conditionMet(param txtparser.INIEntry) bool {
+	if param.Condition == "" {
+		return true
+	}
+	cond, err := txtparser.ParseSectionCondition(param.Condition)
+	if err != nil {
+		system.WarningLog("skipping parameter '%s' of section '[%s:%s]': %v", param.Key, param.Section, param.Condition, err)
+		return false
+	}
+	release := kernelRelease()
+	if release == "" {
+		system.WarningLog("skipping parameter '%s' of section '[%s:%s]': unable to get the kernel release", param.Key, param.Section, param.Condition)
+		return false
+	}
+	return cond.Matches(release)
+}
+
+// resolveConditions returns the content of the INI file reduced to the
+// entries of the sections without condition and of the conditional sections,
+// whose condition is met by the running kernel. The remaining entries lose
+// their condition, so KeyValue is indexed by the plain section name again.
+// If a parameter is part of several of these sections, the entry of the
+// last section wins
+func resolveConditions(ini *txtparser.INIFile) *txtparser.INIFile {
+	ret := *ini
+	ret.AllValues = make([]txtparser.INIEntry, 0, len(ini.AllValues))
+	ret.KeyValue = make(map[string]map[string]txtparser.INIEntry)
+	for _, param := range ini.AllValues {
+		// a section occurring several times with the same condition
+		// is replaced by its last occurrence
+		entry, ok := ini.KeyValue[param.SectionKey()][param.Key]
+		if !ok || !conditionMet(param) {
+			continue
+		}
+		entry.Condition = ""
+		ret.AllValues = append(ret.AllValues, entry)
+		if ret.KeyValue[entry.Section] == nil {
+			ret.KeyValue[entry.Section] = make(map[string]txtparser.INIEntry)
+		}
+		ret.KeyValue[entry.Section][entry.Key] = entry
+	}
+	return &ret
+}
+
 // Initialise retrieves the current parameter values from the system
 func (vend INISettings) Initialise() (Note, error) {
 	// Parse the configuration file
-	ini, err := txtparser.ParseINIFile(vend.ConfFilePath, false)
+	ini, err := vend.ParseDefinition()
 	if err != nil {
 		return vend, err
 	}
 
 	// looking for override file
 	override := false
-	ow, err := txtparser.ParseINIFile(path.Join(OverrideTuningSheets, vend.ID), false)
+	ow, err := vend.parseOverride()
 	if err == nil {
 		override = true
 	}
@@ -109,31 +348,55 @@ func (vend INISettings) Initialise() (Note, error) {
 	vend.SysctlParams = make(map[string]string)
 	vend.OverrideParams = make(map[string]string)
 	vend.Inform = make(map[string]string)
-	pc = LinuxPagingImprovements{}
-	blck = param.BlockDeviceQueue{param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}}
+	vend.ParamSections = make([]string, 0)
+	state := getINIState(vend.ID, true)
 
 	for _, param := range ini.AllValues {
+		if !conditionMet(param) {
+			// the parameter does not apply to the running kernel,
+			// but may be set by another section of the Note
+			if _, exists := vend.SysctlParams[param.Key]; !exists {
+				vend.SysctlParams[param.Key] = "NA"
+			}
+			continue
+		}
 		if override && len(ow.KeyValue[param.Section]) != 0 {
 			param.Key, param.Value, param.Operator = vend.handleInitOverride(param.Key, param.Value, param.Section, param.Operator, ow)
 		}
+		if !vend.IsManaged(param.Key) && param.Section != INISectionReminder {
+			// the current value is only read to show it
+			vend.OverrideParams[param.Key] = NotManaged
+		}
+		switch param.Section {
+		case INISectionRpm, INISectionGrub, INISectionReminder:
+			// only checked, never set
+		default:
+			vend.ParamSections = append(vend.ParamSections, param.Section+":"+param.Key)
+		}
 
 		switch param.Section {
 		case INISectionSysctl:
 			vend.SysctlParams[param.Key], _ = system.GetSysctlString(param.Key)
+			if IsSysctlFormula(param.Value) {
+				// remember the formula to show it during 'verify'
+				vend.Inform[param.Key] = NormaliseSysctlFormula(param.Value)
+			}
 		case INISectionVM:
 			vend.SysctlParams[param.Key] = GetVMVal(param.Key)
 		case INISectionBlock:
-			vend.SysctlParams[param.Key], vend.Inform[param.Key], _ = GetBlkVal(param.Key, &blck)
+			vend.SysctlParams[param.Key], vend.Inform[param.Key], _ = GetBlkVal(param.Key, &state.blck)
 		case INISectionLimits:
 			vend.SysctlParams[param.Key], _ = GetLimitsVal(param.Value)
 		case INISectionService:
 			vend.SysctlParams[param.Key] = GetServiceVal(param.Key)
 		case INISectionLogin:
 			vend.SysctlParams[param.Key], _ = GetLoginVal(param.Key)
+		case INISectionSystemd:
+			vend.SysctlParams[param.Key] = GetSystemdVal(param.Key)
 		case INISectionMEM:
 			vend.SysctlParams[param.Key] = GetMemVal(param.Key)
 		case INISectionCPU:
-			vend.SysctlParams[param.Key], flstates, vend.Inform[param.Key] = GetCPUVal(param.Key)
+			vend.SysctlParams[param.Key], state.flstates, vend.Inform[param.Key] = GetCPUVal(param.Key)
 		case INISectionRpm:
 			vend.SysctlParams[param.Key] = GetRpmVal(param.Key)
 			continue
@@ -145,19 +408,27 @@ func (vend INISettings) Initialise() (Note, error) {
 			continue
 		case INISectionPagecache:
 			// page cache is special, has it's own config file
-			// so adjust path to pagecache config file, if needed
-			if override {
-				pc.PagingConfig = path.Join(OverrideTuningSheets, vend.ID)
-			} else {
-				pc.PagingConfig = vend.ConfFilePath
-			}
-			vend.SysctlParams[param.Key] = GetPagecacheVal(param.Key, &pc)
+			// the override files are merged on top of it
+			state.pc.PagingConfig = vend.ConfFilePath
+			state.pc.PagingOverrides = vend.overrideFileNames()
+			vend.SysctlParams[param.Key] = GetPagecacheVal(param.Key, &state.pc)
 		default:
-			system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
-			continue
+			handler, ok := getSectionHandler(param.Section)
+			if !ok {
+				system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
+				continue
+			}
+			vend.SysctlParams[param.Key], _ = handler.Get(param.Key)
+		}
+		if (IsExecValue(param.Value) || IsRAMValue(param.Value)) && vend.Inform[param.Key] == "" {
+			// remember the helper program or the memory formula to
+			// show it during 'verify'
+			vend.Inform[param.Key] = NormaliseSysctlFormula(param.Value)
 		}
 		// create parameter saved state file, if NOT in 'verify'
-		vend.createParamSavedStates(param.Key, flstates)
+		if vend.IsManaged(param.Key) {
+			vend.createParamSavedStates(param.Key, state.flstates)
+		}
 	}
 	return vend, nil
 }
@@ -165,14 +436,19 @@ func (vend INISettings) Initialise() (Note, error) {
 // Optimise gets the expected parameter values from the configuration
 func (vend INISettings) Optimise() (Note, error) {
 	blckOK := make(map[string][]string)
+	state := getINIState(vend.ID, false)
 	scheds := ""
 	// Parse the configuration file
-	ini, err := txtparser.ParseINIFile(vend.ConfFilePath, false)
+	ini, err := vend.ParseDefinition()
 	if err != nil {
 		return vend, err
 	}
 
 	for _, param := range ini.AllValues {
+		if !conditionMet(param) {
+			// the parameter keeps 'NA' from Initialise
+			continue
+		}
 		// Compare current values against INI's definition
 		if len(vend.OverrideParams) != 0 && vend.ID == "1805750" {
 			// as note 1805750 does not set a limits domain, but
@@ -190,17 +466,48 @@ func (vend INISettings) Optimise() (Note, error) {
 				}
 				continue
 			}
+			if vend.OverrideParams[param.Key] == NotManaged {
+				// keep the current value from Initialise
+				continue
+			}
 			param.Value = vend.OverrideParams[param.Key]
 		}
+		if IsExecValue(param.Value) && !isOneOf(param.Section, INISectionRpm, INISectionGrub, INISectionReminder) {
+			// expected value is the output of a helper program
+			value, err := OptExecVal(param.Key, param.Value)
+			if err != nil {
+				// only this parameter fails the comparison,
+				// the other parameters are verified as usual
+				_ = system.ErrorLog("Note %s: %v", vend.ID, err)
+				vend.SysctlParams[param.Key] = ExecFailed
+				vend.Inform[param.Key] = execErrorPrefix + err.Error()
+				continue
+			}
+			param.Value = value
+		}
+		if IsRAMValue(param.Value) && !isOneOf(param.Section, INISectionRpm, INISectionGrub, INISectionReminder) {
+			// expected value depends on the total memory
+			param.Value, err = OptRAMVal(param.Key, param.Value)
+			if err != nil {
+				return vend, err
+			}
+		}
 		switch param.Section {
 		case INISectionSysctl:
 			//optimisedValue, err := CalculateOptimumValue(param.Operator, vend.SysctlParams[param.Key], param.Value)
 			//vend.SysctlParams[param.Key] = optimisedValue
-			vend.SysctlParams[param.Key] = OptSysctlVal(param.Operator, param.Key, vend.SysctlParams[param.Key], param.Value)
+			if IsSysctlFormula(param.Value) {
+				vend.SysctlParams[param.Key], err = OptSysctlFormula(param.Key, sysctlFormulaBase(param.Key, vend.SysctlParams[param.Key]), param.Value)
+				if err != nil {
+					return vend, err
+				}
+			} else {
+				vend.SysctlParams[param.Key] = OptSysctlVal(param.Operator, param.Key, vend.SysctlParams[param.Key], param.Value)
+			}
 		case INISectionVM:
 			vend.SysctlParams[param.Key] = OptVMVal(param.Key, param.Value)
 		case INISectionBlock:
-			vend.SysctlParams[param.Key], vend.Inform[param.Key] = OptBlkVal(param.Key, param.Value, &blck, blckOK)
+			vend.SysctlParams[param.Key], vend.Inform[param.Key] = OptBlkVal(param.Key, param.Value, &state.blck, blckOK)
 			if isSched.MatchString(param.Key) {
 				scheds = param.Value
 			}
@@ -210,9 +517,11 @@ func (vend INISettings) Optimise() (Note, error) {
 			vend.SysctlParams[param.Key] = OptServiceVal(param.Key, param.Value)
 		case INISectionLogin:
 			vend.SysctlParams[param.Key] = OptLoginVal(param.Value)
+		case INISectionSystemd:
+			vend.SysctlParams[param.Key] = OptSystemdVal(param.Value)
 		case INISectionMEM:
 			if vend.OverrideParams["VSZ_TMPFS_PERCENT"] == "untouched" || vend.OverrideParams["VSZ_TMPFS_PERCENT"] == "" {
-				vend.SysctlParams[param.Key] = OptMemVal(param.Key, vend.SysctlParams[param.Key], param.Value, ini.KeyValue["mem"]["VSZ_TMPFS_PERCENT"].Value)
+				vend.SysctlParams[param.Key] = OptMemVal(param.Key, vend.SysctlParams[param.Key], param.Value, resolveConditions(ini).KeyValue["mem"]["VSZ_TMPFS_PERCENT"].Value)
 			} else {
 				vend.SysctlParams[param.Key] = OptMemVal(param.Key, vend.SysctlParams[param.Key], param.Value, vend.OverrideParams["VSZ_TMPFS_PERCENT"])
 			}
@@ -228,10 +537,14 @@ func (vend INISettings) Optimise() (Note, error) {
 			vend.SysctlParams[param.Key] = param.Value
 			continue
 		case INISectionPagecache:
-			vend.SysctlParams[param.Key] = OptPagecacheVal(param.Key, param.Value, &pc)
+			vend.SysctlParams[param.Key] = OptPagecacheVal(param.Key, param.Value, &state.pc)
 		default:
-			system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
-			continue
+			handler, ok := getSectionHandler(param.Section)
+			if !ok {
+				system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
+				continue
+			}
+			vend.SysctlParams[param.Key] = handler.Opt(param.Key, vend.SysctlParams[param.Key], param.Value)
 		}
 		// add values to parameter saved state file, if NOT in 'verify'
 		vend.addParamSavedStates(param.Key)
@@ -263,17 +576,44 @@ func (vend INISettings) Apply() error {
 		// nothing to apply
 		return nil
 	}
+	revertSingle := false
+	state := getINIState(vend.ID, false)
 	if _, ok := vend.ValuesToApply["revert"]; ok {
 		revertValues = true
+		// additional parameter names restrict the revert to these
+		// parameters
+		revertSingle = len(vend.ValuesToApply) > 1
 	}
 	// Parse the configuration file
-	ini, err := txtparser.ParseINIFile(vend.ConfFilePath, false)
+	ini, err := vend.ParseDefinition()
 	if err != nil {
 		return err
 	}
 
+	entries := ini.AllValues
+	if revertValues {
+		// the parameters removed from the Note definition after the
+		// note was applied are reverted, too
+		entries = append(make([]txtparser.INIEntry, 0, len(entries)), entries...)
+		for _, param := range vend.removedParams(ini) {
+			if param.Section == INISectionSysctl {
+				if _, err := system.GetSysctlString(param.Key); err != nil {
+					// e.g. the network interface is gone, so
+					// only the parameter saved state file is
+					// cleaned up
+					system.InfoLog("sysctl parameter '%s' is no longer available and is not reverted", param.Key)
+					RevertParameter(param.Key, vend.ID)
+					continue
+				}
+			}
+			entries = append(entries, param)
+		}
+	}
 	//for key, value := range vend.SysctlParams {
-	for _, param := range ini.AllValues {
+	for _, param := range entries {
+		if !conditionMet(param) {
+			continue
+		}
 		if len(vend.OverrideParams) != 0 && vend.ID == "1805750" {
 			// as note 1805750 does not set a limits domain, but
 			// the customer should be able to set the correct
@@ -289,13 +629,26 @@ func (vend INISettings) Apply() error {
 			continue
 		}
 
-		if _, ok := vend.ValuesToApply[param.Key]; !ok && !revertValues {
+		if _, ok := vend.ValuesToApply[param.Key]; !ok && (!revertValues || revertSingle) {
+			continue
+		}
+		if msg, failed := ExecFailure(vend.Inform[param.Key]); failed && !revertValues {
+			_ = system.ErrorLog("Note %s: parameter '%s' is not set, as the helper program of its expected value failed: %s", vend.ID, param.Key, msg)
+			continue
+		}
+		if _, ok := vend.SysctlParams[param.Key]; revertValues && !ok {
+			// parameter is not part of the saved state, because it
+			// was reverted separately before or was added to the
+			// Note definition after the note was applied. There is
+			// no former value, so setting it would pass an empty
+			// value, which e.g. a section handler would set as the
+			// new value of the parameter
 			continue
 		}
 
 		if revertValues && vend.SysctlParams[param.Key] != "" {
 			// revert parameter value
-			pvendID, flstates = vend.setRevertParamValues(param.Key)
+			pvendID, state.flstates = vend.setRevertParamValues(param.Key)
 		}
 
 		switch param.Section {
@@ -312,30 +665,36 @@ func (vend INISettings) Apply() error {
 		case INISectionVM:
 			errs = append(errs, SetVMVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionBlock:
-			errs = append(errs, SetBlkVal(param.Key, vend.SysctlParams[param.Key], &blck, revertValues))
+			errs = append(errs, SetBlkVal(param.Key, vend.SysctlParams[param.Key], &state.blck, revertValues))
 		case INISectionLimits:
 			errs = append(errs, SetLimitsVal(param.Key, pvendID, vend.SysctlParams[param.Key], revertValues))
 		case INISectionService:
 			errs = append(errs, SetServiceVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionLogin:
 			errs = append(errs, SetLoginVal(param.Key, vend.SysctlParams[param.Key], revertValues))
+		case INISectionSystemd:
+			errs = append(errs, SetSystemdVal(param.Key, vend.SysctlParams[param.Key], revertValues))
 		case INISectionMEM:
 			errs = append(errs, SetMemVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionCPU:
-			errs = append(errs, SetCPUVal(param.Key, vend.SysctlParams[param.Key], vend.ID, flstates, vend.OverrideParams[param.Key], vend.Inform[param.Key], revertValues))
+			errs = append(errs, SetCPUVal(param.Key, vend.SysctlParams[param.Key], vend.ID, state.flstates, vend.OverrideParams[param.Key], vend.Inform[param.Key], revertValues))
 		case INISectionPagecache:
 			if revertValues {
 				switch param.Key {
 				case system.SysctlPagecacheLimitIgnoreDirty:
-					pc.VMPagecacheLimitIgnoreDirty, _ = strconv.Atoi(vend.SysctlParams[param.Key])
+					state.pc.VMPagecacheLimitIgnoreDirty, _ = strconv.Atoi(vend.SysctlParams[param.Key])
 				case "OVERRIDE_PAGECACHE_LIMIT_MB":
-					pc.VMPagecacheLimitMB, _ = strconv.ParseUint(vend.SysctlParams[param.Key], 10, 64)
+					state.pc.VMPagecacheLimitMB, _ = strconv.ParseUint(vend.SysctlParams[param.Key], 10, 64)
 				}
 			}
-			errs = append(errs, SetPagecacheVal(param.Key, &pc))
+			errs = append(errs, SetPagecacheVal(param.Key, &state.pc))
 		default:
-			system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
-			continue
+			handler, ok := getSectionHandler(param.Section)
+			if !ok {
+				system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
+				continue
+			}
+			errs = append(errs, handler.Set(param.Key, vend.SysctlParams[param.Key], revertValues))
 		}
 	}
 	err = sap.PrintErrors(errs)
@@ -351,6 +710,144 @@ func (vend INISettings) SetValuesToApply(values []string) Note {
 	return vend
 }
 
+// CheckOnly returns true, if the Note definition file or the related override
+// file contains a [check_only] section. The parameter values of such a Note
+// are only verified, but never set.
+func (vend INISettings) CheckOnly() bool {
+	if ini, err := vend.ParseDefinition(); err == nil && ini.CheckOnly {
+		return true
+	}
+	ow, err := vend.parseOverride()
+	return err == nil && ow.CheckOnly
+}
+
+// GetDefinitionHash returns the sha256 hash of the content of the Note
+// definition file and the included Note definition files or an empty
+// string, if a file can not be read
+func (vend INISettings) GetDefinitionHash() string {
+	hash := sha256.New()
+	for _, fileName := range append([]string{vend.ConfFilePath}, vend.IncludeFiles...) {
+		content, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return ""
+		}
+		hash.Write(content)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// ParamComments returns the comments found directly above the parameters in
+// the Note definition file. They explain the expected parameter values.
+// A comment in the related override file takes precedence
+func (vend INISettings) ParamComments() map[string]string {
+	comments := make(map[string]string)
+	for _, ini := range vend.parseDefinitionAndOverride() {
+		for key, comment := range ini.Comments {
+			comments[key] = comment
+		}
+	}
+	return comments
+}
+
+// ParamSeverities returns the severities of a deviation of the parameters
+// found in the [severity] section of the Note definition file. A severity
+// in the related override file takes precedence. Parameters without
+// severity are 'critical'
+func (vend INISettings) ParamSeverities() map[string]string {
+	severities := make(map[string]string)
+	for _, ini := range vend.parseDefinitionAndOverride() {
+		for key, severity := range ini.Severity {
+			severities[key] = severity
+		}
+	}
+	return severities
+}
+
+// Tags returns the sorted tags of the Note found in the [tags] sections of
+// the Note definition file and of the related override file
+func (vend INISettings) Tags() []string {
+	return vend.collectWords(func(ini *txtparser.INIFile) []string { return ini.Tags })
+}
+
+// Requires returns the sorted IDs of the Notes, which need to be applied
+// before this Note. They are found in the [requires] sections of the Note
+// definition file and of the related override file
+func (vend INISettings) Requires() []string {
+	return vend.collectWords(func(ini *txtparser.INIFile) []string { return ini.Requires })
+}
+
+// collectWords returns the sorted and unique words returned by the function
+// words for the Note definition file and the related override file
+func (vend INISettings) collectWords(words func(ini *txtparser.INIFile) []string) []string {
+	ret := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, ini := range vend.parseDefinitionAndOverride() {
+		for _, word := range words(ini) {
+			if !seen[word] {
+				seen[word] = true
+				ret = append(ret, word)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// DefinedParams returns the parameters and their values as defined in the
+// Note definition file. Values from an existing override file take
+// precedence. Parameters disabled by the override file are reported as
+// 'untouched', parameters not selected by OnlyParams as NotManaged.
+func (vend INISettings) DefinedParams() (map[string]string, error) {
+	params := make(map[string]string)
+	err := vend.walkDefinedParams(func(section, key, value string) {
+		params[key] = value
+	})
+	return params, err
+}
+
+// DefinedSectionParams returns the parameters and their values like
+// DefinedParams, but keyed by section and parameter name, so parameters
+// with the same name in different sections are kept apart
+func (vend INISettings) DefinedSectionParams() (map[string]map[string]string, error) {
+	params := make(map[string]map[string]string)
+	err := vend.walkDefinedParams(func(section, key, value string) {
+		if params[section] == nil {
+			params[section] = make(map[string]string)
+		}
+		params[section][key] = value
+	})
+	return params, err
+}
+
+// walkDefinedParams calls fn for all parameters of the Note definition file
+// in the order of the file with the value described at DefinedParams
+func (vend INISettings) walkDefinedParams(fn func(section, key, value string)) error {
+	ini, err := vend.ParseDefinition()
+	if err != nil {
+		return err
+	}
+	ow, owErr := vend.parseOverride()
+	for _, param := range ini.AllValues {
+		if param.Section == INISectionReminder {
+			continue
+		}
+		value := param.Value
+		if owErr == nil {
+			if owParam, ok := ow.KeyValue[param.Section][param.Key]; ok {
+				value = owParam.Value
+				if value == "" {
+					value = "untouched"
+				}
+			}
+		}
+		if !vend.IsManaged(param.Key) {
+			value = NotManaged
+		}
+		fn(param.Section, param.Key, value)
+	}
+	return nil
+}
+
 // getCounterPart gets the counterpart parameters of the vm.dirty parameters
 func (vend INISettings) getCounterPart(key string, revert bool) (string, string) {
 	// for the vm.dirty parameters take the counterpart
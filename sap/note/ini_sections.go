@@ -1,6 +1,8 @@
 package note
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/system"
@@ -8,10 +10,13 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"os/exec"
 	"path"
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 // section name definition
@@ -24,11 +29,18 @@ const (
 	INISectionService   = "service"
 	INISectionLimits    = "limits"
 	INISectionLogin     = "login"
+	INISectionSystemd   = "systemd"
 	INISectionVersion   = "version"
 	INISectionPagecache = "pagecache"
 	INISectionRpm       = "rpm"
 	INISectionGrub      = "grub"
 	INISectionReminder  = "reminder"
+	INISectionCheckOnly = "check_only"
+	INISectionTags      = "tags"
+	INISectionRequires  = "requires"
+	INISectionInclude   = "include"
+	INISectionBounds    = "bounds"
+	INISectionSeverity  = "severity"
 	SysKernelTHPEnabled = "kernel/mm/transparent_hugepage/enabled"
 	SysKSMRun           = "kernel/mm/ksm/run"
 
@@ -41,6 +53,87 @@ const (
 // section handling
 // section [sysctl]
 
+// SysctlInterfacePlaceholder in the name of a sysctl parameter is replaced
+// by the names of the network interfaces of the system, e.g.
+// 'net.ipv4.conf.{iface}.rp_filter'
+const SysctlInterfacePlaceholder = "{iface}"
+
+// expandSysctlInterfaces replaces the sysctl parameters containing the
+// placeholder for the network interfaces by one parameter per network
+// interface found in the system at the time of the call. So interfaces
+// appearing or disappearing between apply and verify are taken into
+// account. A parameter defined explicitly for an interface takes
+// precedence over the parameter using the placeholder.
+func expandSysctlInterfaces(ini *txtparser.INIFile) *txtparser.INIFile {
+	allValues := make([]txtparser.INIEntry, 0, len(ini.AllValues))
+	for _, entry := range ini.AllValues {
+		if entry.Section != INISectionSysctl || !strings.Contains(entry.Key, SysctlInterfacePlaceholder) {
+			allValues = append(allValues, entry)
+			continue
+		}
+		delete(ini.KeyValue[entry.SectionKey()], entry.Key)
+		comment, hasComment := ini.Comments[entry.Key]
+		prefix := strings.SplitN(entry.Key, SysctlInterfacePlaceholder, 2)[0]
+		for _, iface := range system.GetSysctlInterfaces(prefix) {
+			ifEntry := entry
+			ifEntry.Key = strings.Replace(entry.Key, SysctlInterfacePlaceholder, iface, -1)
+			if _, exists := ini.KeyValue[entry.SectionKey()][ifEntry.Key]; exists {
+				continue
+			}
+			allValues = append(allValues, ifEntry)
+			ini.KeyValue[entry.SectionKey()][ifEntry.Key] = ifEntry
+			if hasComment {
+				ini.Comments[ifEntry.Key] = comment
+			}
+		}
+	}
+	ini.AllValues = allValues
+	return ini
+}
+
+// RemovedParams returns the names of the parameters of the saved state of
+// an applied note, which are no longer part of the Note definition
+func (vend INISettings) RemovedParams() ([]string, error) {
+	ini, err := vend.ParseDefinition()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	for _, entry := range vend.removedParams(ini) {
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+// removedParams returns the parameters of the saved state of the note,
+// which are no longer part of the Note definition, e.g. because they were
+// removed from the Note definition file or because the network interface
+// of a parameter using the placeholder for the network interfaces is gone.
+// If the values to apply select parameters, only these are returned.
+func (vend INISettings) removedParams(ini *txtparser.INIFile) []txtparser.INIEntry {
+	removed := make([]txtparser.INIEntry, 0)
+	active := resolveConditions(ini)
+	for _, sectionParam := range vend.ParamSections {
+		fields := strings.SplitN(sectionParam, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		section, key := fields[0], fields[1]
+		if _, defined := active.KeyValue[section][key]; defined {
+			continue
+		}
+		if _, saved := vend.SysctlParams[key]; !saved {
+			continue
+		}
+		if _, selected := vend.ValuesToApply[key]; len(vend.ValuesToApply) > 1 && !selected {
+			// the revert is restricted to other parameters
+			continue
+		}
+		removed = append(removed, txtparser.INIEntry{Section: section, Key: key, Operator: txtparser.OperatorEqual, Value: vend.SysctlParams[key]})
+	}
+	return removed
+}
+
 // OptSysctlVal optimises a sysctl parameter value
 // use exactly the value from the config file. No calculation any more
 func OptSysctlVal(operator txtparser.Operator, key, actval, cfgval string) string {
@@ -87,11 +180,300 @@ func OptSysctlVal(operator txtparser.Operator, key, actval, cfgval string) strin
 	return strings.TrimSpace(allFieldsS)
 }
 
+// IsSysctlFormula returns true, if the value of a sysctl parameter is a
+// formula like '@MUL 2' instead of a fixed value
+func IsSysctlFormula(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "@")
+}
+
+// NormaliseSysctlFormula returns the formula with single blanks between
+// operator and operand, as the parser replaces blanks by tabs
+func NormaliseSysctlFormula(formula string) string {
+	return strings.Join(strings.Fields(formula), " ")
+}
+
+// ParseSysctlFormula splits a sysctl formula into operator and operand.
+// Supported operators are
+// @MUL n - multiply the current value by n
+// @ADD n - add n to the current value
+// @MIN n - use n, if the current value is greater than n
+// @MAX n - use n, if the current value is less than n
+func ParseSysctlFormula(formula string) (string, int64, error) {
+	fields := strings.Fields(formula)
+	if len(fields) != 2 {
+		return "", 0, fmt.Errorf("wrong formula '%s', expected '@<operator> <integer>'", NormaliseSysctlFormula(formula))
+	}
+	op := strings.ToUpper(strings.TrimPrefix(fields[0], "@"))
+	if !isOneOf(op, "MUL", "ADD", "MIN", "MAX") {
+		return "", 0, fmt.Errorf("unknown operator '%s' in formula '%s', supported are @MUL, @ADD, @MIN and @MAX", fields[0], NormaliseSysctlFormula(formula))
+	}
+	operand, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("operand '%s' of formula '%s' is not an integer", fields[1], NormaliseSysctlFormula(formula))
+	}
+	return op, operand, nil
+}
+
+// OptSysctlFormula calculates the expected value of a sysctl parameter from
+// the formula and the base value of the parameter.
+// The base value needs to be a single integer
+func OptSysctlFormula(key, baseval, formula string) (string, error) {
+	if baseval == "" {
+		// sysctl parameter not available in system
+		return "", nil
+	}
+	op, operand, err := ParseSysctlFormula(formula)
+	if err != nil {
+		return "", fmt.Errorf("parameter '%s': %v", key, err)
+	}
+	base, err := strconv.ParseInt(strings.TrimSpace(baseval), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("formula '%s' of parameter '%s' needs a numeric value of the parameter, but found '%s'", NormaliseSysctlFormula(formula), key, strings.Join(strings.Fields(baseval), " "))
+	}
+	val := base
+	inRange := true
+	switch op {
+	case "MUL":
+		val, inRange = mulInt64(base, operand)
+	case "ADD":
+		val, inRange = addInt64(base, operand)
+	case "MIN":
+		if operand < base {
+			val = operand
+		}
+	case "MAX":
+		if operand > base {
+			val = operand
+		}
+	}
+	if !inRange {
+		return "", fmt.Errorf("formula '%s' of parameter '%s' with the value '%d' exceeds the range of a 64-bit integer", NormaliseSysctlFormula(formula), key, base)
+	}
+	return strconv.FormatInt(val, 10), nil
+}
+
+// mulInt64 returns the product of a and b and false, if the product
+// overflows a 64-bit integer
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	prod := a * b
+	if prod/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return prod, true
+}
+
+// addInt64 returns the sum of a and b and false, if the sum overflows
+// a 64-bit integer
+func addInt64(a, b int64) (int64, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
+
+// ExecHelperDir is the directory containing the helper programs, which can
+// be used by '@EXEC' to calculate the expected value of a parameter.
+// Other programs are not called for safety reasons
+var ExecHelperDir = "/usr/share/saptune/helpers/"
+
+// execHelperTimeout limits the runtime of a helper program
+const execHelperTimeout = 60 * time.Second
+
+// IsExecValue returns true, if the value of a parameter is the output of a
+// helper program like '@EXEC /usr/share/saptune/helpers/calc_shmall'
+func IsExecValue(value string) bool {
+	fields := strings.Fields(value)
+	return len(fields) != 0 && strings.ToUpper(fields[0]) == "@EXEC"
+}
+
+// ParseExecValue splits an '@EXEC' value into the helper program and its
+// arguments. The helper program needs to be located in ExecHelperDir
+func ParseExecValue(value string) (string, []string, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("missing helper program in '%s', expected '@EXEC %s<helper>'", NormaliseSysctlFormula(value), ExecHelperDir)
+	}
+	helper := fields[1]
+	if !path.IsAbs(helper) || path.Clean(helper) != helper || path.Dir(helper) != path.Clean(ExecHelperDir) {
+		return "", nil, fmt.Errorf("helper program '%s' is not located in '%s'", helper, ExecHelperDir)
+	}
+	return helper, fields[2:], nil
+}
+
+// OptExecVal calls the helper program of an '@EXEC' value and returns its
+// output as the expected value of the parameter. Blanks of the output are
+// replaced by tabs like the parser does for the values of the Note
+// definition file. A failing helper or a helper without output is an error
+func OptExecVal(key, value string) (string, error) {
+	helper, args, err := ParseExecValue(value)
+	if err != nil {
+		return "", fmt.Errorf("parameter '%s': %v", key, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), execHelperTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, helper, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	errText := strings.TrimSpace(stderr.String())
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("helper '%s' of parameter '%s' did not finish within %v", helper, key, execHelperTimeout)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", fmt.Errorf("helper '%s' of parameter '%s' failed with exit code %d: %s", helper, key, exitErr.Sys().(syscall.WaitStatus).ExitStatus(), errText)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to call helper '%s' of parameter '%s': %v", helper, key, err)
+	}
+	val := strings.Join(strings.Fields(stdout.String()), "\t")
+	if val == "" {
+		return "", fmt.Errorf("helper '%s' of parameter '%s' printed no value: %s", helper, key, errText)
+	}
+	if errText != "" {
+		system.DebugLog("helper '%s' of parameter '%s' reports: %s", helper, key, errText)
+	}
+	return val, nil
+}
+
+// ExecFailed is the expected value of a parameter, whose helper program
+// failed. The parameter does not conform and is not set during apply
+const ExecFailed = "helper failed"
+
+// execErrorPrefix marks the error of a failed helper program in the Inform
+// entry of the parameter
+const execErrorPrefix = "@EXEC error: "
+
+// ExecFailure returns the error of the failed helper program of a parameter
+// found in its Inform entry. The second return value is false, if the
+// helper program did not fail
+func ExecFailure(inform string) (string, bool) {
+	if !strings.HasPrefix(inform, execErrorPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(inform, execErrorPrefix), true
+}
+
+// ramValueUnits contains the units of the parameters, whose values are not
+// given in bytes. The values calculated from '@RAM' are converted to these
+// units
+var ramValueUnits = map[string]int64{
+	"kernel.shmall":               int64(os.Getpagesize()),
+	"vm.min_free_kbytes":          1 << 10,
+	"vm.pagecache_limit_mb":       1 << 20,
+	"ShmFileSystemSizeMB":         1 << 20,
+	"OVERRIDE_PAGECACHE_LIMIT_MB": 1 << 20,
+}
+
+// ramSizeUnits are the supported unit suffixes of the sizes used by '@RAM'
+var ramSizeUnits = map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+
+// IsRAMValue returns true, if the value of a parameter depends on the total
+// memory of the system like '@RAM 75%' or '@RAM -2G'
+func IsRAMValue(value string) bool {
+	fields := strings.Fields(value)
+	return len(fields) != 0 && strings.ToUpper(fields[0]) == "@RAM"
+}
+
+// ParseRAMValue splits an '@RAM' value into the percentage of the total
+// memory and the size in bytes added to the total memory. Supported are
+// @RAM n%            - n percent of the total memory
+// @RAM +n[K|M|G|T]   - the total memory plus n
+// @RAM -n[K|M|G|T]   - the total memory minus n
+func ParseRAMValue(value string) (int64, int64, error) {
+	fields := strings.Fields(value)
+	wrongValue := fmt.Errorf("wrong value '%s', expected '@RAM <percent>%%' or '@RAM +|-<size>[K|M|G|T]'", NormaliseSysctlFormula(value))
+	if len(fields) != 2 {
+		return 0, 0, wrongValue
+	}
+	operand := fields[1]
+	if strings.HasSuffix(operand, "%") {
+		percent, err := strconv.ParseInt(strings.TrimSuffix(operand, "%"), 10, 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return 0, 0, fmt.Errorf("percentage '%s' of '%s' needs to be an integer between 1 and 100", operand, NormaliseSysctlFormula(value))
+		}
+		return percent, 0, nil
+	}
+	if !strings.HasPrefix(operand, "+") && !strings.HasPrefix(operand, "-") {
+		return 0, 0, wrongValue
+	}
+	unit := int64(1)
+	if u, ok := ramSizeUnits[strings.ToUpper(operand[len(operand)-1:])]; ok {
+		unit = u
+		operand = operand[:len(operand)-1]
+	}
+	size, err := strconv.ParseInt(operand, 10, 64)
+	if err != nil {
+		return 0, 0, wrongValue
+	}
+	size, inRange := mulInt64(size, unit)
+	if !inRange {
+		return 0, 0, fmt.Errorf("size '%s' of '%s' exceeds the range of a 64-bit integer", fields[1], NormaliseSysctlFormula(value))
+	}
+	return 0, size, nil
+}
+
+// OptRAMVal calculates the expected value of a parameter from its '@RAM'
+// value and the total memory of the system read from /proc/meminfo. The
+// result is converted to the unit of the parameter, e.g. to pages for
+// kernel.shmall
+func OptRAMVal(key, value string) (string, error) {
+	percent, delta, err := ParseRAMValue(value)
+	if err != nil {
+		return "", fmt.Errorf("parameter '%s': %v", key, err)
+	}
+	total, err := system.GetMainMemSizeBytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the total memory needed by '%s' of parameter '%s': %v", NormaliseSysctlFormula(value), key, err)
+	}
+	if total > math.MaxInt64 {
+		return "", fmt.Errorf("total memory of %d bytes exceeds the range of '%s' of parameter '%s'", total, NormaliseSysctlFormula(value), key)
+	}
+	var size int64
+	inRange := true
+	if percent != 0 {
+		// percent is at most 100, so divide first to prevent the
+		// multiplication from overflowing
+		size = int64(total)/100*percent + int64(total)%100*percent/100
+	} else {
+		size, inRange = addInt64(int64(total), delta)
+	}
+	if !inRange {
+		return "", fmt.Errorf("'%s' of parameter '%s' exceeds the range of a 64-bit integer", NormaliseSysctlFormula(value), key)
+	}
+	if size < 0 {
+		return "", fmt.Errorf("'%s' of parameter '%s' results in a negative value", NormaliseSysctlFormula(value), key)
+	}
+	if unit, ok := ramValueUnits[key]; ok {
+		size = size / unit
+	}
+	return strconv.FormatInt(size, 10), nil
+}
+
+// sysctlFormulaBase returns the value, a sysctl formula is calculated from.
+// This is the value the parameter had before saptune changed it, so that
+// a formula does not grow the value with each 'apply' and 'verify' compares
+// against the same value as 'apply' used
+func sysctlFormulaBase(key, actval string) string {
+	pEntries := GetSavedParameterNotes(key)
+	if len(pEntries.AllNotes) > 0 && pEntries.AllNotes[0].NoteID == "start" {
+		return pEntries.AllNotes[0].Value
+	}
+	return actval
+}
+
 // section [block]
 
 var isSched = regexp.MustCompile(`^IO_SCHEDULER_\w+$`)
 var isNrreq = regexp.MustCompile(`^NRREQ_\w+$`)
 
+// isBlkPattern matches the parameters of block device patterns, which do
+// not match any block device of the system (e.g. 'IO_SCHEDULER_[nvme*]')
+var isBlkPattern = regexp.MustCompile(`^(IO_SCHEDULER|NRREQ)_\[.*\]$`)
+
 // GetBlkVal initialise the block device structure with the current
 // system settings
 func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error) {
@@ -101,6 +483,9 @@ func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error)
 	info := ""
 
 	switch {
+	case isBlkPattern.MatchString(key):
+		// no block device available for the pattern
+		retVal = "NA"
 	case isSched.MatchString(key):
 		newIOQ, err := cur.BlockDeviceSchedulers.Inspect()
 		if err != nil {
@@ -130,6 +515,9 @@ func OptBlkVal(key, cfgval string, cur *param.BlockDeviceQueue, bOK map[string][
 	}
 	sval := cfgval
 	switch {
+	case isBlkPattern.MatchString(key):
+		// no block device available for the pattern
+		sval = "NA"
 	case isSched.MatchString(key):
 		oval := ""
 		sfound := false
@@ -399,7 +787,7 @@ func SetCPUVal(key, value, noteID, savedStates, oval, info string, revert bool)
 				// as we set and handle 2 different sort of values
 				// the 'force_latency' value and the related
 				// cpu state values
-				_, flstates, _ = system.GetFLInfo()
+				_, flstates, _ := system.GetFLInfo()
 				AddParameterNoteValues("fl_states", flstates, noteID)
 			}
 		}
@@ -515,6 +903,26 @@ func GetGrubVal(key string) string {
 	return val
 }
 
+// states of a grub parameter found by GetGrubState
+const (
+	GrubStateRunning = "running" // the running kernel uses the value
+	GrubStateReboot  = "reboot"  // only the boot loader configuration contains the value
+	GrubStateMissing = "missing" // the value is neither used nor configured
+)
+
+// GetGrubState checks the grub parameter against the command line of the
+// running kernel and against the kernel command line used by the next boot
+func GetGrubState(key, expected string) string {
+	keyFields := strings.Split(key, ":")
+	if system.ParseCmdline("/proc/cmdline", keyFields[1]) == expected {
+		return GrubStateRunning
+	}
+	if system.ParseNextBootCmdline(keyFields[1]) == expected {
+		return GrubStateReboot
+	}
+	return GrubStateMissing
+}
+
 // OptGrubVal returns the value from the configuration file
 func OptGrubVal(key, cfgval string) string {
 	// nothing to do, only checking for 'verify'
@@ -662,13 +1070,117 @@ func SetLoginVal(key, value string, revert bool) error {
 	return nil
 }
 
+// section [systemd]
+
+// SystemdUnitDir is the directory, which contains the drop-in directories
+// of the systemd units
+var SystemdUnitDir = "/etc/systemd/system"
+
+// systemdUnitSections maps the supported unit types to the section of the
+// unit file, which contains the type specific properties
+var systemdUnitSections = map[string]string{
+	"service": "Service",
+	"socket":  "Socket",
+	"mount":   "Mount",
+	"swap":    "Swap",
+	"slice":   "Slice",
+	"scope":   "Scope",
+	"timer":   "Timer",
+	"path":    "Path",
+}
+
+// SplitSystemdKey splits the key of a [systemd] parameter into the unit
+// and the property
+func SplitSystemdKey(key string) (string, string, error) {
+	idx := strings.LastIndex(key, ":")
+	if idx <= 0 || idx == len(key)-1 {
+		return "", "", fmt.Errorf("wrong systemd parameter '%s', expected '<unit>:<property>'", key)
+	}
+	unit := key[:idx]
+	if _, ok := systemdUnitSections[strings.TrimPrefix(path.Ext(unit), ".")]; !ok {
+		return "", "", fmt.Errorf("unsupported type of unit '%s'", unit)
+	}
+	return unit, key[idx+1:], nil
+}
+
+// SystemdDropInFile returns the name of the drop-in file saptune uses to
+// set the property of the unit
+func SystemdDropInFile(unit, property string) string {
+	return path.Join(SystemdUnitDir, unit+".d", "saptune-"+property+".conf")
+}
+
+// GetSystemdVal initialise the systemd unit property structure with the
+// current value of the unit property
+func GetSystemdVal(key string) string {
+	unit, property, err := SplitSystemdKey(key)
+	if err != nil {
+		system.WarningLog("%v", err)
+		return "NA"
+	}
+	val, err := system.SystemctlShowProperty(unit, property)
+	if err != nil {
+		system.InfoLog("%v", err)
+		return "NA"
+	}
+	// multiple values are separated by tabs in the note definition
+	return strings.Join(strings.Fields(val), "\t")
+}
+
+// OptSystemdVal returns the value from the configuration file. Multiple
+// values are separated by single tabs like the values returned by
+// GetSystemdVal, so that they can be compared
+func OptSystemdVal(cfgval string) string {
+	return strings.Join(strings.Fields(cfgval), "\t")
+}
+
+// SetSystemdVal applies the unit property to the system by writing a
+// drop-in file for the unit. During revert the drop-in file is removed,
+// if no other note sets the property.
+// The unit is not restarted, the property takes effect with the next
+// (re)start of the unit
+func SetSystemdVal(key, value string, revert bool) error {
+	unit, property, err := SplitSystemdKey(key)
+	if err != nil {
+		return err
+	}
+	dropIn := SystemdDropInFile(unit, property)
+	if revert && IsLastNoteOfParameter(key) {
+		// revert - remove the drop-in file and the drop-in
+		// directory, if it is empty now
+		if err := os.Remove(dropIn); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		os.Remove(path.Dir(dropIn))
+		return system.SystemctlDaemonReload()
+	}
+	if value == "" || value == "NA" {
+		return nil
+	}
+	// revert with value from another former applied note
+	// or
+	// apply - prepare the drop-in file
+	section := systemdUnitSections[strings.TrimPrefix(path.Ext(unit), ".")]
+	content := fmt.Sprintf("# created by saptune, do not edit\n[%s]\n%s=%s\n", section, property, strings.Replace(value, "\t", " ", -1))
+	if err := os.MkdirAll(path.Dir(dropIn), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(dropIn, []byte(content), 0644); err != nil {
+		return err
+	}
+	if err := system.SystemctlDaemonReload(); err != nil {
+		return err
+	}
+	system.InfoLog("property '%s' of unit '%s' changed, it takes effect with the next (re)start of the unit", property, unit)
+	return nil
+}
+
 // section [pagecache]
 
 // GetPagecacheVal initialise the pagecache structure with the current
 // system settings
 func GetPagecacheVal(key string, cur *LinuxPagingImprovements) string {
 	val := ""
-	currentPagecache, err := LinuxPagingImprovements{PagingConfig: cur.PagingConfig}.Initialise()
+	currentPagecache, err := LinuxPagingImprovements{PagingConfig: cur.PagingConfig, PagingOverrides: cur.PagingOverrides}.Initialise()
 	if err != nil {
 		return ""
 	}
@@ -4,9 +4,11 @@ import (
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -79,6 +81,57 @@ func TestOptSysctlVal(t *testing.T) {
 	}
 }
 
+func TestOptSysctlFormula(t *testing.T) {
+	if !IsSysctlFormula(" @MUL\t2") || IsSysctlFormula("100") {
+		t.Error("wrong detection of formula")
+	}
+	if val := NormaliseSysctlFormula("@MUL\t2"); val != "@MUL 2" {
+		t.Error(val)
+	}
+	tests := []struct {
+		base, formula, expected string
+	}{
+		{"1024", "@MUL 2", "2048"},
+		{"1024", "@mul\t2", "2048"},
+		{"1024", "@ADD 100", "1124"},
+		{"1024", "@MIN 512", "512"},
+		{"1024", "@MIN 2048", "1024"},
+		{"1024", "@MAX 512", "1024"},
+		{"1024", "@MAX 1048576", "1048576"},
+		{"", "@MUL 2", ""},
+	}
+	for _, tst := range tests {
+		val, err := OptSysctlFormula("TestParam", tst.base, tst.formula)
+		if err != nil || val != tst.expected {
+			t.Errorf("'%s' with base '%s': expected '%s', got '%s' (%v)", tst.formula, tst.base, tst.expected, val, err)
+		}
+	}
+	for _, formula := range []string{"@DIV 2", "@MUL", "@MUL two", "@MUL 2 3"} {
+		if _, err := OptSysctlFormula("TestParam", "1024", formula); err == nil {
+			t.Errorf("expected an error for formula '%s'", formula)
+		}
+	}
+	_, err := OptSysctlFormula("TestParam", "4096\t16384\t4194304", "@MUL 2")
+	if err == nil || err.Error() != "formula '@MUL 2' of parameter 'TestParam' needs a numeric value of the parameter, but found '4096 16384 4194304'" {
+		t.Error(err)
+	}
+	// the results need to fit into a 64-bit integer
+	for _, tst := range []struct{ base, formula string }{
+		{"9223372036854775807", "@MUL 2"},
+		{"-9223372036854775808", "@MUL -1"},
+		{"4611686018427387904", "@MUL -3"},
+		{"9223372036854775807", "@ADD 1"},
+		{"-9223372036854775808", "@ADD -1"},
+	} {
+		if _, err := OptSysctlFormula("TestParam", tst.base, tst.formula); err == nil || !strings.Contains(err.Error(), "exceeds the range") {
+			t.Errorf("'%s' with base '%s': expected an overflow error, got '%v'", tst.formula, tst.base, err)
+		}
+	}
+	if val, err := OptSysctlFormula("TestParam", "4611686018427387903", "@MUL 2"); err != nil || val != "9223372036854775806" {
+		t.Error(val, err)
+	}
+}
+
 func TestGetBlkVal(t *testing.T) {
 	tblck := param.BlockDeviceQueue{BlockDeviceSchedulers: param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, BlockDeviceNrRequests: param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}}
 	_, _, err := GetBlkVal("IO_SCHEDULER_sda", &tblck)
@@ -167,6 +220,27 @@ func TestOptBlkVal(t *testing.T) {
 	}
 }
 
+func TestBlkValPattern(t *testing.T) {
+	blckOK := make(map[string][]string)
+	tblck := param.BlockDeviceQueue{BlockDeviceSchedulers: param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, BlockDeviceNrRequests: param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}}
+	for _, key := range []string{"IO_SCHEDULER_[nvme*]", "NRREQ_[sd* vd*]"} {
+		val, info, err := GetBlkVal(key, &tblck)
+		if err != nil || val != "NA" || info != "" {
+			t.Error(key, val, info, err)
+		}
+		val, info = OptBlkVal(key, "1024", &tblck, blckOK)
+		if val != "NA" || info != "" {
+			t.Error(key, val, info)
+		}
+		if err := SetBlkVal(key, "NA", &tblck, false); err != nil {
+			t.Error(key, err)
+		}
+	}
+	if len(blckOK) != 0 {
+		t.Error(blckOK)
+	}
+}
+
 func TestSetBlkVal(t *testing.T) {
 	blckOK := make(map[string][]string)
 	tblck := param.BlockDeviceQueue{BlockDeviceSchedulers: param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)}, BlockDeviceNrRequests: param.BlockDeviceNrRequests{NrRequests: make(map[string]int)}}
@@ -459,6 +533,27 @@ func TestGetGrubVal(t *testing.T) {
 	}
 }
 
+func TestGetGrubState(t *testing.T) {
+	oldGrubConfig := system.GrubConfigFile
+	defer func() { system.GrubConfigFile = oldGrubConfig }()
+	system.GrubConfigFile = path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/grub.cfg")
+
+	val := GetGrubState("grub:UNKNOWN", "NA")
+	if val != GrubStateRunning {
+		t.Fatal(val)
+	}
+	if GetGrubVal("grub:transparent_hugepage") != "never" {
+		val = GetGrubState("grub:transparent_hugepage", "never")
+		if val != GrubStateReboot {
+			t.Fatal(val)
+		}
+	}
+	val = GetGrubState("grub:UNKNOWN", "1")
+	if val != GrubStateMissing {
+		t.Fatal(val)
+	}
+}
+
 func TestOptGrubVal(t *testing.T) {
 	val := OptGrubVal("grub:processor.max_cstate", "NO_OPT")
 	if val != "NO_OPT" {
@@ -753,3 +848,223 @@ func TestSetPagecacheVal(t *testing.T) {
 		t.Fatal(val)
 	}
 }
+
+func TestExecValue(t *testing.T) {
+	oldDir := ExecHelperDir
+	defer func() { ExecHelperDir = oldDir }()
+	helperDir, err := ioutil.TempDir("", "saptune-helpers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(helperDir)
+	ExecHelperDir = helperDir + "/"
+	okHelper := path.Join(helperDir, "calc_ok")
+	ioutil.WriteFile(okHelper, []byte("#!/bin/sh\necho \"$1  4096\"\necho note >&2\n"), 0755)
+	failHelper := path.Join(helperDir, "calc_fail")
+	ioutil.WriteFile(failHelper, []byte("#!/bin/sh\necho broken >&2\nexit 3\n"), 0755)
+
+	if !IsExecValue("@EXEC\t"+okHelper) || !IsExecValue("@exec "+okHelper) || IsExecValue("@MUL 2") {
+		t.Error("wrong detection of '@EXEC' values")
+	}
+	val, err := OptExecVal("kernel.shmall", "@EXEC\t"+okHelper+"\t1024")
+	if err != nil || val != "1024\t4096" {
+		t.Errorf("got '%s', '%v'", val, err)
+	}
+	_, err = OptExecVal("kernel.shmall", "@EXEC "+failHelper)
+	if err == nil || !strings.Contains(err.Error(), "failed with exit code 3: broken") {
+		t.Errorf("wrong error '%v'", err)
+	}
+	for _, value := range []string{"@EXEC", "@EXEC /usr/bin/id", "@EXEC " + helperDir + "/../calc_ok", "@EXEC calc_ok"} {
+		if _, err := OptExecVal("kernel.shmall", value); err == nil {
+			t.Errorf("expected an error for '%s'", value)
+		}
+	}
+}
+
+func TestOptSystemdVal(t *testing.T) {
+	for cfgval, expected := range map[string]string{"1024": "1024", " 0 1 ": "0\t1", "0\t\t1": "0\t1", "0  \t 1": "0\t1", "": ""} {
+		if val := OptSystemdVal(cfgval); val != expected {
+			t.Errorf("'%s': expected '%s', got '%s'", cfgval, expected, val)
+		}
+	}
+}
+
+func TestSetSystemdVal(t *testing.T) {
+	oldDir := SystemdUnitDir
+	defer func() { SystemdUnitDir = oldDir }()
+	unitDir, err := ioutil.TempDir("", "saptune-units")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(unitDir)
+	SystemdUnitDir = unitDir
+
+	if _, _, err := SplitSystemdKey("sapinit:LimitNOFILE"); err == nil {
+		t.Error("expected an error for a unit without type")
+	}
+	if _, _, err := SplitSystemdKey("sapinit.service:"); err == nil {
+		t.Error("expected an error for a missing property")
+	}
+	key := "sapinit.service:CPUAffinity"
+	dropIn := path.Join(unitDir, "sapinit.service.d", "saptune-CPUAffinity.conf")
+	if err := SetSystemdVal(key, "0\t1", false); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(dropIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "[Service]\nCPUAffinity=0 1\n") {
+		t.Errorf("wrong drop-in file content '%s'", string(content))
+	}
+	if err := SetSystemdVal(key, "", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Dir(dropIn)); !os.IsNotExist(err) {
+		t.Errorf("drop-in directory '%s' not removed", path.Dir(dropIn))
+	}
+}
+
+func TestRAMValue(t *testing.T) {
+	oldFile := system.MemInfoFile
+	defer func() { system.MemInfoFile = oldFile }()
+	memInfo, err := ioutil.TempFile("", "saptune-meminfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(memInfo.Name())
+	// 16 GiB
+	memInfo.WriteString("MemTotal:       16777216 kB\nMemFree:         1048576 kB\n")
+	memInfo.Close()
+	system.MemInfoFile = memInfo.Name()
+
+	if !IsRAMValue("@RAM\t75%") || !IsRAMValue("@ram -2G") || IsRAMValue("@MUL 2") {
+		t.Error("wrong detection of '@RAM' values")
+	}
+	pageSize := int64(os.Getpagesize())
+	for _, tc := range []struct {
+		key, value, expected string
+	}{
+		{"kernel.shmmax", "@RAM\t75%", "12884901888"},
+		{"kernel.shmmax", "@RAM -2G", "15032385536"},
+		{"kernel.shmmax", "@RAM +512m", "17716740096"},
+		{"kernel.shmall", "@RAM 50%", strconv.FormatInt(8589934592/pageSize, 10)},
+		{"ShmFileSystemSizeMB", "@RAM -1024M", "15360"},
+		{"vm.min_free_kbytes", "@RAM 1%", "167772"},
+	} {
+		val, err := OptRAMVal(tc.key, tc.value)
+		if err != nil || val != tc.expected {
+			t.Errorf("'%s' of '%s': expected '%s', got '%s', '%v'", tc.value, tc.key, tc.expected, val, err)
+		}
+	}
+	for _, value := range []string{"@RAM", "@RAM 2G", "@RAM 0%", "@RAM 120%", "@RAM -2X", "@RAM 50% 2"} {
+		if _, err := OptRAMVal("kernel.shmmax", value); err == nil {
+			t.Errorf("expected an error for '%s'", value)
+		}
+	}
+	if _, err := OptRAMVal("kernel.shmmax", "@RAM +9999999999T"); err == nil || !strings.Contains(err.Error(), "exceeds the range") {
+		t.Error(err)
+	}
+	if _, err := OptRAMVal("kernel.shmmax", "@RAM +9223372036854775807"); err == nil || !strings.Contains(err.Error(), "exceeds the range") {
+		t.Error(err)
+	}
+	if _, err := OptRAMVal("kernel.shmmax", "@RAM -20G"); err == nil || !strings.Contains(err.Error(), "negative value") {
+		t.Errorf("wrong error '%v'", err)
+	}
+	system.MemInfoFile = "/not_avail"
+	if _, err := OptRAMVal("kernel.shmmax", "@RAM 75%"); err == nil || !strings.Contains(err.Error(), "failed to get the total memory") {
+		t.Errorf("wrong error '%v'", err)
+	}
+}
+
+func TestExpandSysctlInterfaces(t *testing.T) {
+	ifaces := system.GetSysctlInterfaces("net.ipv4.conf.")
+	if len(ifaces) == 0 {
+		t.Skip("no network interfaces found")
+	}
+	ini := expandSysctlInterfaces(txtparser.ParseINI(`[sysctl]
+# reverse path filtering
+net.ipv4.conf.{iface}.rp_filter = 1
+net.ipv4.conf.lo.rp_filter = 0
+vm.swappiness = 10
+[service]
+sapinit-{iface}.service = start
+`))
+	keys := make(map[string]int)
+	for _, entry := range ini.AllValues {
+		keys[entry.Key]++
+	}
+	if keys["net.ipv4.conf.{iface}.rp_filter"] != 0 || keys["vm.swappiness"] != 1 || keys["sapinit-{iface}.service"] != 1 {
+		t.Error(ini.AllValues)
+	}
+	if _, ok := ini.KeyValue[INISectionSysctl]["net.ipv4.conf.{iface}.rp_filter"]; ok {
+		t.Error(ini.KeyValue)
+	}
+	for _, iface := range ifaces {
+		key := "net.ipv4.conf." + iface + ".rp_filter"
+		if keys[key] != 1 {
+			t.Errorf("expected exactly one entry for '%s', got %d", key, keys[key])
+		}
+		expected := "1"
+		if iface == "lo" {
+			// the explicit entry takes precedence
+			expected = "0"
+		}
+		if ini.KeyValue[INISectionSysctl][key].Value != expected {
+			t.Errorf("'%s': expected '%s', got '%+v'", key, expected, ini.KeyValue[INISectionSysctl][key])
+		}
+		if iface != "lo" && ini.Comments[key] == "" {
+			t.Errorf("missing comment of '%s'", key)
+		}
+	}
+	if len(ini.AllValues) != len(ifaces)+2 {
+		t.Error(ini.AllValues)
+	}
+}
+
+func TestRemovedParams(t *testing.T) {
+	tstDir := "/tmp/saptune_removed_test"
+	defer os.RemoveAll(tstDir)
+	if err := os.MkdirAll(tstDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	noteFile := path.Join(tstDir, "ifaceNote")
+	if err := ioutil.WriteFile(noteFile, []byte("[sysctl]\nnet.ipv4.conf.{iface}.rp_filter = 1\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vanished := "net.ipv4.conf.saptunetst0.rp_filter"
+	defer CleanUpParamFile(vanished)
+	CreateParameterStartValues(vanished, "2")
+	AddParameterNoteValues(vanished, "2", "ifaceNote")
+	if IsLastNoteOfParameter(vanished) {
+		t.Fatal("parameter saved state file not created")
+	}
+
+	vend := INISettings{ConfFilePath: noteFile, ID: "ifaceNote", SysctlParams: map[string]string{vanished: "2", "vm.swappiness": "60", "vm.dirty_ratio": "20", "UserTasksMax": "12288"}, ParamSections: []string{"sysctl:" + vanished, "sysctl:vm.swappiness", "sysctl:vm.dirty_ratio", "login:UserTasksMax"}}
+	ini, err := vend.ParseDefinition()
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := vend.removedParams(ini)
+	if len(removed) != 3 || removed[0].Key != vanished || removed[1].Section != "sysctl" || removed[1].Key != "vm.dirty_ratio" || removed[1].Value != "20" || removed[2].Section != "login" || removed[2].Key != "UserTasksMax" {
+		t.Error(removed)
+	}
+	if keys, err := vend.RemovedParams(); err != nil || strings.Join(keys, " ") != vanished+" vm.dirty_ratio UserTasksMax" {
+		t.Error(keys, err)
+	}
+	// the revert is restricted to another parameter
+	vend.ValuesToApply = map[string]string{"revert": "revert", "vm.swappiness": "vm.swappiness"}
+	if removed := vend.removedParams(ini); len(removed) != 0 {
+		t.Error(removed)
+	}
+
+	// the interface does not exist, so there is nothing to set, only the
+	// parameter saved state file is removed
+	vend = INISettings{ConfFilePath: noteFile, ID: "ifaceNote", SysctlParams: map[string]string{vanished: "2"}, ParamSections: []string{"sysctl:" + vanished}}
+	if err := vend.SetValuesToApply([]string{"revert"}).Apply(); err != nil {
+		t.Error(err)
+	}
+	if !IsLastNoteOfParameter(vanished) {
+		t.Error("parameter saved state file of the vanished interface not removed")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
@@ -489,3 +491,547 @@ func TestPageCacheSettings(t *testing.T) {
 	}
 	cleanUp()
 }
+
+func TestPageCacheOverrideLayers(t *testing.T) {
+	cleanUp()
+	oldOverrideTuningSheets := OverrideTuningSheets
+	oldOverrideLayers := OverrideLayers
+	defer func() {
+		OverrideTuningSheets = oldOverrideTuningSheets
+		OverrideLayers = oldOverrideLayers
+	}()
+	baseDir, err := ioutil.TempDir("", "saptune-pagecache-layers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	siteDir := path.Join(baseDir, "site") + "/"
+	hostDir := path.Join(baseDir, "host") + "/"
+	for _, dir := range []string{siteDir, hostDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	OverrideTuningSheets = hostDir
+	OverrideLayers = []string{siteDir}
+	// the value of the site layer is kept, if the host layer does not set it
+	if err := ioutil.WriteFile(path.Join(siteDir, "1557506"), []byte("[pagecache]\nOVERRIDE_PAGECACHE_LIMIT_MB=500\nvm.pagecache_limit_ignore_dirty=0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(hostDir, "1557506"), []byte("[pagecache]\nvm.pagecache_limit_ignore_dirty=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	iniPath := path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/pcTest6.ini")
+	ini := INISettings{ConfFilePath: iniPath, ID: "1557506"}
+	initialised, err := ini.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimised, err := initialised.(INISettings).Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+	if optimisedINI.SysctlParams["OVERRIDE_PAGECACHE_LIMIT_MB"] != "500" {
+		t.Fatal(optimisedINI.SysctlParams)
+	}
+	if optimisedINI.SysctlParams[system.SysctlPagecacheLimitIgnoreDirty] != "2" {
+		t.Fatal(optimisedINI.SysctlParams)
+	}
+	cleanUp()
+}
+
+func TestDefinedParams(t *testing.T) {
+	simpleNote := INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "simpleNote.conf"), ID: "simpleNote", DescriptiveName: ""}
+	params, err := simpleNote.DefinedParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(params) != 1 {
+		t.Fatal(params)
+	}
+	if params["net.ipv4.ip_local_port_range"] != "31768\t61999" {
+		t.Fatal(params)
+	}
+	sectionParams, err := simpleNote.DefinedSectionParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sectionParams) != 1 || sectionParams["sysctl"]["net.ipv4.ip_local_port_range"] != "31768\t61999" {
+		t.Fatal(sectionParams)
+	}
+	noNote := INISettings{ConfFilePath: "/not_avail", ID: "no", DescriptiveName: ""}
+	if _, err := noNote.DefinedParams(); err == nil {
+		t.Fatal("expected an error for a missing definition file")
+	}
+	if _, err := noNote.DefinedSectionParams(); err == nil {
+		t.Fatal("expected an error for a missing definition file")
+	}
+}
+
+func TestSolutionOverride(t *testing.T) {
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	ovDir, err := ioutil.TempDir("", "saptune-override")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(ovDir)
+	OverrideTuningSheets = ovDir
+	if err := ioutil.WriteFile(path.Join(ovDir, "simpleNote"), []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 32768 60999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for solName, value := range map[string]string{"HANA": "40000 60999", "NETW": "50000 60999"} {
+		solOverride := GetSolutionOverrideFile(solName, "simpleNote")
+		if err := os.MkdirAll(path.Dir(solOverride), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(solOverride, []byte("[sysctl]\nnet.ipv4.ip_local_port_range = "+value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if noteIDs := SolutionOverrideNotes("HANA"); len(noteIDs) != 1 || noteIDs[0] != "simpleNote" {
+		t.Fatal(noteIDs)
+	}
+	simpleNote := INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "simpleNote.conf"), ID: "simpleNote", DescriptiveName: ""}
+	for _, tc := range []struct {
+		solutions []string
+		expected  string
+	}{
+		{nil, "32768\t60999"},
+		{[]string{"HANA"}, "40000\t60999"},
+		{[]string{"HANA", "NETW"}, "50000\t60999"},
+		{[]string{"S4HANA"}, "32768\t60999"},
+	} {
+		simpleNote.Solutions = tc.solutions
+		params, err := simpleNote.DefinedParams()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params["net.ipv4.ip_local_port_range"] != tc.expected {
+			t.Errorf("solutions %v: expected '%s', got '%s'", tc.solutions, tc.expected, params["net.ipv4.ip_local_port_range"])
+		}
+	}
+	// solution specific override file without override file of the Note
+	os.Remove(path.Join(ovDir, "simpleNote"))
+	simpleNote.Solutions = []string{"HANA"}
+	if params, _ := simpleNote.DefinedParams(); params["net.ipv4.ip_local_port_range"] != "40000\t60999" {
+		t.Errorf("got '%s'", params["net.ipv4.ip_local_port_range"])
+	}
+}
+
+func TestOverrideLayers(t *testing.T) {
+	oldOverrideTuningSheets := OverrideTuningSheets
+	oldOverrideLayers := OverrideLayers
+	defer func() {
+		OverrideTuningSheets = oldOverrideTuningSheets
+		OverrideLayers = oldOverrideLayers
+	}()
+	baseDir, err := ioutil.TempDir("", "saptune-override-layers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	siteDir := path.Join(baseDir, "site") + "/"
+	hostDir := path.Join(baseDir, "host") + "/"
+	for _, dir := range []string{siteDir, hostDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	OverrideTuningSheets = hostDir
+	// the host directory is always the last layer, even if listed
+	OverrideLayers = []string{siteDir, hostDir}
+	if dirs := OverrideDirs(); len(dirs) != 2 || dirs[0] != siteDir || dirs[1] != hostDir {
+		t.Fatal(dirs)
+	}
+	simpleNote := INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "simpleNote.conf"), ID: "simpleNote", DescriptiveName: ""}
+	if files := OverrideFiles("simpleNote"); len(files) != 0 {
+		t.Fatal(files)
+	}
+
+	// site layer only
+	if err := ioutil.WriteFile(path.Join(siteDir, "simpleNote"), []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 40000 60999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if files := OverrideFiles("simpleNote"); len(files) != 1 || files[0] != path.Join(siteDir, "simpleNote") {
+		t.Fatal(files)
+	}
+	if params, _ := simpleNote.DefinedParams(); params["net.ipv4.ip_local_port_range"] != "40000\t60999" {
+		t.Errorf("got '%s'", params["net.ipv4.ip_local_port_range"])
+	}
+
+	// the host layer takes precedence over the site layer
+	if err := ioutil.WriteFile(path.Join(hostDir, "simpleNote"), []byte("[sysctl]\nnet.ipv4.ip_local_port_range = 50000 60999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := OverrideFiles("simpleNote")
+	if len(files) != 2 || files[0] != path.Join(siteDir, "simpleNote") || files[1] != path.Join(hostDir, "simpleNote") {
+		t.Fatal(files)
+	}
+	if params, _ := simpleNote.DefinedParams(); params["net.ipv4.ip_local_port_range"] != "50000\t60999" {
+		t.Errorf("got '%s'", params["net.ipv4.ip_local_port_range"])
+	}
+}
+
+func TestCheckOnly(t *testing.T) {
+	simpleNote := INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "simpleNote.conf"), ID: "simpleNote", DescriptiveName: ""}
+	if simpleNote.CheckOnly() {
+		t.Fatal("simpleNote is not a 'check only' note")
+	}
+	checkFile := "/tmp/saptune_checkonly_note"
+	if err := ioutil.WriteFile(checkFile, []byte("[check_only]\n[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(checkFile)
+	checkNote := INISettings{ConfFilePath: checkFile, ID: "checkOnlyNote", DescriptiveName: ""}
+	if !checkNote.CheckOnly() {
+		t.Fatal("checkOnlyNote is a 'check only' note")
+	}
+}
+
+func TestTags(t *testing.T) {
+	simpleNote := INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "simpleNote.conf"), ID: "simpleNote", DescriptiveName: ""}
+	if tags := simpleNote.Tags(); len(tags) != 0 {
+		t.Fatal(tags)
+	}
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_tags_override/"
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(OverrideTuningSheets)
+	tagFile := "/tmp/saptune_tags_note"
+	if err := ioutil.WriteFile(tagFile, []byte("[tags]\nproduction\n[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tagFile)
+	if err := ioutil.WriteFile(path.Join(OverrideTuningSheets, "tagNote"), []byte("[tags]\nHANA production\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tagNote := INISettings{ConfFilePath: tagFile, ID: "tagNote", DescriptiveName: ""}
+	if tags := tagNote.Tags(); !reflect.DeepEqual(tags, []string{"HANA", "production"}) {
+		t.Fatal(tags)
+	}
+}
+
+func TestRequires(t *testing.T) {
+	simpleNote := INISettings{ConfFilePath: path.Join(TstFilesInGOPATH, "simpleNote.conf"), ID: "simpleNote", DescriptiveName: ""}
+	if reqs := simpleNote.Requires(); len(reqs) != 0 {
+		t.Fatal(reqs)
+	}
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_requires_override/"
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(OverrideTuningSheets)
+	reqFile := "/tmp/saptune_requires_note"
+	if err := ioutil.WriteFile(reqFile, []byte("[requires]\n2205917\n[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(reqFile)
+	if err := ioutil.WriteFile(path.Join(OverrideTuningSheets, "reqNote"), []byte("[requires]\n1980196 2205917\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reqNote := INISettings{ConfFilePath: reqFile, ID: "reqNote", DescriptiveName: ""}
+	if reqs := reqNote.Requires(); !reflect.DeepEqual(reqs, []string{"1980196", "2205917"}) {
+		t.Fatal(reqs)
+	}
+}
+
+func TestGetDefinitionHash(t *testing.T) {
+	hashFile := "/tmp/saptune_hash_note"
+	defer os.Remove(hashFile)
+	if err := ioutil.WriteFile(hashFile, []byte("[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hashNote := INISettings{ConfFilePath: hashFile, ID: "hashNote", DescriptiveName: ""}
+	hash := hashNote.GetDefinitionHash()
+	if len(hash) != 64 || hash != hashNote.GetDefinitionHash() {
+		t.Fatal(hash)
+	}
+	if err := ioutil.WriteFile(hashFile, []byte("[sysctl]\nvm.swappiness = 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hashNote.GetDefinitionHash() == hash {
+		t.Fatal("hash not changed for a changed Note definition file")
+	}
+	hashNote.ConfFilePath = "/file_does_not_exist"
+	if hash := hashNote.GetDefinitionHash(); hash != "" {
+		t.Fatal(hash)
+	}
+}
+
+func TestParamComments(t *testing.T) {
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_comments_override/"
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(OverrideTuningSheets)
+	commentFile := "/tmp/saptune_comments_note"
+	if err := ioutil.WriteFile(commentFile, []byte("[sysctl]\n# swap less\nvm.swappiness = 10\n# more segments\nkernel.shmmni = 32768\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(commentFile)
+	if err := ioutil.WriteFile(path.Join(OverrideTuningSheets, "commentNote"), []byte("[sysctl]\n# swap even less\nvm.swappiness = 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commentNote := INISettings{ConfFilePath: commentFile, ID: "commentNote", DescriptiveName: ""}
+	expected := map[string]string{"vm.swappiness": "swap even less", "kernel.shmmni": "more segments"}
+	if comments := commentNote.ParamComments(); !reflect.DeepEqual(comments, expected) {
+		t.Fatal(comments)
+	}
+}
+
+func TestParamSeverities(t *testing.T) {
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_severity_override/"
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(OverrideTuningSheets)
+	severityFile := "/tmp/saptune_severity_note"
+	if err := ioutil.WriteFile(severityFile, []byte("[sysctl]\nvm.swappiness = 10\nkernel.shmmni = 32768\n\n[severity]\nvm.swappiness = warning\nkernel.shmmni = info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(severityFile)
+	if err := ioutil.WriteFile(path.Join(OverrideTuningSheets, "severityNote"), []byte("[severity]\nvm.swappiness = info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	severityNote := INISettings{ConfFilePath: severityFile, ID: "severityNote", DescriptiveName: ""}
+	expected := map[string]string{"vm.swappiness": "info", "kernel.shmmni": "info"}
+	if severities := severityNote.ParamSeverities(); !reflect.DeepEqual(severities, expected) {
+		t.Fatal(severities)
+	}
+}
+
+func TestSysctlFormula(t *testing.T) {
+	formulaFile := "/tmp/saptune_formula_note"
+	defer os.Remove(formulaFile)
+	if err := ioutil.WriteFile(formulaFile, []byte("[sysctl]\nvm.swappiness = @ADD 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	formulaNote := INISettings{ConfFilePath: formulaFile, ID: "formulaNote", DescriptiveName: "", ValuesToApply: map[string]string{"verify": "verify"}}
+	initialised, err := formulaNote.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the formula is calculated from the start value, if available
+	base, _ := strconv.ParseInt(sysctlFormulaBase("vm.swappiness", initialised.(INISettings).SysctlParams["vm.swappiness"]), 10, 64)
+	optimised, err := initialised.(INISettings).Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+	if optimisedINI.SysctlParams["vm.swappiness"] != strconv.FormatInt(base+1, 10) {
+		t.Error(base, optimisedINI.SysctlParams["vm.swappiness"])
+	}
+	if optimisedINI.Inform["vm.swappiness"] != "@ADD 1" {
+		t.Error(optimisedINI.Inform["vm.swappiness"])
+	}
+
+	// formula needs a numeric value
+	if err := ioutil.WriteFile(formulaFile, []byte("[sysctl]\nkernel.sem = @MUL 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	initialised, err = formulaNote.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initialised.(INISettings).SysctlParams["kernel.sem"] == "" {
+		t.Skip("kernel.sem not available")
+	}
+	if _, err := initialised.(INISettings).Optimise(); err == nil {
+		t.Error("expected an error for a formula on a value with several fields")
+	}
+}
+
+func TestConditionalSections(t *testing.T) {
+	oldKernelRelease := kernelRelease
+	defer func() { kernelRelease = oldKernelRelease }()
+	kernelRelease = func() string { return "5.14.21-150400.24.11-default" }
+	condFile := "/tmp/saptune_condition_note"
+	defer os.Remove(condFile)
+	if err := ioutil.WriteFile(condFile, []byte("[sysctl:kernel>=5.3]\nvm.swappiness = 10\n[sysctl:kernel<5.3]\nvm.swappiness = 20\nkernel.shmmni = 32768\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	condNote := INISettings{ConfFilePath: condFile, ID: "condNote", DescriptiveName: "", ValuesToApply: map[string]string{"verify": "verify"}}
+	initialised, err := condNote.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initialised.(INISettings).SysctlParams["kernel.shmmni"] != "NA" {
+		t.Error(initialised.(INISettings).SysctlParams)
+	}
+	optimised, err := initialised.(INISettings).Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+	if optimisedINI.SysctlParams["vm.swappiness"] != "10" || optimisedINI.SysctlParams["kernel.shmmni"] != "NA" {
+		t.Error(optimisedINI.SysctlParams)
+	}
+
+	// with an older kernel the other section is used
+	kernelRelease = func() string { return "4.12.14-122.37-default" }
+	initialised, err = condNote.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimised, err = initialised.(INISettings).Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI = optimised.(INISettings)
+	if optimisedINI.SysctlParams["vm.swappiness"] != "20" || optimisedINI.SysctlParams["kernel.shmmni"] != "32768" {
+		t.Error(optimisedINI.SysctlParams)
+	}
+
+	// the conditions of the override file are evaluated, too
+	oldOverrideTuningSheets := OverrideTuningSheets
+	defer func() { OverrideTuningSheets = oldOverrideTuningSheets }()
+	OverrideTuningSheets = "/tmp/saptune_condition_override"
+	defer os.RemoveAll(OverrideTuningSheets)
+	if err := os.MkdirAll(OverrideTuningSheets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(OverrideTuningSheets, "condNote"), []byte("[sysctl:kernel>=5.3]\nvm.swappiness = 15\n[sysctl:kernel<5.3]\nvm.swappiness = 25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for release, expected := range map[string]string{"4.12.14-122.37-default": "25", "5.14.21-150400.24.11-default": "15"} {
+		kernelRelease = func() string { return release }
+		initialised, err = condNote.Initialise()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if initialised.(INISettings).OverrideParams["vm.swappiness"] != expected {
+			t.Error(release, initialised.(INISettings).OverrideParams)
+		}
+		optimised, err = initialised.(INISettings).Optimise()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if optimised.(INISettings).SysctlParams["vm.swappiness"] != expected {
+			t.Error(release, optimised.(INISettings).SysctlParams)
+		}
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	incDir := "/tmp/saptune_include_notes"
+	if err := os.MkdirAll(incDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(incDir)
+	files := map[string]string{
+		"baseNote":  "[tags]\nHANA\n[sysctl]\n# base comment\nvm.swappiness = 60\nkernel.shmmni = 4096\n",
+		"midNote":   "[include]\nbaseNote\n[sysctl]\n# mid comment\nkernel.shmmni = 32768\n",
+		"topNote":   "[include]\nmidNote baseNote\n[sysctl]\nvm.swappiness = 10\n",
+		"cycleNote": "[include]\nloopNote\n[sysctl]\nvm.swappiness = 10\n",
+		"loopNote":  "[include]\ncycleNote\n",
+		"lostNote":  "[include]\nunknownNote\n",
+	}
+	allNotes := TuningOptions{}
+	for id, content := range files {
+		fileName := path.Join(incDir, id)
+		if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		allNotes[id] = INISettings{ConfFilePath: fileName, ID: id}
+	}
+
+	includeFiles, err := ResolveIncludes("topNote", allNotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(includeFiles, []string{path.Join(incDir, "baseNote"), path.Join(incDir, "midNote")}) {
+		t.Fatal(includeFiles)
+	}
+	if includeFiles, err := ResolveIncludes("baseNote", allNotes); err != nil || len(includeFiles) != 0 {
+		t.Fatal(includeFiles, err)
+	}
+	if _, err := ResolveIncludes("cycleNote", allNotes); err == nil || err.Error() != "the notes have cyclic includes: cycleNote -> loopNote -> cycleNote" {
+		t.Fatal(err)
+	}
+	if _, err := ResolveIncludes("lostNote", allNotes); err == nil || err.Error() != "the Note unknownNote included by Note lostNote is unknown or has no Note definition file" {
+		t.Fatal(err)
+	}
+
+	topNote := INISettings{ConfFilePath: path.Join(incDir, "topNote"), ID: "topNote", IncludeFiles: includeFiles}
+	ini, err := topNote.ParseDefinition()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ini.KeyValue[INISectionSysctl]["vm.swappiness"].Value != "10" || ini.KeyValue[INISectionSysctl]["kernel.shmmni"].Value != "32768" {
+		t.Fatal(ini.KeyValue)
+	}
+	if len(ini.AllValues) != 2 {
+		t.Fatal(ini.AllValues)
+	}
+	if tags := topNote.Tags(); !reflect.DeepEqual(tags, []string{"HANA"}) {
+		t.Fatal(tags)
+	}
+	// the comment of the replaced value of vm.swappiness is dropped
+	if comments := topNote.ParamComments(); !reflect.DeepEqual(comments, map[string]string{"kernel.shmmni": "mid comment"}) {
+		t.Fatal(comments)
+	}
+	params, err := topNote.DefinedParams()
+	if err != nil || !reflect.DeepEqual(params, map[string]string{"vm.swappiness": "10", "kernel.shmmni": "32768"}) {
+		t.Fatal(params, err)
+	}
+	hash := topNote.GetDefinitionHash()
+	if err := ioutil.WriteFile(path.Join(incDir, "baseNote"), []byte("[sysctl]\nvm.swappiness = 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if topNote.GetDefinitionHash() == hash {
+		t.Fatal("hash not changed for a changed included Note definition file")
+	}
+}
+
+func TestExecFailure(t *testing.T) {
+	oldDir := ExecHelperDir
+	defer func() { ExecHelperDir = oldDir }()
+	helperDir, err := ioutil.TempDir("", "saptune-helpers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(helperDir)
+	ExecHelperDir = helperDir + "/"
+	failHelper := path.Join(helperDir, "calc_fail")
+	if err := ioutil.WriteFile(failHelper, []byte("#!/bin/sh\necho broken >&2\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	execFile := path.Join(helperDir, "execNote")
+	if err := ioutil.WriteFile(execFile, []byte("[sysctl]\nvm.swappiness = @EXEC "+failHelper+"\nvm.dirty_ratio = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	execNote := INISettings{ConfFilePath: execFile, ID: "execNote", DescriptiveName: "", ValuesToApply: map[string]string{"verify": "verify"}}
+	initialised, err := execNote.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the failing helper fails only the comparison of its parameter
+	optimised, err := initialised.(INISettings).Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+	if optimisedINI.SysctlParams["vm.swappiness"] != ExecFailed {
+		t.Errorf("unexpected value '%s'", optimisedINI.SysctlParams["vm.swappiness"])
+	}
+	if msg, ok := ExecFailure(optimisedINI.Inform["vm.swappiness"]); !ok || !strings.Contains(msg, "failed with exit code 3: broken") {
+		t.Errorf("missing helper error '%s'", optimisedINI.Inform["vm.swappiness"])
+	}
+	if optimisedINI.SysctlParams["vm.dirty_ratio"] != "10" {
+		t.Errorf("unexpected value '%s'", optimisedINI.SysctlParams["vm.dirty_ratio"])
+	}
+	if _, ok := ExecFailure(optimisedINI.Inform["vm.dirty_ratio"]); ok {
+		t.Error("unexpected helper error for 'vm.dirty_ratio'")
+	}
+}
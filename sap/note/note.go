@@ -32,6 +32,11 @@ type Note interface {
 	Name() string              // The original note name.
 }
 
+// ExtraNotesPrecedence defines, if a vendor or customer specific Note
+// definition file takes precedence over a built-in Note definition using
+// the same Note ID. By default the built-in definition is used.
+var ExtraNotesPrecedence = false
+
 // TuningOptions is the collection of tuning options from SAP notes and
 // 3rd party vendors.
 type TuningOptions map[string]Note
@@ -60,12 +65,20 @@ func GetTuningOptions(saptuneTuningDir, thirdPartyTuningDir string) TuningOption
 			system.WarningLog("For more information refer to the man page saptune-migrate(7)")
 			continue
 		}
+		if strings.HasSuffix(fileName, ".conf"+ChecksumSuffix) {
+			// checksum file of a Note definition file
+			continue
+		}
 		if !strings.HasSuffix(fileName, ".conf") {
 			// skip filenames without .conf suffix
 			system.WarningLog("skip file \"%s\", wrong filename syntax, missing '.conf' suffix", fileName)
 			continue
 		}
 
+		if !extraNoteChecksumOK(path.Join(thirdPartyTuningDir, fileName)) {
+			continue
+		}
+
 		id := ""
 		// get the description of the note from the header inside the file
 		name := txtparser.GetINIFileDescriptiveName(path.Join(thirdPartyTuningDir, fileName))
@@ -89,20 +102,87 @@ func GetTuningOptions(saptuneTuningDir, thirdPartyTuningDir string) TuningOption
 			// let name empty, to get the right information during 'note list'
 			id = strings.TrimSuffix(fileName, ".conf")
 		}
-		// Do not allow vendor to override built-in
-		if _, exists := ret[id]; exists {
-			system.WarningLog("GetTuningOptions: vendor's \"%s\" will not override built-in tuning implementation", fileName)
-			continue
-		}
-		ret[id] = INISettings{
+		extraNote := INISettings{
 			ConfFilePath:    path.Join(thirdPartyTuningDir, fileName),
 			ID:              id,
 			DescriptiveName: name,
 		}
+		if existing, exists := ret[id]; exists {
+			// the vendor file shadows a built-in Note definition or
+			// another vendor file using the same ID
+			existingNote, ok := existing.(INISettings)
+			if !ok {
+				system.WarningLog("GetTuningOptions: vendor's \"%s\" uses the ID of an internal Note implementation and will be ignored", extraNote.ConfFilePath)
+				continue
+			}
+			origin := "vendor"
+			if path.Dir(existingNote.ConfFilePath) == path.Clean(saptuneTuningDir) {
+				origin = "built-in"
+			}
+			// only a built-in Note definition can be replaced, the
+			// first of several vendor files is always used
+			if !ExtraNotesPrecedence || origin != "built-in" {
+				system.WarningLog("GetTuningOptions: vendor's \"%s\" uses the ID of the %s Note definition \"%s\" and will be ignored", extraNote.ConfFilePath, origin, existingNote.ConfFilePath)
+				existingNote.ShadowFile = extraNote.ConfFilePath
+				ret[id] = existingNote
+				continue
+			}
+			system.WarningLog("GetTuningOptions: vendor's \"%s\" uses the ID of the %s Note definition \"%s\" and will be used instead", extraNote.ConfFilePath, origin, existingNote.ConfFilePath)
+			extraNote.ShadowFile = existingNote.ConfFilePath
+		}
+		ret[id] = extraNote
 	}
+	// the Note definitions included by the Notes are resolved, when
+	// a Note is used, so that commands like 'saptune version' do not
+	// need to parse all Note definition files
 	return ret
 }
 
+// ResolveIncludes returns the paths of the Note definition files, which are
+// included directly or indirectly by the Note with the given ID using the
+// [include] section. The files are returned in the order their content
+// needs to be merged, every file only once.
+// Returns an error, if an included Note is unknown or if the includes of
+// the Notes are cyclic
+func ResolveIncludes(noteID string, allNotes TuningOptions) ([]string, error) {
+	includeFiles := make([]string, 0)
+	seen := make(map[string]bool)
+	var walk func(iniNote INISettings, chain []string) error
+	walk = func(iniNote INISettings, chain []string) error {
+		ini, err := parseNoteFile(iniNote.ConfFilePath)
+		if err != nil {
+			return err
+		}
+		for _, incID := range ini.Includes {
+			for cnt, chainID := range chain {
+				if chainID == incID {
+					return fmt.Errorf("the notes have cyclic includes: %s -> %s", strings.Join(chain[cnt:], " -> "), incID)
+				}
+			}
+			incNote, ok := allNotes[incID].(INISettings)
+			if !ok {
+				return fmt.Errorf("the Note %s included by Note %s is unknown or has no Note definition file", incID, iniNote.ID)
+			}
+			incChain := append(append(make([]string, 0, len(chain)+1), chain...), incID)
+			if err := walk(incNote, incChain); err != nil {
+				return err
+			}
+			if !seen[incNote.ConfFilePath] {
+				seen[incNote.ConfFilePath] = true
+				includeFiles = append(includeFiles, incNote.ConfFilePath)
+			}
+		}
+		return nil
+	}
+	iniNote, ok := allNotes[noteID].(INISettings)
+	if !ok {
+		// only Notes with a Note definition file can include others
+		return includeFiles, nil
+	}
+	err := walk(iniNote, []string{noteID})
+	return includeFiles, err
+}
+
 // GetSortedIDs returns all tuning option IDs, sorted in ascending order.
 func (opts *TuningOptions) GetSortedIDs() (ret []string) {
 	ret = make([]string, 0, len(*opts))
@@ -121,6 +201,10 @@ type FieldComparison struct {
 	ActualValue, ExpectedValue     interface{}
 	ActualValueJS, ExpectedValueJS string
 	MatchExpectation               bool
+	NotApplicable                  string // virtualization environment, in which the parameter can not be set
+	GrubState                      string // running, reboot or missing for grub parameters
+	Ignored                        bool   // deviation accepted by the ignore list of verify
+	Severity                       string // severity of a deviation from the [severity] section, empty if not classified
 }
 
 // CompareJSValue compares JSON representation of two values and see
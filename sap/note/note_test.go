@@ -2,6 +2,7 @@ package note
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
@@ -143,6 +144,52 @@ func TestGetTuningOptions(t *testing.T) {
 	}
 }
 
+func TestGetTuningOptionsShadowed(t *testing.T) {
+	tstDir := "/tmp/saptune_shadow_test"
+	builtinDir := path.Join(tstDir, "notes")
+	extraDir := path.Join(tstDir, "extra")
+	defer os.RemoveAll(tstDir)
+	for _, dir := range []string{builtinDir, extraDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	builtinFile := path.Join(builtinDir, "1001")
+	extraFile := path.Join(extraDir, "1001-Shadow_Note.conf")
+	if err := ioutil.WriteFile(builtinFile, []byte("[sysctl]\nvm.swappiness = 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(extraFile, []byte("[sysctl]\nvm.swappiness = 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	allOpts := GetTuningOptions(builtinDir, extraDir)
+	iniNote := allOpts["1001"].(INISettings)
+	if len(allOpts) != 1 || iniNote.ConfFilePath != builtinFile || iniNote.ShadowFile != extraFile {
+		t.Error(allOpts)
+	}
+
+	ExtraNotesPrecedence = true
+	defer func() { ExtraNotesPrecedence = false }()
+	allOpts = GetTuningOptions(builtinDir, extraDir)
+	iniNote = allOpts["1001"].(INISettings)
+	if len(allOpts) != 1 || iniNote.ConfFilePath != extraFile || iniNote.ShadowFile != builtinFile || iniNote.Name() != "Shadow_Note" {
+		t.Error(allOpts)
+	}
+
+	// a second vendor file with the same ID never replaces the first one
+	secondFile := path.Join(extraDir, "1001-Second_Note.conf")
+	if err := ioutil.WriteFile(secondFile, []byte("[sysctl]\nvm.swappiness = 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(builtinFile)
+	allOpts = GetTuningOptions(builtinDir, extraDir)
+	iniNote = allOpts["1001"].(INISettings)
+	if len(allOpts) != 1 || iniNote.ConfFilePath != secondFile || iniNote.ShadowFile != extraFile {
+		t.Error(allOpts)
+	}
+}
+
 func TestCompareJSValu(t *testing.T) {
 	op := ""
 	v1 := "tst_string"
@@ -105,6 +105,28 @@ func AddParameterNoteValues(param, value, noteID string) {
 	}
 }
 
+// UpdateParameterNoteValues changes the note parameter value in the state
+// file. If the note has no entry yet, the value is added like in
+// AddParameterNoteValues. The position of the note in the chain of applied
+// parameter values is not changed.
+func UpdateParameterNoteValues(param, value, noteID string) {
+	pEntries := GetSavedParameterNotes(param)
+	if len(pEntries.AllNotes) == 0 {
+		return
+	}
+	entry := PositionInParameterList(noteID, pEntries.AllNotes)
+	if entry == 0 {
+		// position 0 is the 'start' entry
+		AddParameterNoteValues(param, value, noteID)
+		return
+	}
+	pEntries.AllNotes[entry].Value = value
+	err := StoreParameter(param, pEntries, true)
+	if err != nil {
+		system.WarningLog("Failed to update note '%s' values for parameter file '%s' for parameter '%s'", noteID, GetPathToParameter(param), param)
+	}
+}
+
 // GetSavedParameterNotes reads content of stored parameter states.
 // Return the content as ParameterNotes
 func GetSavedParameterNotes(param string) ParameterNotes {
@@ -1,6 +1,7 @@
 package note
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -115,6 +116,25 @@ func TestAddParameterNoteValues(t *testing.T) {
 	CleanUpParamFile("TEST_PARAMETER")
 }
 
+func TestUpdateParameterNoteValues(t *testing.T) {
+	defer CleanUpParamFile("TEST_PARAMETER")
+	UpdateParameterNoteValues("TEST_PARAMETER", "TestUpdValue", "4711")
+	if val := GetSavedParameterNotes("TEST_PARAMETER"); len(val.AllNotes) != 0 {
+		t.Fatalf("parameter state file 'TEST_PARAMETER' exists. content: '%+v'\n", val)
+	}
+
+	CreateParameterStartValues("TEST_PARAMETER", "TestStartValue")
+	AddParameterNoteValues("TEST_PARAMETER", "TestAddValue", "4711")
+	AddParameterNoteValues("TEST_PARAMETER", "TestAddValue2", "4712")
+	UpdateParameterNoteValues("TEST_PARAMETER", "TestUpdValue", "4711")
+	UpdateParameterNoteValues("TEST_PARAMETER", "TestUpdValue3", "4713")
+	val := GetSavedParameterNotes("TEST_PARAMETER")
+	exp := []ParameterNoteEntry{{"start", "TestStartValue"}, {"4711", "TestUpdValue"}, {"4712", "TestAddValue2"}, {"4713", "TestUpdValue3"}}
+	if !reflect.DeepEqual(val.AllNotes, exp) {
+		t.Fatalf("wrong content in state file 'TEST_PARAMETER': '%+v'\n", val)
+	}
+}
+
 func TestGetAllSavedParameters(t *testing.T) {
 	CreateParameterStartValues("TEST_PARAMETER_1", "TestStartValue1")
 	AddParameterNoteValues("TEST_PARAMETER_1", "TestAddValue1", "4711")
@@ -0,0 +1,304 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ValidationProblem describes a problem found in a Note definition file
+type ValidationProblem struct {
+	FileName string
+	Line     int
+	Message  string
+}
+
+// String returns the problem in the format 'file:line: message'
+func (prob ValidationProblem) String() string {
+	return fmt.Sprintf("%s:%d: %s", prob.FileName, prob.Line, prob.Message)
+}
+
+// validSectionKeys contains the options supported by the sections of a
+// Note definition file. Sections with free-form option names (sysctl,
+// service, grub, rpm) and sections without options are not listed.
+var validSectionKeys = map[string][]string{
+	INISectionBlock:     {"IO_SCHEDULER", "NRREQ"},
+	INISectionCPU:       {"energy_perf_bias", "governor", "force_latency"},
+	INISectionLimits:    {"LIMITS"},
+	INISectionLogin:     {"UserTasksMax"},
+	INISectionMEM:       {"ShmFileSystemSizeMB", "VSZ_TMPFS_PERCENT"},
+	INISectionPagecache: {"ENABLE_PAGECACHE_LIMIT", "vm.pagecache_limit_ignore_dirty", "OVERRIDE_PAGECACHE_LIMIT_MB"},
+	INISectionVM:        {"THP", "KSM"},
+}
+
+// ValidateNoteFile checks the content of a Note definition or override
+// file without touching the system. It returns all problems found
+// together with the line numbers
+func ValidateNoteFile(fileName string) ([]ValidationProblem, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateNoteDefinition(fileName, string(content)), nil
+}
+
+// ValidateNoteDefinition checks the sections, options and values of the
+// content of a Note definition or override file
+func ValidateNoteDefinition(fileName, content string) []ValidationProblem {
+	problems := make([]ValidationProblem, 0)
+	addProblem := func(lineNo int, template string, stuff ...interface{}) {
+		problems = append(problems, ValidationProblem{FileName: fileName, Line: lineNo, Message: fmt.Sprintf(template, stuff...)})
+	}
+	section := ""
+	for idx, line := range strings.Split(content, "\n") {
+		lineNo := idx + 1
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				addProblem(lineNo, "malformed section header '%s'", line)
+				section = ""
+				continue
+			}
+			var condition string
+			section, condition = txtparser.SplitSectionName(line[1 : len(line)-1])
+			if !isKnownSection(section) {
+				addProblem(lineNo, "unknown section '[%s]'", section)
+			} else if condition != "" {
+				if isOneOf(section, INISectionVersion, INISectionReminder, INISectionCheckOnly, INISectionTags, INISectionRequires, INISectionInclude, INISectionBounds, INISectionSeverity) {
+					addProblem(lineNo, "section '[%s]' does not support a condition", section)
+				} else if _, err := txtparser.ParseSectionCondition(condition); err != nil {
+					addProblem(lineNo, "section '[%s]': %v", section, err)
+				}
+			}
+			continue
+		}
+		switch section {
+		case "":
+			addProblem(lineNo, "option '%s' outside of a section", line)
+			continue
+		case INISectionVersion, INISectionReminder, INISectionCheckOnly:
+			addProblem(lineNo, "section '[%s]' does not support options, only comments", section)
+			continue
+		case INISectionGrub, INISectionTags, INISectionRequires, INISectionInclude:
+			// every kernel command line option, every tag and every
+			// Note ID is allowed
+			continue
+		case INISectionBounds:
+			if kov := txtparser.RegexKeyOperatorValue.FindStringSubmatch(line); kov == nil || kov[2] != txtparser.OperatorEqual {
+				addProblem(lineNo, "malformed line '%s', expected '<parameter> = MIN:MAX'", line)
+			} else if _, err := txtparser.ParseBounds(kov[3]); err != nil {
+				addProblem(lineNo, "parameter '%s': %v", kov[1], err)
+			}
+			continue
+		case INISectionSeverity:
+			if kov := txtparser.RegexKeyOperatorValue.FindStringSubmatch(line); kov == nil || kov[2] != txtparser.OperatorEqual {
+				addProblem(lineNo, "malformed line '%s', expected '<parameter> = info|warning|critical'", line)
+			} else if _, err := txtparser.ParseSeverity(kov[3]); err != nil {
+				addProblem(lineNo, "parameter '%s': %v", kov[1], err)
+			}
+			continue
+		case INISectionRpm:
+			if len(strings.Fields(line)) != 3 {
+				addProblem(lineNo, "rpm entry '%s' needs the 3 fields 'package os_version package_version'", line)
+			}
+			continue
+		}
+		if !isKnownSection(section) {
+			// already reported at the section header
+			continue
+		}
+		kov := txtparser.RegexKeyOperatorValue.FindStringSubmatch(line)
+		if section == INISectionSystemd {
+			kov = nil
+			if kupv := txtparser.RegexSystemdProperty.FindStringSubmatch(line); kupv != nil {
+				kov = []string{kupv[0], kupv[1] + ":" + kupv[2], kupv[3], kupv[4]}
+			}
+		}
+		if section == INISectionBlock && txtparser.RegexBlockDevicePattern.MatchString(line) {
+			kopv := txtparser.RegexBlockDevicePattern.FindStringSubmatch(line)
+			kov = []string{kopv[0], kopv[1], kopv[3], kopv[4]}
+			if len(strings.Fields(kopv[2])) == 0 {
+				addProblem(lineNo, "missing block device pattern for parameter '%s'", kopv[1])
+			}
+			for _, pattern := range strings.Fields(kopv[2]) {
+				if _, err := path.Match(pattern, ""); err != nil {
+					addProblem(lineNo, "wrong block device pattern '%s' for parameter '%s'", pattern, kopv[1])
+				}
+			}
+		}
+		if kov == nil && section == INISectionSystemd {
+			addProblem(lineNo, "malformed line '%s', expected '<unit>:<property> = value'", line)
+			continue
+		}
+		if kov == nil {
+			addProblem(lineNo, "malformed line '%s', expected 'parameter = value'", line)
+			continue
+		}
+		for _, msg := range validateNoteParameter(section, kov[1], kov[2], kov[3]) {
+			addProblem(lineNo, "%s", msg)
+		}
+	}
+	return problems
+}
+
+// isKnownSection returns true, if the section is supported in a Note
+// definition file, either built-in or by a registered section handler
+func isKnownSection(section string) bool {
+	if isBuiltinSection(section) {
+		return true
+	}
+	_, ok := getSectionHandler(section)
+	return ok
+}
+
+// isBuiltinSection returns true, if the section is handled by saptune itself
+func isBuiltinSection(section string) bool {
+	switch section {
+	case INISectionSysctl, INISectionVM, INISectionCPU, INISectionMEM, INISectionBlock, INISectionService, INISectionLimits, INISectionLogin, INISectionSystemd, INISectionVersion, INISectionPagecache, INISectionRpm, INISectionGrub, INISectionReminder, INISectionCheckOnly, INISectionTags, INISectionRequires, INISectionInclude, INISectionBounds, INISectionSeverity:
+		return true
+	}
+	return false
+}
+
+// validateNoteParameter checks a single parameter of a section and
+// returns a message for each problem found.
+// An empty value is always allowed, as it marks a parameter as
+// 'untouched' in an override file
+func validateNoteParameter(section, key, operator, value string) []string {
+	msgs := make([]string, 0)
+	switch txtparser.Operator(operator) {
+	case txtparser.OperatorEqual:
+	case txtparser.OperatorLessThan, txtparser.OperatorLessThanEqual, txtparser.OperatorMoreThan, txtparser.OperatorMoreThanEqual:
+		if section != INISectionSysctl {
+			msgs = append(msgs, fmt.Sprintf("operator '%s' of parameter '%s' is only supported in section '[%s]'", operator, key, INISectionSysctl))
+		} else if _, err := strconv.ParseInt(value, 10, 64); err != nil && !IsExecValue(value) && !IsRAMValue(value) {
+			msgs = append(msgs, fmt.Sprintf("operator '%s' of parameter '%s' needs an integer value, but found '%s'", operator, key, value))
+		}
+	default:
+		msgs = append(msgs, fmt.Sprintf("unknown operator '%s' for parameter '%s'", operator, key))
+	}
+	if keys, ok := validSectionKeys[section]; ok && !isOneOf(key, keys...) {
+		return append(msgs, fmt.Sprintf("unknown parameter '%s' in section '[%s]'", key, section))
+	}
+	if section == INISectionSysctl && !strings.Contains(key, ".") {
+		msgs = append(msgs, fmt.Sprintf("'%s' is not a valid sysctl parameter name", key))
+	}
+	if strings.Contains(key, SysctlInterfacePlaceholder) {
+		if section != INISectionSysctl {
+			msgs = append(msgs, fmt.Sprintf("placeholder '%s' of parameter '%s' is only supported in section '[%s]'", SysctlInterfacePlaceholder, key, INISectionSysctl))
+		} else if !strings.Contains(key, "."+SysctlInterfacePlaceholder+".") {
+			msgs = append(msgs, fmt.Sprintf("placeholder '%s' of parameter '%s' needs to be a complete part of the parameter name, e.g. 'net.ipv4.conf.%s.rp_filter'", SysctlInterfacePlaceholder, key, SysctlInterfacePlaceholder))
+		}
+	}
+	if section == INISectionSystemd {
+		if _, _, err := SplitSystemdKey(key); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if value == "" {
+		return msgs
+	}
+	if IsExecValue(value) {
+		// the value itself is only known, when the helper program runs
+		if _, _, err := ParseExecValue(value); err != nil {
+			msgs = append(msgs, fmt.Sprintf("parameter '%s': %v", key, err))
+		}
+		return msgs
+	}
+	if IsRAMValue(value) {
+		// the value itself is only known on the system
+		if _, _, err := ParseRAMValue(value); err != nil {
+			msgs = append(msgs, fmt.Sprintf("parameter '%s': %v", key, err))
+		}
+		return msgs
+	}
+	wrongValue := func(expected string) {
+		msgs = append(msgs, fmt.Sprintf("wrong value '%s' for parameter '%s', expected %s", value, key, expected))
+	}
+	switch section {
+	case INISectionSysctl:
+		if IsSysctlFormula(value) {
+			if _, _, err := ParseSysctlFormula(value); err != nil {
+				msgs = append(msgs, fmt.Sprintf("parameter '%s': %v", key, err))
+			}
+		}
+	case INISectionService:
+		if sval := strings.ToLower(value); sval != "start" && sval != "stop" {
+			wrongValue("'start' or 'stop'")
+		}
+	case INISectionBlock, INISectionMEM:
+		if key != "IO_SCHEDULER" && !isUnsignedInt(value) {
+			wrongValue("an integer")
+		}
+	case INISectionCPU:
+		switch key {
+		case "energy_perf_bias":
+			if !isOneOf(strings.ToLower(value), "performance", "normal", "powersave") {
+				wrongValue("'performance', 'normal' or 'powersave'")
+			}
+		case "force_latency":
+			if !isUnsignedInt(value) {
+				wrongValue("an integer")
+			}
+		}
+	case INISectionLimits:
+		for _, limit := range strings.Split(value, ",") {
+			if len(strings.Fields(limit)) != 4 {
+				msgs = append(msgs, fmt.Sprintf("wrong limits entry '%s', expected '<domain> <type> <item> <value>'", strings.TrimSpace(limit)))
+			}
+		}
+	case INISectionLogin:
+		if value != "infinity" && !isUnsignedInt(value) {
+			wrongValue("an integer or 'infinity'")
+		}
+	case INISectionPagecache:
+		switch key {
+		case "ENABLE_PAGECACHE_LIMIT":
+			if !isOneOf(strings.ToLower(value), "yes", "no") {
+				wrongValue("'yes' or 'no'")
+			}
+		case "vm.pagecache_limit_ignore_dirty":
+			if !isOneOf(value, "0", "1", "2") {
+				wrongValue("'0', '1' or '2'")
+			}
+		case "OVERRIDE_PAGECACHE_LIMIT_MB":
+			if !isUnsignedInt(value) {
+				wrongValue("an integer")
+			}
+		}
+	case INISectionVM:
+		switch key {
+		case "THP":
+			if !isOneOf(value, "always", "madvise", "never") {
+				wrongValue("'always', 'madvise' or 'never'")
+			}
+		case "KSM":
+			if !isOneOf(value, "0", "1") {
+				wrongValue("'0' or '1'")
+			}
+		}
+	}
+	return msgs
+}
+
+// isOneOf returns true, if value is one of the given choices
+func isOneOf(value string, choices ...string) bool {
+	for _, choice := range choices {
+		if value == choice {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnsignedInt returns true, if value is a non-negative integer
+func isUnsignedInt(value string) bool {
+	_, err := strconv.ParseUint(value, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,289 @@
+package note
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestValidateNoteDefinition(t *testing.T) {
+	content := `# broken note definition
+foo=bar
+[version]
+# SAP-NOTE=4711 CATEGORY=TEST VERSION=1 DATE=01.01.2020 NAME="broken note"
+[sysctl]
+kernel.shmmni=32768
+vm.nr_hugepages=""
+kernel.shmmax >= huge
+noDots=1
+kernel.shmall=@MUL 2
+kernel.msgmni=@DIV 2
+[vm]
+THP=sometimes
+KSM=1
+SWAP=0
+[cpu]
+energy_perf_bias=fast
+force_latency=70
+[service]
+uuidd.socket=restart
+[limits]
+LIMITS = @sapsys hard nofile 65536, @sdba soft nofile
+[login]
+UserTasksMax=infinity
+[mem]
+VSZ_TMPFS_PERCENT=75 percent
+[pagecache]
+ENABLE_PAGECACHE_LIMIT=maybe
+[rpm]
+glibc 12-SP2
+[grub]
+numa_balancing=disable
+[unknown]
+foo=bar
+[block
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 2, "option 'foo=bar' outside of a section"},
+		{"4711", 8, "operator '>=' of parameter 'kernel.shmmax' needs an integer value, but found 'huge'"},
+		{"4711", 9, "'noDots' is not a valid sysctl parameter name"},
+		{"4711", 11, "parameter 'kernel.msgmni': unknown operator '@DIV' in formula '@DIV 2', supported are @MUL, @ADD, @MIN and @MAX"},
+		{"4711", 13, "wrong value 'sometimes' for parameter 'THP', expected 'always', 'madvise' or 'never'"},
+		{"4711", 15, "unknown parameter 'SWAP' in section '[vm]'"},
+		{"4711", 17, "wrong value 'fast' for parameter 'energy_perf_bias', expected 'performance', 'normal' or 'powersave'"},
+		{"4711", 20, "wrong value 'restart' for parameter 'uuidd.socket', expected 'start' or 'stop'"},
+		{"4711", 22, "wrong limits entry '@sdba soft nofile', expected '<domain> <type> <item> <value>'"},
+		{"4711", 26, "wrong value '75 percent' for parameter 'VSZ_TMPFS_PERCENT', expected an integer"},
+		{"4711", 28, "wrong value 'maybe' for parameter 'ENABLE_PAGECACHE_LIMIT', expected 'yes' or 'no'"},
+		{"4711", 30, "rpm entry 'glibc 12-SP2' needs the 3 fields 'package os_version package_version'"},
+		{"4711", 33, "unknown section '[unknown]'"},
+		{"4711", 35, "malformed section header '[block'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+	if problems[0].String() != "4711:2: option 'foo=bar' outside of a section" {
+		t.Error(problems[0].String())
+	}
+}
+
+func TestValidateBlockDevicePattern(t *testing.T) {
+	content := `[block]
+IO_SCHEDULER[sd* vd*] = bfq, none
+NRREQ[nvme*] = 1024
+IO_SCHEDULER[] = none
+NRREQ[sd[a] = 1024
+NRREQ[vd*] = many
+QUEUE[sd*] = 1
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 4, "missing block device pattern for parameter 'IO_SCHEDULER'"},
+		{"4711", 5, "wrong block device pattern 'sd[a' for parameter 'NRREQ'"},
+		{"4711", 6, "wrong value 'many' for parameter 'NRREQ', expected an integer"},
+		{"4711", 7, "unknown parameter 'QUEUE' in section '[block]'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateBounds(t *testing.T) {
+	content := `[bounds]
+vm.dirty_ratio = 5:40
+vm.swappiness = :100
+vm.max_map_count < 10:
+vm.dirty_bytes = 40:5
+kernel.shmmni = many
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 4, "malformed line 'vm.max_map_count < 10:', expected '<parameter> = MIN:MAX'"},
+		{"4711", 5, "parameter 'vm.dirty_bytes': wrong bounds '40:5', the minimum is greater than the maximum"},
+		{"4711", 6, "parameter 'kernel.shmmni': wrong bounds 'many', expected 'MIN:MAX'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	content := `[severity]
+vm.swappiness = info
+vm.dirty_ratio = Critical
+vm.max_map_count < warning
+kernel.shmmni = fatal
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 4, "malformed line 'vm.max_map_count < warning', expected '<parameter> = info|warning|critical'"},
+		{"4711", 5, "parameter 'kernel.shmmni': wrong severity 'fatal', expected 'info', 'warning' or 'critical'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateSectionCondition(t *testing.T) {
+	content := `[sysctl:kernel>=5.3]
+vm.swappiness = 10
+[vm: kernel < 5.3]
+THP = never
+[sysctl:os>=15]
+[block:kernel>=five]
+[tags:kernel>=5.3]
+[unknown:kernel>=5.3]
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 5, "section '[sysctl]': unsupported subject 'os' in condition 'os>=15', only 'kernel' is supported"},
+		{"4711", 6, "section '[block]': wrong version 'five' in condition 'kernel>=five'"},
+		{"4711", 7, "section '[tags]' does not support a condition"},
+		{"4711", 8, "unknown section '[unknown]'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateExecValue(t *testing.T) {
+	content := `[sysctl]
+kernel.shmall = @EXEC /usr/share/saptune/helpers/calc_shmall
+kernel.shmmax > @EXEC /usr/share/saptune/helpers/calc_shmmax 2
+kernel.shmmni = @EXEC /usr/bin/id
+kernel.sem = @EXEC
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 4, "parameter 'kernel.shmmni': helper program '/usr/bin/id' is not located in '/usr/share/saptune/helpers/'"},
+		{"4711", 5, "parameter 'kernel.sem': missing helper program in '@EXEC', expected '@EXEC /usr/share/saptune/helpers/<helper>'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateRAMValue(t *testing.T) {
+	content := `[sysctl]
+kernel.shmmax = @RAM 75%
+kernel.shmall < @RAM -2G
+kernel.shmmni = @RAM 2G
+[mem]
+ShmFileSystemSizeMB = @RAM 200%
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 4, "parameter 'kernel.shmmni': wrong value '@RAM 2G', expected '@RAM <percent>%' or '@RAM +|-<size>[K|M|G|T]'"},
+		{"4711", 6, "parameter 'ShmFileSystemSizeMB': percentage '200%' of '@RAM 200%' needs to be an integer between 1 and 100"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateSysctlInterface(t *testing.T) {
+	content := `[sysctl]
+net.ipv4.conf.{iface}.rp_filter = 1
+net.ipv4.conf.eth{iface}.rp_filter = 1
+[service]
+sapinit-{iface}.service = start
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 3, "placeholder '{iface}' of parameter 'net.ipv4.conf.eth{iface}.rp_filter' needs to be a complete part of the parameter name, e.g. 'net.ipv4.conf.{iface}.rp_filter'"},
+		{"4711", 5, "placeholder '{iface}' of parameter 'sapinit-{iface}.service' is only supported in section '[sysctl]'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateSystemdProperty(t *testing.T) {
+	content := `[systemd]
+sapinit.service:LimitNOFILE = 1048576
+sapinit:LimitNOFILE = 1048576
+LimitNOFILE = 1048576
+uuidd.socket:Backlog > 128
+`
+	problems := ValidateNoteDefinition("4711", content)
+	expected := []ValidationProblem{
+		{"4711", 3, "unsupported type of unit 'sapinit'"},
+		{"4711", 4, "malformed line 'LimitNOFILE = 1048576', expected '<unit>:<property> = value'"},
+		{"4711", 5, "operator '>' of parameter 'uuidd.socket:Backlog' is only supported in section '[sysctl]'"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, prob := range problems {
+		if prob != expected[i] {
+			t.Errorf("expected '%s', got '%s'", expected[i], prob)
+		}
+	}
+}
+
+func TestValidateNoteFile(t *testing.T) {
+	// all shipped Note definitions need to be valid
+	noteDir := path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/ospackage/usr/share/saptune/notes")
+	dir, err := os.Open(noteDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dir.Close()
+	noteFiles, err := dir.Readdirnames(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, noteFile := range noteFiles {
+		problems, err := ValidateNoteFile(path.Join(noteDir, noteFile))
+		if err != nil {
+			t.Error(err)
+		}
+		for _, prob := range problems {
+			t.Error(prob)
+		}
+	}
+	if _, err := ValidateNoteFile("/file_does_not_exist"); err == nil {
+		t.Error("expected an error for a non existing file")
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"io/ioutil"
 	"os"
+	"path"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -21,12 +24,16 @@ const (
 	OverrideSolutionSheet = "/etc/saptune/override/solutions"
 	DeprecSolutionSheet   = "/usr/share/saptune/solsdeprecated"
 	NoteTuningSheets      = "/usr/share/saptune/notes/"
-	ArchX86               = "amd64"      // ArchX86 is the GOARCH value for x86 platform.
-	ArchPPC64LE           = "ppc64le"    // ArchPPC64LE is the GOARCH for 64-bit PowerPC little endian platform.
-	ArchX86PC             = "amd64_PC"   // ArchX86 is the GOARCH value for x86 platform. PC indicates PageCache is available
-	ArchPPC64LEPC         = "ppc64le_PC" // ArchPPC64LE is the GOARCH for 64-bit PowerPC little endian platform. PC indicates PageCache is available
+	ExtraSolutionSheets   = "/etc/saptune/extra/solutions/" // directory containing the user-defined solutions
+	ArchX86               = "amd64"                         // ArchX86 is the GOARCH value for x86 platform.
+	ArchPPC64LE           = "ppc64le"                       // ArchPPC64LE is the GOARCH for 64-bit PowerPC little endian platform.
+	ArchX86PC             = "amd64_PC"                      // ArchX86 is the GOARCH value for x86 platform. PC indicates PageCache is available
+	ArchPPC64LEPC         = "ppc64le_PC"                    // ArchPPC64LE is the GOARCH for 64-bit PowerPC little endian platform. PC indicates PageCache is available
 )
 
+// isSolName matches valid solution names
+var isSolName = regexp.MustCompile(`^[\w.+-]+$`)
+
 // Solution is identified by set of note numbers.
 type Solution []string
 
@@ -35,7 +42,11 @@ type Solution []string
 
 // AllSolutions contains a list of all available solutions with their related
 // SAP Notes for all supported architectures
-var AllSolutions = GetSolutionDefintion(SolutionSheet)
+var AllSolutions = AddCustomSolutions(GetSolutionDefintion(SolutionSheet), CustomSolutions)
+
+// CustomSolutions contains a list of all user-defined solutions with their
+// related SAP Notes for all supported architectures
+var CustomSolutions = GetCustomSolutions(ExtraSolutionSheets)
 
 // OverrideSolutions contains a list of all available override solutions with
 // their related SAP Notes for all supported architectures
@@ -230,6 +241,75 @@ func GetDeprecatedSolution(fileName string) map[string]map[string]string {
 	return sols
 }
 
+// GetCustomSolutions reads the user-defined solution definitions from the
+// '.sol' files in the given directory. The files use the same format as the
+// solution definition file
+func GetCustomSolutions(solDir string) map[string]map[string]Solution {
+	sols := make(map[string]map[string]Solution)
+	_, files := system.ListDir(solDir, "")
+	for _, fileName := range files {
+		if !strings.HasSuffix(fileName, ".sol") {
+			system.WarningLog("skip file \"%s\", wrong filename syntax, missing '.sol' suffix", fileName)
+			continue
+		}
+		for arch, archSols := range GetSolutionDefintion(path.Join(solDir, fileName)) {
+			if sols[arch] == nil {
+				sols[arch] = make(map[string]Solution)
+			}
+			for solName, sol := range archSols {
+				sols[arch][solName] = sol
+			}
+		}
+	}
+	return sols
+}
+
+// AddCustomSolutions adds the user-defined solutions to the solutions
+// shipped by saptune. User-defined solutions do not override built-in ones
+func AddCustomSolutions(sols, customSols map[string]map[string]Solution) map[string]map[string]Solution {
+	for arch, archSols := range customSols {
+		if sols[arch] == nil {
+			sols[arch] = make(map[string]Solution)
+		}
+		for solName, sol := range archSols {
+			if _, exists := sols[arch][solName]; exists {
+				system.WarningLog("user-defined solution \"%s\" will not override built-in solution", solName)
+				continue
+			}
+			sols[arch][solName] = sol
+		}
+	}
+	return sols
+}
+
+// IsCustomSolution returns true, if the solution is user-defined
+func IsCustomSolution(archName, solName string) bool {
+	_, exists := CustomSolutions[archName][solName]
+	return exists
+}
+
+// CreateCustomSolution writes a user-defined solution containing the given
+// notes for all supported architectures into the directory solDir.
+// Returns the name of the created solution file
+func CreateCustomSolution(solDir, solName string, noteIDs []string) (string, error) {
+	if !isSolName.MatchString(solName) {
+		return "", fmt.Errorf("invalid solution name '%s'. Only letters, digits and the characters '.', '+', '_' and '-' are allowed", solName)
+	}
+	if len(noteIDs) == 0 {
+		return "", fmt.Errorf("no notes specified for solution '%s'", solName)
+	}
+	fileName := path.Join(solDir, solName+".sol")
+	if _, err := os.Stat(fileName); err == nil {
+		return "", fmt.Errorf("solution definition file '%s' already exists", fileName)
+	}
+	if err := os.MkdirAll(solDir, 0755); err != nil {
+		return "", err
+	}
+	notes := strings.Join(noteIDs, " ")
+	content := fmt.Sprintf("# user-defined solution '%s' created by saptune\n[ArchX86]\n%s = %s\n\n[ArchPPC64LE]\n%s = %s\n", solName, solName, notes, solName, notes)
+	return fileName, ioutil.WriteFile(fileName, []byte(content), 0644)
+}
+
 // GetSortedSolutionNames returns all solution names, sorted alphabetically.
 func GetSortedSolutionNames(archName string) (ret []string) {
 	ret = make([]string, 0, len(AllSolutions))
@@ -92,3 +92,47 @@ func TestGetSortedSolutionIDs(t *testing.T) {
 		t.Fatal(GetSortedSolutionNames(runtime.GOARCH))
 	}
 }
+
+func TestCustomSolutions(t *testing.T) {
+	solDir := "/tmp/saptune_custom_solutions"
+	os.RemoveAll(solDir)
+	defer os.RemoveAll(solDir)
+
+	if sols := GetCustomSolutions(solDir); len(sols) != 0 {
+		t.Fatal(sols)
+	}
+	fileName, err := CreateCustomSolution(solDir, "MYSOL", []string{"941735", "1771258"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileName != path.Join(solDir, "MYSOL.sol") {
+		t.Fatal(fileName)
+	}
+	if _, err := CreateCustomSolution(solDir, "MYSOL", []string{"941735"}); err == nil {
+		t.Fatal("expected an error for an already existing solution")
+	}
+	if _, err := CreateCustomSolution(solDir, "MY SOL", []string{"941735"}); err == nil {
+		t.Fatal("expected an error for an invalid solution name")
+	}
+	if _, err := CreateCustomSolution(solDir, "EMPTY", []string{}); err == nil {
+		t.Fatal("expected an error for a solution without notes")
+	}
+
+	customSols := GetCustomSolutions(solDir)
+	if strings.Join(customSols[runtime.GOARCH]["MYSOL"], " ") != "941735 1771258" {
+		t.Fatal(customSols)
+	}
+	// user-defined solutions do not override built-in ones
+	builtinSols := map[string]map[string]Solution{runtime.GOARCH: {"MYSOL": Solution{"4711"}, "HANA": Solution{"1980196"}}}
+	allSols := AddCustomSolutions(builtinSols, customSols)
+	if strings.Join(allSols[runtime.GOARCH]["MYSOL"], " ") != "4711" {
+		t.Fatal(allSols)
+	}
+	allSols = AddCustomSolutions(map[string]map[string]Solution{}, customSols)
+	if strings.Join(allSols[runtime.GOARCH]["MYSOL"], " ") != "941735 1771258" {
+		t.Fatal(allSols)
+	}
+	if IsCustomSolution(runtime.GOARCH, "HANA") {
+		t.Fatal("HANA is not a user-defined solution")
+	}
+}
@@ -4,19 +4,72 @@ package system
 
 import (
 	"io/ioutil"
+	"os"
 	"strings"
 )
 
+// GrubConfigFile is the boot loader configuration generated by
+// grub2-mkconfig, which contains the kernel command line used by the next
+// boot. Changes of /etc/default/grub are only used after the file is
+// generated again.
+var GrubConfigFile = "/boot/grub2/grub.cfg"
+
+// KernelCmdlineFile contains the kernel command line used by the next boot
+// on systems without a generated grub2 configuration, e.g. with
+// systemd-boot or grub2-bls
+var KernelCmdlineFile = "/etc/kernel/cmdline"
+
 // ParseCmdline parse /proc/cmdline into key(string) - value(string) pairs.
 // return value for given boot option or 'NA', if not available
 func ParseCmdline(fileName, option string) string {
-	opt := "NA"
 	cmdLine, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		WarningLog("ParseCmdline: failed to read  %s: %v", fileName, err)
-		return opt
+		return "NA"
 	}
-	for _, param := range strings.Fields(string(cmdLine)) {
+	return cmdlineOption(string(cmdLine), option)
+}
+
+// ParseNextBootCmdline returns the value of the given boot option from the
+// kernel command line used by the next boot or 'NA', if not available.
+// The generated grub2 configuration is used, if available, otherwise the
+// kernel command line file.
+func ParseNextBootCmdline(option string) string {
+	if _, err := os.Stat(GrubConfigFile); err == nil {
+		return ParseGrubConfig(GrubConfigFile, option)
+	}
+	return ParseCmdline(KernelCmdlineFile, option)
+}
+
+// ParseGrubConfig parse the kernel command line of the first boot entry of
+// a generated grub2 configuration (the 'linux' or 'linuxefi' line) into
+// key(string) - value(string) pairs.
+// return value for given boot option or 'NA', if not available
+func ParseGrubConfig(fileName, option string) string {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		WarningLog("ParseGrubConfig: failed to read  %s: %v", fileName, err)
+		return "NA"
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "linux", "linuxefi", "linux16":
+			// the first field after the command is the kernel image
+			return cmdlineOption(strings.Join(fields[2:], " "), option)
+		}
+	}
+	return "NA"
+}
+
+// cmdlineOption returns the value of the given boot option from a kernel
+// command line or 'NA', if not available
+func cmdlineOption(cmdLine, option string) string {
+	opt := "NA"
+	for _, param := range strings.Fields(cmdLine) {
 		fields := strings.Split(param, "=")
 		if fields[0] == option {
 			if len(fields) > 1 {
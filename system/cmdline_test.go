@@ -45,3 +45,32 @@ func TestParseCmdline(t *testing.T) {
 		t.Fatalf("File '/saptune_file_not_avail' should not be available, so return should 'NA', but is '%s'\n", actualVal)
 	}
 }
+
+func TestParseGrubConfig(t *testing.T) {
+	grubConfig := path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/grub.cfg")
+	// only the first boot entry is used, not the recovery entry
+	for option, exp := range map[string]string{"intel_idle.max_cstate": "1", "transparent_hugepage": "never", "quiet": "quiet", "numa_balancing": "NA", "showopts": "NA", "root": "UUID"} {
+		if actualVal := ParseGrubConfig(grubConfig, option); actualVal != exp {
+			t.Errorf("%s: got '%s', expected '%s'\n", option, actualVal, exp)
+		}
+	}
+	if actualVal := ParseGrubConfig("/saptune_file_not_avail", "quiet"); actualVal != "NA" {
+		t.Errorf("File '/saptune_file_not_avail' should not be available, so return should 'NA', but is '%s'\n", actualVal)
+	}
+}
+
+func TestParseNextBootCmdline(t *testing.T) {
+	oldGrubConfig, oldKernelCmdline := GrubConfigFile, KernelCmdlineFile
+	defer func() { GrubConfigFile, KernelCmdlineFile = oldGrubConfig, oldKernelCmdline }()
+	GrubConfigFile = path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/grub.cfg")
+	KernelCmdlineFile = procCmdline2
+	if actualVal := ParseNextBootCmdline("transparent_hugepage"); actualVal != "never" {
+		t.Errorf("got '%s', expected 'never'", actualVal)
+	}
+	// without generated grub2 configuration the kernel command line
+	// file is used
+	GrubConfigFile = "/saptune_file_not_avail"
+	if actualVal := ParseNextBootCmdline("showopts"); actualVal != "showopts" {
+		t.Errorf("got '%s', expected 'showopts'", actualVal)
+	}
+}
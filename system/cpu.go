@@ -11,6 +11,7 @@ import (
 	"path"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -333,6 +334,83 @@ func SetForceLatency(value, savedStates, info string, revert bool) error {
 	return err
 }
 
+// CPUIdleState describes an idle state of a cpu as found in
+// /sys/devices/system/cpu/cpu*/cpuidle/state*
+type CPUIdleState struct {
+	CPU      string
+	State    string
+	Name     string
+	Latency  int
+	Disabled bool
+}
+
+// GetCPUIdleStates returns the idle states of all cpus, ordered by cpu and
+// state number
+func GetCPUIdleStates() []CPUIdleState {
+	states := make([]CPUIdleState, 0)
+	dirCont, err := ioutil.ReadDir(cpuDir)
+	if runtime.GOARCH == "ppc64le" || err != nil {
+		// latency settings are only relevant for Intel-based systems
+		return states
+	}
+	for _, entry := range dirCont {
+		if !isCPU.MatchString(entry.Name()) {
+			continue
+		}
+		cpudirCont, err := ioutil.ReadDir(path.Join(cpuDir, entry.Name(), "cpuidle"))
+		if err != nil {
+			// idle settings not supported for entry.Name()
+			continue
+		}
+		for _, centry := range cpudirCont {
+			if !isState.MatchString(centry.Name()) {
+				continue
+			}
+			stateDir := path.Join(cpuDirSys, entry.Name(), "cpuidle", centry.Name())
+			disable, _ := GetSysString(path.Join(stateDir, "disable"))
+			name, _ := GetSysString(path.Join(stateDir, "name"))
+			lat, _ := GetSysInt(path.Join(stateDir, "latency"))
+			states = append(states, CPUIdleState{CPU: entry.Name(), State: centry.Name(), Name: name, Latency: lat, Disabled: disable == "1"})
+		}
+	}
+	sortCPUIdleStates(states)
+	return states
+}
+
+// CPUIdleStateDiffs returns the idle states, whose 'disable' setting does
+// not match the force latency value 'value'. Like in SetForceLatency a
+// state with a latency greater or equal to the force latency value is
+// expected to be disabled, all other states are expected to be enabled
+func CPUIdleStateDiffs(states []CPUIdleState, value string) []CPUIdleState {
+	diffs := make([]CPUIdleState, 0)
+	flval, err := strconv.Atoi(value)
+	if err != nil {
+		// e.g. 'all:none'
+		return diffs
+	}
+	for _, state := range states {
+		if state.Disabled != (state.Latency >= flval) {
+			diffs = append(diffs, state)
+		}
+	}
+	return diffs
+}
+
+// sortCPUIdleStates sorts the idle states numerically by cpu and state, so
+// that cpu10 follows cpu9 and not cpu1
+func sortCPUIdleStates(states []CPUIdleState) {
+	num := func(name, prefix string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(name, prefix))
+		return n
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].CPU != states[j].CPU {
+			return num(states[i].CPU, "cpu") < num(states[j].CPU, "cpu")
+		}
+		return num(states[i].State, "state") < num(states[j].State, "state")
+	})
+}
+
 // CheckCPUState checks, if all cpus have the same state settings
 // returns true, if the cpu states differ
 func CheckCPUState(csMap map[string]string) bool {
@@ -219,3 +219,28 @@ func TestMissingCmd(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCPUIdleStateDiffs(t *testing.T) {
+	states := []CPUIdleState{
+		{CPU: "cpu10", State: "state0", Latency: 0},
+		{CPU: "cpu2", State: "state10", Latency: 200, Disabled: true},
+		{CPU: "cpu2", State: "state2", Latency: 133},
+		{CPU: "cpu2", State: "state1", Latency: 2, Disabled: true},
+	}
+	sortCPUIdleStates(states)
+	order := ""
+	for _, state := range states {
+		order = order + " " + state.CPU + ":" + state.State
+	}
+	if order != " cpu2:state1 cpu2:state2 cpu2:state10 cpu10:state0" {
+		t.Errorf("wrong order: '%s'", order)
+	}
+
+	diffs := CPUIdleStateDiffs(states, "70")
+	if len(diffs) != 2 || diffs[0].State != "state1" || diffs[1].State != "state2" {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+	if diffs := CPUIdleStateDiffs(states, "all:none"); len(diffs) != 0 {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
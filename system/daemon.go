@@ -1,15 +1,40 @@
 package system
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 )
 
+// CommandTimeout limits the runtime of the calls of systemctl, systemd-run
+// and tuned-adm, so that a hanging systemd does not block saptune forever.
+// A value of 0 disables the timeout
+var CommandTimeout = 90 * time.Second
+
+// runCommand runs the command and returns its combined output. If the
+// command does not finish within CommandTimeout, it is killed and an error
+// is returned
+func runCommand(name string, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, CommandTimeout)
+		defer cancel()
+	}
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return out, fmt.Errorf("command '%s' did not finish within %v", strings.Join(append([]string{name}, args...), " "), CommandTimeout)
+	}
+	return out, err
+}
+
 // SystemctlEnable call systemctl enable on thing.
 func SystemctlEnable(thing string) error {
-	if out, err := exec.Command("systemctl", "enable", thing).CombinedOutput(); err != nil {
+	if out, err := runCommand("systemctl", "enable", thing); err != nil {
 		return ErrorLog("%v - Failed to call systemctl enable on %s - %s", err, thing, string(out))
 	}
 	return nil
@@ -17,7 +42,7 @@ func SystemctlEnable(thing string) error {
 
 // SystemctlDisable call systemctl disable on thing.
 func SystemctlDisable(thing string) error {
-	if out, err := exec.Command("systemctl", "disable", thing).CombinedOutput(); err != nil {
+	if out, err := runCommand("systemctl", "disable", thing); err != nil {
 		return ErrorLog("%v - Failed to call systemctl disable on %s - %s", err, thing, string(out))
 	}
 	return nil
@@ -26,7 +51,7 @@ func SystemctlDisable(thing string) error {
 // SystemctlRestart call systemctl restart on thing.
 func SystemctlRestart(thing string) error {
 	if IsSystemRunning() {
-		if out, err := exec.Command("systemctl", "restart", thing).CombinedOutput(); err != nil {
+		if out, err := runCommand("systemctl", "restart", thing); err != nil {
 			return ErrorLog("%v - Failed to call systemctl restart on %s - %s", err, thing, string(out))
 		}
 	}
@@ -36,7 +61,7 @@ func SystemctlRestart(thing string) error {
 // SystemctlStart call systemctl start on thing.
 func SystemctlStart(thing string) error {
 	if IsSystemRunning() {
-		if out, err := exec.Command("systemctl", "start", thing).CombinedOutput(); err != nil {
+		if out, err := runCommand("systemctl", "start", thing); err != nil {
 			return ErrorLog("%v - Failed to call systemctl start on %s - %s", err, thing, string(out))
 		}
 	}
@@ -46,13 +71,60 @@ func SystemctlStart(thing string) error {
 // SystemctlStop call systemctl stop on thing.
 func SystemctlStop(thing string) error {
 	if IsSystemRunning() {
-		if out, err := exec.Command("systemctl", "stop", thing).CombinedOutput(); err != nil {
+		if out, err := runCommand("systemctl", "stop", thing); err != nil {
 			return ErrorLog("%v - Failed to call systemctl stop on %s - %s", err, thing, string(out))
 		}
 	}
 	return nil
 }
 
+// SystemctlDaemonReload call systemctl daemon-reload to reload the unit
+// files and drop-in files of all units
+func SystemctlDaemonReload() error {
+	if IsSystemRunning() {
+		if out, err := runCommand("systemctl", "daemon-reload"); err != nil {
+			return ErrorLog("%v - Failed to call systemctl daemon-reload - %s", err, string(out))
+		}
+	}
+	return nil
+}
+
+// SystemctlShowProperty returns the current value of the property of the
+// unit as reported by 'systemctl show'
+func SystemctlShowProperty(unit, property string) (string, error) {
+	out, err := runCommand("systemctl", "show", "--property="+property, unit)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the property '%s' of unit '%s' - %v - %s", property, unit, err, string(out))
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, property+"=") {
+			return strings.TrimSpace(strings.TrimPrefix(line, property+"=")), nil
+		}
+	}
+	return "", fmt.Errorf("unknown property '%s' of unit '%s'", property, unit)
+}
+
+// SystemdRunTimer starts the transient systemd timer 'unit', which runs the
+// command once after the delay. A running timer with the same name is
+// stopped before.
+func SystemdRunTimer(unit string, delay time.Duration, command ...string) error {
+	SystemdStopTimer(unit)
+	secs := int64((delay + time.Second - 1) / time.Second)
+	args := append([]string{"--unit=" + unit, fmt.Sprintf("--on-active=%ds", secs), "--timer-property=AccuracySec=1s"}, command...)
+	if out, err := runCommand("systemd-run", args...); err != nil {
+		return ErrorLog("%v - Failed to start the timer %s - %s", err, unit, string(out))
+	}
+	return nil
+}
+
+// SystemdStopTimer stops the transient systemd timer 'unit', if it is
+// running
+func SystemdStopTimer(unit string) {
+	if SystemctlIsRunning(unit + ".timer") {
+		_ = SystemctlStop(unit + ".timer")
+	}
+}
+
 // SystemctlEnableStart call systemctl enable and then systemctl start on thing.
 func SystemctlEnableStart(thing string) error {
 	if err := SystemctlEnable(thing); err != nil {
@@ -75,7 +147,7 @@ func SystemctlDisableStop(thing string) error {
 // SystemctlIsRunning return true only if systemctl suggests that the thing is
 // running.
 func SystemctlIsRunning(thing string) bool {
-	if _, err := exec.Command("systemctl", "is-active", thing).CombinedOutput(); err == nil {
+	if _, err := runCommand("systemctl", "is-active", thing); err == nil {
 		return true
 	}
 	return false
@@ -86,7 +158,7 @@ func SystemctlIsRunning(thing string) bool {
 // call 'start' or 'restart' to prevent 'Transaction is destructive' messages
 func IsSystemRunning() bool {
 	match := false
-	out, err := exec.Command("/usr/bin/systemctl", "is-system-running").CombinedOutput()
+	out, err := runCommand("/usr/bin/systemctl", "is-system-running")
 	DebugLog("IsSystemRunning - /usr/bin/systemctl is-system-running : '%+v %s'", err, string(out))
 	for _, line := range strings.Split(string(out), "\n") {
 		if strings.TrimSpace(line) == "starting" || strings.TrimSpace(line) == "running" || strings.TrimSpace(line) == "degraded" {
@@ -122,7 +194,7 @@ func GetTunedProfile() string {
 
 // TunedAdmOff calls tuned-adm to switch off the active profile.
 func TunedAdmOff() error {
-	if out, err := exec.Command("tuned-adm", "off").CombinedOutput(); err != nil {
+	if out, err := runCommand("tuned-adm", "off"); err != nil {
 		return ErrorLog("Failed to call tuned-adm to switch off the active profile - %v %s", err, string(out))
 	}
 	return nil
@@ -132,7 +204,7 @@ func TunedAdmOff() error {
 // newer versions of tuned seems to be reliable with this command and they
 // changed the behaviour/handling of the file /etc/tuned/active_profile
 func TunedAdmProfile(profileName string) error {
-	if out, err := exec.Command("tuned-adm", "profile", profileName).CombinedOutput(); err != nil {
+	if out, err := runCommand("tuned-adm", "profile", profileName); err != nil {
 		return ErrorLog("Failed to call tuned-adm to active profile %s - %v %s", profileName, err, string(out))
 	}
 	return nil
@@ -141,7 +213,7 @@ func TunedAdmProfile(profileName string) error {
 // GetTunedAdmProfile return the currently active tuned profile.
 // Return empty string if it cannot be determined.
 func GetTunedAdmProfile() string {
-	out, err := exec.Command("tuned-adm", "active").CombinedOutput()
+	out, err := runCommand("tuned-adm", "active")
 	if err != nil {
 		_ = ErrorLog("Failed to call tuned-adm to get the active profile - %v %s", err, string(out))
 		return ""
@@ -2,7 +2,9 @@ package system
 
 import (
 	"os/exec"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSystemctl(t *testing.T) {
@@ -181,3 +183,27 @@ func TestGetTunedAdmProfile(t *testing.T) {
 		t.Fatalf("seams 'tuned-adm off' does not work: profile is '%v'\n", actVal)
 	}
 }
+
+func TestRunCommandTimeout(t *testing.T) {
+	if !CmdIsAvailable("/usr/bin/sleep") && !CmdIsAvailable("/bin/sleep") {
+		t.Skip("command 'sleep' not available. Skip tests")
+	}
+	oldTimeout := CommandTimeout
+	defer func() { CommandTimeout = oldTimeout }()
+
+	CommandTimeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err := runCommand("sleep", "5")
+	if err == nil || !strings.Contains(err.Error(), "command 'sleep 5' did not finish within 100ms") {
+		t.Errorf("expected a timeout error, got '%v'", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("the command was not stopped after the timeout")
+	}
+
+	// 0 disables the timeout
+	CommandTimeout = 0
+	if _, err := runCommand("sleep", "0.2"); err != nil {
+		t.Error(err)
+	}
+}
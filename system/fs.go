@@ -3,6 +3,7 @@ package system
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"reflect"
 	"regexp"
@@ -139,3 +140,28 @@ func ListDir(dirPath, logMsg string) (dirNames, fileNames []string) {
 	}
 	return
 }
+
+// FilePermissionProblems checks, that the file or directory is owned by
+// root and can not be changed by other users. It returns the problems found,
+// e.g. 'world writable'
+func FilePermissionProblems(fileName string) ([]string, error) {
+	problems := make([]string, 0)
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return problems, err
+	}
+	uid, gid := uint32(0), uint32(0)
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		uid, gid = stat.Uid, stat.Gid
+	}
+	if uid != 0 {
+		problems = append(problems, fmt.Sprintf("owned by uid %d instead of root", uid))
+	}
+	if info.Mode().Perm()&0020 != 0 && gid != 0 {
+		problems = append(problems, fmt.Sprintf("writable by group gid %d", gid))
+	}
+	if info.Mode().Perm()&0002 != 0 {
+		problems = append(problems, "world writable")
+	}
+	return problems, nil
+}
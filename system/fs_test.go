@@ -229,3 +229,35 @@ func TestListDir(t *testing.T) {
 		t.Fatal(files)
 	}
 }
+
+func TestFilePermissionProblems(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("the test needs to run as root")
+	}
+	fileName := "/tmp/saptune_permission_test"
+	defer os.Remove(fileName)
+	if err := ioutil.WriteFile(fileName, []byte("[sysctl]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(fileName, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := FilePermissionProblems(fileName); err != nil || len(problems) != 0 {
+		t.Error(problems, err)
+	}
+	if err := os.Chmod(fileName, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := FilePermissionProblems(fileName); err != nil || !reflect.DeepEqual(problems, []string{"world writable"}) {
+		t.Error(problems, err)
+	}
+	if err := os.Chown(fileName, 4711, 4711); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := FilePermissionProblems(fileName); err != nil || !reflect.DeepEqual(problems, []string{"owned by uid 4711 instead of root", "writable by group gid 4711", "world writable"}) {
+		t.Error(problems, err)
+	}
+	if _, err := FilePermissionProblems("/tmp/saptune_permission_test_missing"); !os.IsNotExist(err) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,107 @@
+package system
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// JournalSocket is the socket of the systemd journal for native messages
+var JournalSocket = "/run/systemd/journal/socket"
+
+var journalSwitch bool // Switch the journal sink on or off
+
+// journal priorities as defined by syslog
+const (
+	journalPrioError   = "3"
+	journalPrioWarning = "4"
+	journalPrioInfo    = "6"
+	journalPrioDebug   = "7"
+)
+
+// SetLogJournal switches the journal sink on or off. If switched on, the
+// log messages are additionally sent to the systemd journal
+func SetLogJournal(journal bool) {
+	journalSwitch = journal
+}
+
+// journalFields encodes the fields of a journal entry in the native
+// journal protocol. Values containing a newline are encoded with an
+// explicit length
+func journalFields(fields map[string]string) []byte {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, key := range keys {
+		val := fields[key]
+		if !strings.Contains(val, "\n") {
+			fmt.Fprintf(&buf, "%s=%s\n", key, val)
+			continue
+		}
+		buf.WriteString(key + "\n")
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(val)))
+		buf.WriteString(val + "\n")
+	}
+	return buf.Bytes()
+}
+
+// journalSend sends an entry with the given fields to the systemd journal
+func journalSend(fields map[string]string) error {
+	conn, err := net.Dial("unixgram", JournalSocket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write(journalFields(fields))
+	return err
+}
+
+// journalLog sends a log message to the systemd journal, if the journal
+// sink is switched on. The saptune command and the Note ID are added as
+// structured fields
+func journalLog(prio, source, msg string, extra map[string]string) {
+	if !journalSwitch {
+		return
+	}
+	fields := map[string]string{
+		"MESSAGE":           strings.TrimSuffix(msg, "\n"),
+		"PRIORITY":          prio,
+		"SYSLOG_IDENTIFIER": "saptune",
+	}
+	if file := strings.Split(strings.TrimSuffix(source, ": "), ":"); len(file) == 2 {
+		fields["CODE_FILE"] = file[0]
+		fields["CODE_LINE"] = file[1]
+	}
+	if logAction != "" {
+		fields["SAPTUNE_COMMAND"] = logAction
+	}
+	if logNote != "" {
+		fields["SAPTUNE_NOTE"] = logNote
+	}
+	for key, val := range extra {
+		fields[key] = val
+	}
+	if err := journalSend(fields); err != nil && warningLogger != nil {
+		// do not try again for every log message
+		journalSwitch = false
+		warningLogger.Print(logLine("WARNING", "", fmt.Sprintf("Failed to write to the systemd journal, journal logging switched off: %v", err)))
+	}
+}
+
+// EventLog logs the apply or the revert of a Note. In the systemd journal
+// the entry carries the fields SAPTUNE_ACTION and SAPTUNE_NOTE, so that
+// e.g. 'journalctl SAPTUNE_ACTION=apply' lists all applied notes
+func EventLog(action, noteID, txt string, stuff ...interface{}) {
+	src := calledFrom()
+	msg := fmt.Sprintf(txt, stuff...)
+	if infoLogger != nil {
+		infoLogger.Print(logLine("INFO", src, msg))
+	}
+	journalLog(journalPrioInfo, src, msg, map[string]string{"SAPTUNE_ACTION": action, "SAPTUNE_NOTE": noteID})
+}
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJournalFields(t *testing.T) {
+	content := journalFields(map[string]string{"MESSAGE": "line1\nline2", "PRIORITY": "6"})
+	exp := "MESSAGE\n\x0b\x00\x00\x00\x00\x00\x00\x00line1\nline2\nPRIORITY=6\n"
+	if string(content) != exp {
+		t.Errorf("got: %q, expected: %q", string(content), exp)
+	}
+}
+
+func TestJournalLog(t *testing.T) {
+	sockFile := "/tmp/saptune_journal_tst.sock"
+	os.Remove(sockFile)
+	defer os.Remove(sockFile)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sockFile, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets not available: %v", err)
+	}
+	defer conn.Close()
+	oldSocket := JournalSocket
+	JournalSocket = sockFile
+	defer func() {
+		JournalSocket = oldSocket
+		SetLogJournal(false)
+		SetLogContext("", "")
+	}()
+	LogInit("/tmp/saptune_tst.log", "0", "off")
+	SetLogContext("note apply", "1410736")
+	SetLogJournal(true)
+
+	buf := make([]byte, 4096)
+	EventLog("apply", "1410736", "Note '%s' applied", "1410736")
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := string(buf[:n])
+	for _, field := range []string{"MESSAGE=Note '1410736' applied\n", "PRIORITY=6\n", "SYSLOG_IDENTIFIER=saptune\n", "SAPTUNE_ACTION=apply\n", "SAPTUNE_NOTE=1410736\n", "SAPTUNE_COMMAND=note apply\n", "CODE_FILE=journal_test.go\n"} {
+		if !strings.Contains(entry, field) {
+			t.Errorf("field '%s' missing in journal entry '%s'", strings.TrimSpace(field), entry)
+		}
+	}
+	if !CheckForPattern("/tmp/saptune_tst.log", "Note '1410736' applied") {
+		t.Error("event not found in log file")
+	}
+
+	WarningLog("TestMessage%s_%s", "6", "Warning")
+	n, err = conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry = string(buf[:n])
+	if !strings.Contains(entry, "MESSAGE=TestMessage6_Warning\n") || !strings.Contains(entry, "PRIORITY=4\n") || strings.Contains(entry, "SAPTUNE_ACTION") {
+		t.Errorf("wrong journal entry '%s'", entry)
+	}
+
+	// an unreachable journal switches the journal sink off
+	JournalSocket = "/tmp/saptune_no_journal.sock"
+	InfoLog("TestMessage%s_%s", "7", "Info")
+	if journalSwitch {
+		t.Error("journal sink not switched off")
+	}
+	if !CheckForPattern("/tmp/saptune_tst.log", "Failed to write to the systemd journal") {
+		t.Error("missing warning about the unreachable journal")
+	}
+}
@@ -1,12 +1,14 @@
 package system
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -16,6 +18,61 @@ var errorLogger *log.Logger   // Error logger
 var warningLogger *log.Logger // Warning logger
 var debugSwitch string        // Switch Debug on or off
 var verboseSwitch string      // Switch verbose mode on or off
+var logFormat = "text"        // format of the log lines, "text" or "json"
+var logAction string          // saptune action added to json log lines
+var logNote string            // Note ID added to json log lines
+
+// logEntry is a single log line in json format
+type logEntry struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Action    string `json:"action,omitempty"`
+	Note      string `json:"note,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Message   string `json:"message"`
+}
+
+// SetLogFormat sets the format of the log lines written by the loggers
+// created by the next call of LogInit. Supported are 'text' (default) and
+// 'json'
+func SetLogFormat(format string) error {
+	switch format {
+	case "", "text":
+		logFormat = "text"
+	case "json":
+		logFormat = "json"
+	default:
+		return fmt.Errorf("unsupported log format '%s', use 'text' or 'json'", format)
+	}
+	return nil
+}
+
+// SetLogContext sets the saptune action and the Note ID, which are added
+// to the log lines in json format
+func SetLogContext(action, note string) {
+	logAction = action
+	logNote = note
+}
+
+// logLine returns the log message in the configured log format
+func logLine(level, source, msg string) string {
+	if logFormat != "json" {
+		return source + msg + "\n"
+	}
+	entry := logEntry{
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Level:     level,
+		Action:    logAction,
+		Note:      logNote,
+		Source:    strings.TrimSuffix(source, ": "),
+		Message:   strings.TrimSuffix(msg, "\n"),
+	}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return source + msg + "\n"
+	}
+	return string(content) + "\n"
+}
 
 // calledFrom returns the name and the line number of the calling source file
 func calledFrom() string {
@@ -31,7 +88,9 @@ func calledFrom() string {
 // DebugLog sents text to the DebugLogWriter
 func DebugLog(txt string, stuff ...interface{}) {
 	if debugLogger != nil && debugSwitch == "1" {
-		debugLogger.Printf(calledFrom()+txt+"\n", stuff...)
+		src := calledFrom()
+		debugLogger.Print(logLine("DEBUG", src, fmt.Sprintf(txt, stuff...)))
+		journalLog(journalPrioDebug, src, fmt.Sprintf(txt, stuff...), nil)
 		fmt.Fprintf(os.Stderr, "DEBUG: "+txt+"\n", stuff...)
 	}
 }
@@ -39,7 +98,9 @@ func DebugLog(txt string, stuff ...interface{}) {
 // InfoLog sents text to the InfoLogWriter
 func InfoLog(txt string, stuff ...interface{}) {
 	if infoLogger != nil {
-		infoLogger.Printf(calledFrom()+txt+"\n", stuff...)
+		src := calledFrom()
+		infoLogger.Print(logLine("INFO", src, fmt.Sprintf(txt, stuff...)))
+		journalLog(journalPrioInfo, src, fmt.Sprintf(txt, stuff...), nil)
 		if verboseSwitch == "on" {
 			fmt.Fprintf(os.Stdout, "    INFO: "+txt+"\n", stuff...)
 		}
@@ -49,7 +110,9 @@ func InfoLog(txt string, stuff ...interface{}) {
 // WarningLog sents text to the WarningLogWriter
 func WarningLog(txt string, stuff ...interface{}) {
 	if warningLogger != nil {
-		warningLogger.Printf(calledFrom()+txt+"\n", stuff...)
+		src := calledFrom()
+		warningLogger.Print(logLine("WARNING", src, fmt.Sprintf(txt, stuff...)))
+		journalLog(journalPrioWarning, src, fmt.Sprintf(txt, stuff...), nil)
 		if verboseSwitch == "on" {
 			fmt.Fprintf(os.Stderr, "    WARNING: "+txt+"\n", stuff...)
 		}
@@ -59,7 +122,9 @@ func WarningLog(txt string, stuff ...interface{}) {
 // ErrorLog sents text to the ErrorLogWriter
 func ErrorLog(txt string, stuff ...interface{}) error {
 	if errorLogger != nil {
-		errorLogger.Printf(calledFrom()+txt+"\n", stuff...)
+		src := calledFrom()
+		errorLogger.Print(logLine("ERROR", src, fmt.Sprintf(txt, stuff...)))
+		journalLog(journalPrioError, src, fmt.Sprintf(txt, stuff...), nil)
 		fmt.Fprintf(os.Stderr, "ERROR: "+txt+"\n", stuff...)
 	}
 	return fmt.Errorf(txt+"\n", stuff...)
@@ -73,6 +138,9 @@ func LogInit(logFile, debug, verbose string) {
 
 	//create log file with desired read/write permissions
 	//saptuneLog, err := os.OpenFile("/var/log/tuned/tuned.log", os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+		panic(err.Error())
+	}
 	saptuneLog, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
 	if err != nil {
 		panic(err.Error())
@@ -92,10 +160,18 @@ func LogInit(logFile, debug, verbose string) {
 	//errorLogger = log.New(errorLogWriter, logTimeFormat+"ERROR    saptune.", log.Lshortfile)
 	//log.SetFlags(0)
 
-	debugLogger = log.New(saptuneLog, logTimeFormat+"DEBUG    saptune.", 0)
-	infoLogger = log.New(saptuneLog, logTimeFormat+"INFO     saptune.", 0)
-	warningLogger = log.New(saptuneLog, logTimeFormat+"WARNING  saptune.", 0)
-	errorLogger = log.New(saptuneLog, logTimeFormat+"ERROR    saptune.", 0)
+	if logFormat == "json" {
+		// timestamp and level are part of the json log line
+		debugLogger = log.New(saptuneLog, "", 0)
+		infoLogger = log.New(saptuneLog, "", 0)
+		warningLogger = log.New(saptuneLog, "", 0)
+		errorLogger = log.New(saptuneLog, "", 0)
+	} else {
+		debugLogger = log.New(saptuneLog, logTimeFormat+"DEBUG    saptune.", 0)
+		infoLogger = log.New(saptuneLog, logTimeFormat+"INFO     saptune.", 0)
+		warningLogger = log.New(saptuneLog, logTimeFormat+"WARNING  saptune.", 0)
+		errorLogger = log.New(saptuneLog, logTimeFormat+"ERROR    saptune.", 0)
+	}
 
 	debugSwitch = debug
 	verboseSwitch = verbose
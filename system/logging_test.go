@@ -1,8 +1,12 @@
 package system
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCalledFrom(t *testing.T) {
@@ -34,3 +38,40 @@ func TestLog(t *testing.T) {
 		t.Fatal("Error message not found in log file")
 	}
 }
+
+func TestJSONLog(t *testing.T) {
+	logFile := "/tmp/saptune_json_tst/saptune.log"
+	defer os.RemoveAll("/tmp/saptune_json_tst")
+	if err := SetLogFormat("xml"); err == nil {
+		t.Fatal("expected an error for an unsupported log format")
+	}
+	if err := SetLogFormat("json"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = SetLogFormat("text")
+		SetLogContext("", "")
+		LogInit("/tmp/saptune_tst.log", "0", "off")
+	}()
+	SetLogContext("note apply", "1410736")
+	LogInit(logFile, "0", "off")
+	InfoLog("TestMessage%s_%s", "5", "Info")
+
+	content, err := ioutil.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := logEntry{}
+	if err := json.Unmarshal(content, &entry); err != nil {
+		t.Fatalf("log line '%s' is not in json format: %v", string(content), err)
+	}
+	if entry.Level != "INFO" || entry.Action != "note apply" || entry.Note != "1410736" || entry.Message != "TestMessage5_Info" {
+		t.Errorf("wrong log entry: %+v", entry)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err != nil {
+		t.Errorf("wrong timestamp '%s': %v", entry.Timestamp, err)
+	}
+	if !strings.HasPrefix(entry.Source, "logging_test.go:") {
+		t.Errorf("wrong source '%s'", entry.Source)
+	}
+}
@@ -16,6 +16,9 @@ const (
 	MemSwapTotalKey = "SwapTotal"
 )
 
+// MemInfoFile is the file containing the memory information of the system
+var MemInfoFile = "/proc/meminfo"
+
 // ParseMeminfo parse /proc/meminfo into key(string) - value(int) pairs.
 // Panic on error.
 func ParseMeminfo() (infoMap map[string]uint64) {
@@ -41,6 +44,28 @@ func ParseMeminfo() (infoMap map[string]uint64) {
 	return
 }
 
+// GetMainMemSizeBytes returns the size of the system main memory, excluding
+// swap, in bytes. Contrary to ParseMeminfo an error is returned, if the
+// size can not be read
+func GetMainMemSizeBytes() (uint64, error) {
+	memInfo, err := ioutil.ReadFile(MemInfoFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %v", MemInfoFile, err)
+	}
+	for _, line := range strings.Split(string(memInfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != MemMainTotalKey+":" {
+			continue
+		}
+		kbytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse uint64 value from '%s' in %s", line, MemInfoFile)
+		}
+		return kbytes * 1024, nil
+	}
+	return 0, fmt.Errorf("missing '%s' in %s", MemMainTotalKey, MemInfoFile)
+}
+
 // GetMainMemSizeMB return size of system main memory, excluding swap.
 // Panic on error.
 func GetMainMemSizeMB() uint64 {
@@ -24,6 +24,18 @@ func TestGetMemSize(t *testing.T) {
 	}
 }
 
+func TestGetMainMemSizeBytes(t *testing.T) {
+	if size, err := GetMainMemSizeBytes(); err != nil || size/1024/1024 != GetMainMemSizeMB() {
+		t.Fatal(size, err)
+	}
+	oldFile := MemInfoFile
+	defer func() { MemInfoFile = oldFile }()
+	MemInfoFile = "/not_avail"
+	if _, err := GetMainMemSizeBytes(); err == nil {
+		t.Fatal("expected an error for a missing meminfo file")
+	}
+}
+
 func TestGetTotalMemSizePages(t *testing.T) {
 	if pages := GetTotalMemSizePages(); pages != GetTotalMemSizeMB()*1024/uint64(os.Getpagesize()) {
 		t.Fatal(pages)
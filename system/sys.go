@@ -37,6 +37,38 @@ func GetSysChoice(parameter string) (string, error) {
 	return "", nil
 }
 
+// GetBlockDeviceSchedulers returns the schedulers supported by the block
+// device and the scheduler currently used by the device as listed in
+// /sys/block/<device>/queue/scheduler
+func GetBlockDeviceSchedulers(blockdev string) ([]string, string, error) {
+	val, err := ioutil.ReadFile(path.Join("/sys/block", blockdev, "queue", "scheduler"))
+	if err != nil {
+		return nil, "", err
+	}
+	available, current := ParseSchedulerChoices(string(val))
+	return available, current, nil
+}
+
+// ParseSchedulerChoices splits the content of a scheduler file like
+// 'mq-deadline kyber [bfq] none' into the available schedulers and the
+// current scheduler, which is marked by brackets. A single scheduler
+// without brackets is the current one
+func ParseSchedulerChoices(choices string) ([]string, string) {
+	available := []string{}
+	current := ""
+	for _, choice := range strings.Fields(choices) {
+		if len(choice) > 2 && choice[0] == '[' && choice[len(choice)-1] == ']' {
+			choice = choice[1 : len(choice)-1]
+			current = choice
+		}
+		available = append(available, choice)
+	}
+	if current == "" && len(available) == 1 {
+		current = available[0]
+	}
+	return available, current
+}
+
 // GetSysInt read an integer /sys/ key.
 func GetSysInt(parameter string) (int, error) {
 	value, err := GetSysString(parameter)
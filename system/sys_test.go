@@ -2,6 +2,7 @@ package system
 
 import (
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -78,6 +79,20 @@ func TestWriteSys(t *testing.T) {
 	}
 }
 
+func TestParseSchedulerChoices(t *testing.T) {
+	available, current := ParseSchedulerChoices("mq-deadline kyber [bfq] none\n")
+	if strings.Join(available, " ") != "mq-deadline kyber bfq none" || current != "bfq" {
+		t.Error(available, current)
+	}
+	available, current = ParseSchedulerChoices("none")
+	if len(available) != 1 || current != "none" {
+		t.Error(available, current)
+	}
+	if _, _, err := GetBlockDeviceSchedulers("saptune_no_dev"); err == nil {
+		t.Error("expected an error for an unknown block device")
+	}
+}
+
 func TestTestSysString(t *testing.T) {
 	if tstErr := TestSysString("kernel/mm/ksm/run", "0"); tstErr == nil {
 		t.Log("writing sys key is possible")
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -148,6 +149,27 @@ func SetSysctlUint64Field(param string, field int, value uint64) error {
 	return err
 }
 
+// GetSysctlInterfaces returns the names of the network interfaces, which
+// have sysctl parameters below the given prefix, e.g. 'net.ipv4.conf.'.
+// The entries 'all' and 'default' are no interfaces and are skipped.
+func GetSysctlInterfaces(prefix string) []string {
+	dir := path.Join("/proc/sys", strings.Replace(strings.TrimSuffix(prefix, "."), ".", "/", -1))
+	dirNames, _ := ListDir(dir, "network interfaces")
+	ifaces := make([]string, 0, len(dirNames))
+	for _, iface := range dirNames {
+		switch {
+		case iface == "all" || iface == "default":
+			continue
+		case strings.Contains(iface, "."):
+			// the dot separates the parts of a sysctl parameter name
+			WarningLog("skipping network interface '%s', sysctl parameters of interfaces containing a '.' in their name are not supported", iface)
+			continue
+		}
+		ifaces = append(ifaces, iface)
+	}
+	return ifaces
+}
+
 // IsPagecacheAvailable check, if system supports pagecache limit
 func IsPagecacheAvailable() bool {
 	_, err := ioutil.ReadFile(path.Join("/proc/sys", strings.Replace(SysctlPagecacheLimitMB, ".", "/", -1)))
@@ -156,3 +178,132 @@ func IsPagecacheAvailable() bool {
 	}
 	return false
 }
+
+// SysctlConfigDirs are the directories containing the sysctl configuration
+// files applied during boot, in the order of their precedence. A file in
+// a directory hides a file with the same name in the following directories
+var SysctlConfigDirs = []string{"/etc/sysctl.d", "/run/sysctl.d", "/usr/local/lib/sysctl.d", "/usr/lib/sysctl.d", "/lib/sysctl.d"}
+
+// SysctlConfigFile is the sysctl configuration file applied during boot
+// after the files from SysctlConfigDirs
+var SysctlConfigFile = "/etc/sysctl.conf"
+
+// sysctlKernelDefaults are the default values of sysctl parameters as
+// documented by the kernel. Parameters, whose default depends on the
+// hardware or on the kernel version, are not listed.
+var sysctlKernelDefaults = map[string]string{
+	SysctlPagecacheLimitMB:               "0",
+	SysctlPagecacheLimitIgnoreDirty:      "1",
+	SysctlShmall:                         "18446744073692774399",
+	SysctlShmax:                          "18446744073692774399",
+	SysctlShmni:                          "4096",
+	SysctlMaxMapCount:                    "65530",
+	SysctlSem:                            "32000 1024000000 500 32000",
+	SysctlNumberHugepages:                "0",
+	SysctlSwappines:                      "60",
+	SysctlVFSCachePressure:               "100",
+	SysctlOvercommitMemory:               "0",
+	SysctlOvercommitRatio:                "50",
+	SysctlDirtyRatio:                     "20",
+	SysctlDirtyBackgroundRatio:           "10",
+	"vm.dirty_bytes":                     "0",
+	"vm.dirty_background_bytes":          "0",
+	"vm.dirty_expire_centisecs":          "3000",
+	"vm.dirty_writeback_centisecs":       "500",
+	"vm.zone_reclaim_mode":               "0",
+	SysctlTCPTimestamps:                  "1",
+	SysctlTCPSack:                        "1",
+	SysctlTCPDsack:                       "1",
+	SysctlTCPSynackRetries:               "5",
+	SysctpTCPRetries2:                    "15",
+	SysctlTCPKeepaliveTime:               "7200",
+	SysctlTCPKeepaliveProbes:             "9",
+	SysctlTCPKeepaliveInterval:           "75",
+	SysctlTCPFinTimeout:                  "60",
+	SysctlTCPMTUProbing:                  "0",
+	SysctlTCPSynCookies:                  "1",
+	"net.ipv4.tcp_slow_start_after_idle": "1",
+	SysctlRandomizeVASpace:               "2",
+	SysctlRunChildFirst:                  "0",
+}
+
+// sysctlDirtyCounterparts maps the vm.dirty byte parameters to their ratio
+// counterparts. The kernel does not accept '0' for the byte parameters,
+// instead the counterpart needs to be set.
+var sysctlDirtyCounterparts = map[string]string{
+	"vm.dirty_bytes":            SysctlDirtyRatio,
+	"vm.dirty_background_bytes": SysctlDirtyBackgroundRatio,
+}
+
+// GetSysctlBootValues returns the sysctl parameter values set by the sysctl
+// configuration files during boot
+func GetSysctlBootValues() map[string]string {
+	values := make(map[string]string)
+	files := make(map[string]string)
+	for _, dir := range SysctlConfigDirs {
+		_, fileNames := ListDir(dir, "")
+		for _, fileName := range fileNames {
+			if _, hidden := files[fileName]; !hidden && strings.HasSuffix(fileName, ".conf") {
+				files[fileName] = path.Join(dir, fileName)
+			}
+		}
+	}
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	confFiles := make([]string, 0, len(fileNames)+1)
+	for _, fileName := range fileNames {
+		confFiles = append(confFiles, files[fileName])
+	}
+	confFiles = append(confFiles, SysctlConfigFile)
+	for _, confFile := range confFiles {
+		content, err := ioutil.ReadFile(confFile)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			fields := strings.SplitN(line, "=", 2)
+			if len(fields) != 2 {
+				continue
+			}
+			key := strings.Replace(strings.TrimPrefix(strings.TrimSpace(fields[0]), "-"), "/", ".", -1)
+			values[key] = consecutiveSpaces.ReplaceAllString(strings.TrimSpace(fields[1]), " ")
+		}
+	}
+	return values
+}
+
+// GetSysctlDefault returns the default value of a sysctl parameter. The
+// value set by the sysctl configuration files during boot takes precedence
+// over the default value documented by the kernel. Returns false, if the
+// default value of the parameter is unknown.
+func GetSysctlDefault(parameter string) (string, bool) {
+	if value, ok := GetSysctlBootValues()[parameter]; ok {
+		return value, true
+	}
+	value, ok := sysctlKernelDefaults[parameter]
+	return value, ok
+}
+
+// SetSysctlDefault sets a sysctl parameter to its default value. Returns
+// false, if the default value of the parameter is unknown.
+func SetSysctlDefault(parameter string) (bool, error) {
+	value, ok := GetSysctlDefault(parameter)
+	if !ok {
+		return false, nil
+	}
+	if counterpart, ok := sysctlDirtyCounterparts[parameter]; ok && value == "0" {
+		// setting the ratio resets the byte parameter to '0'
+		if cpValue, ok := GetSysctlDefault(counterpart); ok {
+			return true, SetSysctlString(counterpart, cpValue)
+		}
+		return false, nil
+	}
+	return true, SetSysctlString(parameter, value)
+}
@@ -1,6 +1,11 @@
 package system
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
 
 func TestReadSysctl(t *testing.T) {
 	if value, err := GetSysctlInt("vm.max_map_count"); err != nil {
@@ -85,3 +90,72 @@ func TestIsPagecacheAvailable(t *testing.T) {
 		t.Log("pagecache setting NOT available")
 	}
 }
+
+func TestSysctlDefault(t *testing.T) {
+	tstDir := "/tmp/saptune_sysctl_test"
+	defer os.RemoveAll(tstDir)
+	etcDir := path.Join(tstDir, "etc")
+	usrDir := path.Join(tstDir, "usr")
+	for _, dir := range []string{etcDir, usrDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldDirs, oldFile := SysctlConfigDirs, SysctlConfigFile
+	defer func() { SysctlConfigDirs, SysctlConfigFile = oldDirs, oldFile }()
+	SysctlConfigDirs = []string{etcDir, usrDir}
+	SysctlConfigFile = path.Join(tstDir, "sysctl.conf")
+
+	writeFile := func(fileName, content string) {
+		if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(path.Join(usrDir, "50-default.conf"), "# comment\nvm.swappiness = 10\nkernel/shmmni=8192\nnet.ipv4.tcp_rmem = 4096\t87380   6291456\n")
+	writeFile(path.Join(usrDir, "60-hidden.conf"), "vm.max_map_count = 1\n")
+	writeFile(path.Join(usrDir, "README"), "vm.overcommit_ratio = 1\n")
+	writeFile(path.Join(etcDir, "60-hidden.conf"), "; comment\n-vm.max_map_count = 2\n")
+	writeFile(SysctlConfigFile, "vm.swappiness=30\n")
+
+	values := GetSysctlBootValues()
+	expected := map[string]string{"vm.swappiness": "30", "kernel.shmmni": "8192", "net.ipv4.tcp_rmem": "4096 87380 6291456", "vm.max_map_count": "2"}
+	if len(values) != len(expected) {
+		t.Fatal(values)
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("'%s': expected '%s', got '%s'", key, value, values[key])
+		}
+	}
+	if value, ok := GetSysctlDefault("vm.swappiness"); !ok || value != "30" {
+		t.Error(value, ok)
+	}
+	if value, ok := GetSysctlDefault("vm.dirty_ratio"); !ok || value != "20" {
+		t.Error(value, ok)
+	}
+	if value, ok := GetSysctlDefault("vm.min_free_kbytes"); ok {
+		t.Error(value, ok)
+	}
+	if set, err := SetSysctlDefault("vm.min_free_kbytes"); set || err != nil {
+		t.Error(set, err)
+	}
+}
+
+func TestGetSysctlInterfaces(t *testing.T) {
+	ifaces := GetSysctlInterfaces("net.ipv4.conf.")
+	found := false
+	for _, iface := range ifaces {
+		if iface == "all" || iface == "default" {
+			t.Errorf("'%s' is no network interface", iface)
+		}
+		if iface == "lo" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error(ifaces)
+	}
+	if ifaces := GetSysctlInterfaces("not.available."); len(ifaces) != 0 {
+		t.Error(ifaces)
+	}
+}
@@ -55,6 +55,16 @@ func GetOsName() string {
 	return matches[1]
 }
 
+// GetKernelRelease returns the release of the running kernel like
+// 'uname -r', e.g. '5.3.18-150300.59.63-default'
+func GetKernelRelease() string {
+	val, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(val))
+}
+
 // CheckForPattern returns true, if the file is available and
 // contains the expected string
 func CheckForPattern(file, pattern string) bool {
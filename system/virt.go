@@ -0,0 +1,92 @@
+package system
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// kinds of virtualization environments
+const (
+	VirtKindVM        = "vm"
+	VirtKindContainer = "container"
+)
+
+var detectVirtCmd = "/usr/bin/systemd-detect-virt"
+
+// files used to detect the virtualization environment, if
+// systemd-detect-virt is not available
+var cpuInfoFile = "/proc/cpuinfo"
+var containerEnvFiles = []struct{ file, virt string }{
+	{"/run/systemd/container", ""},
+	{"/.dockerenv", "docker"},
+	{"/run/.containerenv", "podman"},
+}
+
+// GetVirtualization returns the kind ("vm" or "container") and the type
+// (e.g. "kvm" or "docker") of the virtualization environment, in which
+// saptune is running. An empty kind means the system is running on bare metal.
+// A container is reported even if it runs inside a virtual machine, as
+// it is the more restrictive environment.
+func GetVirtualization() (string, string) {
+	if CmdIsAvailable(detectVirtCmd) {
+		if virt := runDetectVirt("--container"); virt != "" {
+			return VirtKindContainer, virt
+		}
+		if virt := runDetectVirt("--vm"); virt != "" {
+			return VirtKindVM, virt
+		}
+		return "", ""
+	}
+	return detectVirtFromFiles()
+}
+
+// runDetectVirt calls systemd-detect-virt with the given option and returns
+// the detected virtualization type or an empty string, if none was found
+func runDetectVirt(option string) string {
+	// systemd-detect-virt prints 'none' and exits with 1, if no
+	// virtualization is detected
+	out, err := exec.Command(detectVirtCmd, option).Output()
+	virt := strings.TrimSpace(string(out))
+	if err != nil || virt == "none" {
+		return ""
+	}
+	return virt
+}
+
+// detectVirtFromFiles is the fallback for systems without systemd-detect-virt.
+// It only knows the most common container runtimes and reports a virtual
+// machine, if the cpu carries the 'hypervisor' flag
+func detectVirtFromFiles() (string, string) {
+	for _, env := range containerEnvFiles {
+		if _, err := os.Stat(env.file); err != nil {
+			continue
+		}
+		virt := env.virt
+		if virt == "" {
+			// /run/systemd/container contains the container type
+			content, err := ReadConfigFile(env.file, false)
+			virt = strings.TrimSpace(string(content))
+			if err != nil || virt == "" {
+				virt = "container-other"
+			}
+		}
+		return VirtKindContainer, virt
+	}
+	content, err := ReadConfigFile(cpuInfoFile, false)
+	if err != nil {
+		return "", ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "flags") {
+			continue
+		}
+		for _, flag := range strings.Fields(line) {
+			if flag == "hypervisor" {
+				return VirtKindVM, "vm-other"
+			}
+		}
+		break
+	}
+	return "", ""
+}
@@ -0,0 +1,35 @@
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDetectVirtFromFiles(t *testing.T) {
+	oldCPUInfo, oldEnvFiles := cpuInfoFile, containerEnvFiles
+	defer func() { cpuInfoFile, containerEnvFiles = oldCPUInfo, oldEnvFiles }()
+	tmpDir, err := ioutil.TempDir("", "saptune-virt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cpuInfoFile = path.Join(tmpDir, "cpuinfo")
+	envFile := path.Join(tmpDir, "dockerenv")
+	containerEnvFiles = []struct{ file, virt string }{{envFile, "docker"}}
+
+	ioutil.WriteFile(cpuInfoFile, []byte("processor\t: 0\nflags\t\t: fpu vme de pse\n"), 0644)
+	if kind, virt := detectVirtFromFiles(); kind != "" || virt != "" {
+		t.Errorf("expected bare metal, got '%s', '%s'", kind, virt)
+	}
+	ioutil.WriteFile(cpuInfoFile, []byte("processor\t: 0\nflags\t\t: fpu vme de pse hypervisor\n"), 0644)
+	if kind, virt := detectVirtFromFiles(); kind != VirtKindVM || virt != "vm-other" {
+		t.Errorf("expected virtual machine, got '%s', '%s'", kind, virt)
+	}
+	ioutil.WriteFile(envFile, []byte{}, 0644)
+	if kind, virt := detectVirtFromFiles(); kind != VirtKindContainer || virt != "docker" {
+		t.Errorf("expected docker container, got '%s', '%s'", kind, virt)
+	}
+}
@@ -0,0 +1,64 @@
+package txtparser
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParamBounds contains the sane bounds of the value of a parameter defined
+// in the [bounds] section of a Note definition file. An empty bound is not
+// checked
+type ParamBounds struct {
+	Min string
+	Max string
+}
+
+// ParseBounds parses the bounds of a parameter given as 'MIN:MAX'. One of
+// both bounds may be empty, e.g. '5:' or ':40'
+func ParseBounds(value string) (ParamBounds, error) {
+	fields := strings.Split(strings.TrimSpace(value), ":")
+	if len(fields) != 2 || (fields[0] == "" && fields[1] == "") {
+		return ParamBounds{}, fmt.Errorf("wrong bounds '%s', expected 'MIN:MAX'", value)
+	}
+	bounds := ParamBounds{Min: strings.TrimSpace(fields[0]), Max: strings.TrimSpace(fields[1])}
+	for _, bound := range []string{bounds.Min, bounds.Max} {
+		if _, ok := new(big.Int).SetString(bound, 10); bound != "" && !ok {
+			return ParamBounds{}, fmt.Errorf("wrong bounds '%s', '%s' is not an integer", value, bound)
+		}
+	}
+	if bounds.Min != "" && bounds.Max != "" && compareInt(bounds.Min, bounds.Max) > 0 {
+		return ParamBounds{}, fmt.Errorf("wrong bounds '%s', the minimum is greater than the maximum", value)
+	}
+	return bounds, nil
+}
+
+// String returns the bounds in the format of the [bounds] section
+func (bounds ParamBounds) String() string {
+	return bounds.Min + ":" + bounds.Max
+}
+
+// InBounds returns false, if one of the integer fields of the value is
+// outside of the bounds. Fields, which are not integers, are not checked
+func (bounds ParamBounds) InBounds(value string) bool {
+	for _, field := range strings.Fields(value) {
+		if _, ok := new(big.Int).SetString(field, 10); !ok {
+			continue
+		}
+		if bounds.Min != "" && compareInt(field, bounds.Min) < 0 {
+			return false
+		}
+		if bounds.Max != "" && compareInt(field, bounds.Max) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compareInt compares two integers given as strings. Both need to be valid
+// integers
+func compareInt(a, b string) int {
+	x, _ := new(big.Int).SetString(a, 10)
+	y, _ := new(big.Int).SetString(b, 10)
+	return x.Cmp(y)
+}
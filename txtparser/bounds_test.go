@@ -0,0 +1,37 @@
+package txtparser
+
+import (
+	"testing"
+)
+
+func TestParseBounds(t *testing.T) {
+	for value, expected := range map[string]ParamBounds{"5:40": {"5", "40"}, " 5 : ": {"5", ""}, ":18446744073709551615": {"", "18446744073709551615"}, "-1:0": {"-1", "0"}} {
+		bounds, err := ParseBounds(value)
+		if err != nil || bounds != expected {
+			t.Errorf("'%s': got '%+v', '%v'", value, bounds, err)
+		}
+	}
+	for _, value := range []string{"", ":", "5", "5:40:60", "a:40", "5:4x", "40:5"} {
+		if _, err := ParseBounds(value); err == nil {
+			t.Errorf("'%s': expected an error", value)
+		}
+	}
+	if bounds, _ := ParseBounds("5:40"); bounds.String() != "5:40" {
+		t.Error(bounds.String())
+	}
+}
+
+func TestInBounds(t *testing.T) {
+	bounds := ParamBounds{Min: "5", Max: "40"}
+	for value, expected := range map[string]bool{"5": true, "40": true, "20": true, "4": false, "41": false, "never": true, "": true, "10\t50": false, "10 20": true} {
+		if bounds.InBounds(value) != expected {
+			t.Errorf("'%s': expected '%v'", value, expected)
+		}
+	}
+	if !(ParamBounds{Min: "0"}).InBounds("18446744073709551615") {
+		t.Error("no upper bound expected")
+	}
+	if (ParamBounds{Max: "100"}).InBounds("18446744073709551615") {
+		t.Error("value greater than the upper bound")
+	}
+}
@@ -0,0 +1,145 @@
+package txtparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ConditionKernel is the subject of a section condition comparing the
+// release of the running kernel, e.g. '[sysctl:kernel>=5.3]'
+const ConditionKernel = "kernel"
+
+// SectionCondition is the condition of a conditional section like
+// '[sysctl:kernel>=5.3]'. The entries of such a section are only used, if
+// the condition is true for the running system
+type SectionCondition struct {
+	Subject  string
+	Operator string
+	Version  string
+}
+
+// regexSectionCondition breaks up a condition into subject, operator and
+// version
+var regexSectionCondition = regexp.MustCompile(`^(\w+)\s*(>=|<=|==|!=|=|>|<)\s*(\S+)$`)
+
+// regexConditionVersion matches a version, which starts with a digit
+var regexConditionVersion = regexp.MustCompile(`^[0-9][0-9A-Za-z._+~-]*$`)
+
+// SplitSectionName splits the name of a section into the name of the
+// section and its condition, e.g. 'sysctl:kernel>=5.3' into 'sysctl' and
+// 'kernel>=5.3'. The condition is empty for a section without condition
+func SplitSectionName(name string) (string, string) {
+	idx := strings.Index(name, ":")
+	if idx < 0 {
+		return name, ""
+	}
+	return strings.TrimSpace(name[:idx]), strings.TrimSpace(name[idx+1:])
+}
+
+// SectionKey returns the key of a section in INIFile.KeyValue. The entries
+// of a conditional section are kept apart from the entries of the section
+// without condition and of sections with other conditions, e.g. the key of
+// '[sysctl:kernel>=5.3]' is 'sysctl:kernel>=5.3'
+func SectionKey(section, condition string) string {
+	if condition == "" {
+		return section
+	}
+	return section + ":" + condition
+}
+
+// SectionKey returns the key of the section of the entry in INIFile.KeyValue
+func (entry INIEntry) SectionKey() string {
+	return SectionKey(entry.Section, entry.Condition)
+}
+
+// ParseSectionCondition parses the condition of a section like
+// 'kernel>=5.3'. Supported operators are '<', '<=', '=', '==', '!=', '>='
+// and '>'. Only the subject 'kernel' is supported
+func ParseSectionCondition(condition string) (SectionCondition, error) {
+	fields := regexSectionCondition.FindStringSubmatch(strings.TrimSpace(condition))
+	if fields == nil {
+		return SectionCondition{}, fmt.Errorf("malformed condition '%s', expected e.g. 'kernel>=5.3'", condition)
+	}
+	if fields[1] != ConditionKernel {
+		return SectionCondition{}, fmt.Errorf("unsupported subject '%s' in condition '%s', only '%s' is supported", fields[1], condition, ConditionKernel)
+	}
+	if !regexConditionVersion.MatchString(fields[3]) {
+		return SectionCondition{}, fmt.Errorf("wrong version '%s' in condition '%s'", fields[3], condition)
+	}
+	return SectionCondition{Subject: fields[1], Operator: fields[2], Version: fields[3]}, nil
+}
+
+// Matches returns true, if the version, e.g. the kernel release from
+// 'uname -r', fulfils the condition
+func (cond SectionCondition) Matches(version string) bool {
+	cmp := CompareVersions(version, cond.Version)
+	switch cond.Operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// String returns the condition as written in the section name
+func (cond SectionCondition) String() string {
+	return cond.Subject + cond.Operator + cond.Version
+}
+
+// CompareVersions compares the version with the reference version and
+// returns -1, 0 or 1, if the version is lower, equal or higher.
+// The versions are split into components at '.', '-', '_', '+' and '~'.
+// Only as many components as the reference version contains are compared,
+// so the kernel release '5.3.18-150300.59.63-default' is equal to '5.3'.
+// Numeric components are compared as numbers, so '5.14' is higher than
+// '5.3', other components are compared as strings. A missing component of
+// the version counts as '0'
+func CompareVersions(version, reference string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == '.' || r == '-' || r == '_' || r == '+' || r == '~'
+		})
+	}
+	vParts := split(version)
+	for idx, ref := range split(reference) {
+		part := "0"
+		if idx < len(vParts) {
+			part = vParts[idx]
+		}
+		if cmp := compareVersionPart(part, ref); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareVersionPart compares a single component of two versions
+func compareVersionPart(part, ref string) int {
+	pNum, pErr := strconv.ParseUint(part, 10, 64)
+	rNum, rErr := strconv.ParseUint(ref, 10, 64)
+	switch {
+	case pErr == nil && rErr == nil:
+		if pNum < rNum {
+			return -1
+		} else if pNum > rNum {
+			return 1
+		}
+		return 0
+	case pErr == nil:
+		// a number is higher than a text like 'rc1'
+		return 1
+	case rErr == nil:
+		return -1
+	}
+	return strings.Compare(part, ref)
+}
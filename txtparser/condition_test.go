@@ -0,0 +1,104 @@
+package txtparser
+
+import (
+	"testing"
+)
+
+func TestParseSectionCondition(t *testing.T) {
+	for value, expected := range map[string]SectionCondition{
+		"kernel>=5.3":     {Subject: "kernel", Operator: ">=", Version: "5.3"},
+		" kernel < 5.14 ": {Subject: "kernel", Operator: "<", Version: "5.14"},
+		"kernel!=4.12.14": {Subject: "kernel", Operator: "!=", Version: "4.12.14"},
+	} {
+		cond, err := ParseSectionCondition(value)
+		if err != nil || cond != expected {
+			t.Errorf("'%s': got '%+v', '%v'", value, cond, err)
+		}
+	}
+	for _, value := range []string{"", "kernel", "kernel>=", "kernel=>5.3", "os>=15", "kernel>=five", "kernel>=5.3 6.1"} {
+		if _, err := ParseSectionCondition(value); err == nil {
+			t.Errorf("'%s': expected an error", value)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		version, reference string
+		expected           int
+	}{
+		{"5.3.18-150300.59.63-default", "5.3", 0},
+		{"5.3.18-150300.59.63-default", "5.3.18", 0},
+		{"5.3.18-150300.59.63-default", "5.3.19", -1},
+		{"5.14.21-150400.24.11-default", "5.3", 1},
+		{"4.12.14-122.37-default", "5.3", -1},
+		{"6.1", "6.1.0", 0},
+		{"5.3", "5.3.1", -1},
+		{"6.0.0-rc1", "6.0.0-1", -1},
+		{"6.0.0-rc2", "6.0.0-rc1", 1},
+	} {
+		if cmp := CompareVersions(tc.version, tc.reference); cmp != tc.expected {
+			t.Errorf("'%s' compared with '%s': got %d, expected %d", tc.version, tc.reference, cmp, tc.expected)
+		}
+	}
+}
+
+func TestSectionConditionMatches(t *testing.T) {
+	release := "5.14.21-150400.24.11-default"
+	for condition, expected := range map[string]bool{
+		"kernel>=5.3":     true,
+		"kernel>5.14":     false,
+		"kernel<5.14":     false,
+		"kernel<=5.14":    true,
+		"kernel=5.14":     true,
+		"kernel==5.14":    true,
+		"kernel!=5.14":    false,
+		"kernel<6":        true,
+		"kernel>=5.14.22": false,
+	} {
+		cond, err := ParseSectionCondition(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cond.Matches(release) != expected {
+			t.Errorf("'%s' for kernel '%s': expected %v", condition, release, expected)
+		}
+	}
+}
+
+func TestParseINIConditionalSection(t *testing.T) {
+	ini := ParseINI("[sysctl]\nvm.swappiness = 10\n[sysctl:kernel>=5.3]\nkernel.sched_migration_cost_ns = 500000\n[sysctl : kernel<5.3]\nkernel.sched_latency_ns = 24000000\n")
+	if len(ini.AllValues) != 3 {
+		t.Fatalf("%+v", ini.AllValues)
+	}
+	for idx, expected := range []string{"", "kernel>=5.3", "kernel<5.3"} {
+		if ini.AllValues[idx].Section != "sysctl" || ini.AllValues[idx].Condition != expected {
+			t.Errorf("%+v", ini.AllValues[idx])
+		}
+	}
+	// the entries of the conditional sections are kept apart
+	if len(ini.KeyValue["sysctl"]) != 1 || len(ini.KeyValue["sysctl:kernel>=5.3"]) != 1 || ini.KeyValue["sysctl:kernel>=5.3"]["kernel.sched_migration_cost_ns"].Condition != "kernel>=5.3" || ini.KeyValue["sysctl:kernel<5.3"]["kernel.sched_latency_ns"].SectionKey() != "sysctl:kernel<5.3" {
+		t.Fatalf("%+v", ini.KeyValue)
+	}
+	// a section occurring twice with the same condition replaces the
+	// previous one
+	ini = ParseINI("[sysctl:kernel>=5.3]\nvm.swappiness = 10\nvm.dirty_ratio = 10\n[sysctl]\nvm.swappiness = 20\n[sysctl:kernel>=5.3]\nvm.swappiness = 30\n")
+	if len(ini.KeyValue["sysctl:kernel>=5.3"]) != 1 || ini.KeyValue["sysctl:kernel>=5.3"]["vm.swappiness"].Value != "30" || ini.KeyValue["sysctl"]["vm.swappiness"].Value != "20" {
+		t.Fatalf("%+v", ini.KeyValue)
+	}
+
+	// the entries of 'own' replace only the entries of the section with
+	// the same condition
+	base := ParseINI("[sysctl]\nvm.swappiness = 10\n[sysctl:kernel>=5.3]\nvm.swappiness = 20\n")
+	own := ParseINI("[sysctl:kernel>=5.3]\nvm.swappiness = 30\n[sysctl:kernel<5.3]\nvm.swappiness = 40\n")
+	merged := MergeINI(base, own)
+	if len(merged.AllValues) != 3 || merged.AllValues[0].Value != "10" || merged.AllValues[1].Value != "30" || merged.AllValues[2].Value != "40" {
+		t.Fatalf("%+v", merged.AllValues)
+	}
+	if merged.KeyValue["sysctl"]["vm.swappiness"].Value != "10" || merged.KeyValue["sysctl:kernel>=5.3"]["vm.swappiness"].Value != "30" || merged.KeyValue["sysctl:kernel<5.3"]["vm.swappiness"].Value != "40" {
+		t.Fatalf("%+v", merged.KeyValue)
+	}
+	if section, condition := SplitSectionName("sysctl"); section != "sysctl" || condition != "" {
+		t.Error(section, condition)
+	}
+}
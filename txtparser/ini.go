@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
 	"io/ioutil"
+	"path"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // Operator definitions
@@ -21,23 +23,48 @@ const (
 type Operator string
 
 // RegexKeyOperatorValue breaks up a line into key, operator, value.
-var RegexKeyOperatorValue = regexp.MustCompile(`([\w.+_-]+)\s*([<=>]+)\s*["']*(.*?)["']*$`)
+var RegexKeyOperatorValue = regexp.MustCompile(`([\w.+_{}-]+)\s*([<=>]+)\s*["']*(.*?)["']*$`)
 
-// counter to control the [block] section detected warning
-var blckCnt = 0
+// RegexBlockDevicePattern breaks up a line of the [block] section, which
+// restricts the parameter to the block devices matching the glob patterns
+// in brackets, into key, patterns, operator, value.
+// e.g. 'IO_SCHEDULER[sd* vd*] = bfq, none'
+var RegexBlockDevicePattern = regexp.MustCompile(`^(\w+)\[([^\]]*)\]\s*([<=>]+)\s*["']*(.*?)["']*$`)
+
+// RegexSystemdProperty breaks up a line of the [systemd] section, which
+// names the unit and the property separated by a colon, into unit,
+// property, operator, value.
+// e.g. 'sapinit.service:LimitNOFILE = 1048576'
+var RegexSystemdProperty = regexp.MustCompile(`^([^\s:=<>]+):(\w+)\s*([<=>]+)\s*["']*(.*?)["']*$`)
+
+// print the [block] section detected warning only once, even if several
+// Note definition files are parsed at the same time
+var blckWarning sync.Once
 
 // INIEntry contains a single key-value pair in INI file.
+// Condition is the condition of a conditional section like
+// '[sysctl:kernel>=5.3]', Section is the name without the condition
 type INIEntry struct {
-	Section  string
-	Key      string
-	Operator Operator
-	Value    string
+	Section   string
+	Key       string
+	Operator  Operator
+	Value     string
+	Condition string
 }
 
 // INIFile contains all key-value pairs of an INI file.
+// KeyValue is indexed by the SectionKey of the sections, so the entries of
+// conditional sections are kept apart
 type INIFile struct {
 	AllValues []INIEntry
 	KeyValue  map[string]map[string]INIEntry
+	CheckOnly bool                   // a [check_only] section marks the parameters as 'verify only'
+	Tags      []string               // tags from the [tags] section, used to group notes
+	Requires  []string               // note IDs from the [requires] section, which need to be applied before
+	Includes  []string               // note IDs from the [include] section, whose definitions are inherited
+	Comments  map[string]string      // comment lines found directly above a parameter, used as explanation of the value
+	Bounds    map[string]ParamBounds // sane bounds of the parameter values from the [bounds] section
+	Severity  map[string]string      // severity of a deviation of the parameters from the [severity] section
 }
 
 // GetINIFileDescriptiveName return the descriptive name of the Note
@@ -97,25 +124,92 @@ func ParseINI(input string) *INIFile {
 
 	reminder := ""
 	currentSection := ""
+	currentCondition := ""
 	currentEntriesArray := make([]INIEntry, 0, 8)
 	currentEntriesMap := make(map[string]INIEntry)
+	// saveSection adds the entries of the current section. Sections with
+	// different conditions are saved separately, a section occurring
+	// several times with the same condition replaces the previous one
+	saveSection := func() {
+		if currentSection == "" {
+			return
+		}
+		for key, entry := range currentEntriesMap {
+			entry.Condition = currentCondition
+			currentEntriesMap[key] = entry
+		}
+		for idx := range currentEntriesArray {
+			currentEntriesArray[idx].Condition = currentCondition
+		}
+		ret.KeyValue[SectionKey(currentSection, currentCondition)] = currentEntriesMap
+		ret.AllValues = append(ret.AllValues, currentEntriesArray...)
+	}
+	// comment lines directly above the current line
+	comment := make([]string, 0)
 	for _, line := range strings.Split(input, "\n") {
 		line = strings.TrimSpace(line)
 		if len(line) == 0 {
+			comment = comment[:0]
 			continue
 		}
 		if line[0] == '[' {
+			comment = comment[:0]
 			// Save previous section
-			if currentSection != "" {
-				ret.KeyValue[currentSection] = currentEntriesMap
-				ret.AllValues = append(ret.AllValues, currentEntriesArray...)
-			}
+			saveSection()
 			// Start a new section
-			currentSection = line[1 : len(line)-1]
+			currentSection, currentCondition = SplitSectionName(line[1 : len(line)-1])
+			if currentSection == "check_only" {
+				ret.CheckOnly = true
+			}
 			currentEntriesArray = make([]INIEntry, 0, 8)
 			currentEntriesMap = make(map[string]INIEntry)
 			continue
 		}
+		if currentSection == "tags" && !strings.HasPrefix(line, "#") {
+			// tags are separated by blanks, no key=value pairs
+			ret.Tags = append(ret.Tags, strings.Fields(line)...)
+			continue
+		}
+		if currentSection == "requires" && !strings.HasPrefix(line, "#") {
+			// note IDs are separated by blanks, no key=value pairs
+			ret.Requires = append(ret.Requires, strings.Fields(line)...)
+			continue
+		}
+		if currentSection == "include" && !strings.HasPrefix(line, "#") {
+			// note IDs are separated by blanks, no key=value pairs
+			ret.Includes = append(ret.Includes, strings.Fields(line)...)
+			continue
+		}
+		if currentSection == "bounds" && !strings.HasPrefix(line, "#") {
+			// bounds of the parameter values, no tunables
+			if kov := RegexKeyOperatorValue.FindStringSubmatch(line); kov != nil {
+				if bounds, err := ParseBounds(kov[3]); err == nil {
+					if ret.Bounds == nil {
+						ret.Bounds = make(map[string]ParamBounds)
+					}
+					ret.Bounds[kov[1]] = bounds
+				} else {
+					system.WarningLog("skip bounds of parameter '%s': %v", kov[1], err)
+				}
+			}
+			comment = comment[:0]
+			continue
+		}
+		if currentSection == "severity" && !strings.HasPrefix(line, "#") {
+			// severity of a deviation of the parameters, no tunables
+			if kov := RegexKeyOperatorValue.FindStringSubmatch(line); kov != nil {
+				if severity, err := ParseSeverity(kov[3]); err == nil {
+					if ret.Severity == nil {
+						ret.Severity = make(map[string]string)
+					}
+					ret.Severity[kov[1]] = severity
+				} else {
+					system.WarningLog("skip severity of parameter '%s': %v", kov[1], err)
+				}
+			}
+			comment = comment[:0]
+			continue
+		}
 		if strings.HasPrefix(line, "#") {
 			// Skip comments. Need to be done before
 			// 'break apart the line into key, operator, value'
@@ -124,10 +218,14 @@ func ParseINI(input string) *INIFile {
 			if currentSection == "reminder" {
 				reminder = reminder + line + "\n"
 			}
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(line, "#")))
 			continue
 		}
 		// Break apart a line into key, operator, value.
 		kov := make([]string, 0)
+		// glob patterns of the block devices a [block] parameter is
+		// restricted to
+		var devPatterns []string
 		if currentSection == "rpm" {
 			fields := strings.Fields(line)
 			if fields[1] == "all" || fields[1] == system.GetOsVers() {
@@ -135,6 +233,16 @@ func ParseINI(input string) *INIFile {
 			} else {
 				kov = nil
 			}
+		} else if currentSection == "block" && RegexBlockDevicePattern.MatchString(line) {
+			kopv := RegexBlockDevicePattern.FindStringSubmatch(line)
+			kov = []string{kopv[0], kopv[1], kopv[3], kopv[4]}
+			devPatterns = strings.Fields(kopv[2])
+		} else if currentSection == "systemd" {
+			if kupv := RegexSystemdProperty.FindStringSubmatch(line); kupv != nil {
+				kov = []string{kupv[0], kupv[1] + ":" + kupv[2], kupv[3], kupv[4]}
+			} else {
+				kov = nil
+			}
 		} else {
 			kov = RegexKeyOperatorValue.FindStringSubmatch(line)
 			if currentSection == "grub" {
@@ -149,8 +257,10 @@ func ParseINI(input string) *INIFile {
 		}
 		if kov == nil {
 			// Skip comments, empty, and irregular lines.
+			comment = comment[:0]
 			continue
 		}
+		entryStart := len(currentEntriesArray)
 		if currentSection == "limits" {
 			for _, limits := range strings.Split(kov[3], ",") {
 				limits = strings.TrimSpace(limits)
@@ -175,14 +285,17 @@ func ParseINI(input string) *INIFile {
 				currentEntriesMap[entry.Key] = entry
 			}
 		} else if currentSection == "block" {
-			if blckCnt == 0 {
+			blckWarning.Do(func() {
 				system.WarningLog("[block] section detected: Traversing all block devices can take a considerable amount of time.")
-				blckCnt = blckCnt + 1
-			}
+			})
 			// identify virtio block devices
 			isVD := regexp.MustCompile(`^vd\w+$`)
 			_, sysDevs := system.ListDir("/sys/block", "the available block devices of the system")
+			matched := false
 			for _, bdev := range sysDevs {
+				if devPatterns != nil && !matchesDevicePattern(bdev, devPatterns) {
+					continue
+				}
 				// /sys/block/*/device/type (TYPE_DISK / 0x00)
 				// does not work for virtio block devices
 				fname := fmt.Sprintf("/sys/block/%s/device/type", bdev)
@@ -205,6 +318,21 @@ func ParseINI(input string) *INIFile {
 				}
 				currentEntriesArray = append(currentEntriesArray, entry)
 				currentEntriesMap[entry.Key] = entry
+				matched = true
+			}
+			if devPatterns != nil && !matched {
+				// keep the parameter with the patterns as device
+				// name to report it as 'not available' instead
+				// of silently dropping it
+				system.InfoLog("no block device matches the pattern '%s' of parameter '%s'", strings.Join(devPatterns, " "), kov[1])
+				entry := INIEntry{
+					Section:  currentSection,
+					Key:      fmt.Sprintf("%s_[%s]", kov[1], strings.Join(devPatterns, " ")),
+					Operator: Operator(kov[2]),
+					Value:    kov[3],
+				}
+				currentEntriesArray = append(currentEntriesArray, entry)
+				currentEntriesMap[entry.Key] = entry
 			}
 		} else {
 			// handle tunables with more than one value
@@ -218,18 +346,26 @@ func ParseINI(input string) *INIFile {
 			currentEntriesArray = append(currentEntriesArray, entry)
 			currentEntriesMap[entry.Key] = entry
 		}
+		if len(comment) != 0 {
+			// the comment explains all entries of the line
+			if ret.Comments == nil {
+				ret.Comments = make(map[string]string)
+			}
+			for _, entry := range currentEntriesArray[entryStart:] {
+				ret.Comments[entry.Key] = strings.Join(comment, "\n")
+			}
+			comment = comment[:0]
+		}
 	}
 	if reminder != "" {
 		// save reminder section
 		// Save previous section
-		if currentSection != "" {
-			ret.KeyValue[currentSection] = currentEntriesMap
-			ret.AllValues = append(ret.AllValues, currentEntriesArray...)
-		}
+		saveSection()
 		// Start the reminder section
 		currentEntriesArray = make([]INIEntry, 0, 8)
 		currentEntriesMap = make(map[string]INIEntry)
 		currentSection = "reminder"
+		currentCondition = ""
 
 		entry := INIEntry{
 			Section:  "reminder",
@@ -242,9 +378,100 @@ func ParseINI(input string) *INIFile {
 	}
 
 	// Save last section
-	if currentSection != "" {
-		ret.KeyValue[currentSection] = currentEntriesMap
-		ret.AllValues = append(ret.AllValues, currentEntriesArray...)
+	saveSection()
+	return ret
+}
+
+// matchesDevicePattern returns true, if the name of the block device matches
+// one of the glob patterns
+func matchesDevicePattern(bdev string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if match, _ := path.Match(pattern, bdev); match {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeINI returns the content of the INI file 'own' layered on top of the
+// content of the INI file 'base'. Entries of 'own' replace the entries of
+// 'base' with the same section, condition and key at their position, all
+// other entries of 'own' are appended. Tags and required note IDs are combined, the
+// bounds and severities of 'own' replace the ones of 'base' with the same
+// key, the [check_only] and [include] sections are taken from 'own' only.
+// The comments of replaced entries are dropped, as they do not explain the
+// values of 'own'
+func MergeINI(base, own *INIFile) *INIFile {
+	ret := &INIFile{
+		AllValues: make([]INIEntry, 0, len(base.AllValues)+len(own.AllValues)),
+		KeyValue:  make(map[string]map[string]INIEntry),
+		CheckOnly: own.CheckOnly,
+		Includes:  own.Includes,
+	}
+	for _, ini := range []*INIFile{base, own} {
+		for section, entries := range ini.KeyValue {
+			if ret.KeyValue[section] == nil {
+				ret.KeyValue[section] = make(map[string]INIEntry)
+			}
+			for key, entry := range entries {
+				ret.KeyValue[section][key] = entry
+			}
+		}
+		if ini == own {
+			for _, entries := range own.KeyValue {
+				for key := range entries {
+					delete(ret.Comments, key)
+				}
+			}
+		}
+		for key, comment := range ini.Comments {
+			if ret.Comments == nil {
+				ret.Comments = make(map[string]string)
+			}
+			ret.Comments[key] = comment
+		}
+		for key, bounds := range ini.Bounds {
+			if ret.Bounds == nil {
+				ret.Bounds = make(map[string]ParamBounds)
+			}
+			ret.Bounds[key] = bounds
+		}
+		for key, severity := range ini.Severity {
+			if ret.Severity == nil {
+				ret.Severity = make(map[string]string)
+			}
+			ret.Severity[key] = severity
+		}
+		ret.Tags = appendUnique(ret.Tags, ini.Tags)
+		ret.Requires = appendUnique(ret.Requires, ini.Requires)
+	}
+	for _, entry := range base.AllValues {
+		if ownEntry, ok := own.KeyValue[entry.SectionKey()][entry.Key]; ok {
+			entry = ownEntry
+		}
+		ret.AllValues = append(ret.AllValues, entry)
+	}
+	for _, entry := range own.AllValues {
+		if _, ok := base.KeyValue[entry.SectionKey()][entry.Key]; !ok {
+			ret.AllValues = append(ret.AllValues, entry)
+		}
 	}
 	return ret
 }
+
+// appendUnique appends the words, which are not yet part of list
+func appendUnique(list, words []string) []string {
+	for _, word := range words {
+		found := false
+		for _, elem := range list {
+			if elem == word {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, word)
+		}
+	}
+	return list
+}
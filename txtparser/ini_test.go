@@ -242,3 +242,170 @@ func TestGetINIFileVersionSectionEntry(t *testing.T) {
 		t.Fatalf("\n'%+v'\nis not\n'%+v'\n", str, "")
 	}
 }
+
+func TestParseINICheckOnly(t *testing.T) {
+	checkINI := ParseINI("[version]\n# SAP-NOTE=4711 VERSION=1 DATE=01.01.2020 NAME=\"check only\"\n\n[check_only]\n\n[sysctl]\nvm.swappiness = 10\n")
+	if !checkINI.CheckOnly {
+		t.Fatal("[check_only] section not detected")
+	}
+	if len(checkINI.AllValues) != 1 || checkINI.AllValues[0].Key != "vm.swappiness" {
+		t.Fatalf("%+v", checkINI.AllValues)
+	}
+	if ParseINI(iniExample).CheckOnly {
+		t.Fatal("unexpected [check_only] section detected")
+	}
+}
+
+func TestParseINITags(t *testing.T) {
+	tagINI := ParseINI("[tags]\n# tags of the note\nHANA production\nS4\n\n[sysctl]\nvm.swappiness = 10\n")
+	if !reflect.DeepEqual(tagINI.Tags, []string{"HANA", "production", "S4"}) {
+		t.Fatalf("%+v", tagINI.Tags)
+	}
+	if len(tagINI.AllValues) != 1 || tagINI.AllValues[0].Key != "vm.swappiness" {
+		t.Fatalf("%+v", tagINI.AllValues)
+	}
+	if len(ParseINI(iniExample).Tags) != 0 {
+		t.Fatal("unexpected tags detected")
+	}
+}
+
+func TestParseINIBounds(t *testing.T) {
+	boundsINI := ParseINI("[bounds]\n# sane values\nvm.dirty_ratio = 5:40\nvm.swappiness = :100\nvm.wrong = 40:5\n\n[sysctl]\nvm.swappiness = 10\n")
+	if !reflect.DeepEqual(boundsINI.Bounds, map[string]ParamBounds{"vm.dirty_ratio": {"5", "40"}, "vm.swappiness": {"", "100"}}) {
+		t.Fatalf("%+v", boundsINI.Bounds)
+	}
+	if len(boundsINI.AllValues) != 1 || boundsINI.AllValues[0].Key != "vm.swappiness" || len(boundsINI.Comments) != 0 {
+		t.Fatalf("%+v", boundsINI)
+	}
+	merged := MergeINI(boundsINI, ParseINI("[bounds]\nvm.dirty_ratio = 10:20\n"))
+	if !reflect.DeepEqual(merged.Bounds, map[string]ParamBounds{"vm.dirty_ratio": {"10", "20"}, "vm.swappiness": {"", "100"}}) {
+		t.Fatalf("%+v", merged.Bounds)
+	}
+	if len(ParseINI(iniExample).Bounds) != 0 {
+		t.Fatal("unexpected bounds detected")
+	}
+}
+
+func TestParseINISeverity(t *testing.T) {
+	severityINI := ParseINI("[severity]\n# not important\nvm.swappiness = info\nvm.dirty_ratio = Warning\nvm.wrong = fatal\n\n[sysctl]\nvm.swappiness = 10\n")
+	if !reflect.DeepEqual(severityINI.Severity, map[string]string{"vm.swappiness": SeverityInfo, "vm.dirty_ratio": SeverityWarning}) {
+		t.Fatalf("%+v", severityINI.Severity)
+	}
+	if len(severityINI.AllValues) != 1 || severityINI.AllValues[0].Key != "vm.swappiness" || len(severityINI.Comments) != 0 {
+		t.Fatalf("%+v", severityINI)
+	}
+	merged := MergeINI(severityINI, ParseINI("[severity]\nvm.dirty_ratio = critical\n"))
+	if !reflect.DeepEqual(merged.Severity, map[string]string{"vm.swappiness": SeverityInfo, "vm.dirty_ratio": SeverityCritical}) {
+		t.Fatalf("%+v", merged.Severity)
+	}
+	if len(ParseINI(iniExample).Severity) != 0 {
+		t.Fatal("unexpected severity detected")
+	}
+}
+
+func TestParseINIRequires(t *testing.T) {
+	reqINI := ParseINI("[requires]\n# notes applied before\n1980196 2205917\n[sysctl]\nvm.swappiness = 10\n")
+	if !reflect.DeepEqual(reqINI.Requires, []string{"1980196", "2205917"}) {
+		t.Fatalf("%+v", reqINI.Requires)
+	}
+	if len(reqINI.AllValues) != 1 || reqINI.AllValues[0].Key != "vm.swappiness" {
+		t.Fatalf("%+v", reqINI.AllValues)
+	}
+	if len(ParseINI(iniExample).Requires) != 0 {
+		t.Fatal("unexpected requirements detected")
+	}
+}
+
+func TestParseINIComments(t *testing.T) {
+	commentINI := ParseINI("[sysctl]\n# keep the swap usage low\n# as recommended by SAP\nvm.swappiness = 10\n\n# not directly above\n\nkernel.shmmni = 32768\n[grub]\n# disable NUMA balancing\nnuma_balancing=disable\n")
+	expected := map[string]string{
+		"vm.swappiness":       "keep the swap usage low\nas recommended by SAP",
+		"grub:numa_balancing": "disable NUMA balancing",
+	}
+	if !reflect.DeepEqual(commentINI.Comments, expected) {
+		t.Fatalf("%+v", commentINI.Comments)
+	}
+	if ParseINI(iniExample).Comments != nil {
+		t.Fatal("unexpected comments detected")
+	}
+}
+
+func TestParseINIIncludes(t *testing.T) {
+	incINI := ParseINI("[include]\n# inherit the definitions of\n1980196 2205917\n[sysctl]\nvm.swappiness = 10\n")
+	if !reflect.DeepEqual(incINI.Includes, []string{"1980196", "2205917"}) {
+		t.Fatalf("%+v", incINI.Includes)
+	}
+	if len(incINI.AllValues) != 1 || incINI.AllValues[0].Key != "vm.swappiness" {
+		t.Fatalf("%+v", incINI.AllValues)
+	}
+	if len(ParseINI(iniExample).Includes) != 0 {
+		t.Fatal("unexpected includes detected")
+	}
+}
+
+func TestParseINISystemd(t *testing.T) {
+	sdINI := ParseINI("[systemd]\nsapinit.service:LimitNOFILE = 1048576\nuuidd.socket:Backlog=\"128\"\nno property = 1\n")
+	expected := []INIEntry{
+		{Section: "systemd", Key: "sapinit.service:LimitNOFILE", Operator: OperatorEqual, Value: "1048576"},
+		{Section: "systemd", Key: "uuidd.socket:Backlog", Operator: OperatorEqual, Value: "128"},
+	}
+	if !reflect.DeepEqual(sdINI.AllValues, expected) {
+		t.Fatalf("%+v", sdINI.AllValues)
+	}
+	if _, ok := sdINI.KeyValue["systemd"]["sapinit.service:LimitNOFILE"]; !ok {
+		t.Fatalf("%+v", sdINI.KeyValue)
+	}
+}
+
+func TestMergeINI(t *testing.T) {
+	base := ParseINI("[tags]\nHANA\n[sysctl]\n# base comment\nvm.swappiness = 60\n# shmmni comment\nkernel.shmmni = 4096\n# shmmax comment\nkernel.shmmax = 1024\n[grub]\nnuma_balancing=disable\n")
+	own := ParseINI("[include]\n4711\n[tags]\nHANA S4\n[check_only]\n[sysctl]\nvm.swappiness = 10\nvm.max_map_count = 2147483647\n# own comment\nkernel.shmmax = 2048\n")
+	merged := MergeINI(base, own)
+	keys := make([]string, 0)
+	for _, entry := range merged.AllValues {
+		keys = append(keys, entry.Key+"="+entry.Value)
+	}
+	expected := []string{"vm.swappiness=10", "kernel.shmmni=4096", "kernel.shmmax=2048", "grub:numa_balancing=disable", "vm.max_map_count=2147483647"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("\n'%+v'\nis not\n'%+v'\n", keys, expected)
+	}
+	if merged.KeyValue["sysctl"]["vm.swappiness"].Value != "10" || merged.KeyValue["sysctl"]["kernel.shmmni"].Value != "4096" {
+		t.Fatalf("%+v", merged.KeyValue)
+	}
+	if !reflect.DeepEqual(merged.Tags, []string{"HANA", "S4"}) {
+		t.Fatalf("%+v", merged.Tags)
+	}
+	if !merged.CheckOnly || !reflect.DeepEqual(merged.Includes, []string{"4711"}) {
+		t.Fatalf("%+v", merged)
+	}
+	// the comments of replaced entries are dropped
+	if !reflect.DeepEqual(merged.Comments, map[string]string{"kernel.shmmni": "shmmni comment", "kernel.shmmax": "own comment"}) {
+		t.Fatalf("%+v", merged.Comments)
+	}
+}
+
+func TestParseINIBlockDevicePattern(t *testing.T) {
+	blkINI := ParseINI("[block]\nIO_SCHEDULER[saptune_no_dev* saptune_none?] = bfq, none\n")
+	if len(blkINI.AllValues) != 1 {
+		t.Fatalf("%+v", blkINI.AllValues)
+	}
+	entry := blkINI.AllValues[0]
+	if entry.Key != "IO_SCHEDULER_[saptune_no_dev* saptune_none?]" || entry.Value != "bfq, none" || entry.Operator != "=" {
+		t.Fatalf("%+v", entry)
+	}
+	if _, ok := blkINI.KeyValue["block"][entry.Key]; !ok {
+		t.Fatalf("%+v", blkINI.KeyValue)
+	}
+}
+
+func TestMatchesDevicePattern(t *testing.T) {
+	if !matchesDevicePattern("sda", []string{"nvme*", "sd*"}) {
+		t.Error("sda does not match 'sd*'")
+	}
+	if !matchesDevicePattern("nvme0n1", []string{"nvme?n1"}) {
+		t.Error("nvme0n1 does not match 'nvme?n1'")
+	}
+	if matchesDevicePattern("vda", []string{"sd*", "nvme*"}) || matchesDevicePattern("sda", []string{"sd[b-z"}) {
+		t.Error("unexpected match")
+	}
+}
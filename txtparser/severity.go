@@ -0,0 +1,36 @@
+package txtparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity definitions of the parameters in the [severity] section
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// ParseSeverity checks the severity of a parameter given in the [severity]
+// section and returns it in lower case
+func ParseSeverity(value string) (string, error) {
+	severity := strings.ToLower(strings.TrimSpace(value))
+	switch severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return severity, nil
+	}
+	return "", fmt.Errorf("wrong severity '%s', expected '%s', '%s' or '%s'", value, SeverityInfo, SeverityWarning, SeverityCritical)
+}
+
+// SeverityRank returns the rank of the severity to find the highest
+// severity. An unknown severity is ranked like 'critical'
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	}
+	return 3
+}
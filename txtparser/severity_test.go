@@ -0,0 +1,22 @@
+package txtparser
+
+import (
+	"testing"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for value, expected := range map[string]string{"info": SeverityInfo, " Warning ": SeverityWarning, "CRITICAL": SeverityCritical} {
+		severity, err := ParseSeverity(value)
+		if err != nil || severity != expected {
+			t.Errorf("'%s': got '%s', '%v'", value, severity, err)
+		}
+	}
+	for _, value := range []string{"", "error", "info warning"} {
+		if _, err := ParseSeverity(value); err == nil {
+			t.Errorf("'%s': expected an error", value)
+		}
+	}
+	if SeverityRank(SeverityInfo) >= SeverityRank(SeverityWarning) || SeverityRank(SeverityWarning) >= SeverityRank(SeverityCritical) || SeverityRank("") != SeverityRank(SeverityCritical) {
+		t.Error("wrong order of the severities")
+	}
+}